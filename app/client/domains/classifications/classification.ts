@@ -1,7 +1,15 @@
-/** A stage in the classification workflow pipeline. */
-export type WorkflowStage = "init" | "classify" | "enhance" | "finalize";
+/**
+ * A stage in the classification workflow pipeline. `escalate` runs only for
+ * low-confidence results when an escalation agent is configured.
+ */
+export type WorkflowStage =
+  | "init"
+  | "classify"
+  | "enhance"
+  | "finalize"
+  | "escalate";
 
-/** Ordered list of all classification workflow stages. */
+/** Ordered list of the classification workflow stages every run passes through. */
 export const WORKFLOW_STAGES: readonly WorkflowStage[] = [
   "init",
   "classify",
@@ -22,12 +30,70 @@ export interface SearchRequest {
   confidence?: string;
   document_id?: string;
   validated_by?: string;
+  marking?: string;
+  level?: string;
+  sci?: string;
+  dissemination?: string;
+  discrepant?: boolean;
+  partial?: boolean;
+  agent_name?: string;
+  escalated?: boolean;
+}
+
+/**
+ * Structured CAPCO-style marking normalized from a classification string.
+ * Mirrors Go `markings.Marking` struct.
+ */
+export interface ClassificationMarking {
+  canonical: string;
+  level: string;
+  sci: string[];
+  sap: string[];
+  dissemination: string[];
+  releasable_to: string[];
+  declassification?: string;
+}
+
+/**
+ * Model calls and tokens consumed by a classification run.
+ * Mirrors Go `state.Usage` struct.
+ */
+export interface ClassificationUsage {
+  vision_calls: number;
+  chat_calls: number;
+  prompt_tokens: number;
+  completion_tokens: number;
+}
+
+/**
+ * Low-confidence answer that was replaced by classifying the document again
+ * with the escalation agent. `agent`, `model`, and `provider` name the agent
+ * escalated to; the rest is the replaced answer and the usage it cost.
+ * Mirrors Go `state.Escalation` struct.
+ */
+export interface ClassificationEscalation {
+  agent: string;
+  model: string;
+  provider: string;
+  classification: string;
+  confidence: string;
+  rationale: string;
+  failed_pages?: number[];
+  usage: ClassificationUsage;
 }
 
 /**
  * Classification result for a document.
  * Mirrors Go `classifications.Classification` struct.
  * Validation fields are omitted when the classification has not been validated.
+ * `marking` is null when the classification did not parse; `marking_error` says why.
+ * `discrepancy` is set when the workflow's answer disagreed with the `roll_up`
+ * of page markings. `usage` totals the model calls of the producing run.
+ * `failed_pages` lists pages the workflow could not analyze; it is empty for
+ * a complete result. `agent_name` is the configured agent that produced it;
+ * `agreement` is set for ensemble runs as the share of agents that reached
+ * the same marking. `escalation` is set when a low-confidence first answer
+ * was replaced by the escalation agent's.
  */
 export interface Classification {
   id: string;
@@ -41,4 +107,259 @@ export interface Classification {
   provider_name: string;
   validated_by?: string;
   validated_at?: string;
+  marking: ClassificationMarking | null;
+  marking_error: string | null;
+  roll_up: string | null;
+  discrepancy: string | null;
+  failed_pages: number[];
+  usage: ClassificationUsage;
+  agent_name: string | null;
+  agreement: number | null;
+  escalation: ClassificationEscalation | null;
+}
+
+/**
+ * One agent's answer from the run that produced a classification.
+ * `primary` marks the agent whose answer is the classification; `agrees`
+ * whether this agent reached the same marking. `error` is set when the
+ * agent's run failed. `escalated_to` names the escalation agent on a first
+ * answer that escalation replaced. Mirrors Go `classifications.Result` struct.
+ */
+export interface ClassificationResult {
+  id: string;
+  classification_id: string;
+  document_id: string;
+  job_id: string;
+  agent_name: string;
+  model_name: string;
+  provider_name: string;
+  primary: boolean;
+  classification: string;
+  confidence: string;
+  rationale: string;
+  marking: string | null;
+  agrees: boolean;
+  failed_pages: number[];
+  error: string | null;
+  usage: ClassificationUsage;
+  created_at: string;
+  escalated_to: string | null;
+}
+
+/**
+ * Configured agent that classify requests can name. `escalation` marks the
+ * agent low-confidence results are escalated to.
+ * Mirrors Go `classifications.Agent` struct.
+ */
+export interface ClassificationAgent {
+  name: string;
+  model_name: string;
+  provider_name: string;
+  default: boolean;
+  escalation: boolean;
+}
+
+/**
+ * Image adjustments applied when a page is re-rendered by the enhance stage.
+ * Mirrors Go `state.EnhanceSettings`.
+ */
+export interface EnhanceSettings {
+  brightness?: number;
+  contrast?: number;
+  saturation?: number;
+}
+
+/**
+ * Per-page findings stored with a classification.
+ * `error` is set when the page could not be analyzed. `text_only` is set
+ * when the page was classified from its text layer without a vision call,
+ * and `banners_only` when it was classified from its banner crop alone.
+ * Mirrors Go `classifications.Page` struct.
+ */
+export interface ClassificationPage {
+  id: string;
+  classification_id: string;
+  page_number: number;
+  markings_found: string[];
+  rationale: string;
+  enhanced: boolean;
+  enhance_settings: EnhanceSettings | null;
+  error: string | null;
+  usage: ClassificationUsage;
+  text_only: boolean;
+  banners_only: boolean;
+}
+
+/** Lifecycle state of a classification job. */
+export type JobStatus = "queued" | "running" | "succeeded" | "failed";
+
+/**
+ * Durable background request to classify a document.
+ * `agents` names the agents it classifies with, the first being primary;
+ * empty means the default agent.
+ * Mirrors Go `classifications.Job` struct.
+ */
+export interface ClassificationJob {
+  id: string;
+  document_id: string;
+  batch_id?: string;
+  status: JobStatus;
+  attempts: number;
+  max_attempts: number;
+  last_error?: string;
+  classification_id?: string;
+  run_after: string;
+  created_at: string;
+  started_at?: string;
+  completed_at?: string;
+  updated_at: string;
+  agents: string[];
+}
+
+/**
+ * Pagination and filter criteria for classification job queries.
+ * Mirrors Go `classifications.JobFilters` plus page parameters.
+ */
+export interface JobSearchRequest {
+  page?: number;
+  page_size?: number;
+  sort?: string;
+  document_id?: string;
+  batch_id?: string;
+  status?: JobStatus;
+}
+
+/**
+ * Document criteria selecting which documents a bulk classification queues.
+ * Mirrors Go `documents.Filters`.
+ */
+export interface BulkFilters {
+  status?: string;
+  filename?: string;
+  external_id?: number;
+  external_platform?: string;
+  content_type?: string;
+  storage_key?: string;
+  classification?: string;
+  confidence?: string;
+}
+
+/**
+ * Group of classification jobs queued by a single bulk request, with job
+ * counts by status. Mirrors Go `classifications.Batch` struct.
+ */
+export interface ClassificationBatch {
+  id: string;
+  filters: BulkFilters;
+  total: number;
+  skipped: number;
+  queued: number;
+  running: number;
+  succeeded: number;
+  failed: number;
+  created_at: string;
+}
+
+/** Change that produced a classification revision. */
+export type RevisionAction = "classify" | "validate" | "update";
+
+/**
+ * Prompt in effect for a workflow stage when a revision was recorded.
+ * `prompt_id` and `name` are null when the stage used its defaults.
+ * Mirrors Go `prompts.Version` struct.
+ */
+export interface PromptVersion {
+  stage: "classify" | "enhance" | "finalize";
+  prompt_id: string | null;
+  name: string | null;
+  digest: string;
+}
+
+/**
+ * Mutable fields of a classification captured by a revision.
+ * Mirrors Go `classifications.Snapshot` struct.
+ */
+export interface ClassificationSnapshot {
+  classification: string;
+  confidence: string;
+  markings_found: string[];
+  rationale: string;
+  validated_by: string | null;
+  validated_at: string | null;
+}
+
+/**
+ * Immutable record of a single change to a classification.
+ * Mirrors Go `classifications.Revision` struct.
+ */
+export interface ClassificationRevision {
+  id: string;
+  classification_id: string;
+  document_id: string;
+  revision: number;
+  action: RevisionAction;
+  actor: string;
+  model_name: string;
+  provider_name: string;
+  prompts: PromptVersion[] | null;
+  usage: ClassificationUsage;
+  before: ClassificationSnapshot | null;
+  after: ClassificationSnapshot;
+  created_at: string;
+}
+
+/** Single field that differs between two revisions. */
+export interface FieldChange {
+  field: string;
+  from: unknown;
+  to: unknown;
+}
+
+/**
+ * Field-level differences between two revisions of a classification.
+ * Mirrors Go `classifications.RevisionDiff` struct.
+ */
+export interface RevisionDiff {
+  classification_id: string;
+  from: number;
+  to: number;
+  changes: FieldChange[];
+}
+
+/**
+ * Criteria narrowing the usage report. Dates are `YYYY-MM-DD`, inclusive.
+ * Mirrors Go `classifications.UsageFilters`.
+ */
+export interface UsageFilters {
+  from?: string;
+  to?: string;
+  model_name?: string;
+  external_platform?: string;
+}
+
+/**
+ * Usage of the classify runs of one day, model, and external platform.
+ * `cost` is null when the model has no configured price.
+ * Mirrors Go `classifications.UsageRow` struct.
+ */
+export interface UsageRow {
+  day: string;
+  model_name: string;
+  external_platform: string;
+  runs: number;
+  usage: ClassificationUsage;
+  cost: number | null;
+}
+
+/**
+ * Model usage and cost of classify runs.
+ * Mirrors Go `classifications.UsageReport` struct.
+ */
+export interface UsageReport {
+  currency: string;
+  rows: UsageRow[];
+  runs: number;
+  usage: ClassificationUsage;
+  cost: number;
+  unpriced_models: string[];
 }
@@ -1,4 +1,29 @@
 export { WORKFLOW_STAGES } from "./classification";
-export type { Classification, SearchRequest, WorkflowStage } from "./classification";
+export type {
+  BulkFilters,
+  Classification,
+  ClassificationAgent,
+  ClassificationBatch,
+  ClassificationEscalation,
+  ClassificationJob,
+  ClassificationMarking,
+  ClassificationPage,
+  ClassificationResult,
+  ClassificationRevision,
+  ClassificationSnapshot,
+  ClassificationUsage,
+  EnhanceSettings,
+  FieldChange,
+  JobSearchRequest,
+  JobStatus,
+  PromptVersion,
+  RevisionAction,
+  RevisionDiff,
+  SearchRequest,
+  UsageFilters,
+  UsageReport,
+  UsageRow,
+  WorkflowStage,
+} from "./classification";
 export { ClassificationService } from "./service";
-export type { ValidateCommand, UpdateCommand } from "./service";
+export type { JobWatchOptions, ValidateCommand, UpdateCommand } from "./service";
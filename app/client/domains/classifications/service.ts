@@ -1,7 +1,21 @@
 import { request, stream, toQueryString } from "@core";
 import type { PageResult, Result, StreamOptions } from "@core";
 
-import type { Classification, SearchRequest } from "./classification";
+import type {
+  BulkFilters,
+  Classification,
+  ClassificationAgent,
+  ClassificationBatch,
+  ClassificationJob,
+  ClassificationPage,
+  ClassificationResult,
+  ClassificationRevision,
+  JobSearchRequest,
+  RevisionDiff,
+  SearchRequest,
+  UsageFilters,
+  UsageReport,
+} from "./classification";
 
 /** Payload for marking a classification as validated. */
 export interface ValidateCommand {
@@ -15,12 +29,31 @@ export interface UpdateCommand {
   updated_by: string;
 }
 
+/**
+ * Callbacks for following a classification job until it reaches a terminal state.
+ * `onUpdate` fires after every poll; exactly one of `onComplete` or `onError` fires last.
+ */
+export interface JobWatchOptions {
+  onUpdate?: (job: ClassificationJob) => void;
+  onComplete: (job: ClassificationJob) => void;
+  onError: (error: string) => void;
+  interval?: number;
+}
+
 const base = "/classifications";
 
+const DEFAULT_WATCH_INTERVAL = 2000;
+
+/** Repeated `agent` query parameters selecting the agents of a classify request. */
+function agentQuery(agents: string[] = []): string {
+  const params = agents.map((a) => `agent=${encodeURIComponent(a)}`);
+  return params.length > 0 ? `?${params.join("&")}` : "";
+}
+
 /**
  * Stateless API wrapper mirroring the Go classifications handler.
- * Request-response methods return {@link Result}. The `classify` streaming
- * method returns an {@link AbortController} for cancellation.
+ * Request-response methods return {@link Result}. The `events` streaming and
+ * `watchJob` polling methods return an {@link AbortController} for cancellation.
  */
 export const ClassificationService = {
   /** `GET /api/classifications` — paginated classification list. */
@@ -42,6 +75,63 @@ export const ClassificationService = {
     return await request<Classification>(`${base}/document/${documentId}`);
   },
 
+  /** `GET /api/classifications/pages/:id` — per-page findings for a classification. */
+  async pages(id: string): Promise<Result<ClassificationPage[]>> {
+    return await request<ClassificationPage[]>(`${base}/pages/${id}`);
+  },
+
+  /**
+   * `GET /api/classifications/results/:id` — each agent's answer from the
+   * run that produced a classification, primary agent first.
+   */
+  async results(id: string): Promise<Result<ClassificationResult[]>> {
+    return await request<ClassificationResult[]>(`${base}/results/${id}`);
+  },
+
+  /** `GET /api/classifications/agents` — configured agents, default first. */
+  async agents(): Promise<Result<ClassificationAgent[]>> {
+    return await request<ClassificationAgent[]>(`${base}/agents`);
+  },
+
+  /** `GET /api/classifications/history/:id` — revisions of a classification, oldest first. */
+  async history(id: string): Promise<Result<ClassificationRevision[]>> {
+    return await request<ClassificationRevision[]>(`${base}/history/${id}`);
+  },
+
+  /** `GET /api/classifications/history/:id/diff` — field changes between two revisions. */
+  async diff(id: string, from: number, to: number): Promise<Result<RevisionDiff>> {
+    return await request<RevisionDiff>(
+      `${base}/history/${id}/diff${toQueryString({ from, to })}`,
+    );
+  },
+
+  /** `GET /api/classifications/usage` — model usage and cost of classify runs by day. */
+  async usage(filters?: UsageFilters): Promise<Result<UsageReport>> {
+    return await request<UsageReport>(
+      `${base}/usage${filters ? toQueryString(filters) : ""}`,
+    );
+  },
+
+  /**
+   * `GET /api/classifications/document/:documentId/events` — SSE progress
+   * stream for a document's classification. Replays buffered `node.start`,
+   * `node.complete`, `complete`, and `error` events, then streams live ones.
+   * Pass the `id` of the last event received to resume after a dropped
+   * connection without replaying what was already seen.
+   */
+  events(
+    documentId: string,
+    options: StreamOptions,
+    lastEventId?: number,
+  ): AbortController {
+    const headers: Record<string, string> =
+      lastEventId !== undefined ? { "Last-Event-ID": String(lastEventId) } : {};
+
+    return stream(`${base}/document/${documentId}/events`, options, {
+      headers,
+    });
+  },
+
   /** `POST /api/classifications/search` — server-side filtered search. */
   async search(body: SearchRequest): Promise<Result<PageResult<Classification>>> {
     return await request<PageResult<Classification>>(`${base}/search`, {
@@ -52,12 +142,90 @@ export const ClassificationService = {
   },
 
   /**
-   * `POST /api/classifications/:documentId` — run the classification workflow.
-   * Returns an SSE event stream. The caller provides {@link StreamOptions}
-   * callbacks to process `node.start`, `node.complete`, `complete`, and `error` events.
+   * `POST /api/classifications/:documentId` — enqueue a classification job.
+   * Resolves with the queued job (or the document's already-active job);
+   * follow it to completion with {@link ClassificationService.watchJob}.
+   * `agents` names the agents to classify with; more than one runs an
+   * ensemble whose first agent is primary. Omit it for the default agent.
+   */
+  async classify(
+    documentId: string,
+    agents?: string[],
+  ): Promise<Result<ClassificationJob>> {
+    return await request<ClassificationJob>(
+      `${base}/${documentId}${agentQuery(agents)}`,
+      { method: "POST" },
+    );
+  },
+
+  /**
+   * `POST /api/classifications/bulk` — enqueue classification jobs for every
+   * document matching `filters`, each classifying with `agents` as for
+   * {@link ClassificationService.classify}. Resolves with the batch tracking them.
    */
-  classify(documentId: string, options: StreamOptions): AbortController {
-    return stream(`${base}/${documentId}`, options, { method: "POST" });
+  async classifyBulk(
+    filters: BulkFilters = {},
+    agents?: string[],
+  ): Promise<Result<ClassificationBatch>> {
+    return await request<ClassificationBatch>(`${base}/bulk${agentQuery(agents)}`, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(filters),
+    });
+  },
+
+  /** `GET /api/classifications/batches/:id` — batch with job counts by status. */
+  async findBatch(id: string): Promise<Result<ClassificationBatch>> {
+    return await request<ClassificationBatch>(`${base}/batches/${id}`);
+  },
+
+  /** `GET /api/classifications/jobs` — paginated classification job list. */
+  async listJobs(
+    params?: JobSearchRequest,
+  ): Promise<Result<PageResult<ClassificationJob>>> {
+    return await request<PageResult<ClassificationJob>>(
+      `${base}/jobs${params ? toQueryString(params) : ""}`,
+    );
+  },
+
+  /** `GET /api/classifications/jobs/:id` — single classification job by ID. */
+  async findJob(id: string): Promise<Result<ClassificationJob>> {
+    return await request<ClassificationJob>(`${base}/jobs/${id}`);
+  },
+
+  /**
+   * Polls a classification job until it succeeds or fails.
+   * Abort the returned controller to stop polling.
+   */
+  watchJob(id: string, options: JobWatchOptions): AbortController {
+    const controller = new AbortController();
+    const interval = options.interval ?? DEFAULT_WATCH_INTERVAL;
+
+    const poll = async () => {
+      if (controller.signal.aborted) return;
+
+      const result = await ClassificationService.findJob(id);
+      if (controller.signal.aborted) return;
+
+      if (!result.ok) {
+        options.onError(result.error);
+        return;
+      }
+
+      const job = result.data;
+      options.onUpdate?.(job);
+
+      if (job.status === "succeeded") {
+        options.onComplete(job);
+      } else if (job.status === "failed") {
+        options.onError(job.last_error ?? "Classification failed");
+      } else {
+        setTimeout(poll, interval);
+      }
+    };
+
+    poll();
+    return controller;
   },
 
   /** `POST /api/classifications/:id/validate` — mark a classification as human-validated. */
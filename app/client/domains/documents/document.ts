@@ -23,6 +23,17 @@ export interface Document {
   classified_at?: string;
 }
 
+/**
+ * Outcome of one file within a batch or archive upload. Mirrors Go
+ * `documents.BatchResult`: `document` is set on success and `error` when
+ * the file was rejected.
+ */
+export interface BatchResult {
+  filename: string;
+  document?: Document;
+  error?: string;
+}
+
 /** Pagination and filter parameters for document list and search endpoints. */
 export interface SearchRequest {
   page?: number;
@@ -1,3 +1,8 @@
-export type { Document, DocumentStatus, SearchRequest } from "./document";
+export type {
+  BatchResult,
+  Document,
+  DocumentStatus,
+  SearchRequest,
+} from "./document";
 export { DocumentService } from "./service";
 export type { UploadEntry, UploadStatus } from "./upload";
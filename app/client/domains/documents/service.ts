@@ -1,7 +1,8 @@
 import { request, toQueryString } from "@core";
 import type { PageResult, Result } from "@core";
 
-import type { Document, SearchRequest } from "./document";
+import type { BatchResult, Document, SearchRequest } from "./document";
+import type { UploadEntry } from "./upload";
 
 const base = "/documents";
 
@@ -48,6 +49,42 @@ export const DocumentService = {
     });
   },
 
+  /**
+   * `POST /api/documents/batch` — upload many documents in one request,
+   * each with its own metadata. Resolves with one result per file, in
+   * order; a rejected file does not fail the others.
+   */
+  async uploadBatch(
+    entries: Pick<UploadEntry, "file" | "externalId" | "platform">[],
+  ): Promise<Result<BatchResult[]>> {
+    const form = new FormData();
+    for (const entry of entries) {
+      form.append("file", entry.file);
+      form.append("external_id", String(entry.externalId));
+      form.append("external_platform", entry.platform);
+    }
+
+    return await request<BatchResult[]>(`${base}/batch`, {
+      method: "POST",
+      body: form,
+    });
+  },
+
+  /**
+   * `POST /api/documents/archive` — upload a ZIP, tar, or tar.gz archive
+   * whose manifest supplies each file's external metadata. Resolves with
+   * one result per file.
+   */
+  async uploadArchive(file: File): Promise<Result<BatchResult[]>> {
+    const form = new FormData();
+    form.append("file", file);
+
+    return await request<BatchResult[]>(`${base}/archive`, {
+      method: "POST",
+      body: form,
+    });
+  },
+
   /** `DELETE /api/documents/:id` — remove a document and its storage blob. */
   async delete(id: string): Promise<Result<void>> {
     return await request<void>(`${base}/${id}`, {
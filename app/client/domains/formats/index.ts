@@ -1,6 +1,8 @@
 export type { DocumentFormat } from "./format";
 export { imageFormat } from "./image";
+export { officeFormat } from "./office";
 export { pdfFormat } from "./pdf";
+export { tiffFormat } from "./tiff";
 
 export {
   formats,
@@ -0,0 +1,24 @@
+import { html } from "lit";
+
+import type { DocumentFormat } from "./format";
+
+/**
+ * Word, PowerPoint, and Excel format registration. Browsers cannot preview
+ * OOXML, so the viewer offers the file as a download. The server converts
+ * these documents to PDF before classifying them.
+ */
+export const officeFormat: DocumentFormat = {
+  id: "office",
+  displayName: "Office document",
+  contentTypes: [
+    "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+    "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+  ],
+  extensions: [".docx", ".pptx", ".xlsx"],
+  renderViewer: (src, title) =>
+    html`<p>
+      This browser cannot preview Office documents.
+      <a href=${src} target="_blank" rel="noopener" download>Download ${title}</a>
+    </p>`,
+};
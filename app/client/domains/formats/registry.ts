@@ -1,13 +1,20 @@
 import type { DocumentFormat } from "./format";
 import { imageFormat } from "./image";
+import { officeFormat } from "./office";
 import { pdfFormat } from "./pdf";
+import { tiffFormat } from "./tiff";
 
 /**
  * Frozen list of registered formats. Iteration order is stable — it drives
  * the order of extensions in `accept`, the content types in toasts, and
  * the label list in drop-zone hints.
  */
-export const formats: readonly DocumentFormat[] = [imageFormat, pdfFormat];
+export const formats: readonly DocumentFormat[] = [
+  imageFormat,
+  pdfFormat,
+  tiffFormat,
+  officeFormat,
+];
 
 /**
  * Returns the DocumentFormat whose `contentTypes` list includes the given
@@ -0,0 +1,23 @@
+import { html } from "lit";
+
+import type { DocumentFormat } from "./format";
+
+/**
+ * TIFF format registration, including multi-page scans. Few browsers
+ * render TIFF natively, so the viewer embeds it in an `<object>` whose
+ * fallback content links to the file instead of showing a broken image.
+ * Browsers that can decode it show only the first frame.
+ */
+export const tiffFormat: DocumentFormat = {
+  id: "tiff",
+  displayName: "TIFF",
+  contentTypes: ["image/tiff"],
+  extensions: [".tif", ".tiff"],
+  renderViewer: (src, title) =>
+    html`<object data=${src} type="image/tiff" title=${title}>
+      <p>
+        This browser cannot preview TIFF files.
+        <a href=${src} target="_blank" rel="noopener">Open ${title}</a>
+      </p>
+    </object>`,
+};
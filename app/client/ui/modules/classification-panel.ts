@@ -4,7 +4,12 @@ import { customElement, property, state } from "lit/decorators.js";
 import { formatDate } from "@core/formatting";
 import { navigate } from "@core/router";
 import { ClassificationService } from "@domains/classifications";
-import type { Classification } from "@domains/classifications";
+import type {
+  Classification,
+  ClassificationPage,
+  ClassificationResult,
+  EnhanceSettings,
+} from "@domains/classifications";
 import { Toast } from "@ui/elements";
 
 import badgeStyles from "@styles/badge.module.css";
@@ -36,6 +41,8 @@ export class ClassificationPanel extends LitElement {
   @property() documentId = "";
 
   @state() private classification: Classification | null = null;
+  @state() private pages: ClassificationPage[] = [];
+  @state() private results: ClassificationResult[] = [];
   @state() private loading = true;
   @state() private error = "";
   @state() private mode: PanelMode = "view";
@@ -55,6 +62,8 @@ export class ClassificationPanel extends LitElement {
     this.loading = true;
     this.error = "";
     this.classification = null;
+    this.pages = [];
+    this.results = [];
 
     const result = await ClassificationService.findByDocument(this.documentId);
 
@@ -66,6 +75,18 @@ export class ClassificationPanel extends LitElement {
     }
 
     this.classification = result.data;
+
+    const pages = await ClassificationService.pages(result.data.id);
+    if (pages.ok) {
+      this.pages = pages.data;
+    }
+
+    if (result.data.agreement !== null || result.data.escalation !== null) {
+      const results = await ClassificationService.results(result.data.id);
+      if (results.ok) {
+        this.results = results.data;
+      }
+    }
   }
 
   private async handleValidate(e: SubmitEvent) {
@@ -173,6 +194,87 @@ export class ClassificationPanel extends LitElement {
     `;
   }
 
+  private formatEnhancement(settings: EnhanceSettings | null): string {
+    if (!settings) return "enhanced";
+
+    const parts = Object.entries(settings)
+      .filter(([, v]) => v !== undefined && v !== null)
+      .map(([k, v]) => `${k} ${v}`);
+
+    return parts.length ? `enhanced: ${parts.join(", ")}` : "enhanced";
+  }
+
+  private renderPages() {
+    if (!this.pages.length) return nothing;
+
+    return html`
+      <div class="section">
+        <span class="label">Pages</span>
+        <div class="pages">
+          ${this.pages.map(
+            (p) => html`
+              <div class="page">
+                <div class="page-header">
+                  <span class="page-number">Page ${p.page_number}</span>
+                  ${p.enhanced
+                    ? html`<span class="badge enhanced">
+                        ${this.formatEnhancement(p.enhance_settings)}
+                      </span>`
+                    : nothing}
+                  ${p.text_only
+                    ? html`<span class="badge text-only">text layer</span>`
+                    : nothing}
+                  ${p.banners_only
+                    ? html`<span class="badge banners-only">banners</span>`
+                    : nothing}
+                </div>
+                <hd-markings-list .markings=${p.markings_found}></hd-markings-list>
+                <pre class="rationale">${p.rationale}</pre>
+              </div>
+            `,
+          )}
+        </div>
+      </div>
+    `;
+  }
+
+  private renderResults() {
+    const c = this.classification!;
+    if (!this.results.length) return nothing;
+
+    return html`
+      <div class="section">
+        <span class="label">
+          Agent Comparison${c.agreement !== null
+            ? ` (${Math.round(c.agreement * 100)}% agreement)`
+            : ""}
+        </span>
+        <div class="pages">
+          ${this.results.map(
+            (r) => html`
+              <div class="page">
+                <div class="page-header">
+                  <span class="page-number">
+                    ${r.agent_name}${r.primary ? " (primary)" : ""}${r.escalated_to
+                      ? ` (escalated to ${r.escalated_to})`
+                      : ""}
+                  </span>
+                  <span>${r.model_name} / ${r.provider_name}</span>
+                </div>
+                ${r.error
+                  ? html`<span class="marking invalid">${r.error}</span>`
+                  : html`<span class="marking ${r.agrees ? "" : "invalid"}">
+                        ${r.marking ?? r.classification}
+                      </span>
+                      <pre class="rationale">${r.rationale}</pre>`}
+              </div>
+            `,
+          )}
+        </div>
+      </div>
+    `;
+  }
+
   private renderViewMode() {
     const c = this.classification!;
 
@@ -188,6 +290,39 @@ export class ClassificationPanel extends LitElement {
           </div>
         </div>
 
+        ${c.discrepancy
+          ? html`<div class="section">
+              <span class="label">Roll-up Discrepancy</span>
+              <span class="marking invalid">${c.discrepancy}</span>
+            </div>`
+          : nothing}
+
+        ${c.escalation
+          ? html`<div class="section">
+              <span class="label">Escalated to ${c.escalation.agent}</span>
+              <span class="marking invalid">
+                ${c.escalation.classification}
+                (${c.escalation.confidence.toLowerCase()})
+              </span>
+            </div>`
+          : nothing}
+
+        ${c.failed_pages.length > 0
+          ? html`<div class="section">
+              <span class="label">Unreviewed Pages</span>
+              <span class="marking invalid">${c.failed_pages.join(", ")}</span>
+            </div>`
+          : nothing}
+
+        <div class="section">
+          <span class="label">Normalized Marking</span>
+          ${c.marking
+            ? html`<span class="marking">${c.marking.canonical}</span>`
+            : html`<span class="marking invalid">
+                ${c.marking_error ?? "Not normalized"}
+              </span>`}
+        </div>
+
         <div class="section">
           <span class="label">Markings Found</span>
           <hd-markings-list .markings=${c.markings_found}></hd-markings-list>
@@ -198,10 +333,17 @@ export class ClassificationPanel extends LitElement {
           <pre class="rationale">${c.rationale}</pre>
         </div>
 
+        ${this.renderResults()}
+
+        ${this.renderPages()}
+
         ${this.renderValidated()}
 
         <div class="meta">
-          <span>${c.model_name} / ${c.provider_name}</span>
+          <span>
+            ${c.agent_name ? `${c.agent_name}: ` : ""}${c.model_name} /
+            ${c.provider_name}
+          </span>
           <span>Classified ${formatDate(c.classified_at)}</span>
         </div>
       </div>
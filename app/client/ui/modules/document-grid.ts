@@ -29,7 +29,7 @@ interface ClassifyProgress {
 
 /**
  * Stateful module that manages the document browsing experience.
- * Owns search, filtering, sorting, pagination, SSE classify orchestration,
+ * Owns search, filtering, sorting, pagination, classify job orchestration,
  * bulk selection, and delete confirmation.
  */
 @customElement("hd-document-grid")
@@ -151,7 +151,7 @@ export class DocumentGrid extends LitElement {
     this.selectedIds = next;
   }
 
-  private handleClassify(e: CustomEvent<{ id: string }>) {
+  private async handleClassify(e: CustomEvent<{ id: string }>) {
     const docId = e.detail.id;
     if (this.classifying.has(docId)) return;
 
@@ -162,7 +162,29 @@ export class DocumentGrid extends LitElement {
 
     this.classifying = new Map(this.classifying).set(docId, progress);
 
-    const controller = ClassificationService.classify(docId, {
+    const filename = () =>
+      this.documents?.data.find((d) => d.id === docId)?.filename ??
+      "document";
+
+    let events: AbortController | undefined;
+
+    const finish = () => {
+      events?.abort();
+      this.abortControllers.delete(docId);
+      const updated = new Map(this.classifying);
+      updated.delete(docId);
+      this.classifying = updated;
+      this.fetchDocuments();
+    };
+
+    const result = await ClassificationService.classify(docId);
+    if (!result.ok) {
+      finish();
+      Toast.error(`Classification failed for ${filename()}`);
+      return;
+    }
+
+    events = ClassificationService.events(docId, {
       onEvent: (type, data) => {
         try {
           const event = JSON.parse(data);
@@ -177,7 +199,7 @@ export class DocumentGrid extends LitElement {
             });
           } else if (type === "node.complete") {
             const node = event.data?.node as WorkflowStage;
-            if (node) {
+            if (node && !current.completedNodes.includes(node)) {
               updated.set(docId, {
                 ...current,
                 currentNode: null,
@@ -191,30 +213,20 @@ export class DocumentGrid extends LitElement {
           console.warn("Failed to parse SSE event:", data, err);
         }
       },
+    });
+
+    const controller = ClassificationService.watchJob(result.data.id, {
       onComplete: () => {
-        this.abortControllers.delete(docId);
-        const updated = new Map(this.classifying);
-        updated.delete(docId);
-        this.classifying = updated;
-        const filename =
-          this.documents?.data.find((d) => d.id === docId)?.filename ??
-          "document";
-        Toast.success(`Classified ${filename}`);
-        this.fetchDocuments();
+        finish();
+        Toast.success(`Classified ${filename()}`);
       },
       onError: () => {
-        this.abortControllers.delete(docId);
-        const updated = new Map(this.classifying);
-        updated.delete(docId);
-        this.classifying = updated;
-        const filename =
-          this.documents?.data.find((d) => d.id === docId)?.filename ??
-          "document";
-        Toast.error(`Classification failed for ${filename}`);
-        this.fetchDocuments();
+        finish();
+        Toast.error(`Classification failed for ${filename()}`);
       },
     });
 
+    controller.signal.addEventListener("abort", () => events?.abort());
     this.abortControllers.set(docId, controller);
   }
 
@@ -0,0 +1,171 @@
+// Command eval measures classification accuracy against a labeled corpus. It
+// runs the classification workflow over every document in a manifest with
+// the configured agent, optionally overridden by an agent config file and a
+// prompt set, and writes report.json and report.md to the output directory.
+//
+// Usage:
+//
+//	go run ./cmd/eval [-manifest path] [-agent path] [-prompts path] [-out dir]
+//
+// Herald configuration (config.json, overlays, and HERALD_* variables) is
+// loaded as for the server, but no database is required: documents are
+// staged in a temporary filesystem store for the duration of the run.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	tauconfig "github.com/tailored-agentic-units/protocol/config"
+
+	"github.com/JaimeStill/herald/internal/config"
+	"github.com/JaimeStill/herald/internal/evaluation"
+	"github.com/JaimeStill/herald/internal/format"
+	"github.com/JaimeStill/herald/internal/infrastructure"
+	"github.com/JaimeStill/herald/internal/markings"
+	"github.com/JaimeStill/herald/pkg/lifecycle"
+	"github.com/JaimeStill/herald/pkg/storage"
+)
+
+const defaultManifest = "_project/marked-documents/manifest.json"
+
+func main() {
+	var (
+		manifestPath = flag.String("manifest", defaultManifest, "Manifest of documents and expected classifications")
+		agentPath    = flag.String("agent", "", "Agent config JSON merged over the configured agent")
+		promptsPath  = flag.String("prompts", "", "Prompt set JSON overriding stage instructions")
+		outDir       = flag.String("out", ".eval", "Directory for report.json and report.md")
+	)
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, *manifestPath, *agentPath, *promptsPath, *outDir); err != nil {
+		log.Fatal("evaluation failed: ", err)
+	}
+}
+
+func run(ctx context.Context, manifestPath, agentPath, promptsPath, outDir string) error {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("config load: %w", err)
+	}
+
+	if agentPath != "" {
+		overlay, err := loadAgentConfig(agentPath)
+		if err != nil {
+			return err
+		}
+		cfg.Agent.Merge(overlay)
+	}
+
+	promptSet := evaluation.DefaultPromptSet()
+	if promptsPath != "" {
+		if promptSet, err = evaluation.LoadPromptSet(promptsPath); err != nil {
+			return err
+		}
+	}
+
+	manifest, err := evaluation.LoadManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	newAgent, err := infrastructure.NewAgentFactory(cfg, logger)
+	if err != nil {
+		return err
+	}
+
+	scratch, err := os.MkdirTemp("", "herald-eval-*")
+	if err != nil {
+		return fmt.Errorf("create scratch directory: %w", err)
+	}
+	defer os.RemoveAll(scratch)
+
+	store, err := storage.New(&storage.Config{
+		Backend:       storage.BackendFilesystem,
+		Root:          scratch,
+		ContainerName: "documents",
+	}, logger)
+	if err != nil {
+		return fmt.Errorf("storage init: %w", err)
+	}
+
+	lc := lifecycle.New()
+	if err := store.Start(lc); err != nil {
+		return fmt.Errorf("storage start: %w", err)
+	}
+	lc.WaitForStartup()
+
+	report, err := evaluation.Run(ctx, evaluation.Options{
+		NewAgent:  newAgent,
+		PromptSet: promptSet,
+		Markings:  markings.NewParser(cfg.Markings.Taxonomy),
+		Formats: format.NewRegistry(
+			format.NewPDFHandler(format.Options{}),
+			format.NewImageHandler(format.Options{}),
+			format.NewTIFFHandler(format.Options{}),
+		),
+		Storage: store,
+		Logger:  logger,
+	}, manifest, manifestPath)
+	if err != nil {
+		return err
+	}
+
+	if err := writeReport(outDir, report); err != nil {
+		return err
+	}
+
+	logger.Info(
+		"evaluation complete",
+		"documents", report.Summary.Documents,
+		"correct", report.Summary.Correct,
+		"failed", report.Summary.Failed,
+		"accuracy", report.Summary.Accuracy,
+		"out", outDir,
+	)
+	return nil
+}
+
+func loadAgentConfig(path string) (*tauconfig.AgentConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read agent config: %w", err)
+	}
+
+	var cfg tauconfig.AgentConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse agent config: %w", err)
+	}
+	return &cfg, nil
+}
+
+func writeReport(dir string, report *evaluation.Report) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal report: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "report.json"), data, 0o644); err != nil {
+		return fmt.Errorf("write report.json: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "report.md"), []byte(report.Markdown()), 0o644); err != nil {
+		return fmt.Errorf("write report.md: %w", err)
+	}
+	return nil
+}
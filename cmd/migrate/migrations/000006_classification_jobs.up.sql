@@ -0,0 +1,34 @@
+CREATE TABLE classification_jobs (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  document_id UUID NOT NULL
+    REFERENCES documents(id) ON DELETE CASCADE,
+  status TEXT NOT NULL DEFAULT 'queued'
+    CHECK (status IN ('queued', 'running', 'succeeded', 'failed')),
+  attempts INTEGER NOT NULL DEFAULT 0,
+  max_attempts INTEGER NOT NULL DEFAULT 3,
+  last_error TEXT,
+  classification_id UUID
+    REFERENCES classifications(id) ON DELETE SET NULL,
+  run_after TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  lease_expires_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  started_at TIMESTAMPTZ,
+  completed_at TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_classification_jobs_document ON classification_jobs(document_id);
+CREATE INDEX idx_classification_jobs_status ON classification_jobs(status);
+CREATE INDEX idx_classification_jobs_created_at ON classification_jobs(created_at DESC);
+
+CREATE INDEX idx_classification_jobs_queued
+  ON classification_jobs(run_after)
+  WHERE status = 'queued';
+
+CREATE INDEX idx_classification_jobs_leased
+  ON classification_jobs(lease_expires_at)
+  WHERE status = 'running';
+
+CREATE UNIQUE INDEX idx_classification_jobs_active
+  ON classification_jobs(document_id)
+  WHERE status IN ('queued', 'running');
@@ -0,0 +1,3 @@
+ALTER TABLE classification_jobs DROP COLUMN IF EXISTS batch_id;
+
+DROP TABLE IF EXISTS classification_batches;
@@ -0,0 +1,15 @@
+CREATE TABLE classification_batches (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  filters JSONB NOT NULL DEFAULT '{}',
+  total INTEGER NOT NULL DEFAULT 0,
+  skipped INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_classification_batches_created_at ON classification_batches(created_at DESC);
+
+ALTER TABLE classification_jobs
+  ADD COLUMN batch_id UUID
+    REFERENCES classification_batches(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_classification_jobs_batch ON classification_jobs(batch_id, status);
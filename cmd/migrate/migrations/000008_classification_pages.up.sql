@@ -0,0 +1,11 @@
+CREATE TABLE classification_pages (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  classification_id UUID NOT NULL
+    REFERENCES classifications(id) ON DELETE CASCADE,
+  page_number INTEGER NOT NULL,
+  markings_found JSONB NOT NULL DEFAULT '[]',
+  rationale TEXT NOT NULL,
+  enhanced BOOLEAN NOT NULL DEFAULT FALSE,
+  enhance_settings JSONB,
+  UNIQUE (classification_id, page_number)
+);
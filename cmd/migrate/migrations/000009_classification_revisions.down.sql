@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS classification_revisions_immutable ON classification_revisions;
+DROP FUNCTION IF EXISTS prevent_classification_revision_change();
+DROP TABLE IF EXISTS classification_revisions;
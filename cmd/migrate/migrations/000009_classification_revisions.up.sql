@@ -0,0 +1,28 @@
+CREATE TABLE classification_revisions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  classification_id UUID NOT NULL,
+  document_id UUID NOT NULL,
+  revision INTEGER NOT NULL,
+  action TEXT NOT NULL
+    CHECK (action IN ('classify', 'validate', 'update')),
+  actor TEXT NOT NULL,
+  model_name TEXT NOT NULL,
+  provider_name TEXT NOT NULL,
+  prompts JSONB NOT NULL DEFAULT '[]',
+  before JSONB,
+  after JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (classification_id, revision)
+);
+
+CREATE INDEX idx_classification_revisions_document ON classification_revisions(document_id);
+
+CREATE FUNCTION prevent_classification_revision_change() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'classification revisions are immutable: % rejected', TG_OP;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER classification_revisions_immutable
+  BEFORE UPDATE OR DELETE ON classification_revisions
+  FOR EACH ROW EXECUTE FUNCTION prevent_classification_revision_change();
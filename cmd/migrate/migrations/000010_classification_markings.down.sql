@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_classifications_marking_dissemination;
+DROP INDEX IF EXISTS idx_classifications_marking_sci;
+DROP INDEX IF EXISTS idx_classifications_marking_level;
+DROP INDEX IF EXISTS idx_classifications_marking;
+
+ALTER TABLE classifications
+  DROP COLUMN IF EXISTS marking_error,
+  DROP COLUMN IF EXISTS marking_declassification,
+  DROP COLUMN IF EXISTS marking_releasable_to,
+  DROP COLUMN IF EXISTS marking_dissemination,
+  DROP COLUMN IF EXISTS marking_sap,
+  DROP COLUMN IF EXISTS marking_sci,
+  DROP COLUMN IF EXISTS marking_level,
+  DROP COLUMN IF EXISTS marking;
@@ -0,0 +1,14 @@
+ALTER TABLE classifications
+  ADD COLUMN marking TEXT,
+  ADD COLUMN marking_level TEXT,
+  ADD COLUMN marking_sci JSONB NOT NULL DEFAULT '[]',
+  ADD COLUMN marking_sap JSONB NOT NULL DEFAULT '[]',
+  ADD COLUMN marking_dissemination JSONB NOT NULL DEFAULT '[]',
+  ADD COLUMN marking_releasable_to JSONB NOT NULL DEFAULT '[]',
+  ADD COLUMN marking_declassification TEXT,
+  ADD COLUMN marking_error TEXT;
+
+CREATE INDEX idx_classifications_marking ON classifications(marking);
+CREATE INDEX idx_classifications_marking_level ON classifications(marking_level);
+CREATE INDEX idx_classifications_marking_sci ON classifications USING GIN (marking_sci);
+CREATE INDEX idx_classifications_marking_dissemination ON classifications USING GIN (marking_dissemination);
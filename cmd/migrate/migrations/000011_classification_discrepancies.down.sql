@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_classifications_discrepancy;
+
+ALTER TABLE classifications
+  DROP COLUMN IF EXISTS discrepancy,
+  DROP COLUMN IF EXISTS roll_up;
@@ -0,0 +1,6 @@
+ALTER TABLE classifications
+  ADD COLUMN roll_up TEXT,
+  ADD COLUMN discrepancy TEXT;
+
+CREATE INDEX idx_classifications_discrepancy ON classifications(classified_at DESC)
+  WHERE discrepancy IS NOT NULL;
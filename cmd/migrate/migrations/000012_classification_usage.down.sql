@@ -0,0 +1,18 @@
+DROP INDEX IF EXISTS idx_classification_revisions_usage;
+
+ALTER TABLE classification_revisions
+  DROP COLUMN IF EXISTS completion_tokens,
+  DROP COLUMN IF EXISTS prompt_tokens,
+  DROP COLUMN IF EXISTS chat_calls,
+  DROP COLUMN IF EXISTS vision_calls;
+
+ALTER TABLE classification_pages
+  DROP COLUMN IF EXISTS completion_tokens,
+  DROP COLUMN IF EXISTS prompt_tokens,
+  DROP COLUMN IF EXISTS vision_calls;
+
+ALTER TABLE classifications
+  DROP COLUMN IF EXISTS completion_tokens,
+  DROP COLUMN IF EXISTS prompt_tokens,
+  DROP COLUMN IF EXISTS chat_calls,
+  DROP COLUMN IF EXISTS vision_calls;
@@ -0,0 +1,19 @@
+ALTER TABLE classifications
+  ADD COLUMN vision_calls INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN chat_calls INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN prompt_tokens BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN completion_tokens BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE classification_pages
+  ADD COLUMN vision_calls INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN prompt_tokens BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN completion_tokens BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE classification_revisions
+  ADD COLUMN vision_calls INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN chat_calls INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN prompt_tokens BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN completion_tokens BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_classification_revisions_usage ON classification_revisions(created_at)
+  WHERE action = 'classify';
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_classifications_failed_pages;
+
+ALTER TABLE classification_pages
+  DROP COLUMN IF EXISTS error;
+
+ALTER TABLE classifications
+  DROP COLUMN IF EXISTS failed_pages;
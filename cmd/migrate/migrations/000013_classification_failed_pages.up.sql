@@ -0,0 +1,8 @@
+ALTER TABLE classifications
+  ADD COLUMN failed_pages JSONB;
+
+ALTER TABLE classification_pages
+  ADD COLUMN error TEXT;
+
+CREATE INDEX idx_classifications_failed_pages ON classifications(classified_at DESC)
+  WHERE failed_pages IS NOT NULL;
@@ -0,0 +1,14 @@
+CREATE TABLE classification_checkpoints (
+  run_id UUID PRIMARY KEY
+    REFERENCES classification_jobs(id) ON DELETE CASCADE,
+  document_id UUID NOT NULL
+    REFERENCES documents(id) ON DELETE CASCADE,
+  node TEXT NOT NULL,
+  filename TEXT NOT NULL,
+  page_count INTEGER NOT NULL,
+  classified_pages JSONB NOT NULL DEFAULT '[]',
+  state JSONB NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_classification_checkpoints_document ON classification_checkpoints(document_id);
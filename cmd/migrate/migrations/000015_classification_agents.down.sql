@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS classification_results;
+
+DROP INDEX IF EXISTS idx_classifications_agent_name;
+
+ALTER TABLE classifications
+  DROP COLUMN IF EXISTS agreement,
+  DROP COLUMN IF EXISTS agent_name;
+
+ALTER TABLE classification_jobs
+  DROP COLUMN IF EXISTS agents;
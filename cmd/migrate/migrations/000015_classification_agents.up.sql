@@ -0,0 +1,39 @@
+ALTER TABLE classification_jobs
+  ADD COLUMN agents JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE classifications
+  ADD COLUMN agent_name TEXT,
+  ADD COLUMN agreement REAL;
+
+CREATE INDEX idx_classifications_agent_name ON classifications(agent_name);
+
+CREATE TABLE classification_results (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  classification_id UUID NOT NULL,
+  document_id UUID NOT NULL
+    REFERENCES documents(id) ON DELETE CASCADE,
+  job_id UUID NOT NULL,
+  agent_name TEXT NOT NULL,
+  model_name TEXT NOT NULL,
+  provider_name TEXT NOT NULL,
+  primary_result BOOLEAN NOT NULL DEFAULT FALSE,
+  classification TEXT NOT NULL DEFAULT '',
+  confidence TEXT NOT NULL DEFAULT '',
+  rationale TEXT NOT NULL DEFAULT '',
+  marking TEXT,
+  agrees BOOLEAN NOT NULL DEFAULT FALSE,
+  failed_pages JSONB,
+  error TEXT,
+  vision_calls INTEGER NOT NULL DEFAULT 0,
+  chat_calls INTEGER NOT NULL DEFAULT 0,
+  prompt_tokens BIGINT NOT NULL DEFAULT 0,
+  completion_tokens BIGINT NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (job_id, agent_name)
+);
+
+CREATE INDEX idx_classification_results_classification
+  ON classification_results(classification_id, created_at DESC);
+
+CREATE INDEX idx_classification_results_usage ON classification_results(created_at)
+  WHERE NOT primary_result;
@@ -0,0 +1,5 @@
+ALTER TABLE classification_results
+  DROP COLUMN IF EXISTS escalated_to;
+
+ALTER TABLE classifications
+  DROP COLUMN IF EXISTS escalation;
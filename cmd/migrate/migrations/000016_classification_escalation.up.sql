@@ -0,0 +1,5 @@
+ALTER TABLE classifications
+  ADD COLUMN escalation JSONB;
+
+ALTER TABLE classification_results
+  ADD COLUMN escalated_to TEXT;
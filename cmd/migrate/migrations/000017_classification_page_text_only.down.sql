@@ -0,0 +1,2 @@
+ALTER TABLE classification_pages
+  DROP COLUMN IF EXISTS text_only;
@@ -0,0 +1,2 @@
+ALTER TABLE classification_pages
+  ADD COLUMN text_only BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,2 @@
+ALTER TABLE classification_pages
+  ADD COLUMN banners_only BOOLEAN NOT NULL DEFAULT false;
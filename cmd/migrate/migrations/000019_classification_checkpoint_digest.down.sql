@@ -0,0 +1,2 @@
+ALTER TABLE classification_checkpoints
+  DROP COLUMN IF EXISTS digest;
@@ -0,0 +1,2 @@
+ALTER TABLE classification_checkpoints
+  ADD COLUMN digest TEXT NOT NULL DEFAULT '';
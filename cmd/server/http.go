@@ -38,7 +38,7 @@ func (s *httpServer) Start(lc *lifecycle.Coordinator) error {
 		}
 	}()
 
-	lc.OnShutdown(func() {
+	lc.OnDrain(func() {
 		<-lc.Context().Done()
 		s.logger.Info("shutting down server")
 
@@ -8,6 +8,7 @@ import (
 	"github.com/JaimeStill/herald/internal/api"
 	"github.com/JaimeStill/herald/internal/config"
 	"github.com/JaimeStill/herald/internal/infrastructure"
+	"github.com/JaimeStill/herald/internal/metrics"
 	"github.com/JaimeStill/herald/pkg/auth"
 	"github.com/JaimeStill/herald/pkg/module"
 )
@@ -70,5 +71,7 @@ func buildRouter(infra *infrastructure.Infrastructure) *module.Router {
 		json.NewEncoder(w).Encode(map[string]string{"status": "ready"})
 	})
 
+	router.HandleNative("GET /metrics", metrics.Handler().ServeHTTP)
+
 	return router
 }
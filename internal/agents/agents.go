@@ -0,0 +1,133 @@
+// Package agents defines the model interface Herald's workflow calls and the
+// backends that satisfy it: tau agents for live providers, and a replay agent
+// that answers from recorded fixture files so the workflow can run offline.
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tailored-agentic-units/agent"
+	"github.com/tailored-agentic-units/format"
+	"github.com/tailored-agentic-units/protocol"
+)
+
+// ProviderReplay is the agent.provider.name that selects the replay backend.
+const ProviderReplay = "replay"
+
+// Agent is the inference surface the classification workflow depends on.
+// Chat and Vision send a single user prompt and return the raw response text
+// with the tokens it consumed; Vision attaches one PNG page image.
+type Agent interface {
+	Chat(ctx context.Context, prompt string) (Response, error)
+	Vision(ctx context.Context, prompt string, image []byte) (Response, error)
+	Model() string
+	Provider() string
+}
+
+// Response is the outcome of a single model call.
+type Response struct {
+	Text  string
+	Usage Usage
+}
+
+// Usage counts the tokens a model call consumed. Zero values mean the
+// provider did not report usage.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// Total returns the sum of prompt and completion tokens.
+func (u Usage) Total() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Add accumulates other into u.
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+}
+
+// Factory creates an Agent for a single unit of work.
+type Factory func(ctx context.Context) (Agent, error)
+
+// Wrap adapts a tau agent to the Agent interface.
+func Wrap(a agent.Agent) Agent {
+	return &tauAgent{agent: a}
+}
+
+type tauAgent struct {
+	agent agent.Agent
+}
+
+func (a *tauAgent) Chat(ctx context.Context, prompt string) (Response, error) {
+	resp, err := a.agent.Chat(ctx, []protocol.Message{protocol.UserMessage(prompt)})
+	if err != nil {
+		return Response{}, err
+	}
+	return Response{Text: resp.Text(), Usage: usageOf(resp)}, nil
+}
+
+func (a *tauAgent) Vision(ctx context.Context, prompt string, image []byte) (Response, error) {
+	resp, err := a.agent.Vision(
+		ctx,
+		[]protocol.Message{protocol.UserMessage(prompt)},
+		[]format.Image{{Data: image, Format: "png"}},
+	)
+	if err != nil {
+		return Response{}, err
+	}
+	return Response{Text: resp.Text(), Usage: usageOf(resp)}, nil
+}
+
+// usageOf reads token usage from a provider response. tau responses
+// serialize in the OpenAI wire shape, so usage is taken from its "usage"
+// object; responses without one report zero usage.
+func usageOf(resp any) Usage {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return Usage{}
+	}
+
+	var wire struct {
+		Usage Usage `json:"usage"`
+	}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return Usage{}
+	}
+	return wire.Usage
+}
+
+func (a *tauAgent) Model() string    { return a.agent.Model().Name }
+func (a *tauAgent) Provider() string { return a.agent.Provider().Name() }
+
+// Call identifies a single model call within a classification run. Document
+// is the source filename and Digest the hex SHA-256 of its content, Stage the
+// workflow stage, and Page the one-based page number, or zero for
+// document-level calls such as finalize.
+type Call struct {
+	Document string
+	Digest   string
+	Stage    string
+	Page     int
+}
+
+func (c Call) String() string {
+	return fmt.Sprintf("%s/%s/%d", c.Document, c.Stage, c.Page)
+}
+
+type callKey struct{}
+
+// WithCall returns a context carrying c, which the replay and recording
+// backends use to key responses.
+func WithCall(ctx context.Context, c Call) context.Context {
+	return context.WithValue(ctx, callKey{}, c)
+}
+
+// CallFrom returns the Call carried by ctx, if any.
+func CallFrom(ctx context.Context) (Call, bool) {
+	c, ok := ctx.Value(callKey{}).(Call)
+	return c, ok
+}
@@ -0,0 +1,10 @@
+package agents
+
+import "errors"
+
+// Sentinel errors for agent backends.
+var (
+	ErrNoCall          = errors.New("context does not identify the model call")
+	ErrFixtureNotFound = errors.New("no recorded response for model call")
+	ErrUnknownAgent    = errors.New("unknown agent")
+)
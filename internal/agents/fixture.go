@@ -0,0 +1,164 @@
+package agents
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// DefaultFixture is the fixture file a Replay with fallback enabled consults
+// when a document has no fixture of its own or its fixture lacks the call.
+const DefaultFixture = "default.json"
+
+// AnyPage is the page key that matches every page of a stage.
+const AnyPage = "*"
+
+// digestPrefix is the number of hex digits of a document's content digest
+// carried in its fixture filename.
+const digestPrefix = 16
+
+// Fixture holds the recorded responses for one document, keyed by stage and
+// then by page number, where "0" keys document-level calls and AnyPage matches
+// any page. Responses that were valid JSON are stored as JSON values; any
+// other text is stored as a JSON string. Usage, keyed the same way, holds the
+// token counts reported when the response was recorded.
+type Fixture struct {
+	Document  string                                `json:"document"`
+	Digest    string                                `json:"digest,omitempty"`
+	Model     string                                `json:"model,omitempty"`
+	Responses map[string]map[string]json.RawMessage `json:"responses"`
+	Usage     map[string]map[string]Usage           `json:"usage,omitempty"`
+}
+
+// FixturePath returns the fixture file for document within dir. The name
+// carries a prefix of the content digest, so documents that share a filename
+// keep separate fixtures. An empty document maps to DefaultFixture.
+func FixturePath(dir, document, digest string) string {
+	if document == "" {
+		return filepath.Join(dir, DefaultFixture)
+	}
+	name := filepath.Base(document)
+	if digest != "" {
+		name += "." + digest[:min(len(digest), digestPrefix)]
+	}
+	return filepath.Join(dir, name+".json")
+}
+
+// LoadFixture reads the fixture at path. It returns nil without error when
+// the file does not exist.
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read fixture: %w", err)
+	}
+
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse fixture %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// Lookup returns the response recorded for stage and page, falling back to
+// the stage's AnyPage response. JSON values are returned compacted.
+func (f *Fixture) Lookup(stage string, page int) (Response, bool) {
+	pages, ok := f.Responses[stage]
+	if !ok {
+		return Response{}, false
+	}
+
+	key := strconv.Itoa(page)
+	raw, ok := pages[key]
+	if !ok {
+		key = AnyPage
+		raw, ok = pages[key]
+	}
+	if !ok {
+		return Response{}, false
+	}
+
+	resp := Response{Usage: f.Usage[stage][key]}
+
+	var text string
+	var buf bytes.Buffer
+	switch {
+	case json.Unmarshal(raw, &text) == nil:
+		resp.Text = text
+	case json.Compact(&buf, raw) == nil:
+		resp.Text = buf.String()
+	default:
+		resp.Text = string(raw)
+	}
+	return resp, true
+}
+
+// Set records resp as the response for stage and page.
+func (f *Fixture) Set(stage string, page int, resp Response) {
+	if f.Responses == nil {
+		f.Responses = make(map[string]map[string]json.RawMessage)
+	}
+	if f.Responses[stage] == nil {
+		f.Responses[stage] = make(map[string]json.RawMessage)
+	}
+
+	var raw json.RawMessage
+	var buf bytes.Buffer
+	if json.Valid([]byte(resp.Text)) && json.Compact(&buf, []byte(resp.Text)) == nil {
+		raw = buf.Bytes()
+	} else {
+		raw, _ = json.Marshal(resp.Text)
+	}
+
+	key := strconv.Itoa(page)
+	f.Responses[stage][key] = raw
+
+	if resp.Usage == (Usage{}) {
+		delete(f.Usage[stage], key)
+		return
+	}
+	if f.Usage == nil {
+		f.Usage = make(map[string]map[string]Usage)
+	}
+	if f.Usage[stage] == nil {
+		f.Usage[stage] = make(map[string]Usage)
+	}
+	f.Usage[stage][key] = resp.Usage
+}
+
+// Save writes the fixture to path, replacing any existing file atomically.
+func (f *Fixture) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal fixture: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create fixture directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".fixture-*")
+	if err != nil {
+		return fmt.Errorf("create fixture: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write fixture: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write fixture: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("save fixture: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Recorder captures the responses of live agents into fixture files that
+// Replay can serve later. Responses are merged into any existing fixture for
+// the same document content, so a run re-records only the calls it makes.
+type Recorder struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewRecorder creates a recorder writing fixtures to dir.
+func NewRecorder(dir string) *Recorder {
+	return &Recorder{dir: dir}
+}
+
+// Wrap returns an Agent that forwards to a and records each successful
+// response under the Call carried by the request context.
+func (r *Recorder) Wrap(a Agent) Agent {
+	return &recording{Agent: a, recorder: r}
+}
+
+func (r *Recorder) record(ctx context.Context, model string, resp Response) error {
+	call, ok := CallFrom(ctx)
+	if !ok {
+		return ErrNoCall
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := FixturePath(r.dir, call.Document, call.Digest)
+	f, err := LoadFixture(path)
+	if err != nil {
+		return err
+	}
+	if f == nil {
+		f = &Fixture{Document: call.Document, Digest: call.Digest}
+	}
+
+	f.Model = model
+	f.Set(call.Stage, call.Page, resp)
+	return f.Save(path)
+}
+
+type recording struct {
+	Agent
+	recorder *Recorder
+}
+
+func (a *recording) Chat(ctx context.Context, prompt string) (Response, error) {
+	resp, err := a.Agent.Chat(ctx, prompt)
+	if err != nil {
+		return Response{}, err
+	}
+	if err := a.recorder.record(ctx, a.Model(), resp); err != nil {
+		return Response{}, fmt.Errorf("record response: %w", err)
+	}
+	return resp, nil
+}
+
+func (a *recording) Vision(ctx context.Context, prompt string, image []byte) (Response, error) {
+	resp, err := a.Agent.Vision(ctx, prompt, image)
+	if err != nil {
+		return Response{}, err
+	}
+	if err := a.recorder.record(ctx, a.Model(), resp); err != nil {
+		return Response{}, fmt.Errorf("record response: %w", err)
+	}
+	return resp, nil
+}
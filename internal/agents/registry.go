@@ -0,0 +1,76 @@
+package agents
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Entry is a named agent a classification can run with. Model and Provider
+// are the names recorded with its results.
+type Entry struct {
+	Name     string
+	Model    string
+	Provider string
+	New      Factory
+}
+
+// Registry holds the agents configured for a deployment by name. The default
+// entry serves requests that do not name an agent.
+type Registry struct {
+	entries map[string]Entry
+	def     string
+}
+
+// NewRegistry composes a Registry from the default agent and any additional
+// named agents. A later entry with the same name replaces an earlier one.
+func NewRegistry(def Entry, others ...Entry) *Registry {
+	r := &Registry{
+		entries: map[string]Entry{def.Name: def},
+		def:     def.Name,
+	}
+	for _, e := range others {
+		r.entries[e.Name] = e
+	}
+	return r
+}
+
+// Default returns the agent used when a request names none.
+func (r *Registry) Default() Entry {
+	return r.entries[r.def]
+}
+
+// Lookup returns the agent with the given name, or the default agent when
+// name is empty. Returns ErrUnknownAgent for names that are not configured.
+func (r *Registry) Lookup(name string) (Entry, error) {
+	if name == "" {
+		return r.Default(), nil
+	}
+	e, ok := r.entries[name]
+	if !ok {
+		return Entry{}, fmt.Errorf("%w: %s (configured: %s)", ErrUnknownAgent, name, strings.Join(r.Names(), ", "))
+	}
+	return e, nil
+}
+
+// Names returns the configured agent names, default first and the rest sorted.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		if name != r.def {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return append([]string{r.def}, names...)
+}
+
+// Entries returns the configured agents in Names order.
+func (r *Registry) Entries() []Entry {
+	names := r.Names()
+	entries := make([]Entry, len(names))
+	for i, name := range names {
+		entries[i] = r.entries[name]
+	}
+	return entries
+}
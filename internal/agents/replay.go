@@ -0,0 +1,93 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Replay answers model calls from fixture files instead of a live provider.
+// Each call is keyed by the Call carried in its context and answered from the
+// fixture of its document and content digest; only when fallback is enabled
+// are unrecorded calls answered from DefaultFixture. Fixtures are read once
+// and cached. Replay is safe for concurrent use and may be shared across
+// workers.
+type Replay struct {
+	dir      string
+	model    string
+	fallback bool
+
+	mu       sync.Mutex
+	fixtures map[string]*Fixture
+}
+
+// NewReplay creates a replay agent reading fixtures from dir. model is the
+// name it reports, so stored results identify the configured model. Without
+// fallback, a call the document's fixture lacks fails with
+// ErrFixtureNotFound.
+func NewReplay(dir, model string, fallback bool) *Replay {
+	return &Replay{
+		dir:      dir,
+		model:    model,
+		fallback: fallback,
+		fixtures: make(map[string]*Fixture),
+	}
+}
+
+func (r *Replay) Chat(ctx context.Context, _ string) (Response, error) {
+	return r.respond(ctx)
+}
+
+func (r *Replay) Vision(ctx context.Context, _ string, _ []byte) (Response, error) {
+	return r.respond(ctx)
+}
+
+func (r *Replay) Model() string    { return r.model }
+func (r *Replay) Provider() string { return ProviderReplay }
+
+func (r *Replay) respond(ctx context.Context) (Response, error) {
+	if err := ctx.Err(); err != nil {
+		return Response{}, err
+	}
+
+	call, ok := CallFrom(ctx)
+	if !ok {
+		return Response{}, ErrNoCall
+	}
+
+	paths := []string{FixturePath(r.dir, call.Document, call.Digest)}
+	if r.fallback {
+		paths = append(paths, FixturePath(r.dir, "", ""))
+	}
+
+	for _, path := range paths {
+		f, err := r.fixture(path)
+		if err != nil {
+			return Response{}, err
+		}
+		if f == nil {
+			continue
+		}
+		if resp, ok := f.Lookup(call.Stage, call.Page); ok {
+			return resp, nil
+		}
+	}
+
+	return Response{}, fmt.Errorf("%w: %s", ErrFixtureNotFound, call)
+}
+
+func (r *Replay) fixture(path string) (*Fixture, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, ok := r.fixtures[path]; ok {
+		return f, nil
+	}
+
+	f, err := LoadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	r.fixtures[path] = f
+	return f, nil
+}
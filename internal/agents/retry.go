@@ -0,0 +1,98 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+var (
+	// statusPattern finds an HTTP status in provider error text, for
+	// providers that only report the failed response as a message.
+	statusPattern = regexp.MustCompile(`(?i)\bstatus(?:\s+code)?[\s:=]+(\d{3})\b`)
+	// throttledPattern catches rate-limit refusals reported without a status.
+	throttledPattern = regexp.MustCompile(`(?i)too many requests|rate limit`)
+	// retryAfterPattern matches the wait Azure OpenAI includes in the body
+	// of a rate-limited response ("Please retry after 6 seconds.").
+	retryAfterPattern = regexp.MustCompile(`(?i)\bretry after (\d+) seconds?`)
+)
+
+// transient reports whether err is a failure worth retrying: a rate-limit
+// or server-side HTTP status, or a dropped or timed-out connection. It also
+// returns how long the provider asked callers to wait, or zero.
+func transient(err error) (bool, time.Duration) {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false, 0
+	}
+
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		var header http.Header
+		if respErr.RawResponse != nil {
+			header = respErr.RawResponse.Header
+		}
+		return retryableStatus(respErr.StatusCode), retryAfter(header, err)
+	}
+
+	var coded interface{ StatusCode() int }
+	if errors.As(err, &coded) {
+		return retryableStatus(coded.StatusCode()), retryAfter(nil, err)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true, 0
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) {
+		return true, 0
+	}
+
+	if m := statusPattern.FindStringSubmatch(err.Error()); m != nil {
+		status, _ := strconv.Atoi(m[1])
+		return retryableStatus(status), retryAfter(nil, err)
+	}
+	if throttledPattern.MatchString(err.Error()) {
+		return true, retryAfter(nil, err)
+	}
+	return false, 0
+}
+
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	}
+	return status >= http.StatusInternalServerError && status != http.StatusNotImplemented
+}
+
+// retryAfter reads the requested wait from a Retry-After header, in seconds
+// or as an HTTP date, falling back to the error text.
+func retryAfter(header http.Header, err error) time.Duration {
+	if v := header.Get("Retry-After"); v != "" {
+		if secs, perr := strconv.Atoi(v); perr == nil {
+			return time.Duration(secs) * time.Second
+		}
+		if at, perr := http.ParseTime(v); perr == nil {
+			return max(time.Until(at), 0)
+		}
+	}
+
+	var hinted interface{ RetryAfter() time.Duration }
+	if errors.As(err, &hinted) {
+		return hinted.RetryAfter()
+	}
+
+	if m := retryAfterPattern.FindStringSubmatch(err.Error()); m != nil {
+		secs, _ := strconv.Atoi(m[1])
+		return time.Duration(secs) * time.Second
+	}
+	return 0
+}
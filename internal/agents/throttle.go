@@ -0,0 +1,226 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Limits is one deployment's budget per minute. Zero leaves that rate
+// unbounded.
+type Limits struct {
+	RequestsPerMinute int
+	TokensPerMinute   int
+}
+
+// Backoff controls how a failed call is retried. Each call is attempted up
+// to MaxAttempts times; the delay before retry n is drawn from the upper
+// half of Min·2ⁿ⁻¹, capped at Max, and is never shorter than a wait the
+// provider asked for.
+type Backoff struct {
+	MaxAttempts int
+	Min         time.Duration
+	Max         time.Duration
+}
+
+func (b Backoff) delay(attempt int, retryAfter time.Duration) time.Duration {
+	d := b.Max
+	if shift := attempt - 1; shift < 32 {
+		d = min(b.Min<<shift, b.Max)
+	}
+	if half := d / 2; half > 0 {
+		d = half + rand.N(half)
+	}
+	return max(d, retryAfter)
+}
+
+// Throttle rate-limits and retries model calls. Limiters are keyed by model
+// name and shared by every agent the throttle wraps, so concurrent pages and
+// documents draw on one budget per deployment. A wait requested by the
+// provider pauses every caller of that deployment, not just the one that
+// was refused.
+type Throttle struct {
+	limits  func(model string) Limits
+	backoff Backoff
+	logger  *slog.Logger
+
+	mu       sync.Mutex
+	limiters map[string]*limiter
+}
+
+// NewThrottle creates a Throttle. limits reports the budget for a model the
+// first time it is called.
+func NewThrottle(limits func(model string) Limits, backoff Backoff, logger *slog.Logger) *Throttle {
+	return &Throttle{
+		limits:   limits,
+		backoff:  backoff,
+		logger:   logger.With("system", "throttle"),
+		limiters: make(map[string]*limiter),
+	}
+}
+
+// Wrap returns a factory whose agents wait for the rate limiters before
+// every call and retry transient failures in place, so one refused page
+// does not fail the document.
+func (t *Throttle) Wrap(newAgent Factory) Factory {
+	return func(ctx context.Context) (Agent, error) {
+		a, err := newAgent(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &throttledAgent{Agent: a, throttle: t, limiter: t.limiter(a.Model())}, nil
+	}
+}
+
+func (t *Throttle) limiter(model string) *limiter {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	l, ok := t.limiters[model]
+	if !ok {
+		l = newLimiter(t.limits(model))
+		t.limiters[model] = l
+	}
+	return l
+}
+
+type throttledAgent struct {
+	Agent
+	throttle *Throttle
+	limiter  *limiter
+}
+
+func (a *throttledAgent) Chat(ctx context.Context, prompt string) (Response, error) {
+	return a.call(ctx, func(ctx context.Context) (Response, error) {
+		return a.Agent.Chat(ctx, prompt)
+	})
+}
+
+func (a *throttledAgent) Vision(ctx context.Context, prompt string, image []byte) (Response, error) {
+	return a.call(ctx, func(ctx context.Context) (Response, error) {
+		return a.Agent.Vision(ctx, prompt, image)
+	})
+}
+
+func (a *throttledAgent) call(ctx context.Context, fn func(context.Context) (Response, error)) (Response, error) {
+	backoff := a.throttle.backoff
+
+	for attempt := 1; ; attempt++ {
+		if err := a.limiter.wait(ctx); err != nil {
+			return Response{}, err
+		}
+
+		resp, err := fn(ctx)
+		a.limiter.charge(resp.Usage.Total())
+		if err == nil {
+			return resp, nil
+		}
+
+		retry, retryAfter := transient(err)
+		if !retry || ctx.Err() != nil {
+			return resp, err
+		}
+		if attempt >= backoff.MaxAttempts {
+			return resp, fmt.Errorf("%d attempts: %w", attempt, err)
+		}
+
+		a.limiter.pause(retryAfter)
+		delay := backoff.delay(attempt, retryAfter)
+		a.throttle.logger.Warn("model call failed, retrying",
+			"model", a.Model(),
+			"attempt", attempt,
+			"delay", delay,
+			"error", err,
+		)
+
+		if err := sleep(ctx, delay); err != nil {
+			return Response{}, err
+		}
+	}
+}
+
+// limiter holds one deployment's request and token buckets. Token usage is
+// only known once a call returns, so it is charged afterward: the bucket
+// may go into debt, and later callers wait until it is repaid.
+type limiter struct {
+	requests *rate.Limiter
+	tokens   *rate.Limiter
+
+	mu     sync.Mutex
+	resume time.Time
+}
+
+// newLimiter sizes the request burst to a tenth of a minute's budget, the
+// window Azure OpenAI evaluates rate limits over, so a cold start does not
+// spend the whole minute at once.
+func newLimiter(limits Limits) *limiter {
+	l := &limiter{}
+	if n := limits.RequestsPerMinute; n > 0 {
+		l.requests = rate.NewLimiter(perMinute(n), max(1, n/6))
+	}
+	if n := limits.TokensPerMinute; n > 0 {
+		l.tokens = rate.NewLimiter(perMinute(n), n)
+	}
+	return l
+}
+
+func perMinute(n int) rate.Limit {
+	return rate.Limit(float64(n) / 60)
+}
+
+func (l *limiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	resume := l.resume
+	l.mu.Unlock()
+
+	if err := sleep(ctx, time.Until(resume)); err != nil {
+		return err
+	}
+	if l.requests != nil {
+		if err := l.requests.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	if l.tokens != nil {
+		return l.tokens.Wait(ctx)
+	}
+	return nil
+}
+
+func (l *limiter) charge(tokens int) {
+	if l.tokens == nil || tokens <= 0 {
+		return
+	}
+	l.tokens.ReserveN(time.Now(), min(tokens, l.tokens.Burst()))
+}
+
+// pause holds every caller for d, extending any pause already in effect.
+func (l *limiter) pause(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if resume := time.Now().Add(d); resume.After(l.resume) {
+		l.resume = resume
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
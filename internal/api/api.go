@@ -6,15 +6,22 @@ import (
 
 	"github.com/JaimeStill/herald/internal/config"
 	"github.com/JaimeStill/herald/internal/infrastructure"
+	"github.com/JaimeStill/herald/internal/metrics"
+	"github.com/JaimeStill/herald/internal/tracing"
 	"github.com/JaimeStill/herald/pkg/middleware"
 	"github.com/JaimeStill/herald/pkg/module"
 )
 
-// NewModule creates the API module with all domain handlers and middleware.
+// NewModule creates the API module with all domain handlers and middleware
+// and starts the domain systems that run background work.
 func NewModule(cfg *config.Config, infra *infrastructure.Infrastructure) (*module.Module, error) {
 	runtime := NewRuntime(cfg, infra)
 	domain := NewDomain(runtime)
 
+	if err := domain.Classifications.Start(runtime.Lifecycle); err != nil {
+		return nil, err
+	}
+
 	mux := http.NewServeMux()
 	registerRoutes(mux, domain, cfg, runtime)
 
@@ -22,6 +29,8 @@ func NewModule(cfg *config.Config, infra *infrastructure.Infrastructure) (*modul
 	m.Use(middleware.CORS(&cfg.API.CORS))
 	m.Use(middleware.Auth(&cfg.Auth, runtime.Infrastructure.Logger))
 	m.Use(middleware.Logger(runtime.Infrastructure.Logger))
+	m.Use(tracing.HTTP(cfg.API.BasePath))
+	m.Use(metrics.HTTP(cfg.API.BasePath))
 
 	return m, nil
 }
@@ -2,9 +2,13 @@ package api
 
 import (
 	"github.com/JaimeStill/herald/internal/classifications"
+	"github.com/JaimeStill/herald/internal/config"
 	"github.com/JaimeStill/herald/internal/documents"
 	"github.com/JaimeStill/herald/internal/format"
+	"github.com/JaimeStill/herald/internal/markings"
 	"github.com/JaimeStill/herald/internal/prompts"
+	"github.com/JaimeStill/herald/internal/state"
+	"github.com/JaimeStill/herald/internal/workflow"
 )
 
 // Domain holds all domain systems that comprise the API.
@@ -16,9 +20,25 @@ type Domain struct {
 
 // NewDomain creates all domain systems from the API runtime.
 func NewDomain(runtime *Runtime) *Domain {
+	// The config validates the renderer name, so this only falls back when
+	// a runtime is built without a finalized config.
+	renderer, err := format.NewRenderer(runtime.Formats.Renderer)
+	if err != nil {
+		runtime.Logger.Warn("falling back to the magick renderer", "error", err)
+		renderer = format.MagickRenderer()
+	}
+
 	formats := format.NewRegistry(
-		format.NewPDFHandler(),
-		format.NewImageHandler(),
+		format.NewPDFHandler(formatOptions(runtime.Formats.PDF, renderer)),
+		format.NewImageHandler(formatOptions(runtime.Formats.Image, renderer)),
+		format.NewTIFFHandler(formatOptions(runtime.Formats.TIFF, renderer)),
+		format.NewOfficeHandler(
+			formatOptions(runtime.Formats.Office.FormatConfig, renderer),
+			format.CommandConverter(
+				runtime.Formats.Office.ConverterArgs(),
+				runtime.Formats.Office.ConverterTimeoutDuration(),
+			),
+		),
 	)
 
 	docsSystem := documents.New(
@@ -37,15 +57,33 @@ func NewDomain(runtime *Runtime) *Domain {
 
 	classificationsSystem := classifications.New(
 		runtime.Database.Connection(),
-		runtime.NewAgent,
-		runtime.Agent.Model.Name,
-		runtime.Agent.Provider.Name,
+		runtime.Agents,
 		runtime.Logger,
 		runtime.Pagination,
 		runtime.Storage,
 		docsSystem,
 		promptsSystem,
 		formats,
+		classifications.MarkingConfig{
+			Parser: markings.NewParser(runtime.Markings.Taxonomy),
+			Strict: runtime.Markings.Strict,
+		},
+		newPricing(runtime.Usage),
+		classifications.WorkflowConfig{
+			PartialPages:        runtime.Workflow.PartialPages,
+			EscalationAgent:     runtime.Workflow.EscalationAgent,
+			EscalationThreshold: state.Confidence(runtime.Workflow.EscalationThreshold),
+			TextFastPath:        runtime.Workflow.TextFastPath,
+			Strategy:            workflow.Strategy(runtime.Workflow.ClassifyStrategy),
+		},
+		classifications.WorkerConfig{
+			Workers:       runtime.Jobs.Workers,
+			PollInterval:  runtime.Jobs.PollIntervalDuration(),
+			LeaseDuration: runtime.Jobs.LeaseDurationValue(),
+			MaxAttempts:   runtime.Jobs.MaxAttempts,
+			RetryBackoff:  runtime.Jobs.RetryBackoffDuration(),
+			MaxRunning:    runtime.Jobs.MaxRunning,
+		},
 	)
 
 	return &Domain{
@@ -54,3 +92,28 @@ func NewDomain(runtime *Runtime) *Domain {
 		Prompts:         promptsSystem,
 	}
 }
+
+// formatOptions converts a format handler's configured rendering options,
+// rendering with the shared renderer.
+func formatOptions(cfg config.FormatConfig, renderer format.Renderer) format.Options {
+	return format.Options{
+		Crop:        cfg.Crop,
+		CropHeight:  cfg.CropHeight,
+		CropDensity: cfg.CropDensity,
+		PageDensity: cfg.PageDensity,
+		Renderer:    renderer,
+	}
+}
+
+// newPricing converts the configured model prices for the classifications
+// usage report.
+func newPricing(cfg config.UsageConfig) classifications.Pricing {
+	prices := make(map[string]classifications.Price, len(cfg.Prices))
+	for model, p := range cfg.Prices {
+		prices[model] = classifications.Price{
+			PromptPerMillion:     p.PromptPerMillion,
+			CompletionPerMillion: p.CompletionPerMillion,
+		}
+	}
+	return classifications.Pricing{Currency: cfg.Currency, Prices: prices}
+}
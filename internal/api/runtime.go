@@ -10,6 +10,11 @@ import (
 type Runtime struct {
 	*infrastructure.Infrastructure
 	Pagination pagination.Config
+	Formats    config.FormatsConfig
+	Jobs       config.JobsConfig
+	Markings   config.MarkingsConfig
+	Usage      config.UsageConfig
+	Workflow   config.WorkflowConfig
 }
 
 // NewRuntime creates an API runtime with a module-scoped logger.
@@ -17,6 +22,7 @@ func NewRuntime(cfg *config.Config, infra *infrastructure.Infrastructure) *Runti
 	return &Runtime{
 		Infrastructure: &infrastructure.Infrastructure{
 			Agent:      cfg.Agent,
+			Agents:     infra.Agents,
 			Credential: infra.Credential,
 			Lifecycle:  infra.Lifecycle,
 			Logger:     infra.Logger.With("module", "api"),
@@ -25,5 +31,10 @@ func NewRuntime(cfg *config.Config, infra *infrastructure.Infrastructure) *Runti
 			NewAgent:   infra.NewAgent,
 		},
 		Pagination: cfg.API.Pagination,
+		Formats:    cfg.Formats,
+		Jobs:       cfg.Jobs,
+		Markings:   cfg.Markings,
+		Usage:      cfg.Usage,
+		Workflow:   cfg.Workflow,
 	}
 }
@@ -0,0 +1,76 @@
+package classifications
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/JaimeStill/herald/internal/workflow"
+	"github.com/JaimeStill/herald/pkg/repository"
+)
+
+const checkpointColumns = `run_id, document_id, node, filename, digest, page_count, classified_pages, state`
+
+// checkpoints stores workflow checkpoints in classification_checkpoints. A
+// run is keyed by the job that owns it, so a job reclaimed after a crash,
+// released on shutdown, or retried after a failure resumes where it stopped.
+// The checkpoint is deleted when the job succeeds or fails for good.
+type checkpoints struct {
+	db *sql.DB
+}
+
+func (c *checkpoints) Load(ctx context.Context, runID uuid.UUID) (*workflow.Checkpoint, error) {
+	q := `SELECT ` + checkpointColumns + ` FROM classification_checkpoints WHERE run_id = $1`
+
+	cp, err := repository.QueryOne(ctx, c.db, q, []any{runID}, scanCheckpoint)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, workflow.ErrNoCheckpoint
+		}
+		return nil, fmt.Errorf("load checkpoint: %w", err)
+	}
+	return &cp, nil
+}
+
+func (c *checkpoints) Save(ctx context.Context, cp *workflow.Checkpoint) error {
+	classified, err := json.Marshal(cp.ClassifiedPages)
+	if err != nil {
+		return fmt.Errorf("marshal classified pages: %w", err)
+	}
+
+	stateJSON, err := json.Marshal(cp.State)
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint state: %w", err)
+	}
+
+	_, err = c.db.ExecContext(ctx, `
+		INSERT INTO classification_checkpoints (`+checkpointColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (run_id) DO UPDATE SET
+			node = EXCLUDED.node,
+			filename = EXCLUDED.filename,
+			digest = EXCLUDED.digest,
+			page_count = EXCLUDED.page_count,
+			classified_pages = EXCLUDED.classified_pages,
+			state = EXCLUDED.state,
+			updated_at = NOW()`,
+		cp.RunID, cp.DocumentID, cp.Node, cp.Filename, cp.Digest, cp.PageCount, classified, stateJSON,
+	)
+	if err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// deleteCheckpoint removes the checkpoint of a job's run once the job no
+// longer needs to resume.
+func deleteCheckpoint(ctx context.Context, e repository.Executor, jobID uuid.UUID) error {
+	if _, err := e.ExecContext(ctx, "DELETE FROM classification_checkpoints WHERE run_id = $1", jobID); err != nil {
+		return fmt.Errorf("delete checkpoint: %w", err)
+	}
+	return nil
+}
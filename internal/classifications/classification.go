@@ -1,28 +1,132 @@
 // Package classifications implements the classification domain for Herald.
 // It provides types, data access, and business logic for storing, querying,
-// validating, and updating classification results produced by the workflow engine.
+// validating, and updating classification results produced by the workflow engine,
+// along with the durable job queue that runs the workflow in the background.
 package classifications
 
 import (
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/JaimeStill/herald/internal/documents"
+	"github.com/JaimeStill/herald/internal/markings"
+	"github.com/JaimeStill/herald/internal/prompts"
+	"github.com/JaimeStill/herald/internal/state"
 )
 
 // Classification represents a stored classification result for a document.
 // It mirrors the classifications table schema with flattened workflow metadata.
+// Marking is the normalized form of Classification; when the raw string does
+// not parse against the marking taxonomy, Marking is nil and MarkingError
+// describes why. RollUp is the marking derived from the page findings by the
+// workflow, and Discrepancy is set when the workflow's answer disagreed with it.
+// FailedPages lists the pages the run could not analyze; it is empty unless
+// the workflow tolerated page failures, in which case confidence is LOW.
+// Usage totals the model calls and tokens of the run that produced the result.
+// AgentName is the configured agent that produced it, nil for results stored
+// before agents were named. Agreement is set when the run classified with an
+// ensemble of agents: the share of the agents that succeeded whose marking
+// matches this one. Any disagreement lowers confidence to LOW. Escalation is
+// set when the first answer's confidence was at or below the escalation
+// threshold: it names the agent that classified the document again, whose
+// answer this is, and keeps the answer it replaced.
 type Classification struct {
-	ID             uuid.UUID  `json:"id"`
-	DocumentID     uuid.UUID  `json:"document_id"`
-	Classification string     `json:"classification"`
-	Confidence     string     `json:"confidence"`
-	MarkingsFound  []string   `json:"markings_found"`
-	Rationale      string     `json:"rationale"`
-	ClassifiedAt   time.Time  `json:"classified_at"`
-	ModelName      string     `json:"model_name"`
-	ProviderName   string     `json:"provider_name"`
-	ValidatedBy    *string    `json:"validated_by"`
-	ValidatedAt    *time.Time `json:"validated_at"`
+	ID             uuid.UUID         `json:"id"`
+	DocumentID     uuid.UUID         `json:"document_id"`
+	Classification string            `json:"classification"`
+	Confidence     string            `json:"confidence"`
+	MarkingsFound  []string          `json:"markings_found"`
+	Rationale      string            `json:"rationale"`
+	ClassifiedAt   time.Time         `json:"classified_at"`
+	ModelName      string            `json:"model_name"`
+	ProviderName   string            `json:"provider_name"`
+	ValidatedBy    *string           `json:"validated_by"`
+	ValidatedAt    *time.Time        `json:"validated_at"`
+	Marking        *markings.Marking `json:"marking"`
+	MarkingError   *string           `json:"marking_error"`
+	RollUp         *string           `json:"roll_up"`
+	Discrepancy    *string           `json:"discrepancy"`
+	FailedPages    []int             `json:"failed_pages"`
+	Usage          state.Usage       `json:"usage"`
+	AgentName      *string           `json:"agent_name"`
+	Agreement      *float64          `json:"agreement"`
+	Escalation     *state.Escalation `json:"escalation"`
+}
+
+// Snapshot returns the reviewable values of the classification.
+func (c Classification) Snapshot() Snapshot {
+	return Snapshot{
+		Classification: c.Classification,
+		Confidence:     c.Confidence,
+		MarkingsFound:  c.MarkingsFound,
+		Rationale:      c.Rationale,
+		ValidatedBy:    c.ValidatedBy,
+		ValidatedAt:    c.ValidatedAt,
+	}
+}
+
+// Page holds the findings for a single page of a classified document. It
+// mirrors the classification_pages table. EnhanceSettings records the
+// adjustments applied when the page was re-rendered by the enhance stage, and
+// Usage the vision calls made for the page. Error describes why the page could
+// not be analyzed; it is nil for pages that completed. TextOnly is set when
+// the page was classified from its text layer without a vision call, and
+// BannersOnly when it was classified from its banner crop without the full
+// page image.
+type Page struct {
+	ID               uuid.UUID              `json:"id"`
+	ClassificationID uuid.UUID              `json:"classification_id"`
+	PageNumber       int                    `json:"page_number"`
+	MarkingsFound    []string               `json:"markings_found"`
+	Rationale        string                 `json:"rationale"`
+	Enhanced         bool                   `json:"enhanced"`
+	EnhanceSettings  *state.EnhanceSettings `json:"enhance_settings"`
+	Usage            state.Usage            `json:"usage"`
+	Error            *string                `json:"error"`
+	TextOnly         bool                   `json:"text_only"`
+	BannersOnly      bool                   `json:"banners_only"`
+}
+
+// Result is one agent's answer from the run that produced a classification.
+// Every run stores a result per agent it classified with: the primary
+// agent's answer is the stored classification, and the others are kept
+// beside it for comparison. Marking is the canonical form of Classification
+// when it parses. Agrees reports whether the agent reached the primary
+// agent's marking. Error is set when the agent's run failed, leaving its
+// answer empty. EscalatedTo names the escalation agent when this is the
+// primary agent's first answer that escalation replaced.
+type Result struct {
+	ID               uuid.UUID   `json:"id"`
+	ClassificationID uuid.UUID   `json:"classification_id"`
+	DocumentID       uuid.UUID   `json:"document_id"`
+	JobID            uuid.UUID   `json:"job_id"`
+	AgentName        string      `json:"agent_name"`
+	ModelName        string      `json:"model_name"`
+	ProviderName     string      `json:"provider_name"`
+	Primary          bool        `json:"primary"`
+	Classification   string      `json:"classification"`
+	Confidence       string      `json:"confidence"`
+	Rationale        string      `json:"rationale"`
+	Marking          *string     `json:"marking"`
+	Agrees           bool        `json:"agrees"`
+	FailedPages      []int       `json:"failed_pages"`
+	Error            *string     `json:"error"`
+	Usage            state.Usage `json:"usage"`
+	CreatedAt        time.Time   `json:"created_at"`
+	EscalatedTo      *string     `json:"escalated_to"`
+}
+
+// Agent describes a configured agent that classifications can run with.
+// Default marks the agent used when a request names none, and Escalation
+// the agent low-confidence results are escalated to.
+type Agent struct {
+	Name         string `json:"name"`
+	ModelName    string `json:"model_name"`
+	ProviderName string `json:"provider_name"`
+	Default      bool   `json:"default"`
+	Escalation   bool   `json:"escalation"`
 }
 
 // ValidateCommand carries the data needed to validate a classification.
@@ -39,3 +143,166 @@ type UpdateCommand struct {
 	Rationale      string `json:"rationale"`
 	UpdatedBy      string `json:"updated_by"`
 }
+
+// JobStatus is the lifecycle state of a classification job.
+type JobStatus string
+
+// Job status constants. Jobs move from queued to running when claimed by a
+// worker; transient failures return them to queued until MaxAttempts is reached.
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// Job represents a durable request to classify a document. It mirrors the
+// classification_jobs table and is the handle clients poll to follow a
+// classification that runs independently of any HTTP request. Agents names
+// the agents the job classifies with, the first being primary; when it is
+// empty the default agent runs alone.
+type Job struct {
+	ID               uuid.UUID  `json:"id"`
+	DocumentID       uuid.UUID  `json:"document_id"`
+	BatchID          *uuid.UUID `json:"batch_id"`
+	Status           JobStatus  `json:"status"`
+	Attempts         int        `json:"attempts"`
+	MaxAttempts      int        `json:"max_attempts"`
+	LastError        *string    `json:"last_error"`
+	ClassificationID *uuid.UUID `json:"classification_id"`
+	RunAfter         time.Time  `json:"run_after"`
+	CreatedAt        time.Time  `json:"created_at"`
+	StartedAt        *time.Time `json:"started_at"`
+	CompletedAt      *time.Time `json:"completed_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	Agents           []string   `json:"agents"`
+}
+
+// Terminal reports whether the job has reached a final state.
+func (j Job) Terminal() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed
+}
+
+// Batch groups the jobs enqueued by a single bulk classification request.
+// Total is the number of jobs the batch queued; Skipped counts matching
+// documents that already had an active job. The status counts are computed
+// from the batch's jobs when the batch is read.
+type Batch struct {
+	ID        uuid.UUID         `json:"id"`
+	Filters   documents.Filters `json:"filters"`
+	Total     int               `json:"total"`
+	Skipped   int               `json:"skipped"`
+	Queued    int               `json:"queued"`
+	Running   int               `json:"running"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// Done reports whether every job in the batch has reached a terminal state.
+func (b Batch) Done() bool {
+	return b.Succeeded+b.Failed >= b.Total
+}
+
+// RevisionAction identifies the operation that produced a revision.
+type RevisionAction string
+
+// Revision actions. Classify revisions are written by the workflow; validate
+// and update revisions record human review.
+const (
+	RevisionClassify RevisionAction = "classify"
+	RevisionValidate RevisionAction = "validate"
+	RevisionUpdate   RevisionAction = "update"
+)
+
+// SystemActor is the actor recorded on revisions written by the workflow.
+const SystemActor = "herald"
+
+// Snapshot captures the reviewable values of a classification at one point in
+// its history.
+type Snapshot struct {
+	Classification string     `json:"classification"`
+	Confidence     string     `json:"confidence"`
+	MarkingsFound  []string   `json:"markings_found"`
+	Rationale      string     `json:"rationale"`
+	ValidatedBy    *string    `json:"validated_by"`
+	ValidatedAt    *time.Time `json:"validated_at"`
+}
+
+// Revision is an immutable audit record of one change to a classification.
+// It mirrors the classification_revisions table. Before is nil for the first
+// revision of a classification. Prompts lists the prompt versions a classify
+// run used and Usage the model calls and tokens it consumed; both are empty
+// for human actions.
+type Revision struct {
+	ID               uuid.UUID         `json:"id"`
+	ClassificationID uuid.UUID         `json:"classification_id"`
+	DocumentID       uuid.UUID         `json:"document_id"`
+	Revision         int               `json:"revision"`
+	Action           RevisionAction    `json:"action"`
+	Actor            string            `json:"actor"`
+	ModelName        string            `json:"model_name"`
+	ProviderName     string            `json:"provider_name"`
+	Prompts          []prompts.Version `json:"prompts"`
+	Usage            state.Usage       `json:"usage"`
+	Before           *Snapshot         `json:"before"`
+	After            Snapshot          `json:"after"`
+	CreatedAt        time.Time         `json:"created_at"`
+}
+
+// FieldChange is a single value that differs between two revisions.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// RevisionDiff lists the fields that differ between the results of two
+// revisions of the same classification.
+type RevisionDiff struct {
+	ClassificationID uuid.UUID     `json:"classification_id"`
+	From             int           `json:"from"`
+	To               int           `json:"to"`
+	Changes          []FieldChange `json:"changes"`
+}
+
+// Diff compares the resulting values of two revisions. Fields that are equal
+// in both are omitted; an empty Changes means the revisions agree.
+func Diff(from, to Revision) RevisionDiff {
+	a, b := from.After, to.After
+	changes := []FieldChange{}
+
+	add := func(field string, equal bool, x, y any) {
+		if !equal {
+			changes = append(changes, FieldChange{Field: field, From: x, To: y})
+		}
+	}
+
+	add("classification", a.Classification == b.Classification, a.Classification, b.Classification)
+	add("confidence", a.Confidence == b.Confidence, a.Confidence, b.Confidence)
+	add("markings_found", slices.Equal(a.MarkingsFound, b.MarkingsFound), a.MarkingsFound, b.MarkingsFound)
+	add("rationale", a.Rationale == b.Rationale, a.Rationale, b.Rationale)
+	add("validated_by", equalPtr(a.ValidatedBy, b.ValidatedBy), a.ValidatedBy, b.ValidatedBy)
+	add("validated_at", equalTime(a.ValidatedAt, b.ValidatedAt), a.ValidatedAt, b.ValidatedAt)
+
+	return RevisionDiff{
+		ClassificationID: to.ClassificationID,
+		From:             from.Revision,
+		To:               to.Revision,
+		Changes:          changes,
+	}
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,210 @@
+package classifications
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/JaimeStill/herald/internal/agents"
+	"github.com/JaimeStill/herald/internal/state"
+	"github.com/JaimeStill/herald/internal/workflow"
+)
+
+// agentRun is one agent's run in a classification job. The first run of a
+// job is the primary agent's, whose result becomes the classification.
+// Marking and agrees are filled in by agree once every run has finished.
+// EscalatedTo is set on a primary agent's first answer that was replaced by
+// the named escalation agent's.
+type agentRun struct {
+	entry       agents.Entry
+	result      *workflow.WorkflowResult
+	err         error
+	marking     *string
+	agrees      bool
+	escalatedTo string
+}
+
+// usage returns the model usage of the run. A failed run reports none, as
+// the workflow returns no state when it fails.
+func (run agentRun) usage() state.Usage {
+	if run.err != nil {
+		return state.Usage{}
+	}
+	return run.result.State.TotalUsage()
+}
+
+// agentNames validates the agents a classify request names and returns them
+// in order with repeats dropped. No names selects the default agent, which
+// is recorded as an empty list so the job follows the configured default.
+func (r *repo) agentNames(names []string) ([]string, error) {
+	entries, err := r.resolveAgents(names)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return []string{}, nil
+	}
+
+	resolved := make([]string, len(entries))
+	for i, e := range entries {
+		resolved[i] = e.Name
+	}
+	return resolved, nil
+}
+
+// resolveAgents returns the registry entries for names in order, dropping
+// repeats. An empty name, or no names at all, selects the default agent.
+func (r *repo) resolveAgents(names []string) ([]agents.Entry, error) {
+	if len(names) == 0 {
+		return []agents.Entry{r.agents.Default()}, nil
+	}
+
+	entries := make([]agents.Entry, 0, len(names))
+	for _, name := range names {
+		e, err := r.agents.Lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.ContainsFunc(entries, func(x agents.Entry) bool { return x.Name == e.Name }) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// runtime returns the workflow runtime for classifying with e. Only the
+// primary agent's run is checkpointed, under the job's ID; the other agents
+// of an ensemble start over when an interrupted job resumes.
+func (r *repo) runtime(e agents.Entry, primary bool) *workflow.Runtime {
+	rt := *r.rt
+	rt.NewAgent = e.New
+	rt.Model = e.Model
+	rt.Provider = e.Provider
+	rt.Logger = r.rt.Logger.With("agent", e.Name)
+	if !primary {
+		rt.Checkpoints = nil
+		rt.Escalation = nil
+	}
+	return &rt
+}
+
+// primaryRuntime returns the workflow runtime of the job's primary agent. A
+// job whose agents include the escalation agent does not escalate, as that
+// agent answers for the job already.
+func (r *repo) primaryRuntime(entries []agents.Entry) *workflow.Runtime {
+	rt := r.runtime(entries[0], true)
+	if rt.Escalation != nil && slices.ContainsFunc(entries, func(e agents.Entry) bool {
+		return e.Name == rt.Escalation.Agent.Name
+	}) {
+		rt.Escalation = nil
+	}
+	return rt
+}
+
+// escalated splits an escalated primary run in two: the escalation agent's
+// answer, which becomes the primary run, and the primary agent's first
+// answer, which follows it and records the agent that replaced it. Runs that
+// did not escalate are returned unchanged.
+func escalated(runs []agentRun) []agentRun {
+	primary := runs[0]
+	esc := primary.result.State.Escalation
+	if esc == nil {
+		return runs
+	}
+
+	first := agentRun{
+		entry: primary.entry,
+		result: &workflow.WorkflowResult{
+			DocumentID: primary.result.DocumentID,
+			Filename:   primary.result.Filename,
+			PageCount:  primary.result.PageCount,
+			State: state.ClassificationState{
+				Classification: esc.Classification,
+				Confidence:     esc.Confidence,
+				Rationale:      esc.Rationale,
+				FailedPages:    esc.FailedPages,
+				Usage:          esc.Usage,
+			},
+			CompletedAt: primary.result.CompletedAt,
+		},
+		escalatedTo: esc.Agent,
+	}
+
+	primary.entry = agents.Entry{
+		Name:     esc.Agent,
+		Model:    esc.Model,
+		Provider: esc.Provider,
+	}
+
+	return append([]agentRun{primary, first}, runs[1:]...)
+}
+
+// runSecondary classifies the job's document with one of the ensemble's
+// other agents. Its progress is not streamed, and a failure is returned on
+// the run rather than failing the job.
+func (r *repo) runSecondary(ctx context.Context, job *Job, e agents.Entry) agentRun {
+	observer := workflow.NewStreamingObserver(0, r.rt.Logger)
+	defer observer.Close()
+
+	runID := uuid.NewSHA1(job.ID, []byte(e.Name))
+
+	result, err := workflow.Execute(ctx, r.runtime(e, false), runID, job.DocumentID, observer)
+	if err != nil {
+		r.logger.Warn("ensemble agent failed",
+			"job_id", job.ID,
+			"document_id", job.DocumentID,
+			"agent", e.Name,
+			"error", err,
+		)
+	}
+	return agentRun{entry: e, result: result, err: err}
+}
+
+// agree records the marking of each successful run and whether it matches
+// the primary run's, and returns the share of successful runs that match.
+// Markings are compared in canonical form, falling back to the raw string
+// when it does not parse. A first answer replaced by escalation is compared
+// but not counted. It returns nil when the job ran a single agent.
+func (r *repo) agree(runs []agentRun) *float64 {
+	var want string
+	var compared, succeeded, agreed int
+	for i := range runs {
+		run := &runs[i]
+		if run.escalatedTo == "" {
+			compared++
+		}
+		if run.err != nil {
+			continue
+		}
+
+		raw := run.result.State.Classification
+		got := strings.ToUpper(strings.TrimSpace(raw))
+		if m, err := r.markings.Parser.Parse(raw); err == nil {
+			run.marking = &m.Canonical
+			got = m.Canonical
+		}
+
+		if i == 0 {
+			want = got
+		}
+
+		run.agrees = got == want
+		if run.escalatedTo != "" {
+			continue
+		}
+
+		succeeded++
+		if run.agrees {
+			agreed++
+		}
+	}
+
+	if compared < 2 {
+		return nil
+	}
+
+	agreement := float64(agreed) / float64(succeeded)
+	return &agreement
+}
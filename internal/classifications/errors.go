@@ -3,6 +3,9 @@ package classifications
 import (
 	"errors"
 	"net/http"
+
+	"github.com/JaimeStill/herald/internal/agents"
+	"github.com/JaimeStill/herald/internal/markings"
 )
 
 // Domain errors for classification operations.
@@ -10,13 +13,30 @@ var (
 	ErrNotFound      = errors.New("classification not found")
 	ErrDuplicate     = errors.New("classification already exists")
 	ErrInvalidStatus = errors.New("document is not in review status")
+	ErrJobNotFound   = errors.New("classification job not found")
+	ErrBatchNotFound = errors.New("classification batch not found")
+	ErrLeaseLost     = errors.New("classification job lease lost")
+
+	ErrRevisionNotFound = errors.New("classification revision not found")
+	ErrInvalidRevision  = errors.New("revision must be a positive integer")
+
+	ErrInvalidUsageFilter = errors.New("invalid usage filter")
 )
 
 // MapHTTPStatus maps classification domain errors to appropriate HTTP status codes.
 func MapHTTPStatus(err error) int {
-	if errors.Is(err, ErrNotFound) {
+	if errors.Is(err, ErrNotFound) ||
+		errors.Is(err, ErrJobNotFound) ||
+		errors.Is(err, ErrBatchNotFound) ||
+		errors.Is(err, ErrRevisionNotFound) {
 		return http.StatusNotFound
 	}
+	if errors.Is(err, ErrInvalidRevision) ||
+		errors.Is(err, ErrInvalidUsageFilter) ||
+		errors.Is(err, agents.ErrUnknownAgent) ||
+		errors.Is(err, markings.ErrInvalidMarking) {
+		return http.StatusBadRequest
+	}
 	if errors.Is(err, ErrDuplicate) {
 		return http.StatusConflict
 	}
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/google/uuid"
 
+	"github.com/JaimeStill/herald/internal/documents"
+	"github.com/JaimeStill/herald/internal/workflow"
 	"github.com/JaimeStill/herald/pkg/auth"
 	"github.com/JaimeStill/herald/pkg/handlers"
 	"github.com/JaimeStill/herald/pkg/pagination"
@@ -48,7 +51,18 @@ func (h *Handler) Routes() routes.Group {
 			{Method: "GET", Pattern: "", Handler: h.List},
 			{Method: "GET", Pattern: "/{id}", Handler: h.Find},
 			{Method: "GET", Pattern: "/document/{id}", Handler: h.FindByDocument},
+			{Method: "GET", Pattern: "/document/{id}/events", Handler: h.Events},
+			{Method: "GET", Pattern: "/pages/{id}", Handler: h.Pages},
+			{Method: "GET", Pattern: "/results/{id}", Handler: h.Results},
+			{Method: "GET", Pattern: "/history/{id}", Handler: h.History},
+			{Method: "GET", Pattern: "/history/{id}/diff", Handler: h.Diff},
+			{Method: "GET", Pattern: "/usage", Handler: h.Usage},
+			{Method: "GET", Pattern: "/agents", Handler: h.Agents},
 			{Method: "POST", Pattern: "/search", Handler: h.Search},
+			{Method: "POST", Pattern: "/bulk", Handler: h.ClassifyBulk},
+			{Method: "GET", Pattern: "/batches/{id}", Handler: h.FindBatch},
+			{Method: "GET", Pattern: "/jobs", Handler: h.ListJobs},
+			{Method: "GET", Pattern: "/jobs/{id}", Handler: h.FindJob},
 			{Method: "POST", Pattern: "/{documentId}", Handler: h.Classify},
 			{Method: "POST", Pattern: "/{id}/validate", Handler: h.Validate},
 			{Method: "PUT", Pattern: "/{id}", Handler: h.Update},
@@ -105,6 +119,113 @@ func (h *Handler) FindByDocument(w http.ResponseWriter, r *http.Request) {
 	handlers.RespondJSON(w, http.StatusOK, c)
 }
 
+// Pages returns the per-page findings for a classification, ordered by page
+// number, including whether each page was enhanced and with which settings.
+func (h *Handler) Pages(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		handlers.RespondError(w, h.logger, http.StatusBadRequest, ErrNotFound)
+		return
+	}
+
+	pages, err := h.sys.Pages(r.Context(), id)
+	if err != nil {
+		handlers.RespondError(w, h.logger, MapHTTPStatus(err), err)
+		return
+	}
+
+	handlers.RespondJSON(w, http.StatusOK, pages)
+}
+
+// Results returns each agent's answer from the run that produced a
+// classification, primary agent first, so ensemble runs can be compared.
+func (h *Handler) Results(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		handlers.RespondError(w, h.logger, http.StatusBadRequest, ErrNotFound)
+		return
+	}
+
+	results, err := h.sys.Results(r.Context(), id)
+	if err != nil {
+		handlers.RespondError(w, h.logger, MapHTTPStatus(err), err)
+		return
+	}
+
+	handlers.RespondJSON(w, http.StatusOK, results)
+}
+
+// History returns the immutable revision trail of a classification, oldest first.
+func (h *Handler) History(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		handlers.RespondError(w, h.logger, http.StatusBadRequest, ErrNotFound)
+		return
+	}
+
+	revs, err := h.sys.History(r.Context(), id)
+	if err != nil {
+		handlers.RespondError(w, h.logger, MapHTTPStatus(err), err)
+		return
+	}
+
+	handlers.RespondJSON(w, http.StatusOK, revs)
+}
+
+// Diff compares two revisions of a classification, identified by the from and
+// to query parameters, and returns the fields whose values differ.
+func (h *Handler) Diff(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		handlers.RespondError(w, h.logger, http.StatusBadRequest, ErrNotFound)
+		return
+	}
+
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil {
+		handlers.RespondError(w, h.logger, http.StatusBadRequest, ErrInvalidRevision)
+		return
+	}
+
+	to, err := strconv.Atoi(r.URL.Query().Get("to"))
+	if err != nil {
+		handlers.RespondError(w, h.logger, http.StatusBadRequest, ErrInvalidRevision)
+		return
+	}
+
+	d, err := h.sys.DiffRevisions(r.Context(), id, from, to)
+	if err != nil {
+		handlers.RespondError(w, h.logger, MapHTTPStatus(err), err)
+		return
+	}
+
+	handlers.RespondJSON(w, http.StatusOK, d)
+}
+
+// Usage reports the model calls, tokens, and cost of classify runs grouped by
+// day, model, and external platform, narrowed by the from, to, model_name,
+// and external_platform query parameters.
+func (h *Handler) Usage(w http.ResponseWriter, r *http.Request) {
+	filters, err := UsageFiltersFromQuery(r.URL.Query())
+	if err != nil {
+		handlers.RespondError(w, h.logger, http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := h.sys.Usage(r.Context(), filters)
+	if err != nil {
+		handlers.RespondError(w, h.logger, MapHTTPStatus(err), err)
+		return
+	}
+
+	handlers.RespondJSON(w, http.StatusOK, report)
+}
+
+// Agents lists the configured agents that classify requests can name.
+func (h *Handler) Agents(w http.ResponseWriter, r *http.Request) {
+	handlers.RespondJSON(w, http.StatusOK, h.sys.Agents())
+}
+
 // Search accepts a JSON body with pagination and filter criteria and returns matching classifications.
 func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
 	var req SearchRequest
@@ -124,21 +245,38 @@ func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
 	handlers.RespondJSON(w, http.StatusOK, result)
 }
 
-// Classify initiates the classification workflow for a document and streams progress
-// via Server-Sent Events. Pre-stream errors (invalid UUID, document not found) return
-// JSON. Once streaming begins, errors arrive as SSE error events on the channel.
-func (h *Handler) Classify(w http.ResponseWriter, r *http.Request) {
-	documentID, err := uuid.Parse(r.PathValue("documentId"))
+// Events streams classification progress for a document via Server-Sent Events.
+// Buffered events newer than the Last-Event-ID header (or last_event_id query
+// parameter) are replayed first, then live events follow until the run finishes
+// or the client disconnects. Any number of clients may watch the same document;
+// each receives the full stream. Pre-stream errors return JSON.
+func (h *Handler) Events(w http.ResponseWriter, r *http.Request) {
+	documentID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
 		handlers.RespondError(w, h.logger, http.StatusBadRequest, ErrNotFound)
 		return
 	}
 
-	events, err := h.sys.Classify(r.Context(), documentID)
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+
+	var after uint64
+	if lastEventID != "" {
+		after, err = strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			handlers.RespondError(w, h.logger, http.StatusBadRequest, fmt.Errorf("invalid Last-Event-ID: %q", lastEventID))
+			return
+		}
+	}
+
+	replay, sub, err := h.sys.Events(r.Context(), documentID, after)
 	if err != nil {
 		handlers.RespondError(w, h.logger, MapHTTPStatus(err), err)
 		return
 	}
+	defer sub.Cancel()
 
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -149,28 +287,129 @@ func (h *Handler) Classify(w http.ResponseWriter, r *http.Request) {
 		f.Flush()
 	}
 
-	for event := range events {
+	for _, event := range replay {
+		h.writeEvent(w, event)
+	}
+
+	for {
 		select {
 		case <-r.Context().Done():
 			return
-		default:
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			h.writeEvent(w, event)
 		}
+	}
+}
 
-		data, err := json.Marshal(event)
-		if err != nil {
-			h.logger.Error("failed to marshal event", "error", err)
-			continue
-		}
+func (h *Handler) writeEvent(w http.ResponseWriter, event workflow.ExecutionEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		h.logger.Error("failed to marshal event", "error", err)
+		return
+	}
 
-		fmt.Fprintf(w, "event: %s\n", event.Type)
-		fmt.Fprintf(w, "data: %s\n\n", data)
+	fmt.Fprintf(w, "id: %d\n", event.ID)
+	fmt.Fprintf(w, "event: %s\n", event.Type)
+	fmt.Fprintf(w, "data: %s\n\n", data)
 
-		if f, ok := w.(http.Flusher); ok {
-			f.Flush()
-		}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
 	}
 }
 
+// ClassifyBulk decodes a documents.Filters JSON body, enqueues a classification
+// job for every matching document, and responds 202 Accepted with the batch.
+// Jobs run through the shared queue, so the global running-job cap applies.
+// Repeated agent query parameters select the agents, as for Classify.
+func (h *Handler) ClassifyBulk(w http.ResponseWriter, r *http.Request) {
+	var filters documents.Filters
+	if err := json.NewDecoder(r.Body).Decode(&filters); err != nil {
+		handlers.RespondError(w, h.logger, http.StatusBadRequest, err)
+		return
+	}
+
+	b, err := h.sys.ClassifyBulk(r.Context(), filters, r.URL.Query()["agent"])
+	if err != nil {
+		handlers.RespondError(w, h.logger, MapHTTPStatus(err), err)
+		return
+	}
+
+	handlers.RespondJSON(w, http.StatusAccepted, b)
+}
+
+// FindBatch returns a classification batch with its aggregate job status counts.
+func (h *Handler) FindBatch(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		handlers.RespondError(w, h.logger, http.StatusBadRequest, ErrBatchNotFound)
+		return
+	}
+
+	b, err := h.sys.FindBatch(r.Context(), id)
+	if err != nil {
+		handlers.RespondError(w, h.logger, MapHTTPStatus(err), err)
+		return
+	}
+
+	handlers.RespondJSON(w, http.StatusOK, b)
+}
+
+// ListJobs returns a paginated list of classification jobs with optional query parameter filters.
+func (h *Handler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	page := pagination.PageRequestFromQuery(r.URL.Query(), h.pagination)
+	filters := JobFiltersFromQuery(r.URL.Query())
+
+	result, err := h.sys.ListJobs(r.Context(), page, filters)
+	if err != nil {
+		handlers.RespondError(w, h.logger, http.StatusInternalServerError, err)
+		return
+	}
+
+	handlers.RespondJSON(w, http.StatusOK, result)
+}
+
+// FindJob returns a single classification job by its UUID path parameter.
+// Clients poll this endpoint to follow a queued classification to completion.
+func (h *Handler) FindJob(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		handlers.RespondError(w, h.logger, http.StatusBadRequest, ErrJobNotFound)
+		return
+	}
+
+	j, err := h.sys.FindJob(r.Context(), id)
+	if err != nil {
+		handlers.RespondError(w, h.logger, MapHTTPStatus(err), err)
+		return
+	}
+
+	handlers.RespondJSON(w, http.StatusOK, j)
+}
+
+// Classify enqueues a durable classification job for a document and responds
+// 202 Accepted with the job. If the document already has a queued or running
+// job, that job is returned instead of creating a duplicate. An agent query
+// parameter classifies with that configured agent instead of the default;
+// repeating it runs an ensemble whose first agent is primary.
+func (h *Handler) Classify(w http.ResponseWriter, r *http.Request) {
+	documentID, err := uuid.Parse(r.PathValue("documentId"))
+	if err != nil {
+		handlers.RespondError(w, h.logger, http.StatusBadRequest, ErrNotFound)
+		return
+	}
+
+	j, err := h.sys.Classify(r.Context(), documentID, r.URL.Query()["agent"])
+	if err != nil {
+		handlers.RespondError(w, h.logger, MapHTTPStatus(err), err)
+		return
+	}
+
+	handlers.RespondJSON(w, http.StatusAccepted, j)
+}
+
 // Validate marks a classification as human-validated by decoding a ValidateCommand JSON body.
 // Transitions the associated document status from review to complete.
 func (h *Handler) Validate(w http.ResponseWriter, r *http.Request) {
@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"strconv"
 
 	"github.com/google/uuid"
 
+	"github.com/JaimeStill/herald/internal/markings"
+	"github.com/JaimeStill/herald/internal/workflow"
 	"github.com/JaimeStill/herald/pkg/query"
 	"github.com/JaimeStill/herald/pkg/repository"
 )
@@ -23,20 +26,73 @@ var projection = query.
 	Project("model_name", "ModelName").
 	Project("provider_name", "ProviderName").
 	Project("validated_by", "ValidatedBy").
-	Project("validated_at", "ValidatedAt")
+	Project("validated_at", "ValidatedAt").
+	Project("marking", "Marking").
+	Project("marking_level", "MarkingLevel").
+	Project("marking_sci", "MarkingSCI").
+	Project("marking_sap", "MarkingSAP").
+	Project("marking_dissemination", "MarkingDissemination").
+	Project("marking_releasable_to", "MarkingReleasableTo").
+	Project("marking_declassification", "MarkingDeclassification").
+	Project("marking_error", "MarkingError").
+	Project("roll_up", "RollUp").
+	Project("discrepancy", "Discrepancy").
+	Project("failed_pages", "FailedPages").
+	Project("vision_calls", "VisionCalls").
+	Project("chat_calls", "ChatCalls").
+	Project("prompt_tokens", "PromptTokens").
+	Project("completion_tokens", "CompletionTokens").
+	Project("agent_name", "AgentName").
+	Project("agreement", "Agreement").
+	Project("escalation", "Escalation")
 
 var defaultSort = query.SortField{
 	Field:      "ClassifiedAt",
 	Descending: true,
 }
 
+var jobProjection = query.
+	NewProjectionMap("public", "classification_jobs", "j").
+	Project("id", "ID").
+	Project("document_id", "DocumentID").
+	Project("batch_id", "BatchID").
+	Project("status", "Status").
+	Project("attempts", "Attempts").
+	Project("max_attempts", "MaxAttempts").
+	Project("last_error", "LastError").
+	Project("classification_id", "ClassificationID").
+	Project("run_after", "RunAfter").
+	Project("created_at", "CreatedAt").
+	Project("started_at", "StartedAt").
+	Project("completed_at", "CompletedAt").
+	Project("updated_at", "UpdatedAt").
+	Project("agents", "Agents")
+
+var jobDefaultSort = query.SortField{
+	Field:      "CreatedAt",
+	Descending: true,
+}
+
 // Filters contains optional filtering criteria for classification queries.
-// Nil fields are ignored. All fields use exact matching.
+// Nil fields are ignored. Marking matches the canonical marking and Level its
+// canonical level; SCI and Dissemination match classifications carrying that
+// control. Discrepant selects classifications whose workflow result did or did
+// not disagree with the marking roll-up, Partial those whose run did or did
+// not leave pages unanalyzed, and Escalated those whose run did or did not
+// escalate to a stronger agent. All other fields use exact matching.
 type Filters struct {
 	Classification *string    `json:"classification,omitempty"`
 	Confidence     *string    `json:"confidence,omitempty"`
 	DocumentID     *uuid.UUID `json:"document_id,omitempty"`
 	ValidatedBy    *string    `json:"validated_by,omitempty"`
+	Marking        *string    `json:"marking,omitempty"`
+	Level          *string    `json:"level,omitempty"`
+	SCI            *string    `json:"sci,omitempty"`
+	Dissemination  *string    `json:"dissemination,omitempty"`
+	Discrepant     *bool      `json:"discrepant,omitempty"`
+	Partial        *bool      `json:"partial,omitempty"`
+	AgentName      *string    `json:"agent_name,omitempty"`
+	Escalated      *bool      `json:"escalated,omitempty"`
 }
 
 // Apply adds filter conditions to a query builder.
@@ -45,7 +101,15 @@ func (f Filters) Apply(b *query.Builder) *query.Builder {
 		WhereEquals("Classification", f.Classification).
 		WhereEquals("Confidence", f.Confidence).
 		WhereEquals("DocumentID", f.DocumentID).
-		WhereEquals("ValidatedBy", f.ValidatedBy)
+		WhereEquals("ValidatedBy", f.ValidatedBy).
+		WhereEquals("Marking", f.Marking).
+		WhereEquals("MarkingLevel", f.Level).
+		WhereJSONContains("MarkingSCI", f.SCI).
+		WhereJSONContains("MarkingDissemination", f.Dissemination).
+		WherePresent("Discrepancy", f.Discrepant).
+		WherePresent("FailedPages", f.Partial).
+		WhereEquals("AgentName", f.AgentName).
+		WherePresent("Escalation", f.Escalated)
 }
 
 // FiltersFromQuery extracts filter values from URL query parameters.
@@ -70,12 +134,158 @@ func FiltersFromQuery(values url.Values) Filters {
 		f.ValidatedBy = &v
 	}
 
+	if m := values.Get("marking"); m != "" {
+		f.Marking = &m
+	}
+
+	if l := values.Get("level"); l != "" {
+		f.Level = &l
+	}
+
+	if s := values.Get("sci"); s != "" {
+		f.SCI = &s
+	}
+
+	if d := values.Get("dissemination"); d != "" {
+		f.Dissemination = &d
+	}
+
+	if d := values.Get("discrepant"); d != "" {
+		if v, err := strconv.ParseBool(d); err == nil {
+			f.Discrepant = &v
+		}
+	}
+
+	if p := values.Get("partial"); p != "" {
+		if v, err := strconv.ParseBool(p); err == nil {
+			f.Partial = &v
+		}
+	}
+
+	if a := values.Get("agent_name"); a != "" {
+		f.AgentName = &a
+	}
+
+	if e := values.Get("escalated"); e != "" {
+		if v, err := strconv.ParseBool(e); err == nil {
+			f.Escalated = &v
+		}
+	}
+
+	return f
+}
+
+// JobFilters contains optional filtering criteria for classification job queries.
+// Nil fields are ignored. All fields use exact matching.
+type JobFilters struct {
+	DocumentID *uuid.UUID `json:"document_id,omitempty"`
+	BatchID    *uuid.UUID `json:"batch_id,omitempty"`
+	Status     *JobStatus `json:"status,omitempty"`
+}
+
+// Apply adds filter conditions to a query builder.
+func (f JobFilters) Apply(b *query.Builder) *query.Builder {
+	return b.
+		WhereEquals("DocumentID", f.DocumentID).
+		WhereEquals("BatchID", f.BatchID).
+		WhereEquals("Status", f.Status)
+}
+
+// JobFiltersFromQuery extracts job filter values from URL query parameters.
+func JobFiltersFromQuery(values url.Values) JobFilters {
+	var f JobFilters
+
+	if d := values.Get("document_id"); d != "" {
+		if id, err := uuid.Parse(d); err == nil {
+			f.DocumentID = &id
+		}
+	}
+
+	if b := values.Get("batch_id"); b != "" {
+		if id, err := uuid.Parse(b); err == nil {
+			f.BatchID = &id
+		}
+	}
+
+	if s := values.Get("status"); s != "" {
+		status := JobStatus(s)
+		f.Status = &status
+	}
+
 	return f
 }
 
+func scanJob(s repository.Scanner) (Job, error) {
+	var j Job
+	var agentsRaw []byte
+
+	err := s.Scan(
+		&j.ID,
+		&j.DocumentID,
+		&j.BatchID,
+		&j.Status,
+		&j.Attempts,
+		&j.MaxAttempts,
+		&j.LastError,
+		&j.ClassificationID,
+		&j.RunAfter,
+		&j.CreatedAt,
+		&j.StartedAt,
+		&j.CompletedAt,
+		&j.UpdatedAt,
+		&agentsRaw,
+	)
+
+	if err != nil {
+		return j, err
+	}
+
+	if len(agentsRaw) > 0 {
+		if err := json.Unmarshal(agentsRaw, &j.Agents); err != nil {
+			return j, fmt.Errorf("unmarshal agents: %w", err)
+		}
+	}
+
+	if j.Agents == nil {
+		j.Agents = []string{}
+	}
+
+	return j, nil
+}
+
+func scanBatch(s repository.Scanner) (Batch, error) {
+	var b Batch
+	var filtersRaw []byte
+
+	err := s.Scan(
+		&b.ID,
+		&filtersRaw,
+		&b.Total,
+		&b.Skipped,
+		&b.Queued,
+		&b.Running,
+		&b.Succeeded,
+		&b.Failed,
+		&b.CreatedAt,
+	)
+
+	if err != nil {
+		return b, err
+	}
+
+	if len(filtersRaw) > 0 {
+		if err := json.Unmarshal(filtersRaw, &b.Filters); err != nil {
+			return b, fmt.Errorf("unmarshal filters: %w", err)
+		}
+	}
+
+	return b, nil
+}
+
 func scanClassification(s repository.Scanner) (Classification, error) {
 	var c Classification
-	var markingsRaw []byte
+	var markingsRaw, sciRaw, sapRaw, dissemRaw, relToRaw, failedRaw, escalationRaw []byte
+	var canonical, level, declass *string
 
 	err := s.Scan(
 		&c.ID,
@@ -89,6 +299,24 @@ func scanClassification(s repository.Scanner) (Classification, error) {
 		&c.ProviderName,
 		&c.ValidatedBy,
 		&c.ValidatedAt,
+		&canonical,
+		&level,
+		&sciRaw,
+		&sapRaw,
+		&dissemRaw,
+		&relToRaw,
+		&declass,
+		&c.MarkingError,
+		&c.RollUp,
+		&c.Discrepancy,
+		&failedRaw,
+		&c.Usage.VisionCalls,
+		&c.Usage.ChatCalls,
+		&c.Usage.PromptTokens,
+		&c.Usage.CompletionTokens,
+		&c.AgentName,
+		&c.Agreement,
+		&escalationRaw,
 	)
 
 	if err != nil {
@@ -105,5 +333,239 @@ func scanClassification(s repository.Scanner) (Classification, error) {
 		c.MarkingsFound = []string{}
 	}
 
+	if len(failedRaw) > 0 {
+		if err := json.Unmarshal(failedRaw, &c.FailedPages); err != nil {
+			return c, fmt.Errorf("unmarshal failed_pages: %w", err)
+		}
+	}
+
+	if c.FailedPages == nil {
+		c.FailedPages = []int{}
+	}
+
+	if len(escalationRaw) > 0 {
+		if err := json.Unmarshal(escalationRaw, &c.Escalation); err != nil {
+			return c, fmt.Errorf("unmarshal escalation: %w", err)
+		}
+	}
+
+	if canonical == nil {
+		return c, nil
+	}
+
+	m := markings.Marking{Canonical: *canonical}
+	if level != nil {
+		m.Level = *level
+	}
+	if declass != nil {
+		m.Declassification = *declass
+	}
+
+	for _, field := range []struct {
+		name string
+		raw  []byte
+		dst  *[]string
+	}{
+		{"marking_sci", sciRaw, &m.SCI},
+		{"marking_sap", sapRaw, &m.SAP},
+		{"marking_dissemination", dissemRaw, &m.Dissemination},
+		{"marking_releasable_to", relToRaw, &m.ReleasableTo},
+	} {
+		if err := json.Unmarshal(field.raw, field.dst); err != nil {
+			return c, fmt.Errorf("unmarshal %s: %w", field.name, err)
+		}
+	}
+
+	c.Marking = &m
 	return c, nil
 }
+
+// markingArgs returns the values of the marking columns, in column order, for
+// a classification normalized to m or flagged with reason.
+func markingArgs(m *markings.Marking, reason *string) ([]any, error) {
+	if m == nil {
+		return []any{nil, nil, "[]", "[]", "[]", "[]", nil, reason}, nil
+	}
+
+	args := []any{m.Canonical, m.Level}
+	for _, list := range [][]string{m.SCI, m.SAP, m.Dissemination, m.ReleasableTo} {
+		if list == nil {
+			list = []string{}
+		}
+		raw, err := json.Marshal(list)
+		if err != nil {
+			return nil, fmt.Errorf("marshal marking: %w", err)
+		}
+		args = append(args, raw)
+	}
+
+	var declass *string
+	if m.Declassification != "" {
+		declass = &m.Declassification
+	}
+	return append(args, declass, nil), nil
+}
+
+func scanPage(s repository.Scanner) (Page, error) {
+	var p Page
+	var markingsRaw, settingsRaw []byte
+
+	err := s.Scan(
+		&p.ID,
+		&p.ClassificationID,
+		&p.PageNumber,
+		&markingsRaw,
+		&p.Rationale,
+		&p.Enhanced,
+		&settingsRaw,
+		&p.Usage.VisionCalls,
+		&p.Usage.PromptTokens,
+		&p.Usage.CompletionTokens,
+		&p.Error,
+		&p.TextOnly,
+		&p.BannersOnly,
+	)
+
+	if err != nil {
+		return p, err
+	}
+
+	if len(markingsRaw) > 0 {
+		if err := json.Unmarshal(markingsRaw, &p.MarkingsFound); err != nil {
+			return p, fmt.Errorf("unmarshal markings_found: %w", err)
+		}
+	}
+
+	if p.MarkingsFound == nil {
+		p.MarkingsFound = []string{}
+	}
+
+	if len(settingsRaw) > 0 {
+		if err := json.Unmarshal(settingsRaw, &p.EnhanceSettings); err != nil {
+			return p, fmt.Errorf("unmarshal enhance_settings: %w", err)
+		}
+	}
+
+	return p, nil
+}
+
+func scanResult(s repository.Scanner) (Result, error) {
+	var res Result
+	var failedRaw []byte
+
+	err := s.Scan(
+		&res.ID,
+		&res.ClassificationID,
+		&res.DocumentID,
+		&res.JobID,
+		&res.AgentName,
+		&res.ModelName,
+		&res.ProviderName,
+		&res.Primary,
+		&res.Classification,
+		&res.Confidence,
+		&res.Rationale,
+		&res.Marking,
+		&res.Agrees,
+		&failedRaw,
+		&res.Error,
+		&res.Usage.VisionCalls,
+		&res.Usage.ChatCalls,
+		&res.Usage.PromptTokens,
+		&res.Usage.CompletionTokens,
+		&res.CreatedAt,
+		&res.EscalatedTo,
+	)
+
+	if err != nil {
+		return res, err
+	}
+
+	if len(failedRaw) > 0 {
+		if err := json.Unmarshal(failedRaw, &res.FailedPages); err != nil {
+			return res, fmt.Errorf("unmarshal failed_pages: %w", err)
+		}
+	}
+
+	if res.FailedPages == nil {
+		res.FailedPages = []int{}
+	}
+
+	return res, nil
+}
+
+func scanRevision(s repository.Scanner) (Revision, error) {
+	var rev Revision
+	var promptsRaw, beforeRaw, afterRaw []byte
+
+	err := s.Scan(
+		&rev.ID,
+		&rev.ClassificationID,
+		&rev.DocumentID,
+		&rev.Revision,
+		&rev.Action,
+		&rev.Actor,
+		&rev.ModelName,
+		&rev.ProviderName,
+		&promptsRaw,
+		&beforeRaw,
+		&afterRaw,
+		&rev.CreatedAt,
+		&rev.Usage.VisionCalls,
+		&rev.Usage.ChatCalls,
+		&rev.Usage.PromptTokens,
+		&rev.Usage.CompletionTokens,
+	)
+
+	if err != nil {
+		return rev, err
+	}
+
+	if len(promptsRaw) > 0 {
+		if err := json.Unmarshal(promptsRaw, &rev.Prompts); err != nil {
+			return rev, fmt.Errorf("unmarshal prompts: %w", err)
+		}
+	}
+
+	if len(beforeRaw) > 0 {
+		if err := json.Unmarshal(beforeRaw, &rev.Before); err != nil {
+			return rev, fmt.Errorf("unmarshal before: %w", err)
+		}
+	}
+
+	if err := json.Unmarshal(afterRaw, &rev.After); err != nil {
+		return rev, fmt.Errorf("unmarshal after: %w", err)
+	}
+
+	return rev, nil
+}
+
+func scanCheckpoint(s repository.Scanner) (workflow.Checkpoint, error) {
+	var cp workflow.Checkpoint
+	var classifiedRaw, stateRaw []byte
+
+	err := s.Scan(
+		&cp.RunID,
+		&cp.DocumentID,
+		&cp.Node,
+		&cp.Filename,
+		&cp.Digest,
+		&cp.PageCount,
+		&classifiedRaw,
+		&stateRaw,
+	)
+
+	if err != nil {
+		return cp, err
+	}
+
+	if err := json.Unmarshal(classifiedRaw, &cp.ClassifiedPages); err != nil {
+		return cp, fmt.Errorf("unmarshal classified pages: %w", err)
+	}
+
+	if err := json.Unmarshal(stateRaw, &cp.State); err != nil {
+		return cp, fmt.Errorf("unmarshal checkpoint state: %w", err)
+	}
+
+	return cp, nil
+}
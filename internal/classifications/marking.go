@@ -0,0 +1,28 @@
+package classifications
+
+import (
+	"github.com/JaimeStill/herald/internal/markings"
+)
+
+// MarkingConfig controls how classification strings are normalized before
+// they are stored. In strict mode a string that does not parse is rejected;
+// otherwise it is stored as-is and flagged with the parse error.
+type MarkingConfig struct {
+	Parser *markings.Parser
+	Strict bool
+}
+
+// normalize parses raw into its marking columns. It returns an error only in
+// strict mode; otherwise an unparseable marking yields a nil Marking and the
+// reason it was flagged.
+func (c MarkingConfig) normalize(raw string) (*markings.Marking, *string, error) {
+	m, err := c.Parser.Parse(raw)
+	if err == nil {
+		return &m, nil, nil
+	}
+	if c.Strict {
+		return nil, nil, err
+	}
+	reason := err.Error()
+	return nil, &reason, nil
+}
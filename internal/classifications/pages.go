@@ -0,0 +1,92 @@
+package classifications
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/JaimeStill/herald/internal/state"
+	"github.com/JaimeStill/herald/pkg/repository"
+)
+
+// replacePages swaps the stored per-page findings for a classification with
+// the pages from a new workflow result. It runs inside the transaction that
+// upserts the classification so pages always match the stored result.
+func replacePages(ctx context.Context, tx *sql.Tx, classificationID uuid.UUID, pages []state.ClassificationPage) error {
+	if _, err := tx.ExecContext(ctx,
+		"DELETE FROM classification_pages WHERE classification_id = $1",
+		classificationID,
+	); err != nil {
+		return fmt.Errorf("delete classification pages: %w", err)
+	}
+
+	insertQ := `
+		INSERT INTO classification_pages (
+			classification_id, page_number, markings_found,
+			rationale, enhanced, enhance_settings,
+			vision_calls, prompt_tokens, completion_tokens, error, text_only,
+			banners_only
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+	for _, p := range pages {
+		markings := p.MarkingsFound
+		if markings == nil {
+			markings = []string{}
+		}
+
+		markingsJSON, err := json.Marshal(markings)
+		if err != nil {
+			return fmt.Errorf("page %d: marshal markings: %w", p.PageNumber, err)
+		}
+
+		var settings any
+		if p.Enhanced() {
+			settingsJSON, err := json.Marshal(p.EnhancedWith)
+			if err != nil {
+				return fmt.Errorf("page %d: marshal enhance settings: %w", p.PageNumber, err)
+			}
+			settings = settingsJSON
+		}
+
+		if _, err := tx.ExecContext(ctx, insertQ,
+			classificationID,
+			p.PageNumber,
+			markingsJSON,
+			p.Rationale,
+			p.Enhanced(),
+			settings,
+			p.Usage.VisionCalls,
+			p.Usage.PromptTokens,
+			p.Usage.CompletionTokens,
+			nullable(p.Error),
+			p.TextOnly,
+			p.BannersOnly,
+		); err != nil {
+			return fmt.Errorf("page %d: insert classification page: %w", p.PageNumber, err)
+		}
+	}
+
+	return nil
+}
+
+// listPages returns the stored pages for a classification ordered by page number.
+func (r *repo) listPages(ctx context.Context, classificationID uuid.UUID) ([]Page, error) {
+	q := `
+		SELECT id, classification_id, page_number, markings_found,
+			rationale, enhanced, enhance_settings,
+			vision_calls, prompt_tokens, completion_tokens, error, text_only,
+			banners_only
+		FROM classification_pages
+		WHERE classification_id = $1
+		ORDER BY page_number`
+
+	pages, err := repository.QueryMany(ctx, r.db, q, []any{classificationID}, scanPage)
+	if err != nil {
+		return nil, fmt.Errorf("query classification pages: %w", err)
+	}
+	return pages, nil
+}
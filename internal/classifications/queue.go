@@ -0,0 +1,288 @@
+package classifications
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/JaimeStill/herald/internal/documents"
+	"github.com/JaimeStill/herald/pkg/repository"
+)
+
+// enqueueAttempts bounds how often enqueueJob retries a statement that lost a
+// race with a concurrent enqueue or completion and returned no rows.
+const enqueueAttempts = 3
+
+const jobColumns = `id, document_id, batch_id, status, attempts, max_attempts, last_error,
+		classification_id, run_after, created_at, started_at, completed_at, updated_at, agents`
+
+// enqueueJob inserts a queued job for the document that classifies with the
+// named agents. A document can have at most one active (queued or running)
+// job; enqueueing while one exists returns the existing job, with the agents
+// it was queued with, so repeated requests re-attach instead of duplicating
+// work.
+//
+// The insert and the lookup share one snapshot, so an insert that conflicts
+// with a job committed concurrently sees neither row, and a job that finishes
+// mid-statement drops out of the lookup. Either way the statement returns no
+// rows and is retried with a fresh snapshot, up to enqueueAttempts times.
+func (r *repo) enqueueJob(ctx context.Context, documentID uuid.UUID, names []string) (*Job, error) {
+	agentsJSON, err := json.Marshal(names)
+	if err != nil {
+		return nil, fmt.Errorf("marshal agents: %w", err)
+	}
+
+	q := `
+		WITH inserted AS (
+			INSERT INTO classification_jobs (document_id, max_attempts, agents)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (document_id) WHERE status IN ('queued', 'running') DO NOTHING
+			RETURNING ` + jobColumns + `
+		)
+		SELECT ` + jobColumns + ` FROM inserted
+		UNION ALL
+		SELECT ` + jobColumns + ` FROM classification_jobs
+		WHERE document_id = $1 AND status IN ('queued', 'running')
+		LIMIT 1`
+
+	args := []any{documentID, r.jobs.MaxAttempts, agentsJSON}
+	for attempt := 1; ; attempt++ {
+		j, err := repository.QueryOne(ctx, r.db, q, args, scanJob)
+		if err == nil {
+			return &j, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) || attempt == enqueueAttempts {
+			return nil, fmt.Errorf("enqueue classification job: %w", err)
+		}
+	}
+}
+
+// activeJob returns the queued or running job for a document, or
+// ErrJobNotFound when the document has none.
+func (r *repo) activeJob(ctx context.Context, documentID uuid.UUID) (*Job, error) {
+	q := `SELECT ` + jobColumns + ` FROM classification_jobs
+		WHERE document_id = $1 AND status IN ('queued', 'running')`
+
+	j, err := repository.QueryOne(ctx, r.db, q, []any{documentID}, scanJob)
+	if err != nil {
+		return nil, repository.MapError(err, ErrJobNotFound, ErrDuplicate)
+	}
+	return &j, nil
+}
+
+// claimLockKey identifies the transaction-scoped advisory lock that serializes
+// claims so the running-job cap holds across every process sharing the database.
+const claimLockKey = 0x6865726c64
+
+// claimJob atomically leases the next runnable job. Queued jobs whose run_after
+// has passed are eligible, as are running jobs whose lease expired because the
+// worker that held them stopped heartbeating (crash or restart). No job is
+// claimed while MaxRunning jobs hold live leases. Returns ErrJobNotFound when
+// nothing is runnable.
+func (r *repo) claimJob(ctx context.Context) (*Job, error) {
+	q := `
+		UPDATE classification_jobs
+		SET status = 'running',
+			attempts = attempts + 1,
+			started_at = NOW(),
+			lease_expires_at = NOW() + make_interval(secs => $1),
+			updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM classification_jobs
+			WHERE (status = 'queued' AND run_after <= NOW())
+			   OR (status = 'running' AND lease_expires_at < NOW())
+			ORDER BY run_after
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		AND (
+			SELECT COUNT(*) FROM classification_jobs
+			WHERE status = 'running' AND lease_expires_at >= NOW()
+		) < $2
+		RETURNING ` + jobColumns
+
+	args := []any{r.jobs.LeaseDuration.Seconds(), r.jobs.MaxRunning}
+
+	j, err := repository.WithTx(ctx, r.db, func(tx *sql.Tx) (Job, error) {
+		if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", claimLockKey); err != nil {
+			return Job{}, fmt.Errorf("acquire claim lock: %w", err)
+		}
+		return repository.QueryOne(ctx, tx, q, args, scanJob)
+	})
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrJobNotFound
+		}
+		return nil, fmt.Errorf("claim classification job: %w", err)
+	}
+	return &j, nil
+}
+
+// enqueueBatch records a batch and queues a job for every document matching
+// filters in a single transaction, each classifying with the named agents.
+// Matching documents that already have an active job are counted as skipped
+// rather than queued twice.
+func (r *repo) enqueueBatch(ctx context.Context, filters documents.Filters, names []string) (*Batch, error) {
+	filtersJSON, err := json.Marshal(filters)
+	if err != nil {
+		return nil, fmt.Errorf("marshal filters: %w", err)
+	}
+
+	agentsJSON, err := json.Marshal(names)
+	if err != nil {
+		return nil, fmt.Errorf("marshal agents: %w", err)
+	}
+
+	matchSQL, matchArgs := filters.SelectIDs()
+	batchParam := len(matchArgs) + 1
+
+	enqueueQ := fmt.Sprintf(`
+		WITH matched AS (%s),
+		inserted AS (
+			INSERT INTO classification_jobs (document_id, batch_id, max_attempts, agents)
+			SELECT matched.id, $%d, $%d, $%d FROM matched
+			ON CONFLICT (document_id) WHERE status IN ('queued', 'running') DO NOTHING
+			RETURNING id
+		)
+		SELECT (SELECT COUNT(*) FROM matched), (SELECT COUNT(*) FROM inserted)`,
+		matchSQL, batchParam, batchParam+1, batchParam+2,
+	)
+
+	return repository.WithTx(ctx, r.db, func(tx *sql.Tx) (*Batch, error) {
+		var batchID uuid.UUID
+		if err := tx.QueryRowContext(ctx,
+			"INSERT INTO classification_batches (filters) VALUES ($1) RETURNING id",
+			filtersJSON,
+		).Scan(&batchID); err != nil {
+			return nil, fmt.Errorf("insert classification batch: %w", err)
+		}
+
+		args := append(matchArgs, batchID, r.jobs.MaxAttempts, agentsJSON)
+
+		var matched, queued int
+		if err := tx.QueryRowContext(ctx, enqueueQ, args...).Scan(&matched, &queued); err != nil {
+			return nil, fmt.Errorf("enqueue batch jobs: %w", err)
+		}
+
+		b, err := repository.QueryOne(ctx, tx, `
+			UPDATE classification_batches SET total = $2, skipped = $3
+			WHERE id = $1
+			RETURNING id, filters, total, skipped, total, 0, 0, 0, created_at`,
+			[]any{batchID, queued, matched - queued},
+			scanBatch,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("update classification batch: %w", err)
+		}
+		return &b, nil
+	})
+}
+
+// findBatch returns a batch with job counts aggregated by status.
+func (r *repo) findBatch(ctx context.Context, id uuid.UUID) (*Batch, error) {
+	q := `
+		SELECT b.id, b.filters, b.total, b.skipped,
+			COUNT(j.id) FILTER (WHERE j.status = 'queued'),
+			COUNT(j.id) FILTER (WHERE j.status = 'running'),
+			COUNT(j.id) FILTER (WHERE j.status = 'succeeded'),
+			COUNT(j.id) FILTER (WHERE j.status = 'failed'),
+			b.created_at
+		FROM classification_batches b
+		LEFT JOIN classification_jobs j ON j.batch_id = b.id
+		WHERE b.id = $1
+		GROUP BY b.id`
+
+	b, err := repository.QueryOne(ctx, r.db, q, []any{id}, scanBatch)
+	if err != nil {
+		return nil, repository.MapError(err, ErrBatchNotFound, ErrDuplicate)
+	}
+	return &b, nil
+}
+
+// extendLease pushes the lease of a running job forward. Like every update
+// a worker makes to the job it claimed, it is fenced to the attempt the
+// worker claimed: once the lease expires and another worker reclaims the job,
+// attempts moves on and the update returns ErrLeaseLost.
+func (r *repo) extendLease(ctx context.Context, job *Job) error {
+	return leaseLost(repository.ExecExpectOne(ctx, r.db, `
+		UPDATE classification_jobs
+		SET lease_expires_at = NOW() + make_interval(secs => $3), updated_at = NOW()
+		WHERE id = $1 AND status = 'running' AND attempts = $2`,
+		job.ID, job.Attempts, r.jobs.LeaseDuration.Seconds(),
+	))
+}
+
+// completeJob marks a running job as succeeded within the transaction that
+// persists its classification, so a stored result and a succeeded job are
+// always observed together. The run's checkpoint is no longer needed. A job
+// reclaimed by another worker returns ErrLeaseLost, rolling the transaction
+// back so the result is not stored twice.
+func completeJob(ctx context.Context, tx *sql.Tx, job *Job, classificationID uuid.UUID) error {
+	if err := repository.ExecExpectOne(ctx, tx, `
+		UPDATE classification_jobs
+		SET status = 'succeeded', classification_id = $3, last_error = NULL,
+			lease_expires_at = NULL, completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'running' AND attempts = $2`,
+		job.ID, job.Attempts, classificationID,
+	); err != nil {
+		return leaseLost(err)
+	}
+	return deleteCheckpoint(ctx, tx, job.ID)
+}
+
+// retryJob returns a failed job to the queue, delaying its next run with
+// exponential backoff based on the attempts already made.
+func (r *repo) retryJob(ctx context.Context, job *Job, cause error) error {
+	delay := r.jobs.RetryBackoff << max(job.Attempts-1, 0)
+
+	return leaseLost(repository.ExecExpectOne(ctx, r.db, `
+		UPDATE classification_jobs
+		SET status = 'queued', last_error = $3,
+			run_after = NOW() + make_interval(secs => $4),
+			lease_expires_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'running' AND attempts = $2`,
+		job.ID, job.Attempts, cause.Error(), delay.Seconds(),
+	))
+}
+
+// failJob marks a job as permanently failed and discards its checkpoint.
+func (r *repo) failJob(ctx context.Context, job *Job, cause error) error {
+	_, err := repository.WithTx(ctx, r.db, func(tx *sql.Tx) (struct{}, error) {
+		if err := repository.ExecExpectOne(ctx, tx, `
+			UPDATE classification_jobs
+			SET status = 'failed', last_error = $3,
+				lease_expires_at = NULL, completed_at = NOW(), updated_at = NOW()
+			WHERE id = $1 AND status = 'running' AND attempts = $2`,
+			job.ID, job.Attempts, cause.Error(),
+		); err != nil {
+			return struct{}{}, leaseLost(err)
+		}
+		return struct{}{}, deleteCheckpoint(ctx, tx, job.ID)
+	})
+	return err
+}
+
+// releaseJob returns an interrupted job to the queue without charging the
+// attempt, used when the process shuts down mid-classification.
+func (r *repo) releaseJob(ctx context.Context, job *Job) error {
+	return leaseLost(repository.ExecExpectOne(ctx, r.db, `
+		UPDATE classification_jobs
+		SET status = 'queued', attempts = GREATEST(attempts - 1, 0),
+			lease_expires_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'running' AND attempts = $2`,
+		job.ID, job.Attempts,
+	))
+}
+
+// leaseLost reports a fenced job update that matched no row as ErrLeaseLost.
+func leaseLost(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrLeaseLost
+	}
+	return err
+}
@@ -4,13 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"slices"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/tailored-agentic-units/agent"
 
+	"github.com/JaimeStill/herald/internal/agents"
 	"github.com/JaimeStill/herald/internal/documents"
 	"github.com/JaimeStill/herald/internal/format"
 	"github.com/JaimeStill/herald/internal/prompts"
@@ -22,44 +24,82 @@ import (
 	"github.com/JaimeStill/herald/pkg/storage"
 )
 
-const streamBufferSize = 32
+const classificationColumns = `id, document_id, classification, confidence, markings_found,
+		rationale, classified_at, model_name, provider_name, validated_by, validated_at,
+		marking, marking_level, marking_sci, marking_sap, marking_dissemination,
+		marking_releasable_to, marking_declassification, marking_error, roll_up, discrepancy,
+		failed_pages, vision_calls, chat_calls, prompt_tokens, completion_tokens,
+		agent_name, agreement, escalation`
+
+const (
+	streamBufferSize = 32
+	eventBufferSize  = 256
+	eventRetention   = 10 * time.Minute
+)
 
 type repo struct {
 	db         *sql.DB
+	agents     *agents.Registry
 	rt         *workflow.Runtime
+	hub        *workflow.Hub
 	logger     *slog.Logger
 	pagination pagination.Config
+	markings   MarkingConfig
+	pricing    Pricing
+	jobs       WorkerConfig
 }
 
-// New creates a classification repository implementing the System interface.
-// It internally constructs the workflow runtime from the provided dependencies.
+// New creates a classification repository implementing the System interface,
+// building its workflow runtime and progress event hub from the provided
+// dependencies. Its job workers do not run until Start is called.
 func New(
 	db *sql.DB,
-	newAgent func(ctx context.Context) (agent.Agent, error),
-	modelName string,
-	providerName string,
+	registry *agents.Registry,
 	logger *slog.Logger,
 	pagination pagination.Config,
 	storage storage.System,
 	docs documents.System,
 	prompts prompts.System,
 	formats *format.Registry,
+	marking MarkingConfig,
+	pricing Pricing,
+	flow WorkflowConfig,
+	jobs WorkerConfig,
 ) System {
+	def := registry.Default()
 	rt := &workflow.Runtime{
-		NewAgent:  newAgent,
-		Model:     modelName,
-		Provider:  providerName,
-		Storage:   storage,
-		Documents: docs,
-		Prompts:   prompts,
-		Formats:   formats,
-		Logger:    logger.With("workflow", "classify"),
+		NewAgent:     def.New,
+		Model:        def.Model,
+		Provider:     def.Provider,
+		Storage:      storage,
+		Documents:    docs,
+		Prompts:      prompts,
+		Formats:      formats,
+		Markings:     marking.Parser,
+		PartialPages: flow.PartialPages,
+		Checkpoints:  &checkpoints{db: db},
+		TextFastPath: flow.TextFastPath,
+		Strategy:     flow.Strategy,
+		Logger:       logger.With("workflow", "classify"),
+	}
+	if flow.EscalationAgent != "" {
+		e, err := registry.Lookup(flow.EscalationAgent)
+		if err != nil {
+			logger.Warn("escalation disabled", "agent", flow.EscalationAgent, "error", err)
+		} else {
+			rt.Escalation = &workflow.Escalation{Agent: e, Threshold: flow.EscalationThreshold}
+		}
 	}
 	return &repo{
 		db:         db,
+		agents:     registry,
 		rt:         rt,
+		hub:        workflow.NewHub(eventBufferSize, eventRetention),
 		logger:     logger.With("system", "classifications"),
 		pagination: pagination,
+		markings:   marking,
+		pricing:    pricing,
+		jobs:       jobs,
 	}
 }
 
@@ -120,104 +160,168 @@ func (r *repo) FindByDocument(ctx context.Context, documentID uuid.UUID) (*Class
 	return &c, nil
 }
 
-func (r *repo) Classify(ctx context.Context, documentID uuid.UUID) (<-chan workflow.ExecutionEvent, error) {
+func (r *repo) Pages(ctx context.Context, id uuid.UUID) ([]Page, error) {
+	if _, err := r.Find(ctx, id); err != nil {
+		return nil, err
+	}
+	return r.listPages(ctx, id)
+}
+
+func (r *repo) Results(ctx context.Context, id uuid.UUID) ([]Result, error) {
+	if _, err := r.Find(ctx, id); err != nil {
+		return nil, err
+	}
+	return r.listResults(ctx, id)
+}
+
+func (r *repo) Agents() []Agent {
+	def := r.agents.Default()
+	entries := r.agents.Entries()
+	list := make([]Agent, len(entries))
+	for i, e := range entries {
+		list[i] = Agent{
+			Name:         e.Name,
+			ModelName:    e.Model,
+			ProviderName: e.Provider,
+			Default:      e.Name == def.Name,
+			Escalation:   r.rt.Escalation != nil && e.Name == r.rt.Escalation.Agent.Name,
+		}
+	}
+	return list
+}
+
+func (r *repo) History(ctx context.Context, id uuid.UUID) ([]Revision, error) {
+	revs, err := r.listRevisions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(revs) == 0 {
+		return nil, ErrNotFound
+	}
+	return revs, nil
+}
+
+func (r *repo) DiffRevisions(ctx context.Context, id uuid.UUID, from, to int) (*RevisionDiff, error) {
+	if from < 1 || to < 1 {
+		return nil, ErrInvalidRevision
+	}
+
+	a, err := r.findRevision(ctx, id, from)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := r.findRevision(ctx, id, to)
+	if err != nil {
+		return nil, err
+	}
+
+	d := Diff(*a, *b)
+	return &d, nil
+}
+
+func (r *repo) Classify(ctx context.Context, documentID uuid.UUID, names []string) (*Job, error) {
+	names, err := r.agentNames(names)
+	if err != nil {
+		return nil, err
+	}
+
 	if _, err := r.rt.Documents.Find(ctx, documentID); err != nil {
 		return nil, fmt.Errorf("document %s: %w", documentID, err)
 	}
 
-	observer := workflow.NewStreamingObserver(streamBufferSize, r.rt.Logger)
+	j, err := r.enqueueJob(ctx, documentID, names)
+	if err != nil {
+		return nil, err
+	}
 
-	go func() {
-		defer observer.Close()
+	r.logger.Info("classification job queued",
+		"id", j.ID,
+		"document_id", documentID,
+		"status", j.Status,
+		"agents", j.Agents,
+	)
+	return j, nil
+}
 
-		result, err := workflow.Execute(ctx, r.rt, documentID, observer)
-		if err != nil {
-			observer.SendError(fmt.Errorf("classify document: %s: %w", documentID, err), "")
-			return
+func (r *repo) Events(
+	ctx context.Context,
+	documentID uuid.UUID,
+	lastEventID uint64,
+) ([]workflow.ExecutionEvent, *workflow.Subscription, error) {
+	if !r.hub.Active(documentID) {
+		if _, err := r.activeJob(ctx, documentID); err != nil {
+			return nil, nil, err
 		}
+	}
 
-		markings := collectMarkings(result.State.Pages)
-		markingsJSON, err := json.Marshal(markings)
-		if err != nil {
-			observer.SendError(fmt.Errorf("marshal markings: %w", err), "")
-			return
-		}
+	replay, sub := r.hub.Subscribe(documentID, lastEventID)
+	return replay, sub, nil
+}
 
-		upsertQ := `
-		INSERT INTO classifications(
-			document_id, classification, confidence, markings_found,
-			rationale, model_name, provider_name
-		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		ON CONFLICT (document_id) DO UPDATE SET
-			classification = EXCLUDED.classification,
-			confidence = EXCLUDED.confidence,
-			markings_found = EXCLUDED.markings_found,
-			rationale = EXCLUDED.rationale,
-			classified_at = NOW(),
-			model_name = EXCLUDED.model_name,
-			provider_name = EXCLUDED.provider_name,
-			validated_by = NULL,
-			validated_at = NULL
-		RETURNING id, document_id, classification, confidence, markings_found,
-				  rationale, classified_at, model_name, provider_name,
-				  validated_by, validated_at`
-
-		upsertArgs := []any{
-			documentID,
-			result.State.Classification,
-			string(result.State.Confidence),
-			markingsJSON,
-			result.State.Rationale,
-			r.rt.Model,
-			r.rt.Provider,
-		}
+func (r *repo) ClassifyBulk(ctx context.Context, filters documents.Filters, names []string) (*Batch, error) {
+	names, err := r.agentNames(names)
+	if err != nil {
+		return nil, err
+	}
 
-		c, err := repository.WithTx(ctx, r.db, func(tx *sql.Tx) (Classification, error) {
-			cl, err := repository.QueryOne(ctx, tx, upsertQ, upsertArgs, scanClassification)
-			if err != nil {
-				return Classification{}, fmt.Errorf("upsert classification: %w", err)
-			}
+	b, err := r.enqueueBatch(ctx, filters, names)
+	if err != nil {
+		return nil, err
+	}
 
-			if err := repository.ExecExpectOne(
-				ctx, tx,
-				"UPDATE documents SET status = 'review', updated_at = NOW() WHERE id = $1",
-				documentID,
-			); err != nil {
-				return Classification{}, fmt.Errorf("update document status: %w", err)
-			}
+	r.logger.Info("classification batch queued",
+		"id", b.ID,
+		"total", b.Total,
+		"skipped", b.Skipped,
+		"agents", names,
+	)
+	return b, nil
+}
 
-			return cl, nil
-		})
+func (r *repo) FindBatch(ctx context.Context, id uuid.UUID) (*Batch, error) {
+	return r.findBatch(ctx, id)
+}
 
-		if err != nil {
-			observer.SendError(err, "")
-			return
-		}
+func (r *repo) FindJob(ctx context.Context, id uuid.UUID) (*Job, error) {
+	q, args := query.NewBuilder(jobProjection).BuildSingle("ID", id)
 
-		r.logger.Info("document classified",
-			"id", c.ID,
-			"document_id", documentID,
-			"classification", c.Classification,
-			"confidence", c.Confidence,
-		)
+	j, err := repository.QueryOne(ctx, r.db, q, args, scanJob)
+	if err != nil {
+		return nil, repository.MapError(err, ErrJobNotFound, ErrDuplicate)
+	}
+	return &j, nil
+}
 
-		classBytes, err := json.Marshal(c)
-		if err != nil {
-			observer.SendError(fmt.Errorf("marshal classification: %w", err), "")
-			return
-		}
+func (r *repo) ListJobs(
+	ctx context.Context,
+	page pagination.PageRequest,
+	filters JobFilters,
+) (*pagination.PageResult[Job], error) {
+	page.Normalize(r.pagination)
 
-		var classMap map[string]any
-		if err := json.Unmarshal(classBytes, &classMap); err != nil {
-			observer.SendError(fmt.Errorf("unmarshal classification map: %w", err), "")
-			return
-		}
+	qb := query.NewBuilder(jobProjection, jobDefaultSort)
+
+	filters.Apply(qb)
 
-		observer.SendComplete(classMap)
-	}()
+	if len(page.Sort) > 0 {
+		qb.OrderByFields(page.Sort)
+	}
+
+	countSQL, countArgs := qb.BuildCount()
+	var total int
+	if err := r.db.QueryRowContext(ctx, countSQL, countArgs...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("count classification jobs: %w", err)
+	}
+
+	pageSQL, pageArgs := qb.BuildPage(page.Page, page.PageSize)
+	items, err := repository.QueryMany(ctx, r.db, pageSQL, pageArgs, scanJob)
+	if err != nil {
+		return nil, fmt.Errorf("query classification jobs: %w", err)
+	}
 
-	return observer.Events(), nil
+	result := pagination.NewPageResult(items, total, page.Page, page.PageSize)
+	return &result, nil
 }
 
 func (r *repo) Validate(ctx context.Context, id uuid.UUID, cmd ValidateCommand) (*Classification, error) {
@@ -225,11 +329,14 @@ func (r *repo) Validate(ctx context.Context, id uuid.UUID, cmd ValidateCommand)
 		UPDATE classifications
 		SET validated_by = $1, validated_at = NOW()
 		WHERE id = $2
-		RETURNING id, document_id, classification, confidence, markings_found,
-				  rationale, classified_at, model_name, provider_name,
-				  validated_by, validated_at`
+		RETURNING ` + classificationColumns
 
 	c, err := repository.WithTx(ctx, r.db, func(tx *sql.Tx) (Classification, error) {
+		prev, err := lockClassification(ctx, tx, "ID", id)
+		if err != nil {
+			return Classification{}, repository.MapError(err, ErrNotFound, ErrDuplicate)
+		}
+
 		cl, err := repository.QueryOne(ctx, tx, validateQ, []any{cmd.ValidatedBy, id}, scanClassification)
 		if err != nil {
 			return Classification{}, repository.MapError(err, ErrNotFound, ErrDuplicate)
@@ -243,6 +350,10 @@ func (r *repo) Validate(ctx context.Context, id uuid.UUID, cmd ValidateCommand)
 			return Classification{}, ErrInvalidStatus
 		}
 
+		if err := insertRevision(ctx, tx, humanRevision(RevisionValidate, cmd.ValidatedBy, prev, &cl)); err != nil {
+			return Classification{}, err
+		}
+
 		return cl, nil
 	})
 
@@ -258,19 +369,34 @@ func (r *repo) Validate(ctx context.Context, id uuid.UUID, cmd ValidateCommand)
 }
 
 func (r *repo) Update(ctx context.Context, id uuid.UUID, cmd UpdateCommand) (*Classification, error) {
+	marking, reason, err := r.markings.normalize(cmd.Classification)
+	if err != nil {
+		return nil, err
+	}
+
+	markingValues, err := markingArgs(marking, reason)
+	if err != nil {
+		return nil, err
+	}
+
 	updateQ := `
 		UPDATE classifications
-		SET classification = $1, rationale = $2, validated_by = $3, validated_at = NOW()
+		SET classification = $1, rationale = $2, validated_by = $3, validated_at = NOW(),
+			marking = $5, marking_level = $6, marking_sci = $7, marking_sap = $8,
+			marking_dissemination = $9, marking_releasable_to = $10,
+			marking_declassification = $11, marking_error = $12
 		WHERE id = $4
-		RETURNING id, document_id, classification, confidence, markings_found,
-				  rationale, classified_at, model_name, provider_name,
-				  validated_by, validated_at`
+		RETURNING ` + classificationColumns
+
+	updateArgs := append([]any{cmd.Classification, cmd.Rationale, cmd.UpdatedBy, id}, markingValues...)
 
 	c, err := repository.WithTx(ctx, r.db, func(tx *sql.Tx) (Classification, error) {
-		cl, err := repository.QueryOne(ctx, tx, updateQ,
-			[]any{cmd.Classification, cmd.Rationale, cmd.UpdatedBy, id},
-			scanClassification,
-		)
+		prev, err := lockClassification(ctx, tx, "ID", id)
+		if err != nil {
+			return Classification{}, repository.MapError(err, ErrNotFound, ErrDuplicate)
+		}
+
+		cl, err := repository.QueryOne(ctx, tx, updateQ, updateArgs, scanClassification)
 		if err != nil {
 			return Classification{}, repository.MapError(err, ErrNotFound, ErrDuplicate)
 		}
@@ -283,6 +409,10 @@ func (r *repo) Update(ctx context.Context, id uuid.UUID, cmd UpdateCommand) (*Cl
 			return Classification{}, ErrInvalidStatus
 		}
 
+		if err := insertRevision(ctx, tx, humanRevision(RevisionUpdate, cmd.UpdatedBy, prev, &cl)); err != nil {
+			return Classification{}, err
+		}
+
 		return cl, nil
 	})
 
@@ -317,6 +447,229 @@ func (r *repo) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// persist stores the primary agent's workflow result and its per-page
+// findings for the job's document, records a classify revision with the
+// prompt versions and model usage of the run, stores every agent's answer
+// side by side, moves the document into review, and marks the job succeeded
+// in a single transaction, which rolls back with ErrLeaseLost when another
+// worker has reclaimed the job. Pages the run could not analyze are stored
+// with their error and listed on the classification. When the job ran an
+// ensemble, the classification records how far the agents agreed, and any
+// disagreement lowers its confidence to LOW. When the run escalated, the
+// classification is the escalation agent's answer and records the answer it
+// replaced.
+func (r *repo) persist(
+	ctx context.Context,
+	job *Job,
+	runs []agentRun,
+	versions []prompts.Version,
+) (*Classification, error) {
+	primary, result := runs[0].entry, runs[0].result
+
+	agreement := r.agree(runs)
+	if agreement != nil && *agreement < 1 {
+		result.State.Confidence = state.ConfidenceLow
+	}
+
+	found := collectMarkings(result.State.Pages)
+	markingsJSON, err := json.Marshal(found)
+	if err != nil {
+		return nil, fmt.Errorf("marshal markings: %w", err)
+	}
+
+	marking, reason, err := r.markings.normalize(result.State.Classification)
+	if err != nil {
+		return nil, fmt.Errorf("normalize classification: %w", err)
+	}
+	if reason != nil {
+		r.logger.Warn("classification marking flagged",
+			"document_id", result.DocumentID,
+			"classification", result.State.Classification,
+			"reason", *reason,
+		)
+	}
+
+	markingValues, err := markingArgs(marking, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	var failedPages any
+	if len(result.State.FailedPages) > 0 {
+		if failedPages, err = json.Marshal(result.State.FailedPages); err != nil {
+			return nil, fmt.Errorf("marshal failed pages: %w", err)
+		}
+	}
+
+	var escalation any
+	if result.State.Escalation != nil {
+		if escalation, err = json.Marshal(result.State.Escalation); err != nil {
+			return nil, fmt.Errorf("marshal escalation: %w", err)
+		}
+	}
+
+	upsertQ := `
+		INSERT INTO classifications(
+			document_id, classification, confidence, markings_found,
+			rationale, model_name, provider_name,
+			marking, marking_level, marking_sci, marking_sap, marking_dissemination,
+			marking_releasable_to, marking_declassification, marking_error,
+			roll_up, discrepancy, failed_pages,
+			vision_calls, chat_calls, prompt_tokens, completion_tokens,
+			agent_name, agreement, escalation
+		)
+		VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
+			$18, $19, $20, $21, $22, $23, $24, $25
+		)
+		ON CONFLICT (document_id) DO UPDATE SET
+			classification = EXCLUDED.classification,
+			confidence = EXCLUDED.confidence,
+			markings_found = EXCLUDED.markings_found,
+			rationale = EXCLUDED.rationale,
+			classified_at = NOW(),
+			model_name = EXCLUDED.model_name,
+			provider_name = EXCLUDED.provider_name,
+			validated_by = NULL,
+			validated_at = NULL,
+			marking = EXCLUDED.marking,
+			marking_level = EXCLUDED.marking_level,
+			marking_sci = EXCLUDED.marking_sci,
+			marking_sap = EXCLUDED.marking_sap,
+			marking_dissemination = EXCLUDED.marking_dissemination,
+			marking_releasable_to = EXCLUDED.marking_releasable_to,
+			marking_declassification = EXCLUDED.marking_declassification,
+			marking_error = EXCLUDED.marking_error,
+			roll_up = EXCLUDED.roll_up,
+			discrepancy = EXCLUDED.discrepancy,
+			failed_pages = EXCLUDED.failed_pages,
+			vision_calls = EXCLUDED.vision_calls,
+			chat_calls = EXCLUDED.chat_calls,
+			prompt_tokens = EXCLUDED.prompt_tokens,
+			completion_tokens = EXCLUDED.completion_tokens,
+			agent_name = EXCLUDED.agent_name,
+			agreement = EXCLUDED.agreement,
+			escalation = EXCLUDED.escalation
+		RETURNING ` + classificationColumns
+
+	upsertArgs := append([]any{
+		result.DocumentID,
+		result.State.Classification,
+		string(result.State.Confidence),
+		markingsJSON,
+		result.State.Rationale,
+		primary.Model,
+		primary.Provider,
+	}, markingValues...)
+	usage := result.State.TotalUsage()
+	upsertArgs = append(upsertArgs,
+		nullable(result.State.RollUp),
+		nullable(result.State.Discrepancy),
+		failedPages,
+		usage.VisionCalls,
+		usage.ChatCalls,
+		usage.PromptTokens,
+		usage.CompletionTokens,
+		primary.Name,
+		agreement,
+		escalation,
+	)
+
+	c, err := repository.WithTx(ctx, r.db, func(tx *sql.Tx) (Classification, error) {
+		var before *Snapshot
+		prev, err := lockClassification(ctx, tx, "DocumentID", result.DocumentID)
+		switch {
+		case err == nil:
+			snap := prev.Snapshot()
+			before = &snap
+		case !errors.Is(err, sql.ErrNoRows):
+			return Classification{}, fmt.Errorf("lock classification: %w", err)
+		}
+
+		cl, err := repository.QueryOne(ctx, tx, upsertQ, upsertArgs, scanClassification)
+		if err != nil {
+			return Classification{}, fmt.Errorf("upsert classification: %w", err)
+		}
+
+		if err := repository.ExecExpectOne(
+			ctx, tx,
+			"UPDATE documents SET status = 'review', updated_at = NOW() WHERE id = $1",
+			result.DocumentID,
+		); err != nil {
+			return Classification{}, fmt.Errorf("update document status: %w", err)
+		}
+
+		if err := replacePages(ctx, tx, cl.ID, result.State.Pages); err != nil {
+			return Classification{}, err
+		}
+
+		if err := insertResults(ctx, tx, &cl, job.ID, runs); err != nil {
+			return Classification{}, err
+		}
+
+		if err := insertRevision(ctx, tx, Revision{
+			ClassificationID: cl.ID,
+			DocumentID:       cl.DocumentID,
+			Action:           RevisionClassify,
+			Actor:            SystemActor,
+			ModelName:        cl.ModelName,
+			ProviderName:     cl.ProviderName,
+			Prompts:          versions,
+			Usage:            cl.Usage,
+			Before:           before,
+			After:            cl.Snapshot(),
+		}); err != nil {
+			return Classification{}, err
+		}
+
+		if err := completeJob(ctx, tx, job, cl.ID); err != nil {
+			return Classification{}, fmt.Errorf("complete classification job: %w", err)
+		}
+
+		return cl, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Info("document classified",
+		"id", c.ID,
+		"document_id", result.DocumentID,
+		"classification", c.Classification,
+		"confidence", c.Confidence,
+		"discrepant", c.Discrepancy != nil,
+		"failed_pages", c.FailedPages,
+		"agent", primary.Name,
+		"agreement", c.Agreement,
+	)
+	return &c, nil
+}
+
+// nullable maps an empty string to a SQL NULL.
+func nullable(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// humanRevision builds the revision for a review action that changed prev into next.
+func humanRevision(action RevisionAction, actor string, prev, next *Classification) Revision {
+	before := prev.Snapshot()
+	return Revision{
+		ClassificationID: next.ID,
+		DocumentID:       next.DocumentID,
+		Action:           action,
+		Actor:            actor,
+		ModelName:        next.ModelName,
+		ProviderName:     next.ProviderName,
+		Prompts:          []prompts.Version{},
+		Before:           &before,
+		After:            next.Snapshot(),
+	}
+}
+
 func collectMarkings(pages []state.ClassificationPage) []string {
 	var all []string
 	for _, p := range pages {
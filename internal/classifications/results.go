@@ -0,0 +1,108 @@
+package classifications
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/JaimeStill/herald/pkg/repository"
+)
+
+const resultColumns = `id, classification_id, document_id, job_id, agent_name, model_name,
+		provider_name, primary_result, classification, confidence, rationale, marking,
+		agrees, failed_pages, error, vision_calls, chat_calls, prompt_tokens,
+		completion_tokens, created_at, escalated_to`
+
+// insertResults stores each agent's answer from a job's run beside the
+// classification it produced. It runs inside the transaction that upserts
+// the classification. Results of earlier runs are kept, so the usage of
+// every agent a run paid for stays in the usage report. A first answer
+// replaced by escalation is stored as a non-primary result naming the agent
+// it escalated to.
+func insertResults(ctx context.Context, tx *sql.Tx, cl *Classification, jobID uuid.UUID, runs []agentRun) error {
+	insertQ := `
+		INSERT INTO classification_results (
+			classification_id, document_id, job_id, agent_name, model_name,
+			provider_name, primary_result, classification, confidence, rationale,
+			marking, agrees, failed_pages, error,
+			vision_calls, chat_calls, prompt_tokens, completion_tokens, escalated_to
+		)
+		VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
+			$18, $19
+		)`
+
+	for i, run := range runs {
+		var classification, confidence, rationale string
+		var failedPages any
+		var errMsg *string
+
+		if run.err != nil {
+			msg := run.err.Error()
+			errMsg = &msg
+		} else {
+			cs := run.result.State
+			classification, confidence, rationale = cs.Classification, string(cs.Confidence), cs.Rationale
+			if len(cs.FailedPages) > 0 {
+				raw, err := json.Marshal(cs.FailedPages)
+				if err != nil {
+					return fmt.Errorf("agent %s: marshal failed pages: %w", run.entry.Name, err)
+				}
+				failedPages = raw
+			}
+		}
+
+		usage := run.usage()
+
+		if _, err := tx.ExecContext(ctx, insertQ,
+			cl.ID,
+			cl.DocumentID,
+			jobID,
+			run.entry.Name,
+			run.entry.Model,
+			run.entry.Provider,
+			i == 0,
+			classification,
+			confidence,
+			rationale,
+			run.marking,
+			run.agrees,
+			failedPages,
+			errMsg,
+			usage.VisionCalls,
+			usage.ChatCalls,
+			usage.PromptTokens,
+			usage.CompletionTokens,
+			nullable(run.escalatedTo),
+		); err != nil {
+			return fmt.Errorf("agent %s: insert classification result: %w", run.entry.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// listResults returns the agent results of the latest run that produced a
+// classification, primary agent first and the others by name.
+func (r *repo) listResults(ctx context.Context, classificationID uuid.UUID) ([]Result, error) {
+	q := `
+		SELECT ` + resultColumns + `
+		FROM classification_results
+		WHERE classification_id = $1
+		  AND job_id = (
+			SELECT job_id FROM classification_results
+			WHERE classification_id = $1
+			ORDER BY created_at DESC
+			LIMIT 1
+		  )
+		ORDER BY primary_result DESC, agent_name`
+
+	results, err := repository.QueryMany(ctx, r.db, q, []any{classificationID}, scanResult)
+	if err != nil {
+		return nil, fmt.Errorf("query classification results: %w", err)
+	}
+	return results, nil
+}
@@ -0,0 +1,119 @@
+package classifications
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/JaimeStill/herald/pkg/query"
+	"github.com/JaimeStill/herald/pkg/repository"
+)
+
+const revisionColumns = `id, classification_id, document_id, revision, action, actor,
+		model_name, provider_name, prompts, before, after, created_at,
+		vision_calls, chat_calls, prompt_tokens, completion_tokens`
+
+// lockClassification reads the classification matching field = value and locks
+// its row for the rest of the transaction, serializing revisions of the same
+// classification. Returns sql.ErrNoRows when no classification matches.
+func lockClassification(ctx context.Context, tx *sql.Tx, field string, value any) (*Classification, error) {
+	q, args := query.NewBuilder(projection).BuildSingle(field, value)
+
+	c, err := repository.QueryOne(ctx, tx, q+" FOR UPDATE", args, scanClassification)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// insertRevision appends an immutable revision for rev.ClassificationID,
+// numbering it after the latest existing revision. Callers hold the
+// classification row lock, so numbering cannot race.
+func insertRevision(ctx context.Context, tx *sql.Tx, rev Revision) error {
+	promptsJSON, err := json.Marshal(rev.Prompts)
+	if err != nil {
+		return fmt.Errorf("marshal revision prompts: %w", err)
+	}
+
+	var before any
+	if rev.Before != nil {
+		b, err := json.Marshal(rev.Before)
+		if err != nil {
+			return fmt.Errorf("marshal revision before: %w", err)
+		}
+		before = b
+	}
+
+	after, err := json.Marshal(rev.After)
+	if err != nil {
+		return fmt.Errorf("marshal revision after: %w", err)
+	}
+
+	q := `
+		INSERT INTO classification_revisions (
+			classification_id, document_id, revision, action, actor,
+			model_name, provider_name, prompts, before, after,
+			vision_calls, chat_calls, prompt_tokens, completion_tokens
+		)
+		VALUES (
+			$1, $2,
+			(SELECT COALESCE(MAX(revision), 0) + 1 FROM classification_revisions WHERE classification_id = $1),
+			$3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+		)`
+
+	if _, err := tx.ExecContext(ctx, q,
+		rev.ClassificationID,
+		rev.DocumentID,
+		rev.Action,
+		rev.Actor,
+		rev.ModelName,
+		rev.ProviderName,
+		promptsJSON,
+		before,
+		after,
+		rev.Usage.VisionCalls,
+		rev.Usage.ChatCalls,
+		rev.Usage.PromptTokens,
+		rev.Usage.CompletionTokens,
+	); err != nil {
+		return fmt.Errorf("insert classification revision: %w", err)
+	}
+
+	return nil
+}
+
+// listRevisions returns every revision of a classification, oldest first.
+// Revisions outlive the classification and document rows, so history remains
+// readable after either is deleted.
+func (r *repo) listRevisions(ctx context.Context, classificationID uuid.UUID) ([]Revision, error) {
+	q := `SELECT ` + revisionColumns + `
+		FROM classification_revisions
+		WHERE classification_id = $1
+		ORDER BY revision`
+
+	revs, err := repository.QueryMany(ctx, r.db, q, []any{classificationID}, scanRevision)
+	if err != nil {
+		return nil, fmt.Errorf("query classification revisions: %w", err)
+	}
+	return revs, nil
+}
+
+// findRevision returns a single numbered revision of a classification.
+func (r *repo) findRevision(ctx context.Context, classificationID uuid.UUID, revision int) (*Revision, error) {
+	q := `SELECT ` + revisionColumns + `
+		FROM classification_revisions
+		WHERE classification_id = $1 AND revision = $2`
+
+	rev, err := repository.QueryOne(ctx, r.db, q, []any{classificationID, revision}, scanRevision)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("revision %d: %w", revision, ErrRevisionNotFound)
+		}
+		return nil, fmt.Errorf("query classification revision: %w", err)
+	}
+	return &rev, nil
+}
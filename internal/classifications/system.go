@@ -5,7 +5,9 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/JaimeStill/herald/internal/documents"
 	"github.com/JaimeStill/herald/internal/workflow"
+	"github.com/JaimeStill/herald/pkg/lifecycle"
 	"github.com/JaimeStill/herald/pkg/pagination"
 )
 
@@ -21,8 +23,49 @@ type System interface {
 
 	Find(ctx context.Context, id uuid.UUID) (*Classification, error)
 	FindByDocument(ctx context.Context, documentID uuid.UUID) (*Classification, error)
-	Classify(ctx context.Context, documentID uuid.UUID) (<-chan workflow.ExecutionEvent, error)
+	Pages(ctx context.Context, id uuid.UUID) ([]Page, error)
+
+	// Results returns each agent's answer from the latest run that produced
+	// the classification, primary agent first.
+	Results(ctx context.Context, id uuid.UUID) ([]Result, error)
+
+	History(ctx context.Context, id uuid.UUID) ([]Revision, error)
+	DiffRevisions(ctx context.Context, id uuid.UUID, from, to int) (*RevisionDiff, error)
+	Usage(ctx context.Context, filters UsageFilters) (*UsageReport, error)
+
+	// Agents lists the agents classifications can run with, default first.
+	Agents() []Agent
+
+	// Classify and ClassifyBulk queue jobs that classify with the named
+	// agents: one names the agent to use, and more run an ensemble whose
+	// first agent is primary. No names selects the default agent. Returns an
+	// error wrapping agents.ErrUnknownAgent for names that are not configured.
+	Classify(ctx context.Context, documentID uuid.UUID, agents []string) (*Job, error)
+	ClassifyBulk(ctx context.Context, filters documents.Filters, agents []string) (*Batch, error)
+
+	FindBatch(ctx context.Context, id uuid.UUID) (*Batch, error)
 	Validate(ctx context.Context, id uuid.UUID, cmd ValidateCommand) (*Classification, error)
 	Update(ctx context.Context, id uuid.UUID, cmd UpdateCommand) (*Classification, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+
+	ListJobs(
+		ctx context.Context,
+		page pagination.PageRequest,
+		filters JobFilters,
+	) (*pagination.PageResult[Job], error)
+
+	FindJob(ctx context.Context, id uuid.UUID) (*Job, error)
+
+	// Events attaches to the progress stream of a document's classification.
+	// It returns the buffered events after lastEventID followed by a live
+	// subscription the caller must cancel. Returns ErrJobNotFound when the
+	// document has neither buffered events nor an active job.
+	Events(
+		ctx context.Context,
+		documentID uuid.UUID,
+		lastEventID uint64,
+	) ([]workflow.ExecutionEvent, *workflow.Subscription, error)
+
+	// Start launches the background job workers with the lifecycle coordinator.
+	Start(lc *lifecycle.Coordinator) error
 }
@@ -0,0 +1,220 @@
+package classifications
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/JaimeStill/herald/internal/state"
+	"github.com/JaimeStill/herald/pkg/repository"
+)
+
+// usageDateLayout is the calendar-day format of usage report days and filters.
+const usageDateLayout = time.DateOnly
+
+// Price is the cost of a model's tokens per million.
+type Price struct {
+	PromptPerMillion     float64
+	CompletionPerMillion float64
+}
+
+// Pricing converts model usage into cost for the usage report. Prices is
+// keyed by model name.
+type Pricing struct {
+	Currency string
+	Prices   map[string]Price
+}
+
+// Cost returns the cost of u on model, and false when the model has no price.
+func (p Pricing) Cost(model string, u state.Usage) (float64, bool) {
+	price, ok := p.Prices[model]
+	if !ok {
+		return 0, false
+	}
+	cost := float64(u.PromptTokens)*price.PromptPerMillion/1e6 +
+		float64(u.CompletionTokens)*price.CompletionPerMillion/1e6
+	return cost, true
+}
+
+// UsageFilters narrows the usage report. From and To bound the day of each
+// classify run, inclusive, in UTC. Nil fields are ignored.
+type UsageFilters struct {
+	From             *time.Time `json:"from,omitempty"`
+	To               *time.Time `json:"to,omitempty"`
+	ModelName        *string    `json:"model_name,omitempty"`
+	ExternalPlatform *string    `json:"external_platform,omitempty"`
+}
+
+// UsageFiltersFromQuery extracts usage filter values from URL query
+// parameters. Dates use the YYYY-MM-DD layout; an unparseable date returns
+// ErrInvalidUsageFilter rather than silently widening the report.
+func UsageFiltersFromQuery(values url.Values) (UsageFilters, error) {
+	var f UsageFilters
+
+	for _, param := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"from", &f.From},
+		{"to", &f.To},
+	} {
+		v := values.Get(param.name)
+		if v == "" {
+			continue
+		}
+		day, err := time.Parse(usageDateLayout, v)
+		if err != nil {
+			return f, fmt.Errorf("%w: %s %q", ErrInvalidUsageFilter, param.name, v)
+		}
+		*param.dst = &day
+	}
+
+	if m := values.Get("model_name"); m != "" {
+		f.ModelName = &m
+	}
+
+	if p := values.Get("external_platform"); p != "" {
+		f.ExternalPlatform = &p
+	}
+
+	return f, nil
+}
+
+// where renders the filters as SQL conditions over the classification
+// revisions and secondary agent results (r) and the documents (d) table.
+func (f UsageFilters) where() (string, []any) {
+	conds := []string{"r.action = 'classify'"}
+	var args []any
+
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.From != nil {
+		add("r.created_at >= $%d", *f.From)
+	}
+	if f.To != nil {
+		add("r.created_at < $%d", f.To.AddDate(0, 0, 1))
+	}
+	if f.ModelName != nil {
+		add("r.model_name = $%d", *f.ModelName)
+	}
+	if f.ExternalPlatform != nil {
+		add("d.external_platform = $%d", *f.ExternalPlatform)
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+// UsageRow aggregates the classify runs of one day, model, and external
+// platform. Cost is nil when the model has no configured price.
+type UsageRow struct {
+	Day              string      `json:"day"`
+	ModelName        string      `json:"model_name"`
+	ExternalPlatform string      `json:"external_platform"`
+	Runs             int         `json:"runs"`
+	Usage            state.Usage `json:"usage"`
+	Cost             *float64    `json:"cost"`
+}
+
+// UsageReport is the model usage of classify runs grouped by day, model, and
+// external platform, newest day first. Every run is counted, including runs
+// whose result was later replaced by a re-classification and the runs of an
+// ensemble's secondary agents. The totals cover
+// every row; Cost sums only priced rows, and UnpricedModels lists the models
+// it leaves out.
+type UsageReport struct {
+	Currency       string      `json:"currency"`
+	Rows           []UsageRow  `json:"rows"`
+	Runs           int         `json:"runs"`
+	Usage          state.Usage `json:"usage"`
+	Cost           float64     `json:"cost"`
+	UnpricedModels []string    `json:"unpriced_models"`
+}
+
+// NewUsageReport prices rows and computes the report totals.
+func NewUsageReport(rows []UsageRow, pricing Pricing) UsageReport {
+	report := UsageReport{
+		Currency:       pricing.Currency,
+		Rows:           rows,
+		UnpricedModels: []string{},
+	}
+	if report.Rows == nil {
+		report.Rows = []UsageRow{}
+	}
+
+	for i := range report.Rows {
+		row := &report.Rows[i]
+		report.Runs += row.Runs
+		report.Usage.Add(row.Usage)
+
+		cost, ok := pricing.Cost(row.ModelName, row.Usage)
+		if !ok {
+			if !slices.Contains(report.UnpricedModels, row.ModelName) {
+				report.UnpricedModels = append(report.UnpricedModels, row.ModelName)
+			}
+			continue
+		}
+		row.Cost = &cost
+		report.Cost += cost
+	}
+
+	slices.Sort(report.UnpricedModels)
+	return report
+}
+
+func (r *repo) Usage(ctx context.Context, filters UsageFilters) (*UsageReport, error) {
+	where, args := filters.where()
+
+	q := `
+		SELECT
+			to_char(r.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day,
+			r.model_name,
+			d.external_platform,
+			COUNT(*),
+			COALESCE(SUM(r.vision_calls), 0),
+			COALESCE(SUM(r.chat_calls), 0),
+			COALESCE(SUM(r.prompt_tokens), 0),
+			COALESCE(SUM(r.completion_tokens), 0)
+		FROM (
+			SELECT action, created_at, model_name, document_id,
+				vision_calls, chat_calls, prompt_tokens, completion_tokens
+			FROM classification_revisions
+			UNION ALL
+			SELECT 'classify', created_at, model_name, document_id,
+				vision_calls, chat_calls, prompt_tokens, completion_tokens
+			FROM classification_results
+			WHERE NOT primary_result
+		) r
+		JOIN documents d ON d.id = r.document_id
+		WHERE ` + where + `
+		GROUP BY day, r.model_name, d.external_platform
+		ORDER BY day DESC, r.model_name, d.external_platform`
+
+	rows, err := repository.QueryMany(ctx, r.db, q, args, scanUsageRow)
+	if err != nil {
+		return nil, fmt.Errorf("query classification usage: %w", err)
+	}
+
+	report := NewUsageReport(rows, r.pricing)
+	return &report, nil
+}
+
+func scanUsageRow(s repository.Scanner) (UsageRow, error) {
+	var row UsageRow
+	err := s.Scan(
+		&row.Day,
+		&row.ModelName,
+		&row.ExternalPlatform,
+		&row.Runs,
+		&row.Usage.VisionCalls,
+		&row.Usage.ChatCalls,
+		&row.Usage.PromptTokens,
+		&row.Usage.CompletionTokens,
+	)
+	return row, err
+}
@@ -0,0 +1,299 @@
+package classifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/JaimeStill/herald/internal/agents"
+	"github.com/JaimeStill/herald/internal/format"
+	"github.com/JaimeStill/herald/internal/state"
+	"github.com/JaimeStill/herald/internal/workflow"
+	"github.com/JaimeStill/herald/pkg/core"
+	"github.com/JaimeStill/herald/pkg/lifecycle"
+	"github.com/JaimeStill/herald/pkg/storage"
+)
+
+// WorkflowConfig controls how classification runs treat problem documents.
+type WorkflowConfig struct {
+	// PartialPages keeps a run going when individual pages fail, recording
+	// the failed pages on the result instead of failing the job.
+	PartialPages bool
+
+	// EscalationAgent names the agent that classifies a document again when
+	// the first answer's confidence is at or below EscalationThreshold; runs
+	// do not escalate when it is empty.
+	EscalationAgent     string
+	EscalationThreshold state.Confidence
+
+	// TextFastPath classifies pages whose text layer carries unambiguous
+	// markings without a vision call.
+	TextFastPath bool
+
+	// Strategy selects what the classify stage sends the vision model first.
+	Strategy workflow.Strategy
+}
+
+// WorkerConfig controls the background workers that drain the classification
+// job queue.
+type WorkerConfig struct {
+	// Workers bounds concurrency within this process.
+	Workers int
+
+	// PollInterval is how long an idle worker waits before polling again.
+	PollInterval time.Duration
+
+	// LeaseDuration bounds how long a claimed job may go without a heartbeat
+	// before it is reclaimed. Heartbeats run every third of it.
+	LeaseDuration time.Duration
+
+	// MaxAttempts caps the claims of a job before it fails for good, and
+	// RetryBackoff is the delay before its first retry, doubling after each.
+	MaxAttempts  int
+	RetryBackoff time.Duration
+
+	// MaxRunning bounds concurrency across all processes sharing the
+	// database.
+	MaxRunning int
+}
+
+// Start launches the job workers and registers a drain hook that waits for
+// them to stop, so the database closes only after they do. Workers poll the
+// queue until the coordinator context is cancelled; a job interrupted by
+// shutdown is released back to the queue, and a job orphaned by a crash is
+// reclaimed once its lease expires.
+func (r *repo) Start(lc *lifecycle.Coordinator) error {
+	r.logger.Info("starting classification workers", "workers", r.jobs.Workers)
+
+	ctx := lc.Context()
+
+	var wg sync.WaitGroup
+	for i := range r.jobs.Workers {
+		wg.Go(func() {
+			r.work(ctx, r.logger.With("worker", i+1))
+		})
+	}
+
+	lc.OnDrain(func() {
+		<-ctx.Done()
+		r.logger.Info("stopping classification workers")
+		wg.Wait()
+		r.logger.Info("classification workers stopped")
+	})
+
+	return nil
+}
+
+func (r *repo) work(ctx context.Context, logger *slog.Logger) {
+	timer := time.NewTimer(r.jobs.PollInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		for r.runNext(ctx, logger) {
+		}
+
+		timer.Reset(r.jobs.PollInterval)
+	}
+}
+
+// runNext claims and runs a single job. It returns true when a job was
+// processed and the worker should immediately look for another.
+func (r *repo) runNext(ctx context.Context, logger *slog.Logger) bool {
+	job, err := r.claimJob(ctx)
+	if err != nil {
+		if !errors.Is(err, ErrJobNotFound) && ctx.Err() == nil {
+			logger.Error("claim classification job failed", "error", err)
+		}
+		return false
+	}
+
+	r.runJob(ctx, logger, job)
+	return ctx.Err() == nil
+}
+
+func (r *repo) runJob(ctx context.Context, logger *slog.Logger, job *Job) {
+	logger = logger.With(
+		"job_id", job.ID,
+		"document_id", job.DocumentID,
+		"attempt", job.Attempts,
+	)
+
+	// Updates that record the outcome must land even when shutdown cancels ctx.
+	persistCtx := context.WithoutCancel(ctx)
+
+	if job.Attempts > job.MaxAttempts {
+		err := fmt.Errorf("lease expired after %d attempts", job.MaxAttempts)
+		if ferr := r.failJob(persistCtx, job, err); ferr != nil {
+			logger.Error("fail classification job failed", "error", ferr)
+		}
+		r.publishError(job, err)
+		logger.Error("classification job abandoned", "error", err)
+		return
+	}
+
+	logger.Info("classification job started")
+	r.hub.Begin(job.DocumentID)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go r.heartbeat(runCtx, logger, job)
+
+	c, err := r.execute(runCtx, job)
+	if err == nil {
+		r.publishComplete(logger, job, c)
+		logger.Info("classification job succeeded", "classification_id", c.ID)
+		return
+	}
+
+	if errors.Is(err, ErrLeaseLost) {
+		logger.Warn("classification job reclaimed by another worker, discarding result")
+		return
+	}
+
+	if ctx.Err() != nil {
+		if rerr := r.releaseJob(persistCtx, job); rerr != nil {
+			logger.Error("release classification job failed", "error", rerr)
+		}
+		logger.Info("classification job released on shutdown")
+		return
+	}
+
+	if job.Attempts < job.MaxAttempts && !permanent(err) {
+		if rerr := r.retryJob(persistCtx, job, err); rerr != nil {
+			logger.Error("retry classification job failed", "error", rerr)
+		}
+		logger.Warn("classification job failed, retrying", "error", err)
+		return
+	}
+
+	if ferr := r.failJob(persistCtx, job, err); ferr != nil {
+		logger.Error("fail classification job failed", "error", ferr)
+	}
+	r.publishError(job, err)
+	logger.Error("classification job failed", "error", err)
+}
+
+// heartbeat extends the job lease at a third of the lease duration until ctx
+// is cancelled, keeping long-running classifications from being reclaimed.
+func (r *repo) heartbeat(ctx context.Context, logger *slog.Logger, job *Job) {
+	ticker := time.NewTicker(r.jobs.LeaseDuration / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.extendLease(ctx, job); err != nil && ctx.Err() == nil {
+				logger.Warn("extend classification job lease failed", "error", err)
+			}
+		}
+	}
+}
+
+// execute runs the classification workflow for a job and persists the result.
+// The job's first agent is primary: its progress is forwarded to the event
+// hub as it happens and its answer becomes the classification. The other
+// agents of an ensemble classify the document concurrently for comparison
+// and are cancelled if the primary run fails. When the primary run escalates,
+// the escalation agent's answer becomes the classification and the first
+// answer is kept beside it.
+func (r *repo) execute(ctx context.Context, job *Job) (*Classification, error) {
+	entries, err := r.resolveAgents(job.Agents)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := r.rt.Prompts.Versions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("resolve prompt versions: %w", err)
+	}
+
+	runs := make([]agentRun, len(entries))
+
+	compareCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i, e := range entries[1:] {
+		wg.Go(func() {
+			runs[i+1] = r.runSecondary(compareCtx, job, e)
+		})
+	}
+
+	observer := workflow.NewStreamingObserver(streamBufferSize, r.rt.Logger)
+
+	forwarded := make(chan struct{})
+	go func() {
+		defer close(forwarded)
+		for event := range observer.Events() {
+			r.hub.Publish(job.DocumentID, event)
+		}
+	}()
+
+	result, err := workflow.Execute(ctx, r.primaryRuntime(entries), job.ID, job.DocumentID, observer)
+
+	observer.Close()
+	<-forwarded
+
+	if err != nil {
+		cancel()
+	}
+	wg.Wait()
+
+	if err != nil {
+		return nil, fmt.Errorf("classify document: %s: %w", job.DocumentID, err)
+	}
+
+	// A finished run is stored even when shutdown begins as it completes, so
+	// its model calls are not repeated on the next claim.
+	runs[0] = agentRun{entry: entries[0], result: result}
+	return r.persist(context.WithoutCancel(ctx), job, escalated(runs), versions)
+}
+
+// publishComplete publishes the stored classification as the terminal event of
+// the document's stream.
+func (r *repo) publishComplete(logger *slog.Logger, job *Job, c *Classification) {
+	defer r.hub.Finish(job.DocumentID)
+
+	data, err := core.ToMap(c)
+	if err != nil {
+		logger.Error("encode classification event failed", "error", err)
+		return
+	}
+
+	r.hub.Publish(job.DocumentID, workflow.ExecutionEvent{
+		Type:      workflow.Complete,
+		Timestamp: time.Now(),
+		Data:      data,
+	})
+}
+
+// publishError publishes a job's final failure as the terminal event of the
+// document's stream. Failures that will be retried are not published; the
+// stream stays open and carries the next attempt's progress.
+func (r *repo) publishError(job *Job, err error) {
+	r.hub.Publish(job.DocumentID, workflow.ExecutionEvent{
+		Type:      workflow.Error,
+		Timestamp: time.Now(),
+		Data:      map[string]any{"message": err.Error()},
+	})
+	r.hub.Finish(job.DocumentID)
+}
+
+// permanent reports whether err cannot be resolved by retrying the job.
+func permanent(err error) bool {
+	return errors.Is(err, workflow.ErrDocumentNotFound) ||
+		errors.Is(err, agents.ErrUnknownAgent) ||
+		errors.Is(err, format.ErrUnsupportedFormat) ||
+		errors.Is(err, storage.ErrNotFound)
+}
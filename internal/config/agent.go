@@ -28,13 +28,37 @@ func FinalizeAgent(c *tauconfig.AgentConfig) error {
 	return validateAgent(c)
 }
 
+// finalizeAgents applies defaults and validation to the named agents. Each
+// agent's name defaults to its key, and no key may shadow the default agent.
+// Environment overrides apply only to the default agent.
+func finalizeAgents(agents map[string]tauconfig.AgentConfig, defaultName string) error {
+	for name, a := range agents {
+		if name == "" {
+			return fmt.Errorf("agent name required")
+		}
+		if name == defaultName {
+			return fmt.Errorf("%s: conflicts with the default agent", name)
+		}
+		if a.Name == "" {
+			a.Name = name
+		}
+		loadAgentDefaults(&a)
+		initAgent(&a)
+		if err := validateAgent(&a); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		agents[name] = a
+	}
+	return nil
+}
+
 func loadAgentDefaults(c *tauconfig.AgentConfig) {
 	defaults := tauconfig.DefaultAgentConfig()
 	defaults.Merge(c)
 	*c = defaults
 }
 
-func loadAgentEnv(c *tauconfig.AgentConfig) {
+func initAgent(c *tauconfig.AgentConfig) {
 	if c.Provider == nil {
 		c.Provider = &tauconfig.ProviderConfig{}
 	}
@@ -44,6 +68,10 @@ func loadAgentEnv(c *tauconfig.AgentConfig) {
 	if c.Model == nil {
 		c.Model = &tauconfig.ModelConfig{}
 	}
+}
+
+func loadAgentEnv(c *tauconfig.AgentConfig) {
+	initAgent(c)
 	if v := os.Getenv(EnvAgentProviderName); v != "" {
 		c.Provider.Name = v
 	}
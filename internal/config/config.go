@@ -51,22 +51,39 @@ var databaseEnv = &database.Env{
 }
 
 var storageEnv = &storage.Env{
+	Backend:          "HERALD_STORAGE_BACKEND",
 	ContainerName:    "HERALD_STORAGE_CONTAINER_NAME",
 	ConnectionString: "HERALD_STORAGE_CONNECTION_STRING",
 	ServiceURL:       "HERALD_STORAGE_SERVICE_URL",
+	Root:             "HERALD_STORAGE_ROOT",
+	Endpoint:         "HERALD_STORAGE_ENDPOINT",
+	Region:           "HERALD_STORAGE_REGION",
+	AccessKeyID:      "HERALD_STORAGE_ACCESS_KEY_ID",
+	SecretAccessKey:  "HERALD_STORAGE_SECRET_ACCESS_KEY",
 	MaxListSize:      "HERALD_STORAGE_MAX_LIST_SIZE",
 }
 
-// Config is the root configuration for the Herald service.
+// Config is the root configuration for the Herald service. Agent is the
+// default agent; Agents adds named agents that classifications can choose
+// instead, or run alongside it to compare results.
 type Config struct {
-	Agent           tauconfig.AgentConfig `json:"agent"`
-	Auth            auth.Config           `json:"auth"`
-	Server          ServerConfig          `json:"server"`
-	Database        database.Config       `json:"database"`
-	Storage         storage.Config        `json:"storage"`
-	API             APIConfig             `json:"api"`
-	ShutdownTimeout string                `json:"shutdown_timeout"`
-	Version         string                `json:"version"`
+	Agent           tauconfig.AgentConfig            `json:"agent"`
+	Agents          map[string]tauconfig.AgentConfig `json:"agents"`
+	Auth            auth.Config                      `json:"auth"`
+	Server          ServerConfig                     `json:"server"`
+	Database        database.Config                  `json:"database"`
+	Storage         storage.Config                   `json:"storage"`
+	API             APIConfig                        `json:"api"`
+	Formats         FormatsConfig                    `json:"formats"`
+	Jobs            JobsConfig                       `json:"jobs"`
+	Markings        MarkingsConfig                   `json:"markings"`
+	Replay          ReplayConfig                     `json:"replay"`
+	Usage           UsageConfig                      `json:"usage"`
+	Tracing         TracingConfig                    `json:"tracing"`
+	Throttle        ThrottleConfig                   `json:"throttle"`
+	Workflow        WorkflowConfig                   `json:"workflow"`
+	ShutdownTimeout string                           `json:"shutdown_timeout"`
+	Version         string                           `json:"version"`
 }
 
 // Env returns the HERALD_ENV value, defaulting to "local".
@@ -129,11 +146,36 @@ func (c *Config) Merge(overlay *Config) {
 		c.Version = overlay.Version
 	}
 	c.Agent.Merge(&overlay.Agent)
+	c.mergeAgents(overlay.Agents)
 	c.Auth.Merge(&overlay.Auth)
 	c.Server.Merge(&overlay.Server)
 	c.Database.Merge(&overlay.Database)
 	c.Storage.Merge(&overlay.Storage)
 	c.API.Merge(&overlay.API)
+	c.Formats.Merge(&overlay.Formats)
+	c.Jobs.Merge(&overlay.Jobs)
+	c.Markings.Merge(&overlay.Markings)
+	c.Replay.Merge(&overlay.Replay)
+	c.Usage.Merge(&overlay.Usage)
+	c.Tracing.Merge(&overlay.Tracing)
+	c.Throttle.Merge(&overlay.Throttle)
+	c.Workflow.Merge(&overlay.Workflow)
+}
+
+// mergeAgents merges overlay agents by name, so an overlay can add agents
+// or override fields of an existing one.
+func (c *Config) mergeAgents(overlay map[string]tauconfig.AgentConfig) {
+	if len(overlay) > 0 && c.Agents == nil {
+		c.Agents = make(map[string]tauconfig.AgentConfig, len(overlay))
+	}
+	for name, a := range overlay {
+		if existing, ok := c.Agents[name]; ok {
+			initAgent(&existing)
+			existing.Merge(&a)
+			a = existing
+		}
+		c.Agents[name] = a
+	}
 }
 
 func (c *Config) finalize() error {
@@ -146,6 +188,9 @@ func (c *Config) finalize() error {
 	if err := FinalizeAgent(&c.Agent); err != nil {
 		return fmt.Errorf("agent: %w", err)
 	}
+	if err := finalizeAgents(c.Agents, c.Agent.Name); err != nil {
+		return fmt.Errorf("agents: %w", err)
+	}
 	if err := c.Auth.Finalize(authEnv); err != nil {
 		return fmt.Errorf("auth: %w", err)
 	}
@@ -161,6 +206,35 @@ func (c *Config) finalize() error {
 	if err := c.API.Finalize(); err != nil {
 		return fmt.Errorf("api: %w", err)
 	}
+	if err := c.Formats.Finalize(); err != nil {
+		return fmt.Errorf("formats: %w", err)
+	}
+	if err := c.Jobs.Finalize(); err != nil {
+		return fmt.Errorf("jobs: %w", err)
+	}
+	if err := c.Markings.Finalize(); err != nil {
+		return fmt.Errorf("markings: %w", err)
+	}
+	if err := c.Replay.Finalize(); err != nil {
+		return fmt.Errorf("replay: %w", err)
+	}
+	if err := c.Usage.Finalize(); err != nil {
+		return fmt.Errorf("usage: %w", err)
+	}
+	if err := c.Tracing.Finalize(); err != nil {
+		return fmt.Errorf("tracing: %w", err)
+	}
+	if err := c.Throttle.Finalize(); err != nil {
+		return fmt.Errorf("throttle: %w", err)
+	}
+	if err := c.Workflow.Finalize(); err != nil {
+		return fmt.Errorf("workflow: %w", err)
+	}
+	if a := c.Workflow.EscalationAgent; a != "" && a != c.Agent.Name {
+		if _, ok := c.Agents[a]; !ok {
+			return fmt.Errorf("workflow: unknown escalation_agent %q", a)
+		}
+	}
 	return nil
 }
 func (c *Config) loadDefaults() {
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	EnvFormatsRenderer    = "HERALD_FORMATS_RENDERER"
+	EnvFormatsPDFPrefix   = "HERALD_FORMATS_PDF"
+	EnvFormatsImagePrefix = "HERALD_FORMATS_IMAGE"
+	EnvFormatsTIFFPrefix  = "HERALD_FORMATS_TIFF"
+
+	EnvFormatsOfficePrefix           = "HERALD_FORMATS_OFFICE"
+	EnvFormatsOfficeConverter        = "HERALD_FORMATS_OFFICE_CONVERTER"
+	EnvFormatsOfficeConverterTimeout = "HERALD_FORMATS_OFFICE_CONVERTER_TIMEOUT"
+)
+
+// defaultOfficeConverter converts with LibreOffice, in a profile under the
+// output directory so concurrent conversions do not contend for one.
+const defaultOfficeConverter = "soffice -env:UserInstallation=file://{outdir}/.soffice " +
+	"--headless --convert-to pdf --outdir {outdir} {input}"
+
+// FormatsConfig configures the format handlers, one section per handler.
+// Renderer selects the rasterization backend all handlers share: magick
+// (default) shells out to ImageMagick, and auto rasterizes image-only
+// (scanned) pages in-process and falls back to ImageMagick for the rest.
+type FormatsConfig struct {
+	Renderer string       `json:"renderer"`
+	PDF      FormatConfig `json:"pdf"`
+	Image    FormatConfig `json:"image"`
+	TIFF     FormatConfig `json:"tiff"`
+	Office   OfficeConfig `json:"office"`
+}
+
+// FormatConfig controls how a format handler renders pages. Crop enables
+// banner crop mode: each page also gets an image of its header and footer
+// bands, each CropHeight of the page tall (a fraction, default 0.1),
+// rendered at CropDensity DPI (default 300), while the full page is rendered
+// at PageDensity DPI (default 150) instead of 300. Images and TIFF frames
+// are treated as 300 DPI scans, so a density of 150 halves them.
+type FormatConfig struct {
+	Crop        bool    `json:"crop"`
+	CropHeight  float64 `json:"crop_height"`
+	CropDensity int     `json:"crop_density"`
+	PageDensity int     `json:"page_density"`
+}
+
+// OfficeConfig configures the Office handler: the FormatConfig rendering
+// options for the converted PDF, plus Converter, the command that converts a
+// document to PDF. Its arguments are split on whitespace, {input} names the
+// source file, and {outdir} the directory the command must write
+// <source name>.pdf into. ConverterTimeout bounds each conversion.
+type OfficeConfig struct {
+	FormatConfig
+	Converter        string `json:"converter"`
+	ConverterTimeout string `json:"converter_timeout"`
+}
+
+// Finalize applies defaults, environment variable overrides, and validation.
+func (c *FormatsConfig) Finalize() error {
+	if c.Renderer == "" {
+		c.Renderer = "magick"
+	}
+	if v := os.Getenv(EnvFormatsRenderer); v != "" {
+		c.Renderer = v
+	}
+	c.Renderer = strings.ToLower(c.Renderer)
+	switch c.Renderer {
+	case "magick", "auto":
+	default:
+		return fmt.Errorf("renderer must be magick or auto, got %q", c.Renderer)
+	}
+
+	if err := c.PDF.finalize(EnvFormatsPDFPrefix); err != nil {
+		return fmt.Errorf("pdf: %w", err)
+	}
+	if err := c.Image.finalize(EnvFormatsImagePrefix); err != nil {
+		return fmt.Errorf("image: %w", err)
+	}
+	if err := c.TIFF.finalize(EnvFormatsTIFFPrefix); err != nil {
+		return fmt.Errorf("tiff: %w", err)
+	}
+	if err := c.Office.finalize(EnvFormatsOfficePrefix); err != nil {
+		return fmt.Errorf("office: %w", err)
+	}
+	return nil
+}
+
+// Merge overwrites non-zero fields from overlay.
+func (c *FormatsConfig) Merge(overlay *FormatsConfig) {
+	if overlay.Renderer != "" {
+		c.Renderer = overlay.Renderer
+	}
+	c.PDF.Merge(&overlay.PDF)
+	c.Image.Merge(&overlay.Image)
+	c.TIFF.Merge(&overlay.TIFF)
+	c.Office.Merge(&overlay.Office)
+}
+
+// Merge overwrites non-zero fields from overlay.
+func (c *OfficeConfig) Merge(overlay *OfficeConfig) {
+	c.FormatConfig.Merge(&overlay.FormatConfig)
+	if overlay.Converter != "" {
+		c.Converter = overlay.Converter
+	}
+	if overlay.ConverterTimeout != "" {
+		c.ConverterTimeout = overlay.ConverterTimeout
+	}
+}
+
+// ConverterArgs returns the converter command split into its arguments.
+func (c *OfficeConfig) ConverterArgs() []string {
+	return strings.Fields(c.Converter)
+}
+
+// ConverterTimeoutDuration returns ConverterTimeout as a time.Duration.
+func (c *OfficeConfig) ConverterTimeoutDuration() time.Duration {
+	d, _ := time.ParseDuration(c.ConverterTimeout)
+	return d
+}
+
+func (c *OfficeConfig) finalize(prefix string) error {
+	if c.Converter == "" {
+		c.Converter = defaultOfficeConverter
+	}
+	if c.ConverterTimeout == "" {
+		c.ConverterTimeout = "2m"
+	}
+	if v := os.Getenv(EnvFormatsOfficeConverter); v != "" {
+		c.Converter = v
+	}
+	if v := os.Getenv(EnvFormatsOfficeConverterTimeout); v != "" {
+		c.ConverterTimeout = v
+	}
+
+	if !strings.Contains(c.Converter, "{input}") {
+		return fmt.Errorf("converter must reference {input}, got %q", c.Converter)
+	}
+	if d, err := time.ParseDuration(c.ConverterTimeout); err != nil || d <= 0 {
+		return fmt.Errorf("converter_timeout must be a positive duration, got %q", c.ConverterTimeout)
+	}
+
+	return c.FormatConfig.finalize(prefix)
+}
+
+// Merge overwrites non-zero fields from overlay. Boolean Crop only applies
+// when true.
+func (c *FormatConfig) Merge(overlay *FormatConfig) {
+	if overlay.Crop {
+		c.Crop = true
+	}
+	if overlay.CropHeight != 0 {
+		c.CropHeight = overlay.CropHeight
+	}
+	if overlay.CropDensity != 0 {
+		c.CropDensity = overlay.CropDensity
+	}
+	if overlay.PageDensity != 0 {
+		c.PageDensity = overlay.PageDensity
+	}
+}
+
+func (c *FormatConfig) finalize(prefix string) error {
+	c.loadDefaults()
+	c.loadEnv(prefix)
+	return c.validate()
+}
+
+func (c *FormatConfig) loadDefaults() {
+	if c.CropHeight == 0 {
+		c.CropHeight = 0.1
+	}
+	if c.CropDensity == 0 {
+		c.CropDensity = 300
+	}
+	if c.PageDensity == 0 {
+		c.PageDensity = 150
+	}
+}
+
+// loadEnv reads <prefix>_CROP, _CROP_HEIGHT, _CROP_DENSITY, and
+// _PAGE_DENSITY.
+func (c *FormatConfig) loadEnv(prefix string) {
+	if v := os.Getenv(prefix + "_CROP"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			c.Crop = b
+		}
+	}
+	if v := os.Getenv(prefix + "_CROP_HEIGHT"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			c.CropHeight = f
+		}
+	}
+	if v := os.Getenv(prefix + "_CROP_DENSITY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.CropDensity = n
+		}
+	}
+	if v := os.Getenv(prefix + "_PAGE_DENSITY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.PageDensity = n
+		}
+	}
+}
+
+func (c *FormatConfig) validate() error {
+	if c.CropHeight <= 0 || c.CropHeight >= 0.5 {
+		return fmt.Errorf("crop_height must be between 0 and 0.5, got %g", c.CropHeight)
+	}
+	if c.CropDensity < 72 || c.CropDensity > 1200 {
+		return fmt.Errorf("crop_density must be between 72 and 1200, got %d", c.CropDensity)
+	}
+	if c.PageDensity < 72 || c.PageDensity > 1200 {
+		return fmt.Errorf("page_density must be between 72 and 1200, got %d", c.PageDensity)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	EnvJobsWorkers       = "HERALD_JOBS_WORKERS"
+	EnvJobsPollInterval  = "HERALD_JOBS_POLL_INTERVAL"
+	EnvJobsLeaseDuration = "HERALD_JOBS_LEASE_DURATION"
+	EnvJobsMaxAttempts   = "HERALD_JOBS_MAX_ATTEMPTS"
+	EnvJobsRetryBackoff  = "HERALD_JOBS_RETRY_BACKOFF"
+	EnvJobsMaxRunning    = "HERALD_JOBS_MAX_RUNNING"
+)
+
+// JobsConfig holds parameters for the background classification job workers.
+// LeaseDuration bounds how long a claimed job may go without a heartbeat before
+// another worker (or a restarted process) reclaims it. MaxRunning caps the number
+// of jobs running at once across every process sharing the database.
+type JobsConfig struct {
+	Workers       int    `json:"workers"`
+	PollInterval  string `json:"poll_interval"`
+	LeaseDuration string `json:"lease_duration"`
+	MaxAttempts   int    `json:"max_attempts"`
+	RetryBackoff  string `json:"retry_backoff"`
+	MaxRunning    int    `json:"max_running"`
+}
+
+// PollIntervalDuration returns PollInterval as a time.Duration.
+func (c *JobsConfig) PollIntervalDuration() time.Duration {
+	d, _ := time.ParseDuration(c.PollInterval)
+	return d
+}
+
+// LeaseDurationValue returns LeaseDuration as a time.Duration.
+func (c *JobsConfig) LeaseDurationValue() time.Duration {
+	d, _ := time.ParseDuration(c.LeaseDuration)
+	return d
+}
+
+// RetryBackoffDuration returns RetryBackoff as a time.Duration.
+func (c *JobsConfig) RetryBackoffDuration() time.Duration {
+	d, _ := time.ParseDuration(c.RetryBackoff)
+	return d
+}
+
+// Finalize applies defaults, environment variable overrides, and validation.
+func (c *JobsConfig) Finalize() error {
+	c.loadDefaults()
+	c.loadEnv()
+	return c.validate()
+}
+
+// Merge overwrites non-zero fields from overlay.
+func (c *JobsConfig) Merge(overlay *JobsConfig) {
+	if overlay.Workers != 0 {
+		c.Workers = overlay.Workers
+	}
+	if overlay.PollInterval != "" {
+		c.PollInterval = overlay.PollInterval
+	}
+	if overlay.LeaseDuration != "" {
+		c.LeaseDuration = overlay.LeaseDuration
+	}
+	if overlay.MaxAttempts != 0 {
+		c.MaxAttempts = overlay.MaxAttempts
+	}
+	if overlay.RetryBackoff != "" {
+		c.RetryBackoff = overlay.RetryBackoff
+	}
+	if overlay.MaxRunning != 0 {
+		c.MaxRunning = overlay.MaxRunning
+	}
+}
+
+func (c *JobsConfig) loadDefaults() {
+	if c.Workers == 0 {
+		c.Workers = 2
+	}
+	if c.PollInterval == "" {
+		c.PollInterval = "2s"
+	}
+	if c.LeaseDuration == "" {
+		c.LeaseDuration = "5m"
+	}
+	if c.MaxAttempts == 0 {
+		c.MaxAttempts = 3
+	}
+	if c.RetryBackoff == "" {
+		c.RetryBackoff = "30s"
+	}
+	if c.MaxRunning == 0 {
+		c.MaxRunning = 8
+	}
+}
+
+func (c *JobsConfig) loadEnv() {
+	if v := os.Getenv(EnvJobsWorkers); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.Workers = n
+		}
+	}
+	if v := os.Getenv(EnvJobsPollInterval); v != "" {
+		c.PollInterval = v
+	}
+	if v := os.Getenv(EnvJobsLeaseDuration); v != "" {
+		c.LeaseDuration = v
+	}
+	if v := os.Getenv(EnvJobsMaxAttempts); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.MaxAttempts = n
+		}
+	}
+	if v := os.Getenv(EnvJobsRetryBackoff); v != "" {
+		c.RetryBackoff = v
+	}
+	if v := os.Getenv(EnvJobsMaxRunning); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.MaxRunning = n
+		}
+	}
+}
+
+func (c *JobsConfig) validate() error {
+	if c.Workers < 1 {
+		return fmt.Errorf("workers must be at least 1, got %d", c.Workers)
+	}
+	if c.MaxRunning < 1 {
+		return fmt.Errorf("max_running must be at least 1, got %d", c.MaxRunning)
+	}
+	if c.MaxAttempts < 1 {
+		return fmt.Errorf("max_attempts must be at least 1, got %d", c.MaxAttempts)
+	}
+	poll, err := time.ParseDuration(c.PollInterval)
+	if err != nil || poll <= 0 {
+		return fmt.Errorf("invalid poll_interval: %q", c.PollInterval)
+	}
+	lease, err := time.ParseDuration(c.LeaseDuration)
+	if err != nil || lease <= 0 {
+		return fmt.Errorf("invalid lease_duration: %q", c.LeaseDuration)
+	}
+	// Workers heartbeat every third of the lease; the floor keeps that period
+	// positive and no shorter than a poll.
+	if lease < 3*poll {
+		return fmt.Errorf("lease_duration must be at least 3x poll_interval (%s), got %s", 3*poll, lease)
+	}
+	backoff, err := time.ParseDuration(c.RetryBackoff)
+	if err != nil {
+		return fmt.Errorf("invalid retry_backoff: %w", err)
+	}
+	if backoff < 0 {
+		return fmt.Errorf("retry_backoff must not be negative, got %s", backoff)
+	}
+	return nil
+}
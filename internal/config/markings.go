@@ -0,0 +1,42 @@
+package config
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/JaimeStill/herald/internal/markings"
+)
+
+const EnvMarkingsStrict = "HERALD_MARKINGS_STRICT"
+
+// MarkingsConfig controls how classification strings are normalized into
+// structured markings. Strict rejects strings that do not parse against the
+// taxonomy instead of storing them flagged. Taxonomy lists the recognized
+// levels and controls; empty lists fall back to the defaults.
+type MarkingsConfig struct {
+	Strict   bool              `json:"strict"`
+	Taxonomy markings.Taxonomy `json:"taxonomy"`
+}
+
+// Finalize applies defaults, environment variable overrides, and validation.
+func (c *MarkingsConfig) Finalize() error {
+	c.loadEnv()
+	return c.Taxonomy.Finalize()
+}
+
+// Merge overwrites non-zero fields from overlay. Boolean Strict only applies
+// when true; taxonomy lists apply when non-empty.
+func (c *MarkingsConfig) Merge(overlay *MarkingsConfig) {
+	if overlay.Strict {
+		c.Strict = true
+	}
+	c.Taxonomy.Merge(&overlay.Taxonomy)
+}
+
+func (c *MarkingsConfig) loadEnv() {
+	if v := os.Getenv(EnvMarkingsStrict); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			c.Strict = b
+		}
+	}
+}
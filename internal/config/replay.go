@@ -0,0 +1,66 @@
+package config
+
+import (
+	"os"
+	"strconv"
+)
+
+const (
+	EnvReplayFixtures = "HERALD_REPLAY_FIXTURES"
+	EnvReplayRecord   = "HERALD_REPLAY_RECORD"
+	EnvReplayFallback = "HERALD_REPLAY_FALLBACK"
+)
+
+// ReplayConfig controls offline model responses. Fixtures is the directory of
+// recorded responses served when agent.provider.name is "replay". Record
+// captures the responses of a live provider into Fixtures so they can be
+// replayed later. Fallback answers calls with no recording from the default
+// fixture instead of failing them.
+type ReplayConfig struct {
+	Fixtures string `json:"fixtures"`
+	Record   bool   `json:"record"`
+	Fallback bool   `json:"fallback"`
+}
+
+// Finalize applies defaults and environment variable overrides.
+func (c *ReplayConfig) Finalize() error {
+	c.loadDefaults()
+	c.loadEnv()
+	return nil
+}
+
+// Merge overwrites non-zero fields from overlay. Booleans Record and Fallback
+// only apply when true.
+func (c *ReplayConfig) Merge(overlay *ReplayConfig) {
+	if overlay.Fixtures != "" {
+		c.Fixtures = overlay.Fixtures
+	}
+	if overlay.Record {
+		c.Record = true
+	}
+	if overlay.Fallback {
+		c.Fallback = true
+	}
+}
+
+func (c *ReplayConfig) loadDefaults() {
+	if c.Fixtures == "" {
+		c.Fixtures = "_project/marked-documents/replay"
+	}
+}
+
+func (c *ReplayConfig) loadEnv() {
+	if v := os.Getenv(EnvReplayFixtures); v != "" {
+		c.Fixtures = v
+	}
+	if v := os.Getenv(EnvReplayRecord); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			c.Record = b
+		}
+	}
+	if v := os.Getenv(EnvReplayFallback); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			c.Fallback = b
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	EnvThrottleRequestsPerMinute = "HERALD_THROTTLE_REQUESTS_PER_MINUTE"
+	EnvThrottleTokensPerMinute   = "HERALD_THROTTLE_TOKENS_PER_MINUTE"
+	EnvThrottleMaxAttempts       = "HERALD_THROTTLE_MAX_ATTEMPTS"
+	EnvThrottleMinBackoff        = "HERALD_THROTTLE_MIN_BACKOFF"
+	EnvThrottleMaxBackoff        = "HERALD_THROTTLE_MAX_BACKOFF"
+)
+
+// ThrottleConfig bounds the rate of model calls across the process and
+// controls how transient provider failures are retried. RequestsPerMinute
+// and TokensPerMinute apply to every deployment unless Deployments, keyed by
+// model name, overrides them; zero leaves that rate unbounded. Each call is
+// attempted up to MaxAttempts times, backing off exponentially from
+// MinBackoff to MaxBackoff unless the provider asks for a longer wait.
+type ThrottleConfig struct {
+	RequestsPerMinute int                   `json:"requests_per_minute"`
+	TokensPerMinute   int                   `json:"tokens_per_minute"`
+	Deployments       map[string]RateLimits `json:"deployments"`
+	MaxAttempts       int                   `json:"max_attempts"`
+	MinBackoff        string                `json:"min_backoff"`
+	MaxBackoff        string                `json:"max_backoff"`
+}
+
+// RateLimits is the request and token budget of one deployment per minute.
+type RateLimits struct {
+	RequestsPerMinute int `json:"requests_per_minute"`
+	TokensPerMinute   int `json:"tokens_per_minute"`
+}
+
+// Limits returns the rate limits that apply to model.
+func (c *ThrottleConfig) Limits(model string) RateLimits {
+	if limits, ok := c.Deployments[model]; ok {
+		return limits
+	}
+	return RateLimits{
+		RequestsPerMinute: c.RequestsPerMinute,
+		TokensPerMinute:   c.TokensPerMinute,
+	}
+}
+
+// MinBackoffDuration returns MinBackoff as a time.Duration.
+func (c *ThrottleConfig) MinBackoffDuration() time.Duration {
+	d, _ := time.ParseDuration(c.MinBackoff)
+	return d
+}
+
+// MaxBackoffDuration returns MaxBackoff as a time.Duration.
+func (c *ThrottleConfig) MaxBackoffDuration() time.Duration {
+	d, _ := time.ParseDuration(c.MaxBackoff)
+	return d
+}
+
+// Finalize applies defaults, environment variable overrides, and validation.
+func (c *ThrottleConfig) Finalize() error {
+	c.loadDefaults()
+	c.loadEnv()
+	return c.validate()
+}
+
+// Merge overwrites non-zero fields from overlay. Deployments merge per
+// model, so an overlay can add or replace individual deployments.
+func (c *ThrottleConfig) Merge(overlay *ThrottleConfig) {
+	if overlay.RequestsPerMinute != 0 {
+		c.RequestsPerMinute = overlay.RequestsPerMinute
+	}
+	if overlay.TokensPerMinute != 0 {
+		c.TokensPerMinute = overlay.TokensPerMinute
+	}
+	if len(overlay.Deployments) > 0 && c.Deployments == nil {
+		c.Deployments = make(map[string]RateLimits, len(overlay.Deployments))
+	}
+	for model, limits := range overlay.Deployments {
+		c.Deployments[model] = limits
+	}
+	if overlay.MaxAttempts != 0 {
+		c.MaxAttempts = overlay.MaxAttempts
+	}
+	if overlay.MinBackoff != "" {
+		c.MinBackoff = overlay.MinBackoff
+	}
+	if overlay.MaxBackoff != "" {
+		c.MaxBackoff = overlay.MaxBackoff
+	}
+}
+
+func (c *ThrottleConfig) loadDefaults() {
+	if c.Deployments == nil {
+		c.Deployments = map[string]RateLimits{}
+	}
+	if c.MaxAttempts == 0 {
+		c.MaxAttempts = 5
+	}
+	if c.MinBackoff == "" {
+		c.MinBackoff = "1s"
+	}
+	if c.MaxBackoff == "" {
+		c.MaxBackoff = "60s"
+	}
+}
+
+func (c *ThrottleConfig) loadEnv() {
+	if v := os.Getenv(EnvThrottleRequestsPerMinute); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.RequestsPerMinute = n
+		}
+	}
+	if v := os.Getenv(EnvThrottleTokensPerMinute); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.TokensPerMinute = n
+		}
+	}
+	if v := os.Getenv(EnvThrottleMaxAttempts); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.MaxAttempts = n
+		}
+	}
+	if v := os.Getenv(EnvThrottleMinBackoff); v != "" {
+		c.MinBackoff = v
+	}
+	if v := os.Getenv(EnvThrottleMaxBackoff); v != "" {
+		c.MaxBackoff = v
+	}
+}
+
+func (c *ThrottleConfig) validate() error {
+	if c.RequestsPerMinute < 0 || c.TokensPerMinute < 0 {
+		return fmt.Errorf("rate limits must not be negative")
+	}
+	for model, limits := range c.Deployments {
+		if model == "" {
+			return fmt.Errorf("deployment model name required")
+		}
+		if limits.RequestsPerMinute < 0 || limits.TokensPerMinute < 0 {
+			return fmt.Errorf("rate limits for %s must not be negative", model)
+		}
+	}
+	if c.MaxAttempts < 1 {
+		return fmt.Errorf("max_attempts must be at least 1, got %d", c.MaxAttempts)
+	}
+	minBackoff, err := time.ParseDuration(c.MinBackoff)
+	if err != nil || minBackoff <= 0 {
+		return fmt.Errorf("invalid min_backoff: %q", c.MinBackoff)
+	}
+	maxBackoff, err := time.ParseDuration(c.MaxBackoff)
+	if err != nil || maxBackoff < minBackoff {
+		return fmt.Errorf("invalid max_backoff: %q", c.MaxBackoff)
+	}
+	return nil
+}
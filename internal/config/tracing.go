@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+const (
+	EnvTracingExporter    = "HERALD_TRACING_EXPORTER"
+	EnvTracingEndpoint    = "HERALD_TRACING_ENDPOINT"
+	EnvTracingSampleRatio = "HERALD_TRACING_SAMPLE_RATIO"
+)
+
+// Tracing exporters.
+const (
+	TracingNone   = "none"
+	TracingStdout = "stdout"
+	TracingOTLP   = "otlp"
+)
+
+// TracingConfig controls OpenTelemetry tracing. Exporter "none" disables
+// tracing, "stdout" writes spans to standard output for local use, and
+// "otlp" sends them over OTLP/HTTP. Endpoint is the OTLP collector URL; when
+// empty the standard OTEL_EXPORTER_OTLP_* variables apply. SampleRatio is
+// the fraction of new traces recorded; traces continued from an incoming
+// request follow the caller's sampling decision.
+type TracingConfig struct {
+	Exporter    string  `json:"exporter"`
+	Endpoint    string  `json:"endpoint"`
+	SampleRatio float64 `json:"sample_ratio"`
+}
+
+// Finalize applies defaults, environment variable overrides, and validation.
+func (c *TracingConfig) Finalize() error {
+	c.loadDefaults()
+	c.loadEnv()
+	return c.validate()
+}
+
+// Merge overwrites non-zero fields from overlay.
+func (c *TracingConfig) Merge(overlay *TracingConfig) {
+	if overlay.Exporter != "" {
+		c.Exporter = overlay.Exporter
+	}
+	if overlay.Endpoint != "" {
+		c.Endpoint = overlay.Endpoint
+	}
+	if overlay.SampleRatio != 0 {
+		c.SampleRatio = overlay.SampleRatio
+	}
+}
+
+// Enabled reports whether spans are exported. An unset exporter is
+// treated as "none".
+func (c *TracingConfig) Enabled() bool {
+	return c.Exporter != "" && c.Exporter != TracingNone
+}
+
+func (c *TracingConfig) loadDefaults() {
+	if c.Exporter == "" {
+		c.Exporter = TracingNone
+	}
+	if c.SampleRatio == 0 {
+		c.SampleRatio = 1
+	}
+}
+
+func (c *TracingConfig) loadEnv() {
+	if v := os.Getenv(EnvTracingExporter); v != "" {
+		c.Exporter = v
+	}
+	if v := os.Getenv(EnvTracingEndpoint); v != "" {
+		c.Endpoint = v
+	}
+	if v := os.Getenv(EnvTracingSampleRatio); v != "" {
+		if ratio, err := strconv.ParseFloat(v, 64); err == nil {
+			c.SampleRatio = ratio
+		}
+	}
+}
+
+func (c *TracingConfig) validate() error {
+	switch c.Exporter {
+	case TracingNone, TracingStdout, TracingOTLP:
+	default:
+		return fmt.Errorf("unsupported exporter %q", c.Exporter)
+	}
+	if c.SampleRatio <= 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("sample_ratio must be in (0, 1], got %g", c.SampleRatio)
+	}
+	return nil
+}
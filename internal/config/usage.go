@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+const EnvUsageCurrency = "HERALD_USAGE_CURRENCY"
+
+// UsageConfig prices the model usage reported by the classification usage
+// report. Prices is keyed by model name as recorded on classifications;
+// models without an entry are reported without a cost.
+type UsageConfig struct {
+	Currency string                `json:"currency"`
+	Prices   map[string]ModelPrice `json:"prices"`
+}
+
+// ModelPrice is the cost of a model's tokens per million, in the configured
+// currency.
+type ModelPrice struct {
+	PromptPerMillion     float64 `json:"prompt_per_million"`
+	CompletionPerMillion float64 `json:"completion_per_million"`
+}
+
+// Finalize applies defaults, environment variable overrides, and validation.
+func (c *UsageConfig) Finalize() error {
+	c.loadDefaults()
+	c.loadEnv()
+	return c.validate()
+}
+
+// Merge overwrites non-zero fields from overlay. Prices merge per model, so
+// an overlay can add or replace individual models.
+func (c *UsageConfig) Merge(overlay *UsageConfig) {
+	if overlay.Currency != "" {
+		c.Currency = overlay.Currency
+	}
+	if len(overlay.Prices) > 0 && c.Prices == nil {
+		c.Prices = make(map[string]ModelPrice, len(overlay.Prices))
+	}
+	for model, price := range overlay.Prices {
+		c.Prices[model] = price
+	}
+}
+
+func (c *UsageConfig) loadDefaults() {
+	if c.Currency == "" {
+		c.Currency = "USD"
+	}
+	if c.Prices == nil {
+		c.Prices = map[string]ModelPrice{}
+	}
+}
+
+func (c *UsageConfig) loadEnv() {
+	if v := os.Getenv(EnvUsageCurrency); v != "" {
+		c.Currency = v
+	}
+}
+
+func (c *UsageConfig) validate() error {
+	for model, price := range c.Prices {
+		if model == "" {
+			return fmt.Errorf("price model name required")
+		}
+		if price.PromptPerMillion < 0 || price.CompletionPerMillion < 0 {
+			return fmt.Errorf("price for %s must not be negative", model)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	EnvWorkflowPartialPages        = "HERALD_WORKFLOW_PARTIAL_PAGES"
+	EnvWorkflowEscalationAgent     = "HERALD_WORKFLOW_ESCALATION_AGENT"
+	EnvWorkflowEscalationThreshold = "HERALD_WORKFLOW_ESCALATION_THRESHOLD"
+	EnvWorkflowTextFastPath        = "HERALD_WORKFLOW_TEXT_FAST_PATH"
+	EnvWorkflowClassifyStrategy    = "HERALD_WORKFLOW_CLASSIFY_STRATEGY"
+)
+
+// WorkflowConfig controls how the classification workflow handles problem
+// documents. PartialPages keeps a run going when individual pages fail: the
+// failed pages are recorded, the rest are classified, and the result is
+// marked LOW confidence. When false, any page failure fails the run.
+//
+// EscalationAgent names an agent, the default or one of the named agents,
+// that classifies a document again when the first answer's confidence is at
+// or below EscalationThreshold (HIGH, MEDIUM, or LOW; default LOW). Both
+// answers are kept. Runs do not escalate when EscalationAgent is empty.
+//
+// TextFastPath classifies a PDF page from its text layer alone, skipping the
+// vision call, when the text carries a banner and portion markings that agree
+// unambiguously. Other pages, and every page when false, go to the vision
+// model with their text as corroborating evidence.
+//
+// ClassifyStrategy selects what the classify stage sends the vision model:
+// "page" (default) sends each full page; "banners" sends a page's banner
+// crop first and the full page only when the crop is inconclusive. Banner
+// crops come from format handlers with crop enabled.
+type WorkflowConfig struct {
+	PartialPages        bool   `json:"partial_pages"`
+	EscalationAgent     string `json:"escalation_agent"`
+	EscalationThreshold string `json:"escalation_threshold"`
+	TextFastPath        bool   `json:"text_fast_path"`
+	ClassifyStrategy    string `json:"classify_strategy"`
+}
+
+// Finalize applies defaults, environment variable overrides, and validation.
+func (c *WorkflowConfig) Finalize() error {
+	c.loadDefaults()
+	c.loadEnv()
+	return c.validate()
+}
+
+// Merge overwrites non-zero fields from overlay. Booleans PartialPages and
+// TextFastPath only apply when true.
+func (c *WorkflowConfig) Merge(overlay *WorkflowConfig) {
+	if overlay.PartialPages {
+		c.PartialPages = true
+	}
+	if overlay.EscalationAgent != "" {
+		c.EscalationAgent = overlay.EscalationAgent
+	}
+	if overlay.EscalationThreshold != "" {
+		c.EscalationThreshold = overlay.EscalationThreshold
+	}
+	if overlay.TextFastPath {
+		c.TextFastPath = true
+	}
+	if overlay.ClassifyStrategy != "" {
+		c.ClassifyStrategy = overlay.ClassifyStrategy
+	}
+}
+
+func (c *WorkflowConfig) loadDefaults() {
+	if c.EscalationThreshold == "" {
+		c.EscalationThreshold = "LOW"
+	}
+	if c.ClassifyStrategy == "" {
+		c.ClassifyStrategy = "page"
+	}
+}
+
+func (c *WorkflowConfig) loadEnv() {
+	if v := os.Getenv(EnvWorkflowPartialPages); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			c.PartialPages = b
+		}
+	}
+	if v := os.Getenv(EnvWorkflowEscalationAgent); v != "" {
+		c.EscalationAgent = v
+	}
+	if v := os.Getenv(EnvWorkflowEscalationThreshold); v != "" {
+		c.EscalationThreshold = v
+	}
+	if v := os.Getenv(EnvWorkflowTextFastPath); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			c.TextFastPath = b
+		}
+	}
+	if v := os.Getenv(EnvWorkflowClassifyStrategy); v != "" {
+		c.ClassifyStrategy = v
+	}
+}
+
+func (c *WorkflowConfig) validate() error {
+	c.EscalationThreshold = strings.ToUpper(c.EscalationThreshold)
+	switch c.EscalationThreshold {
+	case "HIGH", "MEDIUM", "LOW":
+	default:
+		return fmt.Errorf("invalid escalation_threshold %q: must be HIGH, MEDIUM, or LOW", c.EscalationThreshold)
+	}
+
+	c.ClassifyStrategy = strings.ToLower(c.ClassifyStrategy)
+	switch c.ClassifyStrategy {
+	case "page", "banners":
+	default:
+		return fmt.Errorf("invalid classify_strategy %q: must be page or banners", c.ClassifyStrategy)
+	}
+	return nil
+}
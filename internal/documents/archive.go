@@ -0,0 +1,389 @@
+package documents
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/JaimeStill/herald/pkg/handlers"
+)
+
+// maxArchiveEntries bounds the files read from one archive, so a hostile
+// archive cannot hold the request open indefinitely.
+const maxArchiveEntries = 1000
+
+// maxManifestSize bounds the bytes read from an archive manifest.
+const maxManifestSize = 4 << 20
+
+// UploadArchive processes a multipart form upload whose file field is a ZIP,
+// tar, or tar.gz archive, registering each supported file in it as its own
+// document. A manifest.csv or manifest.json inside the archive supplies each
+// file's external_id and external_platform; its filenames are relative to
+// the manifest's directory. The archive counts against the upload size
+// limit, as does each file within it. Responds with one BatchResult per
+// file, in archive order, followed by any manifest rows naming a file the
+// archive lacks. Directories, dotfiles, and __MACOSX metadata are skipped.
+func (h *Handler) UploadArchive(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxUploadSize)
+
+	archive, size, err := spoolArchive(r)
+	if err != nil {
+		handlers.RespondError(w, h.logger, MapHTTPStatus(err), err)
+		return
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	manifest, err := readManifest(archive, size)
+	if err != nil {
+		handlers.RespondError(w, h.logger, MapHTTPStatus(err), err)
+		return
+	}
+
+	results, err := h.registerArchive(r.Context(), archive, size, manifest)
+	if err != nil {
+		handlers.RespondError(w, h.logger, MapHTTPStatus(err), err)
+		return
+	}
+
+	handlers.RespondJSON(w, http.StatusOK, results)
+}
+
+// registerArchive registers each file of the archive with its manifest row
+// and reports the outcome of every file and of every row left unmatched.
+func (h *Handler) registerArchive(
+	ctx context.Context,
+	archive io.ReaderAt,
+	size int64,
+	manifest *archiveManifest,
+) ([]BatchResult, error) {
+	var results []BatchResult
+	matched := make(map[string]bool, len(manifest.entries))
+
+	err := walkArchive(archive, size, func(name string, r io.Reader) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if name == manifest.path {
+			return nil
+		}
+
+		result := BatchResult{Filename: name}
+		entry, ok := manifest.entries[name]
+		if !ok {
+			result.Error = fmt.Sprintf("%s: no manifest entry", ErrInvalidManifest)
+			results = append(results, result)
+			return nil
+		}
+		matched[name] = true
+
+		doc, err := h.createEntry(ctx, r, name, entry)
+		if err != nil {
+			h.logger.Warn("archive entry rejected", "filename", name, "error", err)
+			result.Error = err.Error()
+		}
+		result.Document = doc
+		results = append(results, result)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range manifest.order {
+		if !matched[name] {
+			results = append(results, BatchResult{
+				Filename: name,
+				Error:    fmt.Sprintf("%s: file not in archive", ErrInvalidManifest),
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// createEntry reads one archive file, rejecting it when it exceeds the
+// upload size limit, and registers it under its base name.
+func (h *Handler) createEntry(
+	ctx context.Context,
+	r io.Reader,
+	name string,
+	entry manifestEntry,
+) (*Document, error) {
+	data, err := io.ReadAll(io.LimitReader(r, h.maxUploadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	if int64(len(data)) > h.maxUploadSize {
+		return nil, ErrFileTooLarge
+	}
+
+	return h.create(ctx, data, path.Base(name), "", entry.ExternalID, entry.ExternalPlatform)
+}
+
+// spoolArchive copies the file part of a multipart request to a temporary
+// file, since ZIP archives are read from their end and the manifest may
+// follow the files it describes. The caller removes the file.
+func spoolArchive(r *http.Request) (*os.File, int64, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, 0, ErrInvalidFile
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			return nil, 0, uploadError(err)
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		f, err := os.CreateTemp("", "herald-archive-*")
+		if err != nil {
+			return nil, 0, fmt.Errorf("spool archive: %w", err)
+		}
+
+		size, err := io.Copy(f, part)
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+			return nil, 0, uploadError(err)
+		}
+		return f, size, nil
+	}
+}
+
+// uploadError maps a failure reading the request body to ErrFileTooLarge
+// when the size limit cut it off, and to ErrInvalidFile otherwise.
+func uploadError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return ErrFileTooLarge
+	}
+	return ErrInvalidFile
+}
+
+// archiveManifest holds the manifest rows of an archive, keyed by the
+// archive path of the file each names, and path, the manifest's own path.
+type archiveManifest struct {
+	path    string
+	entries map[string]manifestEntry
+	order   []string
+}
+
+// manifestEntry is one row of an archive manifest.
+type manifestEntry struct {
+	Filename         string `json:"filename"`
+	ExternalID       int    `json:"external_id"`
+	ExternalPlatform string `json:"external_platform"`
+}
+
+// readManifest finds the archive's single manifest.csv or manifest.json and
+// parses it.
+func readManifest(archive io.ReaderAt, size int64) (*archiveManifest, error) {
+	var manifestPath string
+	var data []byte
+
+	err := walkArchive(archive, size, func(name string, r io.Reader) error {
+		switch strings.ToLower(path.Base(name)) {
+		case "manifest.csv", "manifest.json":
+		default:
+			return nil
+		}
+		if manifestPath != "" {
+			return fmt.Errorf("%w: both %s and %s", ErrInvalidManifest, manifestPath, name)
+		}
+
+		var err error
+		manifestPath = name
+		data, err = io.ReadAll(io.LimitReader(r, maxManifestSize))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if manifestPath == "" {
+		return nil, fmt.Errorf("%w: archive has no manifest.csv or manifest.json", ErrInvalidManifest)
+	}
+
+	var rows []manifestEntry
+	if strings.EqualFold(path.Ext(manifestPath), ".json") {
+		rows, err = parseJSONManifest(data)
+	} else {
+		rows, err = parseCSVManifest(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &archiveManifest{
+		path:    manifestPath,
+		entries: make(map[string]manifestEntry, len(rows)),
+	}
+	for i, row := range rows {
+		if row.Filename == "" || row.ExternalPlatform == "" {
+			return nil, fmt.Errorf("%w: row %d needs filename and external_platform", ErrInvalidManifest, i+1)
+		}
+
+		name := entryName(path.Join(path.Dir(manifestPath), row.Filename))
+		if _, dup := manifest.entries[name]; dup {
+			return nil, fmt.Errorf("%w: %s listed twice", ErrInvalidManifest, row.Filename)
+		}
+		manifest.entries[name] = row
+		manifest.order = append(manifest.order, name)
+	}
+
+	return manifest, nil
+}
+
+// parseJSONManifest reads a JSON array of manifest rows.
+func parseJSONManifest(data []byte) ([]manifestEntry, error) {
+	var rows []manifestEntry
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+	return rows, nil
+}
+
+// parseCSVManifest reads a CSV manifest whose header row names the
+// filename, external_id, and external_platform columns, in any order.
+func parseCSVManifest(data []byte) ([]manifestEntry, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: empty manifest", ErrInvalidManifest)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range []string{"filename", "external_id", "external_platform"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing %s column", ErrInvalidManifest, name)
+		}
+	}
+
+	rows := make([]manifestEntry, 0, len(records)-1)
+	for i, record := range records[1:] {
+		id, err := strconv.Atoi(strings.TrimSpace(record[columns["external_id"]]))
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d external_id: %v", ErrInvalidManifest, i+1, err)
+		}
+		rows = append(rows, manifestEntry{
+			Filename:         strings.TrimSpace(record[columns["filename"]]),
+			ExternalID:       id,
+			ExternalPlatform: strings.TrimSpace(record[columns["external_platform"]]),
+		})
+	}
+	return rows, nil
+}
+
+// walkArchive calls fn with the normalized path and contents of each file
+// in a ZIP, tar, or tar.gz archive, in archive order, skipping directories,
+// dotfiles, and __MACOSX metadata. It stops at the first error fn returns.
+func walkArchive(archive io.ReaderAt, size int64, fn func(name string, r io.Reader) error) error {
+	var head [512]byte
+	n, _ := archive.ReadAt(head[:], 0)
+	sig := head[:n]
+
+	visit := countEntries(fn)
+	switch {
+	case bytes.HasPrefix(sig, []byte("PK\x03\x04")), bytes.HasPrefix(sig, []byte("PK\x05\x06")):
+		return walkZip(archive, size, visit)
+	case bytes.HasPrefix(sig, []byte("\x1f\x8b")):
+		gz, err := gzip.NewReader(io.NewSectionReader(archive, 0, size))
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		defer gz.Close()
+		return walkTar(tar.NewReader(gz), visit)
+	case n >= 262 && string(sig[257:262]) == "ustar":
+		return walkTar(tar.NewReader(io.NewSectionReader(archive, 0, size)), visit)
+	default:
+		return fmt.Errorf("%w: not a zip, tar, or tar.gz archive", ErrInvalidArchive)
+	}
+}
+
+func walkZip(archive io.ReaderAt, size int64, fn func(name string, r io.Reader) error) error {
+	zr, err := zip.NewReader(archive, size)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidArchive, f.Name, err)
+		}
+		err = fn(f.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkTar(tr *tar.Reader, fn func(name string, r io.Reader) error) error {
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		if !hdr.FileInfo().Mode().IsRegular() {
+			continue
+		}
+
+		if err := fn(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// countEntries wraps fn to normalize entry names, skip metadata files, and
+// fail once the archive holds more than maxArchiveEntries files.
+func countEntries(fn func(name string, r io.Reader) error) func(string, io.Reader) error {
+	count := 0
+	return func(name string, r io.Reader) error {
+		name = entryName(name)
+		base := path.Base(name)
+		if strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(base, ".") {
+			return nil
+		}
+
+		count++
+		if count > maxArchiveEntries {
+			return fmt.Errorf("%w: more than %d files", ErrInvalidArchive, maxArchiveEntries)
+		}
+		return fn(name, r)
+	}
+}
+
+// entryName normalizes an archive path to a clean, slash-separated path
+// relative to the archive root.
+func entryName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(name, "\\", "/")), "/")
+}
@@ -12,6 +12,8 @@ var (
 	ErrFileTooLarge           = errors.New("file exceeds maximum upload size")
 	ErrInvalidFile            = errors.New("invalid file")
 	ErrUnsupportedContentType = errors.New("unsupported content type")
+	ErrInvalidArchive         = errors.New("invalid archive")
+	ErrInvalidManifest        = errors.New("invalid archive manifest")
 )
 
 // MapHTTPStatus maps document domain errors to appropriate HTTP status codes.
@@ -25,7 +27,9 @@ func MapHTTPStatus(err error) int {
 		return http.StatusRequestEntityTooLarge
 	case
 		errors.Is(err, ErrInvalidFile),
-		errors.Is(err, ErrUnsupportedContentType):
+		errors.Is(err, ErrUnsupportedContentType),
+		errors.Is(err, ErrInvalidArchive),
+		errors.Is(err, ErrInvalidManifest):
 		return http.StatusBadRequest
 	default:
 		return http.StatusInternalServerError
@@ -2,18 +2,22 @@ package documents
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/JaimeStill/herald/internal/format"
+	"github.com/JaimeStill/herald/pkg/core"
 	"github.com/JaimeStill/herald/pkg/handlers"
 	"github.com/JaimeStill/herald/pkg/pagination"
 	"github.com/JaimeStill/herald/pkg/routes"
@@ -59,6 +63,8 @@ func (h *Handler) Routes() routes.Group {
 			{Method: "GET", Pattern: "", Handler: h.List},
 			{Method: "GET", Pattern: "/{id}", Handler: h.Find},
 			{Method: "POST", Pattern: "", Handler: h.Upload},
+			{Method: "POST", Pattern: "/archive", Handler: h.UploadArchive},
+			{Method: "POST", Pattern: "/batch", Handler: h.UploadBatch},
 			{Method: "POST", Pattern: "/search", Handler: h.Search},
 			{Method: "DELETE", Pattern: "/{id}", Handler: h.Delete},
 		},
@@ -116,7 +122,9 @@ func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
 }
 
 // Upload processes a multipart form upload containing a file and external system metadata.
-// Extracts PDF page count automatically for PDF files using pdfcpu.
+// Extracts the page count automatically for PDF files using pdfcpu and for
+// TIFF files from their frame count. Office documents are not converted at
+// upload, so see extractPageCount for the count they report.
 func (h *Handler) Upload(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseMultipartForm(h.maxUploadSize); err != nil {
 		handlers.RespondError(w, h.logger, http.StatusRequestEntityTooLarge, ErrFileTooLarge)
@@ -148,40 +156,112 @@ func (h *Handler) Upload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	contentType := detectContentType(header.Header.Get("Content-Type"), data)
-	if _, err := h.formats.Lookup(contentType); err != nil {
-		supported := strings.Join(h.formats.SupportedContentTypes(), ", ")
-		handlers.RespondError(
-			w, h.logger,
-			http.StatusBadRequest,
-			fmt.Errorf(
-				"%w: %s (supported: %s)",
-				ErrUnsupportedContentType,
-				contentType,
-				supported,
-			),
-		)
+	doc, err := h.create(
+		r.Context(),
+		data,
+		header.Filename,
+		header.Header.Get("Content-Type"),
+		externalID,
+		externalPlatform,
+	)
+	if err != nil {
+		handlers.RespondError(w, h.logger, MapHTTPStatus(err), err)
 		return
 	}
 
-	pageCount := extractPDFPageCount(h.logger, data, contentType)
+	handlers.RespondJSON(w, http.StatusCreated, doc)
+}
 
-	cmd := CreateCommand{
-		Data:             data,
-		Filename:         header.Filename,
-		ContentType:      contentType,
-		ExternalID:       externalID,
-		ExternalPlatform: externalPlatform,
-		PageCount:        pageCount,
+// UploadBatch processes a multipart form upload containing many file parts.
+// Each file takes the external_id and external_platform at its position
+// among the repeated form fields; a single external_platform applies to
+// every file. Files are validated and registered as Upload does, in
+// parallel with bounded concurrency sized by core.WorkerCount. The request
+// as a whole, and so each file, must fit within the upload size limit.
+// Responds with one BatchResult per file, in form order; a rejected file
+// does not fail the others.
+func (h *Handler) UploadBatch(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxUploadSize)
+	if err := r.ParseMultipartForm(h.maxUploadSize); err != nil {
+		err = uploadError(err)
+		handlers.RespondError(w, h.logger, MapHTTPStatus(err), err)
+		return
 	}
 
-	doc, err := h.sys.Create(r.Context(), cmd)
-	if err != nil {
-		handlers.RespondError(w, h.logger, MapHTTPStatus(err), err)
+	files := r.MultipartForm.File["file"]
+	ids := r.MultipartForm.Value["external_id"]
+	platforms := r.MultipartForm.Value["external_platform"]
+
+	if len(files) == 0 || len(ids) != len(files) {
+		handlers.RespondError(w, h.logger, http.StatusBadRequest, ErrInvalidFile)
+		return
+	}
+	if len(platforms) != len(files) && len(platforms) != 1 {
+		handlers.RespondError(w, h.logger, http.StatusBadRequest, ErrInvalidFile)
 		return
 	}
 
-	handlers.RespondJSON(w, http.StatusCreated, doc)
+	results := make([]BatchResult, len(files))
+
+	var g errgroup.Group
+	g.SetLimit(core.WorkerCount(len(files)))
+
+	for i, header := range files {
+		platform := platforms[0]
+		if len(platforms) > 1 {
+			platform = platforms[i]
+		}
+
+		g.Go(func() error {
+			results[i] = BatchResult{Filename: header.Filename}
+
+			doc, err := h.createPart(r.Context(), header, ids[i], platform)
+			if err != nil {
+				h.logger.Warn("batch file rejected", "filename", header.Filename, "error", err)
+				results[i].Error = err.Error()
+				return nil
+			}
+
+			results[i].Document = doc
+			return nil
+		})
+	}
+	g.Wait()
+
+	handlers.RespondJSON(w, http.StatusOK, results)
+}
+
+// createPart validates one file of a batch upload and its external metadata
+// and registers it.
+func (h *Handler) createPart(
+	ctx context.Context,
+	header *multipart.FileHeader,
+	externalID string,
+	externalPlatform string,
+) (*Document, error) {
+	id, err := strconv.Atoi(externalID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: external_id %q", ErrInvalidFile, externalID)
+	}
+	if externalPlatform == "" {
+		return nil, fmt.Errorf("%w: missing external_platform", ErrInvalidFile)
+	}
+	if header.Size > h.maxUploadSize {
+		return nil, ErrFileTooLarge
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	return h.create(ctx, data, header.Filename, header.Header.Get("Content-Type"), id, externalPlatform)
 }
 
 // Delete removes a document by its UUID path parameter.
@@ -200,24 +280,88 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// create resolves the content type of data from the declared contentType,
+// rejects it when no format handler is registered for it, and registers the
+// document with its page count.
+func (h *Handler) create(
+	ctx context.Context,
+	data []byte,
+	filename string,
+	contentType string,
+	externalID int,
+	externalPlatform string,
+) (*Document, error) {
+	contentType = detectContentType(contentType, data)
+	if _, err := h.formats.Lookup(contentType); err != nil {
+		return nil, fmt.Errorf(
+			"%w: %s (supported: %s)",
+			ErrUnsupportedContentType,
+			contentType,
+			strings.Join(h.formats.SupportedContentTypes(), ", "),
+		)
+	}
+
+	return h.sys.Create(ctx, CreateCommand{
+		Data:             data,
+		Filename:         filename,
+		ContentType:      contentType,
+		ExternalID:       externalID,
+		ExternalPlatform: externalPlatform,
+		PageCount:        extractPageCount(h.logger, data, contentType),
+	})
+}
+
+// detectContentType prefers the part's declared content type, sniffing the
+// data when it is absent or generic. http.DetectContentType does not know
+// TIFF, so its signatures are checked first, and reports Office documents as
+// plain ZIP archives, so their packages are inspected before it.
 func detectContentType(header string, data []byte) string {
 	header = strings.TrimSpace(header)
-	if header != "" && header != "application/octet-stream" {
+	if header != "" && header != "application/octet-stream" && header != "application/zip" {
 		return header
 	}
+	if bytes.HasPrefix(data, []byte("II*\x00")) || bytes.HasPrefix(data, []byte("MM\x00*")) {
+		return "image/tiff"
+	}
+	if ct := format.OfficeContentType(data); ct != "" {
+		return ct
+	}
 	return http.DetectContentType(data)
 }
 
-func extractPDFPageCount(logger *slog.Logger, data []byte, contentType string) *int {
-	if contentType != "application/pdf" {
+// extractPageCount counts the pages of multi-page formats: PDF pages, TIFF
+// frames, and the pages or slides a Word or PowerPoint document records.
+// Other formats, and documents whose count cannot be read, yield nil.
+//
+// Office counts are provisional. Uploads are not converted to PDF, so a Word
+// or PowerPoint count is the one the producing application last saved in
+// docProps/app.xml, and a workbook or a package without one yields nil. The
+// classification workflow replaces the count with the number of pages it
+// renders.
+func extractPageCount(logger *slog.Logger, data []byte, contentType string) *int {
+	switch contentType {
+	case "application/pdf":
+		count, err := api.PageCount(bytes.NewReader(data), nil)
+		if err != nil {
+			logger.Warn("failed to extract PDF page count", "error", err)
+			return nil
+		}
+		return &count
+	case "image/tiff":
+		count, err := format.TIFFFrameCount(data)
+		if err != nil {
+			logger.Warn("failed to extract TIFF page count", "error", err)
+			return nil
+		}
+		return &count
+	case format.ContentTypeDOCX, format.ContentTypePPTX:
+		count, err := format.OfficePageCount(data)
+		if err != nil {
+			logger.Debug("no Office page count recorded", "error", err)
+			return nil
+		}
+		return &count
+	default:
 		return nil
 	}
-
-	count, err := api.PageCount(bytes.NewReader(data), nil)
-	if err != nil {
-		logger.Warn("failed to extract PDF page count", "error", err)
-		return nil
-	}
-
-	return &count
 }
@@ -58,6 +58,12 @@ func (f Filters) Apply(b *query.Builder) *query.Builder {
 		WhereEquals("Confidence", f.Confidence)
 }
 
+// SelectIDs returns a query selecting the IDs of every document matching the
+// filters. Other domains use it to resolve document sets server-side.
+func (f Filters) SelectIDs() (string, []any) {
+	return f.Apply(query.NewBuilder(projection)).BuildSelect("ID")
+}
+
 // FiltersFromQuery extracts filter values from URL query parameters.
 func FiltersFromQuery(values url.Values) Filters {
 	var f Filters
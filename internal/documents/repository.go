@@ -162,6 +162,17 @@ func (r *repo) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+func (r *repo) UpdatePageCount(ctx context.Context, id uuid.UUID, count int) error {
+	if err := repository.ExecExpectOne(
+		ctx, r.db,
+		"UPDATE documents SET page_count = $2, updated_at = NOW() WHERE id = $1",
+		id, count,
+	); err != nil {
+		return repository.MapError(err, ErrNotFound, ErrDuplicate)
+	}
+	return nil
+}
+
 func buildStorageKey(id uuid.UUID, filename string) string {
 	return fmt.Sprintf("documents/%s/%s", id, filename)
 }
@@ -21,4 +21,8 @@ type System interface {
 	Find(ctx context.Context, id uuid.UUID) (*Document, error)
 	Create(ctx context.Context, cmd CreateCommand) (*Document, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+
+	// UpdatePageCount records the page count of a document's rendered pages,
+	// replacing the count read at upload.
+	UpdatePageCount(ctx context.Context, id uuid.UUID, count int) error
 }
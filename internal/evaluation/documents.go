@@ -0,0 +1,69 @@
+package evaluation
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/JaimeStill/herald/internal/documents"
+	"github.com/JaimeStill/herald/pkg/pagination"
+)
+
+// documentStore is an in-memory documents.System holding the corpus files of
+// a run, so evaluations need no database. The workflow uses only Find and
+// UpdatePageCount.
+type documentStore struct {
+	mu   sync.RWMutex
+	docs map[uuid.UUID]documents.Document
+}
+
+func newDocumentStore() *documentStore {
+	return &documentStore{docs: make(map[uuid.UUID]documents.Document)}
+}
+
+func (s *documentStore) add(doc documents.Document) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.docs[doc.ID] = doc
+}
+
+func (s *documentStore) Handler(int64) *documents.Handler { return nil }
+
+func (s *documentStore) List(
+	context.Context,
+	pagination.PageRequest,
+	documents.Filters,
+) (*pagination.PageResult[documents.Document], error) {
+	return nil, errReadOnly
+}
+
+func (s *documentStore) Find(_ context.Context, id uuid.UUID) (*documents.Document, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	doc, ok := s.docs[id]
+	if !ok {
+		return nil, documents.ErrNotFound
+	}
+	return &doc, nil
+}
+
+func (s *documentStore) Create(context.Context, documents.CreateCommand) (*documents.Document, error) {
+	return nil, errReadOnly
+}
+
+func (s *documentStore) Delete(context.Context, uuid.UUID) error { return errReadOnly }
+
+func (s *documentStore) UpdatePageCount(_ context.Context, id uuid.UUID, count int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, ok := s.docs[id]
+	if !ok {
+		return documents.ErrNotFound
+	}
+	doc.PageCount = &count
+	s.docs[id] = doc
+	return nil
+}
@@ -0,0 +1,13 @@
+package evaluation
+
+import "errors"
+
+// Sentinel errors for evaluation runs.
+var (
+	ErrInvalidManifest  = errors.New("invalid evaluation manifest")
+	ErrInvalidPromptSet = errors.New("invalid prompt set")
+)
+
+// errReadOnly is returned by the write operations of the in-memory prompt and
+// document stores an evaluation runs against.
+var errReadOnly = errors.New("evaluation stores are read-only")
@@ -0,0 +1,197 @@
+// Package evaluation measures Herald's classification accuracy against a
+// labeled corpus. It runs the full classification workflow over every
+// document in a Manifest, without a database, and scores each result
+// against its expected marking to produce a Report.
+package evaluation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/JaimeStill/herald/internal/agents"
+	"github.com/JaimeStill/herald/internal/documents"
+	"github.com/JaimeStill/herald/internal/format"
+	"github.com/JaimeStill/herald/internal/markings"
+	"github.com/JaimeStill/herald/internal/workflow"
+	"github.com/JaimeStill/herald/pkg/storage"
+)
+
+// Options configures a run. Storage holds the corpus files while the run
+// executes; a scratch filesystem store is sufficient. PromptSet defaults to
+// the built-in prompts.
+type Options struct {
+	NewAgent  agents.Factory
+	PromptSet *PromptSet
+	Markings  *markings.Parser
+	Formats   *format.Registry
+	Storage   storage.System
+	Logger    *slog.Logger
+}
+
+// Run classifies every document in m and scores the results. Documents are
+// processed one at a time so per-document latency and token usage are
+// attributed exactly. A document whose workflow fails is recorded in the
+// report rather than aborting the run; Run itself fails only when the run
+// cannot be set up.
+func Run(ctx context.Context, opts Options, m *Manifest, manifestPath string) (*Report, error) {
+	if err := m.Validate(opts.Markings); err != nil {
+		return nil, err
+	}
+
+	promptSet := opts.PromptSet
+	if promptSet == nil {
+		promptSet = DefaultPromptSet()
+	}
+	promptSystem := promptSet.System()
+
+	versions, err := promptSystem.Versions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("resolve prompt versions: %w", err)
+	}
+
+	meter := &Meter{}
+	newAgent := meter.Wrap(opts.NewAgent)
+
+	probe, err := opts.NewAgent(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create agent: %w", err)
+	}
+
+	docs := newDocumentStore()
+	rt := &workflow.Runtime{
+		NewAgent:  newAgent,
+		Model:     probe.Model(),
+		Provider:  probe.Provider(),
+		Storage:   opts.Storage,
+		Documents: docs,
+		Prompts:   promptSystem,
+		Formats:   opts.Formats,
+		Markings:  opts.Markings,
+		Logger:    opts.Logger,
+	}
+
+	report := &Report{
+		GeneratedAt: time.Now(),
+		Manifest:    manifestPath,
+		Model:       rt.Model,
+		Provider:    rt.Provider,
+		PromptSet:   promptSet.Name,
+		Prompts:     versions,
+		Documents:   make([]DocumentResult, 0, len(m.Documents)),
+	}
+
+	for _, e := range m.Documents {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		result := evaluate(ctx, rt, docs, opts.Markings, m, e)
+		result.Calls, result.Usage = meter.Take()
+
+		opts.Logger.InfoContext(
+			ctx, "evaluated document",
+			"file", e.File,
+			"classification", result.Classification,
+			"correct", result.Score.Correct,
+			"latency_ms", result.LatencyMS,
+			"error", result.Error,
+		)
+
+		report.Documents = append(report.Documents, result)
+	}
+
+	report.summarize(opts.Markings.Levels())
+	return report, nil
+}
+
+func evaluate(
+	ctx context.Context,
+	rt *workflow.Runtime,
+	docs *documentStore,
+	p *markings.Parser,
+	m *Manifest,
+	e Expectation,
+) DocumentResult {
+	want, _ := p.Parse(e.Classification)
+	result := DocumentResult{
+		File:          e.File,
+		Expected:      want.Canonical,
+		ExpectedLevel: want.Level,
+	}
+
+	id, err := stage(ctx, rt.Storage, docs, m.Path(e), e.ContentType)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer rt.Storage.Delete(context.WithoutCancel(ctx), storageKey(id, m.Path(e)))
+
+	observer := workflow.NewStreamingObserver(64, rt.Logger)
+	defer observer.Close()
+
+	start := time.Now()
+	wr, err := workflow.Execute(ctx, rt, uuid.New(), id, observer)
+	result.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	cs := wr.State
+	result.Classification = cs.Classification
+	result.Confidence = cs.Confidence
+	result.RollUp = cs.RollUp
+	result.Discrepancy = cs.Discrepancy
+	result.Pages = wr.PageCount
+	result.Score = ScoreClassification(p, want, cs.Classification)
+	return result
+}
+
+// stage uploads the file at path and registers it as a document.
+func stage(
+	ctx context.Context,
+	store storage.System,
+	docs *documentStore,
+	path, contentType string,
+) (uuid.UUID, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("open document: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("stat document: %w", err)
+	}
+
+	id := uuid.New()
+	key := storageKey(id, path)
+	if err := store.Upload(ctx, key, f, contentType); err != nil {
+		return uuid.Nil, fmt.Errorf("upload document: %w", err)
+	}
+
+	docs.add(documents.Document{
+		ID:               id,
+		ExternalPlatform: "evaluation",
+		Filename:         filepath.Base(path),
+		ContentType:      contentType,
+		SizeBytes:        info.Size(),
+		StorageKey:       key,
+		Status:           "pending",
+		UploadedAt:       time.Now(),
+		UpdatedAt:        time.Now(),
+	})
+
+	return id, nil
+}
+
+func storageKey(id uuid.UUID, path string) string {
+	return fmt.Sprintf("documents/%s/%s", id, filepath.Base(path))
+}
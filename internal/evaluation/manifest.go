@@ -0,0 +1,90 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/JaimeStill/herald/internal/markings"
+)
+
+// Expectation is the labeled classification of one corpus document. File is
+// relative to the manifest. ContentType is inferred from the file extension
+// when empty.
+type Expectation struct {
+	File           string `json:"file"`
+	Classification string `json:"classification"`
+	ContentType    string `json:"content_type,omitempty"`
+}
+
+// Manifest lists the documents an evaluation runs over and the classification
+// each is expected to receive.
+type Manifest struct {
+	Documents []Expectation `json:"documents"`
+
+	dir string
+}
+
+// LoadManifest reads the manifest at path and checks that every entry names
+// an existing file and an expected classification.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+	}
+	m.dir = filepath.Dir(path)
+
+	if len(m.Documents) == 0 {
+		return nil, fmt.Errorf("%w: no documents", ErrInvalidManifest)
+	}
+
+	for i, e := range m.Documents {
+		if e.File == "" {
+			return nil, fmt.Errorf("%w: document %d: file required", ErrInvalidManifest, i)
+		}
+		if strings.TrimSpace(e.Classification) == "" {
+			return nil, fmt.Errorf("%w: %s: classification required", ErrInvalidManifest, e.File)
+		}
+		if _, err := os.Stat(m.Path(e)); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidManifest, e.File, err)
+		}
+		if m.Documents[i].ContentType == "" {
+			m.Documents[i].ContentType = contentType(e.File)
+		}
+	}
+
+	return &m, nil
+}
+
+// Path returns the location of e's file on disk.
+func (m *Manifest) Path(e Expectation) string {
+	if filepath.IsAbs(e.File) {
+		return e.File
+	}
+	return filepath.Join(m.dir, e.File)
+}
+
+// Validate checks that every expected classification parses against p, so a
+// typo in the manifest is reported up front rather than scored as a miss.
+func (m *Manifest) Validate(p *markings.Parser) error {
+	for _, e := range m.Documents {
+		if _, err := p.Parse(e.Classification); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidManifest, e.File, err)
+		}
+	}
+	return nil
+}
+
+func contentType(file string) string {
+	ct := mime.TypeByExtension(strings.ToLower(filepath.Ext(file)))
+	ct, _, _ = strings.Cut(ct, ";")
+	return ct
+}
@@ -0,0 +1,68 @@
+package evaluation
+
+import (
+	"context"
+	"sync"
+
+	"github.com/JaimeStill/herald/internal/agents"
+)
+
+// Meter tallies the model calls and token usage of every agent created by the
+// factories it wraps. Take returns the totals since the previous Take, so a
+// run can attribute usage to each document in turn.
+type Meter struct {
+	mu    sync.Mutex
+	calls int
+	usage agents.Usage
+}
+
+// Wrap returns a factory whose agents report each completed call to m.
+func (m *Meter) Wrap(newAgent agents.Factory) agents.Factory {
+	return func(ctx context.Context) (agents.Agent, error) {
+		a, err := newAgent(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &meteredAgent{Agent: a, meter: m}, nil
+	}
+}
+
+// Take returns the calls and usage recorded since the last Take and resets
+// the tallies.
+func (m *Meter) Take() (int, agents.Usage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	calls, usage := m.calls, m.usage
+	m.calls, m.usage = 0, agents.Usage{}
+	return calls, usage
+}
+
+func (m *Meter) record(usage agents.Usage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls++
+	m.usage.Add(usage)
+}
+
+type meteredAgent struct {
+	agents.Agent
+	meter *Meter
+}
+
+func (a *meteredAgent) Chat(ctx context.Context, prompt string) (agents.Response, error) {
+	resp, err := a.Agent.Chat(ctx, prompt)
+	if err == nil {
+		a.meter.record(resp.Usage)
+	}
+	return resp, err
+}
+
+func (a *meteredAgent) Vision(ctx context.Context, prompt string, image []byte) (agents.Response, error) {
+	resp, err := a.Agent.Vision(ctx, prompt, image)
+	if err == nil {
+		a.meter.record(resp.Usage)
+	}
+	return resp, err
+}
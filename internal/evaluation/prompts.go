@@ -0,0 +1,127 @@
+package evaluation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/google/uuid"
+
+	"github.com/JaimeStill/herald/internal/prompts"
+	"github.com/JaimeStill/herald/pkg/pagination"
+)
+
+// PromptSet is a named set of instruction overrides evaluated in place of the
+// prompts stored in the database. Stages without an override use the
+// built-in instructions; specifications are never overridden.
+type PromptSet struct {
+	Name         string                   `json:"name"`
+	Instructions map[prompts.Stage]string `json:"instructions"`
+}
+
+// DefaultPromptSet evaluates the built-in instructions for every stage.
+func DefaultPromptSet() *PromptSet {
+	return &PromptSet{Name: "default"}
+}
+
+// LoadPromptSet reads a prompt set from a JSON file.
+func LoadPromptSet(path string) (*PromptSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read prompt set: %w", err)
+	}
+
+	var ps PromptSet
+	if err := json.Unmarshal(data, &ps); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPromptSet, err)
+	}
+	if ps.Name == "" {
+		return nil, fmt.Errorf("%w: name required", ErrInvalidPromptSet)
+	}
+	for stage := range ps.Instructions {
+		if _, err := prompts.ParseStage(string(stage)); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidPromptSet, err)
+		}
+	}
+	return &ps, nil
+}
+
+// System adapts the set to prompts.System so the workflow composes its
+// prompts from it. Only the read operations the workflow uses are supported.
+func (ps *PromptSet) System() prompts.System {
+	return &promptSetSystem{set: ps}
+}
+
+type promptSetSystem struct {
+	set *PromptSet
+}
+
+func (s *promptSetSystem) Handler() *prompts.Handler { return nil }
+
+func (s *promptSetSystem) List(
+	context.Context,
+	pagination.PageRequest,
+	prompts.Filters,
+) (*pagination.PageResult[prompts.Prompt], error) {
+	return nil, errReadOnly
+}
+
+func (s *promptSetSystem) Find(context.Context, uuid.UUID) (*prompts.Prompt, error) {
+	return nil, errReadOnly
+}
+
+func (s *promptSetSystem) Instructions(_ context.Context, stage prompts.Stage) (string, error) {
+	if text, ok := s.set.Instructions[stage]; ok {
+		return text, nil
+	}
+	return prompts.Instructions(stage)
+}
+
+func (s *promptSetSystem) Spec(_ context.Context, stage prompts.Stage) (string, error) {
+	return prompts.Spec(stage)
+}
+
+// Versions reports each stage's digest, naming the set for stages it
+// overrides so reports record exactly which prompts were evaluated.
+func (s *promptSetSystem) Versions(ctx context.Context) ([]prompts.Version, error) {
+	var versions []prompts.Version
+	for _, stage := range prompts.Stages() {
+		instructions, err := s.Instructions(ctx, stage)
+		if err != nil {
+			return nil, err
+		}
+		spec, err := s.Spec(ctx, stage)
+		if err != nil {
+			return nil, err
+		}
+
+		var override *prompts.Prompt
+		if _, ok := s.set.Instructions[stage]; ok {
+			override = &prompts.Prompt{Name: s.set.Name, Stage: stage}
+		}
+
+		v := prompts.NewVersion(stage, override, instructions, spec)
+		v.PromptID = nil
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+func (s *promptSetSystem) Create(context.Context, prompts.CreateCommand) (*prompts.Prompt, error) {
+	return nil, errReadOnly
+}
+
+func (s *promptSetSystem) Update(context.Context, uuid.UUID, prompts.UpdateCommand) (*prompts.Prompt, error) {
+	return nil, errReadOnly
+}
+
+func (s *promptSetSystem) Delete(context.Context, uuid.UUID) error { return errReadOnly }
+
+func (s *promptSetSystem) Activate(context.Context, uuid.UUID) (*prompts.Prompt, error) {
+	return nil, errReadOnly
+}
+
+func (s *promptSetSystem) Deactivate(context.Context, uuid.UUID) (*prompts.Prompt, error) {
+	return nil, errReadOnly
+}
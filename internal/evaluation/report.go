@@ -0,0 +1,231 @@
+package evaluation
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/JaimeStill/herald/internal/agents"
+	"github.com/JaimeStill/herald/internal/prompts"
+	"github.com/JaimeStill/herald/internal/state"
+)
+
+// Confusion matrix columns for documents without a usable level.
+const (
+	ColumnUnparsed = "UNPARSED"
+	ColumnFailed   = "FAILED"
+)
+
+// Report is the outcome of an evaluation run.
+type Report struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	Manifest    string            `json:"manifest"`
+	Model       string            `json:"model"`
+	Provider    string            `json:"provider"`
+	PromptSet   string            `json:"prompt_set"`
+	Prompts     []prompts.Version `json:"prompts"`
+	Summary     Summary           `json:"summary"`
+	Confusion   Confusion         `json:"confusion"`
+	Documents   []DocumentResult  `json:"documents"`
+}
+
+// DocumentResult records one document's run. Error is set, and the
+// classification fields are empty, when the workflow failed.
+type DocumentResult struct {
+	File           string           `json:"file"`
+	Expected       string           `json:"expected"`
+	ExpectedLevel  string           `json:"expected_level"`
+	Classification string           `json:"classification,omitempty"`
+	Confidence     state.Confidence `json:"confidence,omitempty"`
+	RollUp         string           `json:"roll_up,omitempty"`
+	Discrepancy    string           `json:"discrepancy,omitempty"`
+	Pages          int              `json:"pages"`
+	Score          Score            `json:"score"`
+	Calls          int              `json:"calls"`
+	Usage          agents.Usage     `json:"usage"`
+	LatencyMS      int64            `json:"latency_ms"`
+	Error          string           `json:"error,omitempty"`
+}
+
+// Summary aggregates a run. Caveat precision and recall are micro-averaged
+// over every caveat in the corpus and are nil when undefined: precision when
+// no caveats were reported, recall when none were expected.
+type Summary struct {
+	Documents       int          `json:"documents"`
+	Correct         int          `json:"correct"`
+	Failed          int          `json:"failed"`
+	Accuracy        float64      `json:"accuracy"`
+	LevelAccuracy   float64      `json:"level_accuracy"`
+	CaveatPrecision *float64     `json:"caveat_precision"`
+	CaveatRecall    *float64     `json:"caveat_recall"`
+	Calls           int          `json:"calls"`
+	Usage           agents.Usage `json:"usage"`
+	TotalLatencyMS  int64        `json:"total_latency_ms"`
+	MeanLatencyMS   int64        `json:"mean_latency_ms"`
+	MaxLatencyMS    int64        `json:"max_latency_ms"`
+}
+
+// Confusion counts documents by expected level (rows) and classified level
+// (columns). Columns are the taxonomy levels followed by ColumnUnparsed and
+// ColumnFailed.
+type Confusion struct {
+	Levels  []string `json:"levels"`
+	Columns []string `json:"columns"`
+	Counts  [][]int  `json:"counts"`
+}
+
+func newConfusion(levels []string) Confusion {
+	c := Confusion{
+		Levels:  levels,
+		Columns: append(slices.Clone(levels), ColumnUnparsed, ColumnFailed),
+		Counts:  make([][]int, len(levels)),
+	}
+	for i := range c.Counts {
+		c.Counts[i] = make([]int, len(c.Columns))
+	}
+	return c
+}
+
+func (c *Confusion) add(expected, column string) {
+	row := slices.Index(c.Levels, expected)
+	col := slices.Index(c.Columns, column)
+	if row >= 0 && col >= 0 {
+		c.Counts[row][col]++
+	}
+}
+
+// summarize computes the summary and confusion matrix from r.Documents.
+func (r *Report) summarize(levels []string) {
+	s := Summary{Documents: len(r.Documents)}
+	r.Confusion = newConfusion(levels)
+
+	var matched, missing, extra, levelCorrect int
+	for _, d := range r.Documents {
+		s.Calls += d.Calls
+		s.Usage.Add(d.Usage)
+		s.TotalLatencyMS += d.LatencyMS
+		s.MaxLatencyMS = max(s.MaxLatencyMS, d.LatencyMS)
+
+		switch {
+		case d.Error != "":
+			s.Failed++
+			r.Confusion.add(d.ExpectedLevel, ColumnFailed)
+			continue
+		case d.Score.Level == "":
+			r.Confusion.add(d.ExpectedLevel, ColumnUnparsed)
+		default:
+			r.Confusion.add(d.ExpectedLevel, d.Score.Level)
+		}
+
+		if d.Score.Correct {
+			s.Correct++
+		}
+		if d.Score.Level == d.ExpectedLevel {
+			levelCorrect++
+		}
+		matched += len(d.Score.Matched)
+		missing += len(d.Score.Missing)
+		extra += len(d.Score.Extra)
+	}
+
+	if s.Documents > 0 {
+		s.Accuracy = float64(s.Correct) / float64(s.Documents)
+		s.LevelAccuracy = float64(levelCorrect) / float64(s.Documents)
+		s.MeanLatencyMS = s.TotalLatencyMS / int64(s.Documents)
+	}
+	s.CaveatPrecision = ratio(matched, matched+extra)
+	s.CaveatRecall = ratio(matched, matched+missing)
+
+	r.Summary = s
+}
+
+func ratio(n, d int) *float64 {
+	if d == 0 {
+		return nil
+	}
+	v := float64(n) / float64(d)
+	return &v
+}
+
+// Markdown renders the report as a Markdown document.
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	s := r.Summary
+
+	fmt.Fprintf(&b, "# Herald Evaluation\n\n")
+	fmt.Fprintf(&b, "| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Generated | %s |\n", r.GeneratedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "| Manifest | `%s` |\n", r.Manifest)
+	fmt.Fprintf(&b, "| Model | %s (%s) |\n", r.Model, r.Provider)
+	fmt.Fprintf(&b, "| Prompt set | %s |\n", r.PromptSet)
+	fmt.Fprintf(&b, "| Accuracy | %d / %d (%s) |\n", s.Correct, s.Documents, percent(&s.Accuracy))
+	fmt.Fprintf(&b, "| Level accuracy | %s |\n", percent(&s.LevelAccuracy))
+	fmt.Fprintf(&b, "| Failed | %d |\n", s.Failed)
+	fmt.Fprintf(&b, "| Caveat precision | %s |\n", percent(s.CaveatPrecision))
+	fmt.Fprintf(&b, "| Caveat recall | %s |\n", percent(s.CaveatRecall))
+	fmt.Fprintf(&b, "| Model calls | %d |\n", s.Calls)
+	fmt.Fprintf(&b, "| Tokens | %d prompt / %d completion |\n", s.Usage.PromptTokens, s.Usage.CompletionTokens)
+	fmt.Fprintf(&b, "| Latency | %d ms total, %d ms mean, %d ms max |\n", s.TotalLatencyMS, s.MeanLatencyMS, s.MaxLatencyMS)
+
+	fmt.Fprintf(&b, "\n## Prompts\n\n| Stage | Source | Digest |\n|---|---|---|\n")
+	for _, v := range r.Prompts {
+		source := "built-in"
+		if v.Name != nil {
+			source = *v.Name
+		}
+		fmt.Fprintf(&b, "| %s | %s | `%.12s` |\n", v.Stage, source, v.Digest)
+	}
+
+	fmt.Fprintf(&b, "\n## Confusion Matrix\n\nRows are expected levels; columns are classified levels.\n\n")
+	fmt.Fprintf(&b, "| Expected | %s |\n", strings.Join(r.Confusion.Columns, " | "))
+	fmt.Fprintf(&b, "|---|%s\n", strings.Repeat("---|", len(r.Confusion.Columns)))
+	for i, level := range r.Confusion.Levels {
+		cells := make([]string, len(r.Confusion.Counts[i]))
+		for j, n := range r.Confusion.Counts[i] {
+			cells[j] = fmt.Sprint(n)
+		}
+		fmt.Fprintf(&b, "| %s | %s |\n", level, strings.Join(cells, " | "))
+	}
+
+	fmt.Fprintf(&b, "\n## Documents\n\n")
+	fmt.Fprintf(&b, "| File | Expected | Classification | Confidence | Result | Calls | Tokens | Latency |\n")
+	fmt.Fprintf(&b, "|---|---|---|---|---|---|---|---|\n")
+	for _, d := range r.Documents {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %d | %d | %d ms |\n",
+			d.File, d.Expected, orDash(d.Classification), orDash(string(d.Confidence)),
+			result(d), d.Calls, d.Usage.Total(), d.LatencyMS,
+		)
+	}
+
+	return b.String()
+}
+
+func result(d DocumentResult) string {
+	switch {
+	case d.Error != "":
+		return "error: " + escape(d.Error)
+	case d.Score.Correct:
+		return "correct"
+	default:
+		return escape(strings.Join(d.Score.Differences, "; "))
+	}
+}
+
+func percent(v *float64) string {
+	if v == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f%%", *v*100)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func escape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,71 @@
+package evaluation
+
+import (
+	"slices"
+
+	"github.com/JaimeStill/herald/internal/markings"
+)
+
+// Score compares one document's classification with its expectation.
+// Caveats are the SCI, SAP, and dissemination controls: Matched lists those
+// both carry, Missing those only the expectation carries, and Extra those only
+// the classification carries. Level is empty and Differences holds the parse
+// error when the classification does not parse.
+type Score struct {
+	Correct     bool     `json:"correct"`
+	Level       string   `json:"level"`
+	Differences []string `json:"differences,omitempty"`
+	Matched     []string `json:"caveats_matched"`
+	Missing     []string `json:"caveats_missing"`
+	Extra       []string `json:"caveats_extra"`
+}
+
+// ScoreClassification scores got against the expected marking. The
+// declassification instruction is compared only when the expectation states
+// one, so manifests may omit dates the labeler could not confirm.
+func ScoreClassification(p *markings.Parser, want markings.Marking, got string) Score {
+	s := Score{
+		Matched: []string{},
+		Missing: []string{},
+		Extra:   []string{},
+	}
+
+	answer, err := p.Parse(got)
+	if err != nil {
+		s.Differences = []string{err.Error()}
+		s.Missing = caveats(want)
+		return s
+	}
+	s.Level = answer.Level
+
+	compared := answer
+	if want.Declassification == "" {
+		compared.Declassification = ""
+	}
+	s.Differences = markings.Differences(want, compared)
+	if want.Declassification != "" && answer.Declassification == "" {
+		s.Differences = append(s.Differences, "declassification: expected "+want.Declassification+", got none")
+	}
+	s.Correct = len(s.Differences) == 0
+
+	expected := caveats(want)
+	actual := caveats(answer)
+	for _, c := range expected {
+		if slices.Contains(actual, c) {
+			s.Matched = append(s.Matched, c)
+		} else {
+			s.Missing = append(s.Missing, c)
+		}
+	}
+	for _, c := range actual {
+		if !slices.Contains(expected, c) {
+			s.Extra = append(s.Extra, c)
+		}
+	}
+
+	return s
+}
+
+func caveats(m markings.Marking) []string {
+	return slices.Concat(m.SCI, m.SAP, m.Dissemination)
+}
@@ -0,0 +1,180 @@
+package format
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/draw"
+
+	"github.com/JaimeStill/herald/internal/state"
+)
+
+// ruleHeight and ruleGray size and color the rule bands separates the header
+// and footer with, matching magick's `-background gray50 -splice 0x12`.
+const (
+	ruleHeight = 12
+	ruleGray   = 0x7f
+)
+
+// resize scales img by percent.
+func resize(img *image.RGBA, percent int) *image.RGBA {
+	w := max(1, int(math.Round(float64(img.Bounds().Dx()*percent)/100)))
+	h := max(1, int(math.Round(float64(img.Bounds().Dy()*percent)/100)))
+
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(out, out.Bounds(), img, img.Bounds(), draw.Src, nil)
+	return out
+}
+
+// enhance applies settings to img in place, with the formulas of magick's
+// -brightness-contrast and -modulate operators.
+func enhance(img *image.RGBA, settings *state.EnhanceSettings) {
+	if settings.Brightness != nil || settings.Contrast != nil {
+		var brightness, contrast float64
+		if settings.Brightness != nil {
+			brightness = float64(*settings.Brightness)
+		}
+		if settings.Contrast != nil {
+			contrast = float64(*settings.Contrast)
+		}
+		brightnessContrast(img, brightness, contrast)
+	}
+	if settings.Saturation != nil {
+		saturate(img, float64(*settings.Saturation)/100)
+	}
+}
+
+// brightnessContrast maps each channel through the line magick's
+// -brightness-contrast derives from its percentages: contrast sets the
+// slope as tan(π(contrast/100+1)/4), and brightness the intercept.
+func brightnessContrast(img *image.RGBA, brightness, contrast float64) {
+	slope := max(0, math.Tan(math.Pi*(contrast/100+1)/4))
+	intercept := brightness/100 + ((100-brightness)/200)*(1-slope)
+
+	var lut [256]uint8
+	for i := range lut {
+		lut[i] = level(slope*float64(i)/255 + intercept)
+	}
+
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i] = lut[img.Pix[i]]
+		img.Pix[i+1] = lut[img.Pix[i+1]]
+		img.Pix[i+2] = lut[img.Pix[i+2]]
+	}
+}
+
+// saturate scales each pixel's HSL saturation by factor, as magick's
+// `-modulate 100,S,100` does with factor S/100.
+func saturate(img *image.RGBA, factor float64) {
+	for i := 0; i < len(img.Pix); i += 4 {
+		p := img.Pix[i : i+3 : i+3]
+		if p[0] == p[1] && p[1] == p[2] {
+			continue
+		}
+		h, s, l := toHSL(float64(p[0])/255, float64(p[1])/255, float64(p[2])/255)
+		r, g, b := fromHSL(h, s*factor, l)
+		p[0], p[1], p[2] = level(r), level(g), level(b)
+	}
+}
+
+// level converts a channel in [0, 1], clamping it first, to 8 bits.
+func level(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+}
+
+// toHSL converts RGB in [0, 1] to hue in [0, 1), saturation, and lightness.
+func toHSL(r, g, b float64) (h, s, l float64) {
+	hi := max(r, g, b)
+	lo := min(r, g, b)
+	c := hi - lo
+	l = (hi + lo) / 2
+	if c <= 0 {
+		return 0, 0, l
+	}
+
+	switch hi {
+	case r:
+		h = (g - b) / c
+		if g < b {
+			h += 6
+		}
+	case g:
+		h = 2 + (b-r)/c
+	default:
+		h = 4 + (r-g)/c
+	}
+	h /= 6
+
+	if l <= 0.5 {
+		s = c / (2 * l)
+	} else {
+		s = c / (2 - 2*l)
+	}
+	return h, s, l
+}
+
+// fromHSL converts hue, saturation, and lightness back to RGB. Saturation
+// above 1 yields channels outside [0, 1], which the caller clamps.
+func fromHSL(h, s, l float64) (r, g, b float64) {
+	var c float64
+	if l <= 0.5 {
+		c = 2 * l * s
+	} else {
+		c = (2 - 2*l) * s
+	}
+	lo := l - c/2
+
+	h = math.Mod(h*6, 6)
+	x := c * (1 - math.Abs(math.Mod(h, 2)-1))
+
+	switch int(h) {
+	case 0:
+		return lo + c, lo + x, lo
+	case 1:
+		return lo + x, lo + c, lo
+	case 2:
+		return lo, lo + c, lo + x
+	case 3:
+		return lo, lo + x, lo + c
+	case 4:
+		return lo + x, lo, lo + c
+	default:
+		return lo + c, lo, lo + x
+	}
+}
+
+// bands keeps the header and footer bands of img, each height of it tall,
+// stacked top to bottom with a gray rule between them.
+func bands(img *image.RGBA, height float64) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	band := min(h, max(1, int(math.Round(float64(h)*height))))
+
+	out := image.NewRGBA(image.Rect(0, 0, w, 2*band+ruleHeight))
+	draw.Draw(out, image.Rect(0, 0, w, band), img, img.Bounds().Min, draw.Src)
+
+	rule := image.Rect(0, band, w, band+ruleHeight)
+	draw.Draw(out, rule, image.NewUniform(color.Gray{Y: ruleGray}), image.Point{}, draw.Src)
+
+	footer := image.Rect(0, band+ruleHeight, w, out.Bounds().Dy())
+	draw.Draw(out, footer, img, image.Pt(img.Bounds().Min.X, img.Bounds().Max.Y-band), draw.Src)
+	return out
+}
+
+// grayscale returns img as an 8-bit gray image when every pixel is an opaque
+// gray, which scanned pages usually are, so the PNG stays small. Otherwise
+// it returns img.
+func grayscale(img *image.RGBA) image.Image {
+	for i := 0; i < len(img.Pix); i += 4 {
+		p := img.Pix[i : i+4 : i+4]
+		if p[0] != p[1] || p[1] != p[2] || p[3] != 0xff {
+			return img
+		}
+	}
+
+	out := image.NewGray(img.Bounds())
+	for i := range len(out.Pix) {
+		out.Pix[i] = img.Pix[i*4]
+	}
+	return out
+}
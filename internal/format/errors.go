@@ -6,3 +6,20 @@ import "errors"
 // higher layers such as documents.ErrUnsupportedContentType) when a content
 // type has no registered handler. Use errors.Is to detect it.
 var ErrUnsupportedFormat = errors.New("unsupported document format")
+
+// ErrUnsupportedPage is returned by a Renderer that cannot rasterize a page
+// faithfully, such as the native renderer given a page that draws text or
+// vector graphics. The auto renderer falls back to ImageMagick on it.
+var ErrUnsupportedPage = errors.New("page not supported by renderer")
+
+// ErrUnknownRenderer is returned by NewRenderer for a backend name it does
+// not recognize.
+var ErrUnknownRenderer = errors.New("unknown renderer")
+
+// ErrInvalidTIFF is returned when a TIFF's header or directory chain cannot
+// be read.
+var ErrInvalidTIFF = errors.New("invalid tiff")
+
+// ErrInvalidOffice is returned when an OOXML package, or a part it must
+// carry, cannot be read.
+var ErrInvalidOffice = errors.New("invalid office document")
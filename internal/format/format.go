@@ -50,6 +50,72 @@ type Handler interface {
 		page *state.ClassificationPage,
 		settings *state.EnhanceSettings,
 	) (string, error)
+
+	// Restore rebuilds the prior-pass artifacts of an earlier Extract in a
+	// fresh tempDir, for a run resuming after its original temp directory
+	// was lost. It stages the source so Enhance can run, re-renders only
+	// the given pages, and points their ImagePath at the new images.
+	Restore(
+		ctx context.Context,
+		src SourceReader,
+		tempDir string,
+		pages []*state.ClassificationPage,
+	) error
+}
+
+// baseDensity is the DPI pages are rendered at outside banner crop mode, and
+// the DPI raster sources are treated as scanned at.
+const baseDensity = 300
+
+// Options configures how a handler renders pages. Crop enables banner crop
+// mode: each page also gets a BannerPath image of its header and footer
+// bands, each CropHeight of the page tall (a fraction, e.g. 0.1), rendered
+// at CropDensity DPI, while the full page is rendered at the lower
+// PageDensity so the fallback image costs fewer vision tokens. Outside crop
+// mode pages render at 300 DPI. Raster sources are treated as 300 DPI scans,
+// so for them a density of 150 halves the image. Renderer is the backend
+// pages are rasterized with; nil selects MagickRenderer.
+type Options struct {
+	Crop        bool
+	CropHeight  float64
+	CropDensity int
+	PageDensity int
+	Renderer    Renderer
+}
+
+// renderer returns the backend pages are rasterized with.
+func (o Options) renderer() Renderer {
+	if o.Renderer != nil {
+		return o.Renderer
+	}
+	return MagickRenderer()
+}
+
+// crops reports whether pages also get a banner crop.
+func (o Options) crops() bool {
+	return o.Crop && o.CropHeight > 0
+}
+
+// pageDensity returns the DPI full pages are rendered at.
+func (o Options) pageDensity() int {
+	if o.crops() && o.PageDensity > 0 {
+		return o.PageDensity
+	}
+	return baseDensity
+}
+
+// cropDensity returns the DPI banner bands are rendered at.
+func (o Options) cropDensity() int {
+	if o.CropDensity > 0 {
+		return o.CropDensity
+	}
+	return baseDensity
+}
+
+// scale converts a density to the percentage a raster source, treated as a
+// 300 DPI scan, is resized by.
+func scale(density int) int {
+	return density * 100 / baseDensity
 }
 
 // SourceReader abstracts "download this document" so handlers do not depend
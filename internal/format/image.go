@@ -9,13 +9,17 @@ import (
 	"github.com/JaimeStill/herald/internal/state"
 )
 
-type imageHandler struct{}
+type imageHandler struct {
+	opts Options
+}
 
 // NewImageHandler returns a Handler that accepts raw image uploads
 // (PNG, JPEG, WEBP). PNG inputs are copied verbatim as page-1.png;
-// JPEG and WEBP inputs are normalized to PNG via magick so downstream
-// vision calls see uniform bytes regardless of source encoding.
-func NewImageHandler() Handler { return &imageHandler{} }
+// JPEG and WEBP inputs are normalized to PNG by the Renderer in opts so
+// downstream vision calls see uniform bytes regardless of source encoding.
+// In banner crop mode the image's bands are cropped as well, and the page
+// sent to the vision model is scaled to opts.PageDensity.
+func NewImageHandler(opts Options) Handler { return &imageHandler{opts: opts} }
 
 func (h *imageHandler) ID() string { return "image" }
 func (h *imageHandler) ContentTypes() []string {
@@ -29,7 +33,10 @@ func (h *imageHandler) ContentTypes() []string {
 // Extract produces exactly one page for any supported image type. PNG
 // sources are copied byte-for-byte to <tempDir>/page-1.png; JPEG and
 // WEBP sources are staged as <tempDir>/source.<ext> and normalized to
-// PNG via magick. The returned slice always has len == 1.
+// PNG by the renderer. The returned slice always has len == 1. In banner
+// crop mode the normalized page is also scaled to <tempDir>/page-1-scaled.png,
+// which becomes the page image, and its bands are written to
+// <tempDir>/page-1-banners.png.
 func (h *imageHandler) Extract(
 	ctx context.Context,
 	src SourceReader,
@@ -55,12 +62,39 @@ func (h *imageHandler) Extract(
 		if err := os.WriteFile(srcPath, data, 0600); err != nil {
 			return nil, fmt.Errorf("write image source: %w", err)
 		}
-		if err := Render(ctx, srcPath, outPath, false, nil); err != nil {
+		if err := h.opts.renderer().RenderImage(ctx, srcPath, outPath, Raster{}, nil); err != nil {
 			return nil, fmt.Errorf("normalize %s: %w", ct, err)
 		}
 	}
 
-	return []state.ClassificationPage{{PageNumber: 1, ImagePath: outPath}}, nil
+	page := state.ClassificationPage{PageNumber: 1, ImagePath: outPath}
+	if h.opts.crops() {
+		if err := h.crop(ctx, tempDir, &page); err != nil {
+			return nil, err
+		}
+	}
+
+	return []state.ClassificationPage{page}, nil
+}
+
+// crop renders the banner bands of the normalized page and a scaled copy of
+// it, and points the page at them.
+func (h *imageHandler) crop(ctx context.Context, tempDir string, page *state.ClassificationPage) error {
+	srcPath := page.ImagePath
+
+	page.BannerPath = filepath.Join(tempDir, "page-1-banners.png")
+	bands := Raster{Scale: scale(h.opts.cropDensity()), Bands: h.opts.CropHeight}
+	if err := h.opts.renderer().RenderImage(ctx, srcPath, page.BannerPath, bands, nil); err != nil {
+		return fmt.Errorf("crop image banners: %w", err)
+	}
+
+	page.ImagePath = filepath.Join(tempDir, "page-1-scaled.png")
+	full := Raster{Scale: scale(h.opts.pageDensity())}
+	if err := h.opts.renderer().RenderImage(ctx, srcPath, page.ImagePath, full, nil); err != nil {
+		return fmt.Errorf("scale image: %w", err)
+	}
+
+	return nil
 }
 
 // Enhance re-applies filter settings to the normalized PNG produced by
@@ -75,12 +109,32 @@ func (h *imageHandler) Enhance(
 ) (string, error) {
 	srcPath := filepath.Join(tempDir, "page-1.png")
 	outPath := filepath.Join(tempDir, "page-1-enhanced.png")
-	if err := Render(ctx, srcPath, outPath, false, settings); err != nil {
+	if err := h.opts.renderer().RenderImage(ctx, srcPath, outPath, Raster{}, settings); err != nil {
 		return "", fmt.Errorf("enhance image: %w", err)
 	}
 	return outPath, nil
 }
 
+// Restore repeats Extract: the normalized <tempDir>/page-1.png is both the
+// page image and the intermediate Enhance works from, so it is needed
+// whether or not the page itself is being re-rendered.
+func (h *imageHandler) Restore(
+	ctx context.Context,
+	src SourceReader,
+	tempDir string,
+	pages []*state.ClassificationPage,
+) error {
+	extracted, err := h.Extract(ctx, src, tempDir)
+	if err != nil {
+		return err
+	}
+	for _, page := range pages {
+		page.ImagePath = extracted[0].ImagePath
+		page.BannerPath = extracted[0].BannerPath
+	}
+	return nil
+}
+
 // extension maps a supported image content type to its file extension
 // (including the leading dot). Returns "" for unrecognized types; callers
 // should treat an empty return as an unsupported-format signal.
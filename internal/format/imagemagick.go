@@ -5,32 +5,62 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
+	"strconv"
 
 	"github.com/JaimeStill/herald/internal/state"
 )
 
-// Render invokes the `magick` CLI to convert src → dst. When density is
-// true, passes `-density 300` (required for PDF rasterization — image
-// sources should pass false since they already have native resolution).
+type magickRenderer struct{}
+
+// MagickRenderer returns a Renderer that shells out to the `magick` CLI,
+// which rasterizes PDFs through Ghostscript.
+func MagickRenderer() Renderer { return magickRenderer{} }
+
+func (magickRenderer) ID() string { return RendererMagick }
+
+// RenderPage passes magick the page as `<path>[N-1]`, its native page
+// selector syntax for PDFs and multi-frame TIFFs, which is zero-indexed.
+func (r magickRenderer) RenderPage(
+	ctx context.Context,
+	path string,
+	page int,
+	dst string,
+	raster Raster,
+	settings *state.EnhanceSettings,
+) error {
+	return r.render(ctx, pageSelector(path, page), dst, raster, settings)
+}
+
+func (r magickRenderer) RenderImage(
+	ctx context.Context,
+	src, dst string,
+	raster Raster,
+	settings *state.EnhanceSettings,
+) error {
+	raster.Density = 0
+	return r.render(ctx, src, dst, raster, settings)
+}
+
+// render invokes the `magick` CLI to convert src → dst as raster describes.
 // When settings is non-nil, applies brightness/contrast and/or saturation
 // filters in a single pass. Cancellation propagates via the context; errors
 // wrap the magick stderr for diagnostics.
-//
-// PDF callers pass src as `<tempDir>/source.pdf[N-1]` (magick's native PDF
-// page-selector syntax, zero-indexed). Image callers pass the direct file
-// path. In both cases dst is the destination PNG path.
-func Render(
+func (magickRenderer) render(
 	ctx context.Context,
 	src, dst string,
-	density bool,
+	raster Raster,
 	settings *state.EnhanceSettings,
 ) error {
-	args := make([]string, 0, 8)
-	if density {
-		args = append(args, "-density", "300")
+	args := make([]string, 0, 24)
+	if raster.Density > 0 {
+		args = append(args, "-density", strconv.Itoa(raster.Density))
 	}
 	args = append(args, src)
 
+	if raster.Scale > 0 && raster.Scale != 100 {
+		args = append(args, "-resize", fmt.Sprintf("%d%%", raster.Scale))
+	}
+
 	if settings != nil {
 		if bc, ok := brightnessContrastArg(settings); ok {
 			args = append(args, "-brightness-contrast", bc)
@@ -40,15 +70,35 @@ func Render(
 		}
 	}
 
+	if raster.Bands > 0 {
+		args = append(args, bandsArgs(raster.Bands)...)
+	}
+
 	args = append(args, dst)
 
-	var stderr bytes.Buffer
-	cmd := exec.CommandContext(ctx, "magick", args...)
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("magick %s: %w: %s", src, err, stderr.String())
+	return observeRender(ctx, RendererMagick, raster, settings, func(ctx context.Context) error {
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "magick", args...)
+		cmd.Stderr = &stderr
+
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("magick %s: %w: %s", src, err, stderr.String())
+		}
+		return nil
+	})
+}
+
+// bandsArgs crops the header and footer bands from the loaded page, each
+// height of it tall, and appends them top to bottom with a rule between
+// them so the model sees where the page was cut.
+func bandsArgs(height float64) []string {
+	geometry := fmt.Sprintf("100%%x%s%%+0+0", strconv.FormatFloat(height*100, 'f', -1, 64))
+	return []string{
+		"(", "-clone", "0", "-gravity", "North", "-crop", geometry, "+repage", ")",
+		"(", "-clone", "0", "-gravity", "South", "-crop", geometry, "+repage",
+		"-background", "gray50", "-gravity", "North", "-splice", "0x12", ")",
+		"-delete", "0", "-append",
 	}
-	return nil
 }
 
 // brightnessContrastArg assembles the paired `brightness,contrast` argument
@@ -69,3 +119,10 @@ func brightnessContrastArg(s *state.EnhanceSettings) (string, bool) {
 	}
 	return fmt.Sprintf("%d,%d", b, c), true
 }
+
+// pageSelector formats a page selector for magick (zero-indexed).
+// ImageMagick interprets `file.pdf[N]` as "the N-th page of file.pdf",
+// so we translate Herald's 1-indexed PageNumber by subtracting 1.
+func pageSelector(src string, page int) string {
+	return src + "[" + strconv.Itoa(page-1) + "]"
+}
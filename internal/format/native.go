@@ -0,0 +1,709 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+	"golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+
+	"github.com/JaimeStill/herald/internal/state"
+)
+
+// nativeCacheSize bounds how many parsed PDFs the native renderer keeps.
+// Handlers render every page of one document in a burst, so a handful
+// covers the documents classified concurrently.
+const nativeCacheSize = 8
+
+// nativeDensity is the DPI a page renders at when raster sets none,
+// matching magick's default.
+const nativeDensity = 72
+
+// nativeRenderer rasterizes in-process. PDF pages are parsed with pdfcpu and
+// composited from their embedded images, which covers scanned documents:
+// pages that draw one or more images, optionally over solid rectangles and
+// under an invisible OCR text layer. Pages that show visible text, draw
+// vector paths, shadings, inline images, or form XObjects, paint through a
+// soft mask or transparency, or carry annotations, whose appearances are
+// drawn over the page, return ErrUnsupportedPage. TIFF frames are decoded
+// with the tiff package.
+type nativeRenderer struct {
+	mu    sync.Mutex
+	docs  map[string]*nativeDoc
+	order []string
+}
+
+// NativeRenderer returns a Renderer that rasterizes scanned pages without
+// external tools. It is a fast path, not a PDF rasterizer: see
+// ErrUnsupportedPage for the pages it declines, which FallbackRenderer
+// hands to ImageMagick.
+func NativeRenderer() Renderer {
+	return &nativeRenderer{docs: make(map[string]*nativeDoc)}
+}
+
+func (r *nativeRenderer) ID() string { return RendererNative }
+
+// RenderPage composites page of the PDF at path, or decodes the frame of a
+// TIFF. PDF support is checked before the render is observed, so declined
+// pages are not recorded as render errors.
+func (r *nativeRenderer) RenderPage(
+	ctx context.Context,
+	path string,
+	page int,
+	dst string,
+	raster Raster,
+	settings *state.EnhanceSettings,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var header [4]byte
+	if _, err := io.ReadFull(f, header[:]); err == nil && isTIFF(header[:]) {
+		return r.renderFrame(ctx, f, page, dst, raster, settings)
+	}
+
+	doc, err := r.document(path)
+	if err != nil {
+		return err
+	}
+
+	p, err := doc.page(page)
+	if err != nil {
+		return err
+	}
+
+	density := raster.Density
+	if density == 0 {
+		density = nativeDensity
+	}
+
+	return observeRender(ctx, RendererNative, raster, settings, func(ctx context.Context) error {
+		img, err := p.rasterize(density)
+		if err != nil {
+			return fmt.Errorf("render %s page %d: %w", path, page, err)
+		}
+		return finish(ctx, img, dst, raster, settings)
+	})
+}
+
+// RenderImage decodes a PNG, JPEG, WEBP, or TIFF image at src. Other
+// encodings return ErrUnsupportedPage.
+func (r *nativeRenderer) RenderImage(
+	ctx context.Context,
+	src, dst string,
+	raster Raster,
+	settings *state.EnhanceSettings,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", src, err)
+	}
+
+	if _, _, err := image.DecodeConfig(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("%w: decode %s: %v", ErrUnsupportedPage, src, err)
+	}
+
+	return observeRender(ctx, RendererNative, raster, settings, func(ctx context.Context) error {
+		decoded, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("decode %s: %w", src, err)
+		}
+		return finish(ctx, toRGBA(decoded), dst, raster, settings)
+	})
+}
+
+// renderFrame decodes frame of the TIFF read through f. Frames compressed
+// or laid out in ways the tiff package does not decode, such as JPEG
+// compression, return ErrUnsupportedPage.
+func (r *nativeRenderer) renderFrame(
+	ctx context.Context,
+	f *os.File,
+	frame int,
+	dst string,
+	raster Raster,
+	settings *state.EnhanceSettings,
+) error {
+	return observeRender(ctx, RendererNative, raster, settings, func(ctx context.Context) error {
+		decoded, err := decodeTIFFFrame(f, frame)
+		if err != nil {
+			var unsupported tiff.UnsupportedError
+			if errors.As(err, &unsupported) {
+				return fmt.Errorf("%w: %s frame %d: %v", ErrUnsupportedPage, f.Name(), frame, err)
+			}
+			return fmt.Errorf("decode %s frame %d: %w", f.Name(), frame, err)
+		}
+		return finish(ctx, toRGBA(decoded), dst, raster, settings)
+	})
+}
+
+// toRGBA copies img into an RGBA image with its origin at zero.
+func toRGBA(img image.Image) *image.RGBA {
+	out := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(out, out.Bounds(), img, img.Bounds().Min, draw.Src)
+	return out
+}
+
+// document returns the parsed PDF at path, from the cache while the file is
+// unchanged. The least recently parsed document is evicted once the cache
+// is full.
+func (r *nativeRenderer) document(path string) (*nativeDoc, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat %s: %w", path, err)
+	}
+
+	r.mu.Lock()
+	doc, ok := r.docs[path]
+	if !ok || !doc.modTime.Equal(info.ModTime()) || doc.size != info.Size() {
+		doc = &nativeDoc{path: path, modTime: info.ModTime(), size: info.Size()}
+		if !ok {
+			r.order = append(r.order, path)
+		}
+		r.docs[path] = doc
+		if len(r.order) > nativeCacheSize {
+			delete(r.docs, r.order[0])
+			r.order = r.order[1:]
+		}
+	}
+	r.mu.Unlock()
+
+	doc.once.Do(doc.read)
+	if doc.err != nil {
+		return nil, doc.err
+	}
+	return doc, nil
+}
+
+// nativeDoc is a parsed PDF shared by the concurrent renders of its pages.
+// pdfcpu contexts are not safe for concurrent use, so mu serializes the
+// reads of pdf; compositing runs outside it.
+type nativeDoc struct {
+	path    string
+	modTime time.Time
+	size    int64
+
+	once sync.Once
+	err  error
+
+	mu  sync.Mutex
+	pdf *model.Context
+}
+
+// read parses the PDF. Image extraction needs the resource analysis pdfcpu
+// performs when optimizing, so the document is optimized in memory. A PDF
+// pdfcpu cannot parse is reported as unsupported, leaving it to another
+// backend.
+func (d *nativeDoc) read() {
+	f, err := os.Open(d.path)
+	if err != nil {
+		d.err = fmt.Errorf("open %s: %w", d.path, err)
+		return
+	}
+	defer f.Close()
+
+	d.pdf, err = api.ReadValidateAndOptimize(f, model.NewDefaultConfiguration())
+	if err != nil {
+		d.err = fmt.Errorf("%w: read %s: %v", ErrUnsupportedPage, d.path, err)
+	}
+}
+
+// page reads the page box, rotation, content stream, and images of page
+// number n and works out what it paints.
+func (d *nativeDoc) page(n int) (*nativePage, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if n < 1 || n > d.pdf.PageCount {
+		return nil, fmt.Errorf("page %d out of range 1-%d", n, d.pdf.PageCount)
+	}
+
+	dict, _, attrs, err := d.pdf.PageDict(n, false)
+	if err != nil {
+		return nil, fmt.Errorf("read page %d: %w", n, err)
+	}
+	annots, err := d.pdf.DereferenceArray(dict["Annots"])
+	if err != nil || len(annots) > 0 {
+		return nil, fmt.Errorf("%w: page %d has annotations", ErrUnsupportedPage, n)
+	}
+
+	box := attrs.MediaBox
+	if attrs.CropBox != nil {
+		box = attrs.CropBox
+	}
+	if box == nil || box.Width() <= 0 || box.Height() <= 0 {
+		return nil, fmt.Errorf("%w: page %d has no page box", ErrUnsupportedPage, n)
+	}
+
+	r, err := pdfcpu.ExtractPageContent(d.pdf, n)
+	if err != nil {
+		return nil, fmt.Errorf("read page %d content: %w", n, err)
+	}
+	var content []byte
+	if r != nil {
+		if content, err = io.ReadAll(r); err != nil {
+			return nil, fmt.Errorf("read page %d content: %w", n, err)
+		}
+	}
+
+	images, err := pageImages(d.pdf, n)
+	if err != nil {
+		return nil, err
+	}
+
+	res := pageResources{
+		images:  images,
+		masked:  d.resourceNames(attrs.Resources, "XObject", maskedXObject),
+		blended: d.resourceNames(attrs.Resources, "ExtGState", blendedState),
+	}
+
+	p := &nativePage{
+		box:    [4]float64{box.LL.X, box.LL.Y, box.UR.X, box.UR.Y},
+		rotate: ((attrs.Rotate % 360) + 360) % 360,
+	}
+	if err := p.interpret(content, res); err != nil {
+		return nil, fmt.Errorf("%w: page %d: %v", ErrUnsupportedPage, n, err)
+	}
+	return p, nil
+}
+
+// resourceNames returns the names in the category of resources whose
+// dictionaries match. Entries that cannot be read match, so the page is
+// declined rather than painted on a guess.
+func (d *nativeDoc) resourceNames(
+	resources types.Dict,
+	category string,
+	match func(pdf *model.Context, dict types.Dict) bool,
+) map[string]bool {
+	names := make(map[string]bool)
+
+	entries, err := d.pdf.DereferenceDict(resources[category])
+	if err != nil {
+		return names
+	}
+	for name, obj := range entries {
+		obj, err := d.pdf.Dereference(obj)
+		if err != nil {
+			names[name] = true
+			continue
+		}
+		switch v := obj.(type) {
+		case types.Dict:
+			names[name] = match(d.pdf, v)
+		case types.StreamDict:
+			names[name] = match(d.pdf, v.Dict)
+		default:
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// maskedXObject reports whether an image XObject is drawn through a mask:
+// a soft mask, an explicit or color key mask, or as a stencil mask itself,
+// any of which leaves parts of it transparent.
+func maskedXObject(_ *model.Context, dict types.Dict) bool {
+	_, smask := dict.Find("SMask")
+	_, mask := dict.Find("Mask")
+	stencil := dict.BooleanEntry("ImageMask")
+	return smask || mask || (stencil != nil && *stencil)
+}
+
+// blendedState reports whether an extended graphics state makes later
+// painting translucent: a soft mask other than /None, a constant alpha
+// below 1, or a blend mode other than Normal.
+func blendedState(pdf *model.Context, dict types.Dict) bool {
+	if obj, ok := dict.Find("SMask"); ok {
+		if name, ok := obj.(types.Name); !ok || name != "None" {
+			return true
+		}
+	}
+	for _, key := range []string{"CA", "ca"} {
+		if obj, ok := dict.Find(key); ok {
+			if alpha, err := pdf.DereferenceNumber(obj); err != nil || alpha < 1 {
+				return true
+			}
+		}
+	}
+	if obj, ok := dict.Find("BM"); ok {
+		name, ok := obj.(types.Name)
+		return !ok || (name != "Normal" && name != "Compatible")
+	}
+	return false
+}
+
+// pageImages returns the encoded images in the resources of page n by
+// resource name, keeping only those the registered image decoders read.
+func pageImages(pdf *model.Context, n int) (map[string][]byte, error) {
+	extracted, err := pdfcpu.ExtractPageImages(pdf, n, false)
+	if err != nil {
+		return nil, fmt.Errorf("extract page %d images: %w", n, err)
+	}
+
+	images := make(map[string][]byte, len(extracted))
+	for _, img := range extracted {
+		if img.Thumb || img.Reader == nil {
+			continue
+		}
+		data, err := io.ReadAll(img.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("read page %d image %s: %w", n, img.Name, err)
+		}
+		if _, _, err := image.DecodeConfig(bytes.NewReader(data)); err != nil {
+			continue
+		}
+		images[img.Name] = data
+	}
+	return images, nil
+}
+
+// matrix is a PDF transformation matrix [a b c d e f].
+type matrix [6]float64
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+// mul returns m × n, the transform that applies m and then n.
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+// apply maps the point (x, y) through m.
+func (m matrix) apply(x, y float64) (float64, float64) {
+	return m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]
+}
+
+// paint is one drawing operation of a page, in page order: an image drawn
+// into the unit square under ctm, or, when image is nil, rect filled with
+// fill.
+type paint struct {
+	ctm   matrix
+	image []byte
+	rect  [4]float64
+	fill  color.Color
+}
+
+// pageResources are the resources of a page its content is interpreted
+// against: the decodable images by XObject name, the XObjects drawn through
+// a mask, and the extended graphics states that blend.
+type pageResources struct {
+	images  map[string][]byte
+	masked  map[string]bool
+	blended map[string]bool
+}
+
+// nativePage is what a page paints, in user space within box.
+type nativePage struct {
+	box    [4]float64
+	rotate int
+	paints []paint
+}
+
+// graphicsState is the part of the PDF graphics state the native renderer
+// tracks. A nil fill is a color it cannot paint, such as a pattern.
+type graphicsState struct {
+	ctm      matrix
+	fill     color.Color
+	textMode int
+}
+
+// interpret walks content and records its paints, returning an error
+// describing the first operation the native renderer cannot reproduce.
+// Clipping paths are ignored, which is exact for the page-sized clips
+// scanned pages set, and so is an extended graphics state unless it blends.
+func (p *nativePage) interpret(content []byte, res pageResources) error {
+	var (
+		gs        = graphicsState{ctm: identity, fill: color.Black}
+		stack     []graphicsState
+		rects     [][4]float64
+		pathOther bool
+		err       error
+	)
+
+	fail := func(format string, args ...any) {
+		if err == nil {
+			err = fmt.Errorf(format, args...)
+		}
+	}
+
+	s := &contentScanner{data: content}
+	s.visit = func(name string, ops []operand) {
+		switch name {
+		case "q":
+			stack = append(stack, gs)
+		case "Q":
+			if len(stack) > 0 {
+				gs, stack = stack[len(stack)-1], stack[:len(stack)-1]
+			}
+		case "cm":
+			if m, ok := numbers(ops, 6); ok {
+				gs.ctm = matrix(m).mul(gs.ctm)
+			}
+		case "Do":
+			if len(ops) == 0 || !ops[0].isName {
+				return
+			}
+			if res.masked[ops[0].str] {
+				fail("XObject %s is drawn through a mask", ops[0].str)
+				return
+			}
+			data, ok := res.images[ops[0].str]
+			if !ok {
+				fail("XObject %s is not a supported image", ops[0].str)
+				return
+			}
+			p.paints = append(p.paints, paint{ctm: gs.ctm, image: data})
+		case "re":
+			if r, ok := numbers(ops, 4); ok {
+				rects = append(rects, [4]float64{r[0], r[1], r[0] + r[2], r[1] + r[3]})
+			}
+		case "m", "l", "c", "v", "y", "h":
+			pathOther = true
+		case "n":
+			rects, pathOther = nil, false
+		case "f", "F", "f*":
+			switch {
+			case pathOther:
+				fail("fills a non-rectangular path")
+			case gs.fill == nil:
+				fail("fills with a pattern or unsupported color space")
+			case gs.ctm[1] != 0 || gs.ctm[2] != 0:
+				fail("fills a rotated rectangle")
+			}
+			for _, r := range rects {
+				p.paints = append(p.paints, paint{ctm: gs.ctm, rect: r, fill: gs.fill})
+			}
+			rects, pathOther = nil, false
+		case "S", "s", "B", "B*", "b", "b*":
+			fail("strokes a path")
+		case "Tr":
+			if n, ok := numbers(ops, 1); ok {
+				gs.textMode = int(n[0])
+			}
+		case "Tj", "TJ", "'", "\"":
+			if gs.textMode != 3 {
+				fail("shows visible text")
+			}
+		case "g", "rg", "k":
+			gs.fill = deviceColor(ops)
+		case "cs":
+			gs.fill = nil
+			if len(ops) == 1 && ops[0].isName {
+				switch ops[0].str {
+				case "DeviceGray", "DeviceRGB", "DeviceCMYK":
+					gs.fill = color.Black
+				}
+			}
+		case "sc", "scn":
+			gs.fill = deviceColor(ops)
+		case "sh":
+			fail("paints a shading")
+		case "BI":
+			fail("draws an inline image")
+		case "gs":
+			if len(ops) == 1 && ops[0].isName && res.blended[ops[0].str] {
+				fail("graphics state %s blends or soft-masks", ops[0].str)
+			}
+		case "BT", "ET", "Tc", "Tw", "Tz", "TL", "Tf", "Ts", "Td", "TD", "Tm", "T*",
+			"W", "W*", "G", "RG", "K", "CS", "SC", "SCN",
+			"w", "J", "j", "M", "d", "ri", "i",
+			"BMC", "BDC", "EMC", "MP", "DP", "BX", "EX":
+		default:
+			fail("uses operator %s", name)
+		}
+	}
+	s.scan()
+
+	return err
+}
+
+// numbers returns the n numeric operands of an operator.
+func numbers(ops []operand, n int) ([]float64, bool) {
+	if len(ops) != n {
+		return nil, false
+	}
+	nums := make([]float64, n)
+	for i, op := range ops {
+		if !op.isNum {
+			return nil, false
+		}
+		nums[i] = op.num
+	}
+	return nums, true
+}
+
+// deviceColor reads a gray, RGB, or CMYK color from its components, or nil
+// for any other operands.
+func deviceColor(ops []operand) color.Color {
+	if n, ok := numbers(ops, len(ops)); ok {
+		switch len(n) {
+		case 1:
+			return color.Gray{Y: level(n[0])}
+		case 3:
+			return color.RGBA{R: level(n[0]), G: level(n[1]), B: level(n[2]), A: 0xff}
+		case 4:
+			return color.CMYK{C: level(n[0]), M: level(n[1]), Y: level(n[2]), K: level(n[3])}
+		}
+	}
+	return nil
+}
+
+// rasterize composites the page's paints onto white at density DPI, then
+// applies the page rotation.
+func (p *nativePage) rasterize(density int) (*image.RGBA, error) {
+	scale := float64(density) / 72
+	x0, y0, x1, y1 := p.box[0], p.box[1], p.box[2], p.box[3]
+	w := int(math.Round((x1 - x0) * scale))
+	h := int(math.Round((y1 - y0) * scale))
+
+	canvas := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(canvas, canvas.Bounds(), image.White, image.Point{}, draw.Src)
+
+	for _, op := range p.paints {
+		m := op.ctm
+
+		if op.image == nil {
+			ax, ay := m.apply(op.rect[0], op.rect[1])
+			bx, by := m.apply(op.rect[2], op.rect[3])
+			r := image.Rect(
+				int(math.Round((ax-x0)*scale)), int(math.Round((y1-ay)*scale)),
+				int(math.Round((bx-x0)*scale)), int(math.Round((y1-by)*scale)),
+			)
+			draw.Draw(canvas, r, image.NewUniform(op.fill), image.Point{}, draw.Over)
+			continue
+		}
+
+		src, _, err := image.Decode(bytes.NewReader(op.image))
+		if err != nil {
+			return nil, fmt.Errorf("decode image: %w", err)
+		}
+		iw, ih := float64(src.Bounds().Dx()), float64(src.Bounds().Dy())
+
+		// Image space is the unit square with its origin at the lower left;
+		// sample (u, v) of the image, counted from the top row, sits at
+		// (u/iw, 1-v/ih). Device space flips y from the top of the box.
+		aff := f64.Aff3{
+			scale * m[0] / iw, -scale * m[2] / ih, scale * (m[2] + m[4] - x0),
+			-scale * m[1] / iw, scale * m[3] / ih, scale * (y1 - m[3] - m[5]),
+		}
+
+		if aff[1] == 0 && aff[3] == 0 && aff[0] > 0 && aff[4] > 0 {
+			r := image.Rect(
+				int(math.Round(aff[2])), int(math.Round(aff[5])),
+				int(math.Round(aff[2]+aff[0]*iw)), int(math.Round(aff[5]+aff[4]*ih)),
+			)
+			if r.Dx() == src.Bounds().Dx() && r.Dy() == src.Bounds().Dy() {
+				draw.Draw(canvas, r, src, src.Bounds().Min, draw.Over)
+			} else {
+				draw.BiLinear.Scale(canvas, r, src, src.Bounds(), draw.Over, nil)
+			}
+			continue
+		}
+
+		aff[2] -= aff[0]*float64(src.Bounds().Min.X) + aff[1]*float64(src.Bounds().Min.Y)
+		aff[5] -= aff[3]*float64(src.Bounds().Min.X) + aff[4]*float64(src.Bounds().Min.Y)
+		draw.BiLinear.Transform(canvas, aff, src, src.Bounds(), draw.Over, nil)
+	}
+
+	return rotate(canvas, p.rotate), nil
+}
+
+// rotate turns img clockwise by degrees, a multiple of 90, as a page's
+// Rotate entry directs.
+func rotate(img *image.RGBA, degrees int) *image.RGBA {
+	if degrees%90 != 0 || degrees%360 == 0 {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	size := image.Rect(0, 0, h, w)
+	if degrees == 180 {
+		size = image.Rect(0, 0, w, h)
+	}
+
+	out := image.NewRGBA(size)
+	for y := range h {
+		for x := range w {
+			var dx, dy int
+			switch degrees {
+			case 90:
+				dx, dy = h-1-y, x
+			case 180:
+				dx, dy = w-1-x, h-1-y
+			case 270:
+				dx, dy = y, w-1-x
+			}
+			copy(out.Pix[out.PixOffset(dx, dy):][:4], img.Pix[img.PixOffset(x, y):][:4])
+		}
+	}
+	return out
+}
+
+// finish applies the scale, enhancement, and band crop raster and settings
+// ask for, in the order magick applies them, and writes the result to dst
+// as a PNG.
+func finish(
+	ctx context.Context,
+	img *image.RGBA,
+	dst string,
+	raster Raster,
+	settings *state.EnhanceSettings,
+) error {
+	if raster.Scale > 0 && raster.Scale != 100 {
+		img = resize(img, raster.Scale)
+	}
+	if settings != nil {
+		enhance(img, settings)
+	}
+	if raster.Bands > 0 {
+		img = bands(img, raster.Bands)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", dst, err)
+	}
+
+	if err := png.Encode(f, grayscale(img)); err != nil {
+		f.Close()
+		return fmt.Errorf("encode %s: %w", dst, err)
+	}
+	return f.Close()
+}
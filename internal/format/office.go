@@ -0,0 +1,215 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/JaimeStill/herald/internal/state"
+	"github.com/JaimeStill/herald/internal/tracing"
+)
+
+// OOXML content types accepted by the Office handler.
+const (
+	ContentTypeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	ContentTypePPTX = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+	ContentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// officeExtensions maps each Office content type to the extension its source
+// is staged with, since converters choose an import filter by extension.
+var officeExtensions = map[string]string{
+	ContentTypeDOCX: ".docx",
+	ContentTypePPTX: ".pptx",
+	ContentTypeXLSX: ".xlsx",
+}
+
+// Converter converts an Office document to PDF.
+type Converter interface {
+	// Convert converts the document at src to a PDF written into outDir and
+	// returns the PDF's path.
+	Convert(ctx context.Context, src, outDir string) (string, error)
+}
+
+type commandConverter struct {
+	args    []string
+	timeout time.Duration
+}
+
+// CommandConverter returns a Converter that runs the command args, with
+// {input} replaced by the source path and {outdir} by the output directory
+// in each argument. The command must write <outdir>/<source name>.pdf, as
+// `soffice --convert-to pdf --outdir` does. A positive timeout bounds each
+// conversion.
+func CommandConverter(args []string, timeout time.Duration) Converter {
+	return &commandConverter{args: args, timeout: timeout}
+}
+
+func (c *commandConverter) Convert(ctx context.Context, src, outDir string) (string, error) {
+	if len(c.args) == 0 {
+		return "", fmt.Errorf("no converter command configured")
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	ctx, span := tracer.Start(ctx, "format.convert",
+		trace.WithAttributes(attribute.String("format.convert.command", c.args[0])),
+	)
+
+	out, err := c.run(ctx, src, outDir)
+	tracing.End(span, err)
+	return out, err
+}
+
+func (c *commandConverter) run(ctx context.Context, src, outDir string) (string, error) {
+	replacer := strings.NewReplacer("{input}", src, "{outdir}", outDir)
+	args := make([]string, len(c.args))
+	for i, arg := range c.args {
+		args[i] = replacer.Replace(arg)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s %s: %w: %s", args[0], filepath.Base(src), err, stderr.String())
+	}
+
+	pdfPath := filepath.Join(outDir, strings.TrimSuffix(filepath.Base(src), filepath.Ext(src))+".pdf")
+	if _, err := os.Stat(pdfPath); err != nil {
+		return "", fmt.Errorf("%s %s wrote no pdf: %w: %s", args[0], filepath.Base(src), err, stderr.String())
+	}
+
+	return pdfPath, nil
+}
+
+type officeHandler struct {
+	pdf       *pdfHandler
+	converter Converter
+}
+
+// NewOfficeHandler returns a Handler that accepts Word, PowerPoint, and Excel
+// documents in their OOXML formats. It converts each document to PDF with
+// converter and renders the PDF's pages as the PDF handler does, with the
+// converted text layer as each page's Text. Headers and footers are also
+// read from the OOXML package itself and set as each page's Margins, so
+// banner markings survive a converter that lays them out poorly.
+func NewOfficeHandler(opts Options, converter Converter) Handler {
+	return &officeHandler{pdf: &pdfHandler{opts: opts}, converter: converter}
+}
+
+func (h *officeHandler) ID() string { return "office" }
+
+func (h *officeHandler) ContentTypes() []string {
+	return []string{ContentTypeDOCX, ContentTypePPTX, ContentTypeXLSX}
+}
+
+// Extract writes the source document to <tempDir>/source.<ext>, converts it
+// to <tempDir>/source.pdf, and extracts and renders the PDF's pages. Each
+// page's Margins holds the package's header and footer text: per slide for
+// a presentation whose slides map one to one onto pages, otherwise every
+// header and footer in the package on every page.
+func (h *officeHandler) Extract(
+	ctx context.Context,
+	src SourceReader,
+	tempDir string,
+) ([]state.ClassificationPage, error) {
+	data, pdfData, err := h.convert(ctx, src, tempDir)
+	if err != nil {
+		return nil, err
+	}
+
+	pages, err := h.pdf.extract(ctx, pdfData, filepath.Join(tempDir, sourcePDF), tempDir)
+	if err != nil {
+		return nil, err
+	}
+
+	margins := officeMargins(data, src.ContentType(), len(pages))
+	for i := range pages {
+		pages[i].Margins = margins[i]
+	}
+
+	return pages, nil
+}
+
+// Enhance re-renders the page from the converted <tempDir>/source.pdf, as
+// the PDF handler does.
+func (h *officeHandler) Enhance(
+	ctx context.Context,
+	tempDir string,
+	page *state.ClassificationPage,
+	settings *state.EnhanceSettings,
+) (string, error) {
+	return h.pdf.Enhance(ctx, tempDir, page, settings)
+}
+
+// Restore converts the source document to <tempDir>/source.pdf again, so
+// Enhance can re-render from it, and renders only the given pages. Their
+// Margins survive in the checkpointed state and are not re-read.
+func (h *officeHandler) Restore(
+	ctx context.Context,
+	src SourceReader,
+	tempDir string,
+	pages []*state.ClassificationPage,
+) error {
+	if _, _, err := h.convert(ctx, src, tempDir); err != nil {
+		return err
+	}
+
+	return h.pdf.render(ctx, filepath.Join(tempDir, sourcePDF), tempDir, pages)
+}
+
+// convert reads the document from src, writes it to <tempDir>/source.<ext>,
+// and converts it to <tempDir>/source.pdf, returning the bytes of both.
+func (h *officeHandler) convert(
+	ctx context.Context,
+	src SourceReader,
+	tempDir string,
+) ([]byte, []byte, error) {
+	ext, ok := officeExtensions[src.ContentType()]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, src.ContentType())
+	}
+
+	data, err := readAll(ctx, src)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read office source: %w", err)
+	}
+
+	srcPath := filepath.Join(tempDir, "source"+ext)
+	if err := os.WriteFile(srcPath, data, 0600); err != nil {
+		return nil, nil, fmt.Errorf("write office source: %w", err)
+	}
+
+	out, err := h.converter.Convert(ctx, srcPath, tempDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("convert to pdf: %w", err)
+	}
+
+	pdfPath := filepath.Join(tempDir, sourcePDF)
+	if out != pdfPath {
+		if err := os.Rename(out, pdfPath); err != nil {
+			return nil, nil, fmt.Errorf("stage converted pdf: %w", err)
+		}
+	}
+
+	pdfData, err := os.ReadFile(pdfPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read converted pdf: %w", err)
+	}
+
+	return data, pdfData, nil
+}
@@ -0,0 +1,398 @@
+package format
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxOOXMLPart bounds the bytes read from one part of an OOXML package, so a
+// hostile archive cannot inflate without limit.
+const maxOOXMLPart = 8 << 20
+
+// OfficeContentType returns the OOXML content type of data, judged by the
+// main part its package carries, or "" when data is not a Word, PowerPoint,
+// or Excel package. Uploads sniff as application/zip, so this tells them
+// apart.
+func OfficeContentType(data []byte) string {
+	pkg, err := openOOXML(data)
+	if err != nil {
+		return ""
+	}
+
+	for _, f := range pkg.File {
+		switch f.Name {
+		case "word/document.xml":
+			return ContentTypeDOCX
+		case "ppt/presentation.xml":
+			return ContentTypePPTX
+		case "xl/workbook.xml":
+			return ContentTypeXLSX
+		}
+	}
+	return ""
+}
+
+// OfficePageCount returns the page count an OOXML package records in its
+// extended properties: pages for a Word document and slides for a
+// presentation. The count is the producer's, so it can differ from the
+// converted PDF's. Workbooks record none.
+func OfficePageCount(data []byte) (int, error) {
+	pkg, err := openOOXML(data)
+	if err != nil {
+		return 0, err
+	}
+
+	var props struct {
+		Pages  string `xml:"Pages"`
+		Slides string `xml:"Slides"`
+	}
+	if err := readPartXML(pkg, "docProps/app.xml", &props); err != nil {
+		return 0, err
+	}
+
+	count := props.Pages
+	if count == "" {
+		count = props.Slides
+	}
+	if count == "" {
+		return 0, fmt.Errorf("%w: no page count recorded", ErrInvalidOffice)
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%w: bad page count %q", ErrInvalidOffice, count)
+	}
+	return n, nil
+}
+
+// officeMargins reads the header and footer text of an OOXML package for
+// each of pages pages. A presentation whose slide count matches gets each
+// slide's own header and footer placeholders; otherwise every page gets all
+// of the package's header and footer text. Packages that cannot be read
+// yield empty margins; they are evidence, not required input.
+func officeMargins(data []byte, contentType string, pages int) []string {
+	margins := make([]string, pages)
+
+	pkg, err := openOOXML(data)
+	if err != nil {
+		return margins
+	}
+
+	var headers, footers []string
+	switch contentType {
+	case ContentTypeDOCX:
+		headers, footers = documentMargins(pkg)
+	case ContentTypePPTX:
+		slides := slideMargins(pkg)
+		if len(slides) == pages {
+			for i, s := range slides {
+				margins[i] = marginText(s[0], s[1])
+			}
+			return margins
+		}
+		for _, s := range slides {
+			headers = append(headers, s[0]...)
+			footers = append(footers, s[1]...)
+		}
+	case ContentTypeXLSX:
+		headers, footers = sheetMargins(pkg)
+	}
+
+	text := marginText(headers, footers)
+	for i := range margins {
+		margins[i] = text
+	}
+	return margins
+}
+
+// documentMargins reads every header and footer part of a Word document.
+// Which of them a page shows depends on its section and whether it is a
+// first or even page, which the PDF does not record, so all are kept.
+func documentMargins(pkg *zip.Reader) (headers, footers []string) {
+	for _, f := range pkg.File {
+		dir, name := path.Split(f.Name)
+		if dir != "word/" || path.Ext(name) != ".xml" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(name, "header"):
+			headers = append(headers, readPartText(f)...)
+		case strings.HasPrefix(name, "footer"):
+			footers = append(footers, readPartText(f)...)
+		}
+	}
+	return headers, footers
+}
+
+// slideMargins reads each slide's header and footer placeholders, in
+// presentation order, as a pair of header and footer lines per slide.
+func slideMargins(pkg *zip.Reader) [][2][]string {
+	var pres struct {
+		Slides []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sldIdLst>sldId"`
+	}
+	if err := readPartXML(pkg, "ppt/presentation.xml", &pres); err != nil {
+		return nil
+	}
+
+	targets := partRelationships(pkg, "ppt/_rels/presentation.xml.rels", "ppt/")
+
+	slides := make([][2][]string, 0, len(pres.Slides))
+	for _, s := range pres.Slides {
+		var margins [2][]string
+		if f := findPart(pkg, targets[s.ID]); f != nil {
+			margins[0] = readPartText(f, "hdr")
+			margins[1] = readPartText(f, "ftr")
+		}
+		slides = append(slides, margins)
+	}
+	return slides
+}
+
+// sheetMargins reads the header and footer of every worksheet in a
+// workbook.
+func sheetMargins(pkg *zip.Reader) (headers, footers []string) {
+	for _, f := range pkg.File {
+		if !strings.HasPrefix(f.Name, "xl/worksheets/") || path.Ext(f.Name) != ".xml" {
+			continue
+		}
+
+		var sheet struct {
+			HeaderFooter struct {
+				OddHeader   string `xml:"oddHeader"`
+				OddFooter   string `xml:"oddFooter"`
+				EvenHeader  string `xml:"evenHeader"`
+				EvenFooter  string `xml:"evenFooter"`
+				FirstHeader string `xml:"firstHeader"`
+				FirstFooter string `xml:"firstFooter"`
+			} `xml:"headerFooter"`
+		}
+		if err := decodePart(f, &sheet); err != nil {
+			continue
+		}
+
+		hf := sheet.HeaderFooter
+		for _, s := range []string{hf.OddHeader, hf.EvenHeader, hf.FirstHeader} {
+			headers = append(headers, sheetHeaderText(s)...)
+		}
+		for _, s := range []string{hf.OddFooter, hf.EvenFooter, hf.FirstFooter} {
+			footers = append(footers, sheetHeaderText(s)...)
+		}
+	}
+	return headers, footers
+}
+
+// sheetHeaderText strips the formatting codes from a worksheet header or
+// footer, returning the text of each of its left, center, and right
+// sections. && is a literal ampersand; &"font,style" and &K color codes are
+// skipped along with their arguments, font sizes, and field codes such as
+// &P (page number).
+func sheetHeaderText(s string) []string {
+	var sections []string
+	var sb strings.Builder
+	flush := func() {
+		if line := strings.Join(strings.Fields(sb.String()), " "); line != "" {
+			sections = append(sections, line)
+		}
+		sb.Reset()
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '&' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch c := s[i]; {
+		case c == '&':
+			sb.WriteByte('&')
+		case c == 'L' || c == 'C' || c == 'R':
+			flush()
+		case c == '"':
+			if end := strings.IndexByte(s[i+1:], '"'); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(s)
+			}
+		case c == 'K':
+			i = min(i+6, len(s)-1)
+		case c >= '0' && c <= '9':
+			for i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9' {
+				i++
+			}
+		}
+	}
+	flush()
+	return sections
+}
+
+// marginText labels the distinct header and footer lines, one per line.
+func marginText(headers, footers []string) string {
+	var lines []string
+	for _, h := range headers {
+		if line := "Header: " + h; !slices.Contains(lines, line) {
+			lines = append(lines, line)
+		}
+	}
+	for _, f := range footers {
+		if line := "Footer: " + f; !slices.Contains(lines, line) {
+			lines = append(lines, line)
+		}
+	}
+
+	text := strings.Join(lines, "\n")
+	if len(text) > maxPageText {
+		text = text[:maxPageText]
+	}
+	return text
+}
+
+// readPartText reads the paragraphs of a WordprocessingML or DrawingML part:
+// the text of its t elements, one entry per non-blank paragraph. With
+// placeholders, only the paragraphs of shapes filling one of those
+// placeholder types are kept, as slides draw their headers and footers in
+// hdr and ftr placeholder shapes. Unreadable parts yield what was read.
+func readPartText(f *zip.File, placeholders ...string) []string {
+	rc, err := f.Open()
+	if err != nil {
+		return nil
+	}
+	defer rc.Close()
+
+	dec := xml.NewDecoder(io.LimitReader(rc, maxOOXMLPart))
+
+	var out, shape []string
+	var para strings.Builder
+	var inText, inShape bool
+	var placeholder string
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return out
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab", "br":
+				para.WriteByte(' ')
+			case "sp":
+				inShape, shape, placeholder = true, nil, ""
+			case "ph":
+				for _, a := range t.Attr {
+					if a.Name.Local == "type" {
+						placeholder = a.Value
+					}
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				line := strings.Join(strings.Fields(para.String()), " ")
+				para.Reset()
+				switch {
+				case line == "":
+				case len(placeholders) == 0:
+					out = append(out, line)
+				case inShape:
+					shape = append(shape, line)
+				}
+			case "sp":
+				if slices.Contains(placeholders, placeholder) {
+					out = append(out, shape...)
+				}
+				inShape, shape = false, nil
+			}
+		case xml.CharData:
+			if inText {
+				para.Write(t)
+			}
+		}
+	}
+}
+
+// partRelationships maps the relationship IDs of a .rels part to the paths
+// of their internal targets, resolving relative targets against base.
+func partRelationships(pkg *zip.Reader, name, base string) map[string]string {
+	var rels struct {
+		Relationships []struct {
+			ID         string `xml:"Id,attr"`
+			Target     string `xml:"Target,attr"`
+			TargetMode string `xml:"TargetMode,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := readPartXML(pkg, name, &rels); err != nil {
+		return nil
+	}
+
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, r := range rels.Relationships {
+		if r.TargetMode == "External" {
+			continue
+		}
+		if strings.HasPrefix(r.Target, "/") {
+			targets[r.ID] = strings.TrimPrefix(r.Target, "/")
+		} else {
+			targets[r.ID] = path.Join(base, r.Target)
+		}
+	}
+	return targets
+}
+
+// readPartXML decodes the named part of pkg into v.
+func readPartXML(pkg *zip.Reader, name string, v any) error {
+	f := findPart(pkg, name)
+	if f == nil {
+		return fmt.Errorf("%w: missing %s", ErrInvalidOffice, name)
+	}
+	return decodePart(f, v)
+}
+
+func decodePart(f *zip.File, v any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%w: open %s: %v", ErrInvalidOffice, f.Name, err)
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(rc, maxOOXMLPart)).Decode(v); err != nil {
+		return fmt.Errorf("%w: decode %s: %v", ErrInvalidOffice, f.Name, err)
+	}
+	return nil
+}
+
+// findPart returns the named part of pkg, or nil.
+func findPart(pkg *zip.Reader, name string) *zip.File {
+	if name == "" {
+		return nil
+	}
+	for _, f := range pkg.File {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func openOOXML(data []byte) (*zip.Reader, error) {
+	pkg, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOffice, err)
+	}
+	return pkg, nil
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
 
 	"github.com/JaimeStill/herald/internal/state"
 	"github.com/JaimeStill/herald/pkg/core"
@@ -16,21 +15,26 @@ import (
 
 const sourcePDF = "source.pdf"
 
-type pdfHandler struct{}
+type pdfHandler struct {
+	opts Options
+}
 
 // NewPDFHandler returns a Handler that accepts application/pdf. It uses
-// pdfcpu to count pages and ImageMagick (via Render) to rasterize each
-// page to PNG at 300 DPI, parallelized with bounded concurrency sized by
-// core.WorkerCount.
-func NewPDFHandler() Handler { return &pdfHandler{} }
+// pdfcpu to count pages and read each page's text layer, and the Renderer
+// in opts to rasterize each page to PNG at 300 DPI, parallelized with
+// bounded concurrency sized by core.WorkerCount. In banner crop mode each
+// page is also cropped to its header and footer bands, and the full page is
+// rendered at opts.PageDensity instead.
+func NewPDFHandler(opts Options) Handler { return &pdfHandler{opts: opts} }
 
 func (h *pdfHandler) ID() string             { return "pdf" }
 func (h *pdfHandler) ContentTypes() []string { return []string{"application/pdf"} }
 
-// Extract writes the source PDF to <tempDir>/source.pdf, counts pages with
-// pdfcpu, and renders each page to <tempDir>/page-N.png in parallel. The
-// per-page rendering uses magick's native PDF page-selector syntax
-// (source.pdf[N-1]) so we avoid pulling apart the document ourselves.
+// Extract writes the source PDF to <tempDir>/source.pdf, counts pages and
+// extracts each page's text layer with pdfcpu, and renders each page to
+// <tempDir>/page-N.png in parallel. Pages without a readable text layer, such
+// as scans, have empty Text. In banner crop mode each page's bands are
+// written to <tempDir>/page-N-banners.png.
 func (h *pdfHandler) Extract(
 	ctx context.Context,
 	src SourceReader,
@@ -38,52 +42,46 @@ func (h *pdfHandler) Extract(
 ) ([]state.ClassificationPage, error) {
 	pdfPath := filepath.Join(tempDir, sourcePDF)
 
-	data, err := readAll(ctx, src)
+	data, err := h.stage(ctx, src, pdfPath)
 	if err != nil {
-		return nil, fmt.Errorf("read pdf source: %w", err)
+		return nil, err
 	}
 
-	if err := os.WriteFile(pdfPath, data, 0600); err != nil {
-		return nil, fmt.Errorf("write pdf source: %w", err)
-	}
+	return h.extract(ctx, data, pdfPath, tempDir)
+}
 
-	pageCount, err := api.PageCount(bytes.NewReader(data), nil)
+// extract counts the pages of the PDF data, already staged at pdfPath, reads
+// each page's text layer, and renders each page to <tempDir>/page-N.png.
+func (h *pdfHandler) extract(
+	ctx context.Context,
+	data []byte,
+	pdfPath string,
+	tempDir string,
+) ([]state.ClassificationPage, error) {
+	pdf, err := api.ReadAndValidate(bytes.NewReader(data), nil)
 	if err != nil {
 		return nil, fmt.Errorf("count pages: %w", err)
 	}
 
-	pages := make([]state.ClassificationPage, pageCount)
-
-	g, gctx := errgroup.WithContext(ctx)
-	g.SetLimit(core.WorkerCount(pageCount))
-
-	for i := range pageCount {
-		pageNum := i + 1
-		imgPath := filepath.Join(tempDir, fmt.Sprintf("page-%d.png", pageNum))
-		pages[i] = state.ClassificationPage{
-			PageNumber: pageNum,
-			ImagePath:  imgPath,
-		}
-
-		g.Go(func() error {
-			if gctx.Err() != nil {
-				return gctx.Err()
-			}
-			return Render(gctx, pdfPageSelector(pdfPath, pageNum), imgPath, true, nil)
-		})
+	texts := pageTexts(pdf)
+	pages := make([]state.ClassificationPage, pdf.PageCount)
+	render := make([]*state.ClassificationPage, pdf.PageCount)
+	for i := range pdf.PageCount {
+		pages[i] = state.ClassificationPage{PageNumber: i + 1, Text: texts[i]}
+		render[i] = &pages[i]
 	}
 
-	if err := g.Wait(); err != nil {
-		return nil, fmt.Errorf("render pdf pages: %w", err)
+	if err := h.render(ctx, pdfPath, tempDir, render); err != nil {
+		return nil, err
 	}
 
 	return pages, nil
 }
 
 // Enhance re-renders the given page from <tempDir>/source.pdf with the
-// supplied filter settings applied. Extract must have run previously to
-// seed the source PDF; the result is written to <tempDir>/page-N-enhanced.png
-// and the path is returned.
+// supplied filter settings applied, at 300 DPI even in banner crop mode.
+// Extract must have run previously to seed the source PDF; the result is
+// written to <tempDir>/page-N-enhanced.png and the path is returned.
 func (h *pdfHandler) Enhance(
 	ctx context.Context,
 	tempDir string,
@@ -93,11 +91,12 @@ func (h *pdfHandler) Enhance(
 	pdfPath := filepath.Join(tempDir, sourcePDF)
 	imgPath := filepath.Join(tempDir, fmt.Sprintf("page-%d-enhanced.png", page.PageNumber))
 
-	if err := Render(
+	if err := h.opts.renderer().RenderPage(
 		ctx,
-		pdfPageSelector(pdfPath, page.PageNumber),
+		pdfPath,
+		page.PageNumber,
 		imgPath,
-		true,
+		Raster{Density: baseDensity},
 		settings,
 	); err != nil {
 		return "", fmt.Errorf("enhance page %d: %w", page.PageNumber, err)
@@ -106,9 +105,81 @@ func (h *pdfHandler) Enhance(
 	return imgPath, nil
 }
 
-// pdfPageSelector formats a PDF page selector for magick (zero-indexed).
-// ImageMagick interprets `file.pdf[N]` as "the N-th page of file.pdf",
-// so we translate Herald's 1-indexed PageNumber by subtracting 1.
-func pdfPageSelector(src string, page int) string {
-	return src + "[" + strconv.Itoa(page-1) + "]"
+// Restore writes the source PDF to <tempDir>/source.pdf, so Enhance can
+// re-render from it, and renders only the given pages to
+// <tempDir>/page-N.png.
+func (h *pdfHandler) Restore(
+	ctx context.Context,
+	src SourceReader,
+	tempDir string,
+	pages []*state.ClassificationPage,
+) error {
+	pdfPath := filepath.Join(tempDir, sourcePDF)
+
+	if _, err := h.stage(ctx, src, pdfPath); err != nil {
+		return err
+	}
+
+	return h.render(ctx, pdfPath, tempDir, pages)
+}
+
+// stage reads the PDF from src and writes it to pdfPath, returning its bytes.
+func (h *pdfHandler) stage(ctx context.Context, src SourceReader, pdfPath string) ([]byte, error) {
+	data, err := readAll(ctx, src)
+	if err != nil {
+		return nil, fmt.Errorf("read pdf source: %w", err)
+	}
+
+	if err := os.WriteFile(pdfPath, data, 0600); err != nil {
+		return nil, fmt.Errorf("write pdf source: %w", err)
+	}
+
+	return data, nil
+}
+
+// render rasterizes each page of pdfPath to <tempDir>/page-N.png in
+// parallel and sets the page's ImagePath, and in banner crop mode also its
+// bands to <tempDir>/page-N-banners.png and the page's BannerPath.
+func (h *pdfHandler) render(
+	ctx context.Context,
+	pdfPath string,
+	tempDir string,
+	pages []*state.ClassificationPage,
+) error {
+	renderer := h.opts.renderer()
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(core.WorkerCount(len(pages)))
+
+	for _, page := range pages {
+		page.ImagePath = filepath.Join(tempDir, fmt.Sprintf("page-%d.png", page.PageNumber))
+
+		g.Go(func() error {
+			if gctx.Err() != nil {
+				return gctx.Err()
+			}
+			raster := Raster{Density: h.opts.pageDensity()}
+			return renderer.RenderPage(gctx, pdfPath, page.PageNumber, page.ImagePath, raster, nil)
+		})
+
+		if !h.opts.crops() {
+			continue
+		}
+
+		page.BannerPath = filepath.Join(tempDir, fmt.Sprintf("page-%d-banners.png", page.PageNumber))
+
+		g.Go(func() error {
+			if gctx.Err() != nil {
+				return gctx.Err()
+			}
+			raster := Raster{Density: h.opts.cropDensity(), Bands: h.opts.CropHeight}
+			return renderer.RenderPage(gctx, pdfPath, page.PageNumber, page.BannerPath, raster, nil)
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return fmt.Errorf("render pdf pages: %w", err)
+	}
+
+	return nil
 }
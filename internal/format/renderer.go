@@ -0,0 +1,158 @@
+package format
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/JaimeStill/herald/internal/metrics"
+	"github.com/JaimeStill/herald/internal/state"
+	"github.com/JaimeStill/herald/internal/tracing"
+)
+
+var tracer = otel.Tracer("github.com/JaimeStill/herald/internal/format")
+
+// Raster selects how a Renderer reads its source and which part of the page
+// it keeps. Density is the DPI a PDF page is rasterized at; raster sources
+// keep their native resolution. Scale resizes the result by a percentage;
+// zero keeps its size. Bands, when non-zero, keeps only the header and
+// footer bands of the page, each that fraction of its height, stacked into
+// one image with a gray rule between them.
+type Raster struct {
+	Density int
+	Scale   int
+	Bands   float64
+}
+
+// Renderer backend names. NewRenderer accepts magick and auto; native names
+// the in-process backend auto renders with first, which only rasterizes
+// scanned pages and so is not offered on its own.
+const (
+	RendererMagick = "magick"
+	RendererNative = "native"
+	RendererAuto   = "auto"
+)
+
+// Renderer rasterizes document pages to PNG. Both methods honor raster and,
+// when non-nil, apply settings in the same pass. Implementations are safe
+// for concurrent use; handlers render pages in parallel.
+type Renderer interface {
+	// ID returns the backend name, one of the Renderer* constants.
+	ID() string
+
+	// RenderPage rasterizes page (1-indexed) of the multi-page document at
+	// path, a PDF or a TIFF, to dst. raster.Density applies to PDF pages.
+	RenderPage(
+		ctx context.Context,
+		path string,
+		page int,
+		dst string,
+		raster Raster,
+		settings *state.EnhanceSettings,
+	) error
+
+	// RenderImage converts the raster image at src to a PNG at dst.
+	// raster.Density does not apply to raster sources.
+	RenderImage(
+		ctx context.Context,
+		src, dst string,
+		raster Raster,
+		settings *state.EnhanceSettings,
+	) error
+}
+
+// NewRenderer returns the renderer backend named by name: magick shells out
+// to the ImageMagick CLI, and auto takes the native fast path for scanned
+// pages and falls back to ImageMagick for every page it declines, such as
+// born-digital text and vector pages. Both need ImageMagick; a backend that
+// drops it awaits an in-process rasterizer that handles every page. An empty
+// name selects magick.
+func NewRenderer(name string) (Renderer, error) {
+	switch name {
+	case "", RendererMagick:
+		return MagickRenderer(), nil
+	case RendererAuto:
+		return FallbackRenderer(NativeRenderer(), MagickRenderer()), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRenderer, name)
+	}
+}
+
+type fallbackRenderer struct {
+	primary  Renderer
+	fallback Renderer
+}
+
+// FallbackRenderer returns a Renderer that renders with primary and retries
+// with fallback when primary reports ErrUnsupportedPage. Other errors are
+// returned as they are.
+func FallbackRenderer(primary, fallback Renderer) Renderer {
+	return &fallbackRenderer{primary: primary, fallback: fallback}
+}
+
+func (r *fallbackRenderer) ID() string { return RendererAuto }
+
+func (r *fallbackRenderer) RenderPage(
+	ctx context.Context,
+	path string,
+	page int,
+	dst string,
+	raster Raster,
+	settings *state.EnhanceSettings,
+) error {
+	err := r.primary.RenderPage(ctx, path, page, dst, raster, settings)
+	if errors.Is(err, ErrUnsupportedPage) {
+		return r.fallback.RenderPage(ctx, path, page, dst, raster, settings)
+	}
+	return err
+}
+
+func (r *fallbackRenderer) RenderImage(
+	ctx context.Context,
+	src, dst string,
+	raster Raster,
+	settings *state.EnhanceSettings,
+) error {
+	err := r.primary.RenderImage(ctx, src, dst, raster, settings)
+	if errors.Is(err, ErrUnsupportedPage) {
+		return r.fallback.RenderImage(ctx, src, dst, raster, settings)
+	}
+	return err
+}
+
+// observeRender runs one render as a format.render span and records its
+// duration in the render metrics, as an enhance when settings is non-nil
+// and a crop when raster keeps only the banner bands.
+func observeRender(
+	ctx context.Context,
+	backend string,
+	raster Raster,
+	settings *state.EnhanceSettings,
+	render func(context.Context) error,
+) error {
+	operation := metrics.RenderExtract
+	switch {
+	case settings != nil:
+		operation = metrics.RenderEnhance
+	case raster.Bands > 0:
+		operation = metrics.RenderCrop
+	}
+
+	ctx, span := tracer.Start(ctx, "format.render",
+		trace.WithAttributes(
+			attribute.String("format.render.operation", operation),
+			attribute.String("format.render.backend", backend),
+		),
+	)
+
+	start := time.Now()
+	err := render(ctx)
+	metrics.ObserveRender(operation, time.Since(start), err)
+	tracing.End(span, err)
+	return err
+}
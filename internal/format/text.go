@@ -0,0 +1,348 @@
+package format
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// maxPageText bounds the text kept for a page so a dense page cannot crowd
+// the classify prompt.
+const maxPageText = 8 << 10
+
+// pageTexts returns the text layer of each page of the PDF, in page order. A
+// page whose content cannot be read, or that has no text layer, yields "".
+func pageTexts(pdf *model.Context) []string {
+	texts := make([]string, pdf.PageCount)
+	for i := range texts {
+		r, err := pdfcpu.ExtractPageContent(pdf, i+1)
+		if err != nil {
+			continue
+		}
+		content, err := io.ReadAll(r)
+		if err != nil {
+			continue
+		}
+		texts[i] = ContentText(content)
+	}
+	return texts
+}
+
+// ContentText returns the text a PDF page content stream shows, one line per
+// text line, with runs of spaces collapsed and blank lines dropped. Strings
+// shown by the Tj, TJ, ', and " operators are kept; line moves and text
+// objects break lines, and wide TJ kerning is read as a space. Strings in
+// two-byte font encodings cannot be decoded without the font and are
+// skipped, as is text inside form XObjects. The result is truncated to
+// maxPageText bytes.
+func ContentText(content []byte) string {
+	s := &contentScanner{data: content}
+	s.scan()
+
+	var lines []string
+	for line := range strings.SplitSeq(s.text.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	text := strings.Join(lines, "\n")
+	if len(text) > maxPageText {
+		text = text[:maxPageText]
+		for !utf8.ValidString(text) {
+			text = text[:len(text)-1]
+		}
+	}
+	return text
+}
+
+// contentScanner walks a content stream's tokens, collecting the operands of
+// each operator and writing shown text to text. When visit is set, each
+// operator is passed to it instead of being read as text.
+type contentScanner struct {
+	data  []byte
+	pos   int
+	text  strings.Builder
+	visit func(name string, operands []operand)
+
+	operands []operand
+	array    []operand
+	inArray  bool
+}
+
+// operand is a string, name, or number operand of a content stream
+// operator. A name keeps its text, without the leading slash, in str.
+type operand struct {
+	str    string
+	num    float64
+	isText bool
+	isNum  bool
+	isName bool
+}
+
+func (s *contentScanner) scan() {
+	for s.pos < len(s.data) {
+		c := s.data[s.pos]
+		switch {
+		case isSpace(c):
+			s.pos++
+		case c == '%':
+			s.skipLine()
+		case c == '(':
+			s.push(s.literal())
+		case c == '<' && s.peek(1) == '<', c == '>' && s.peek(1) == '>':
+			s.pos += 2
+		case c == '<':
+			s.push(s.hexString())
+		case c == '[':
+			s.pos++
+			s.inArray, s.array = true, nil
+		case c == ']':
+			s.pos++
+			s.inArray = false
+		case c == '/':
+			s.pos++
+			s.push(operand{str: s.word(), isName: true})
+		case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+			n, _ := strconv.ParseFloat(s.word(), 64)
+			s.push(operand{num: n, isNum: true})
+		case isDelimiter(c):
+			s.pos++
+		default:
+			s.operator(s.word())
+		}
+	}
+}
+
+func (s *contentScanner) push(op operand) {
+	if s.inArray {
+		s.array = append(s.array, op)
+		return
+	}
+	s.operands = append(s.operands, op)
+}
+
+// operator applies an operator to the collected operands and clears them.
+func (s *contentScanner) operator(name string) {
+	if s.visit != nil {
+		s.visit(name, s.operands)
+	} else {
+		s.textOperator(name)
+	}
+	if name == "BI" {
+		s.skipInlineImage()
+	}
+	s.operands = s.operands[:0]
+	s.array = nil
+}
+
+// textOperator writes the text a text operator shows or the line break it
+// implies.
+func (s *contentScanner) textOperator(name string) {
+	switch name {
+	case "Tj":
+		s.show(s.operands)
+	case "'", "\"":
+		s.text.WriteByte('\n')
+		s.show(s.operands)
+	case "TJ":
+		s.showArray()
+	case "Td", "TD":
+		if len(s.operands) == 2 && s.operands[1].num != 0 {
+			s.text.WriteByte('\n')
+		} else {
+			s.text.WriteByte(' ')
+		}
+	case "T*", "Tm", "ET":
+		s.text.WriteByte('\n')
+	}
+}
+
+func (s *contentScanner) show(ops []operand) {
+	for _, op := range ops {
+		if op.isText {
+			s.text.WriteString(op.str)
+		}
+	}
+}
+
+// showArray writes the strings of a TJ array, reading wide negative kerning
+// between them as a word space.
+func (s *contentScanner) showArray() {
+	for _, op := range s.array {
+		switch {
+		case op.isText:
+			s.text.WriteString(op.str)
+		case op.isNum && op.num < -200:
+			s.text.WriteByte(' ')
+		}
+	}
+}
+
+// literal reads a parenthesized string, resolving escapes and nested
+// parentheses.
+func (s *contentScanner) literal() operand {
+	s.pos++
+	var raw []byte
+	depth := 1
+	for s.pos < len(s.data) {
+		c := s.data[s.pos]
+		s.pos++
+		switch c {
+		case '\\':
+			raw = s.escape(raw)
+			continue
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return textOperand(raw)
+			}
+		}
+		raw = append(raw, c)
+	}
+	return textOperand(raw)
+}
+
+func (s *contentScanner) escape(raw []byte) []byte {
+	if s.pos >= len(s.data) {
+		return raw
+	}
+	c := s.data[s.pos]
+	s.pos++
+	switch c {
+	case 'n':
+		return append(raw, '\n')
+	case 'r':
+		return append(raw, '\r')
+	case 't':
+		return append(raw, '\t')
+	case 'b', 'f':
+		return raw
+	case '\r':
+		if s.peek(0) == '\n' {
+			s.pos++
+		}
+		return raw
+	case '\n':
+		return raw
+	}
+	if c >= '0' && c <= '7' {
+		v := int(c - '0')
+		for range 2 {
+			d := s.peek(0)
+			if d < '0' || d > '7' {
+				break
+			}
+			v = v*8 + int(d-'0')
+			s.pos++
+		}
+		return append(raw, byte(v))
+	}
+	return append(raw, c)
+}
+
+// hexString reads a <...> string.
+func (s *contentScanner) hexString() operand {
+	s.pos++
+	var digits []byte
+	for s.pos < len(s.data) && s.data[s.pos] != '>' {
+		if c := s.data[s.pos]; !isSpace(c) {
+			digits = append(digits, c)
+		}
+		s.pos++
+	}
+	s.pos++
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	raw, err := hex.DecodeString(string(digits))
+	if err != nil {
+		return operand{}
+	}
+	return textOperand(raw)
+}
+
+// textOperand decodes raw as single-byte text. Strings holding NUL bytes are
+// two-byte glyph codes, which cannot be read without the font, and yield an
+// empty operand.
+func textOperand(raw []byte) operand {
+	if bytes.IndexByte(raw, 0) >= 0 {
+		return operand{}
+	}
+	var sb strings.Builder
+	for _, c := range raw {
+		switch {
+		case c == '\t' || c == '\r' || c == '\n':
+			sb.WriteByte(' ')
+		case c >= 0x20 && c < 0x7f:
+			sb.WriteByte(c)
+		case c >= 0xa0:
+			sb.WriteRune(rune(c))
+		}
+	}
+	return operand{str: sb.String(), isText: true}
+}
+
+// skipInlineImage moves past an inline image's binary data, which runs from
+// the ID operator to an EI operator set off by whitespace.
+func (s *contentScanner) skipInlineImage() {
+	i := bytes.Index(s.data[s.pos:], []byte("ID"))
+	if i < 0 {
+		s.pos = len(s.data)
+		return
+	}
+	s.pos += i + 2
+	for s.pos < len(s.data) {
+		i := bytes.Index(s.data[s.pos:], []byte("EI"))
+		if i < 0 {
+			s.pos = len(s.data)
+			return
+		}
+		at := s.pos + i
+		s.pos = at + 2
+		if isSpace(s.data[at-1]) && (s.pos == len(s.data) || isSpace(s.data[s.pos])) {
+			return
+		}
+	}
+}
+
+func (s *contentScanner) skipLine() {
+	for s.pos < len(s.data) && s.data[s.pos] != '\n' && s.data[s.pos] != '\r' {
+		s.pos++
+	}
+}
+
+// word reads a run of regular characters.
+func (s *contentScanner) word() string {
+	start := s.pos
+	for s.pos < len(s.data) && !isSpace(s.data[s.pos]) && !isDelimiter(s.data[s.pos]) {
+		s.pos++
+	}
+	if s.pos == start {
+		s.pos++
+	}
+	return string(s.data[start:s.pos])
+}
+
+func (s *contentScanner) peek(offset int) byte {
+	if s.pos+offset < len(s.data) {
+		return s.data[s.pos+offset]
+	}
+	return 0
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
@@ -0,0 +1,283 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/image/tiff"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/JaimeStill/herald/internal/state"
+	"github.com/JaimeStill/herald/pkg/core"
+)
+
+const sourceTIFF = "source.tiff"
+
+// maxTIFFFrames bounds the frames read from one TIFF so a corrupt or
+// hostile directory chain cannot produce an unbounded page list.
+const maxTIFFFrames = 10000
+
+type tiffHandler struct {
+	opts Options
+}
+
+// NewTIFFHandler returns a Handler that accepts image/tiff, including
+// multi-page TIFFs. Each frame becomes a page, rendered by the Renderer in
+// opts in parallel with bounded concurrency sized by core.WorkerCount.
+// Frames are treated as 300 DPI scans, as raw images are, so in banner crop
+// mode the page sent to the vision model is scaled to opts.PageDensity.
+func NewTIFFHandler(opts Options) Handler { return &tiffHandler{opts: opts} }
+
+func (h *tiffHandler) ID() string             { return "tiff" }
+func (h *tiffHandler) ContentTypes() []string { return []string{"image/tiff"} }
+
+// Extract writes the source TIFF to <tempDir>/source.tiff, walks its image
+// file directories to count frames, and renders each frame to
+// <tempDir>/page-N.png. In banner crop mode each frame's bands are written
+// to <tempDir>/page-N-banners.png.
+func (h *tiffHandler) Extract(
+	ctx context.Context,
+	src SourceReader,
+	tempDir string,
+) ([]state.ClassificationPage, error) {
+	tiffPath := filepath.Join(tempDir, sourceTIFF)
+
+	data, err := h.stage(ctx, src, tiffPath)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := TIFFFrameCount(data)
+	if err != nil {
+		return nil, fmt.Errorf("count frames: %w", err)
+	}
+
+	pages := make([]state.ClassificationPage, count)
+	render := make([]*state.ClassificationPage, count)
+	for i := range count {
+		pages[i] = state.ClassificationPage{PageNumber: i + 1}
+		render[i] = &pages[i]
+	}
+
+	if err := h.render(ctx, tiffPath, tempDir, render); err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+// Enhance re-renders the page's frame from <tempDir>/source.tiff at full
+// resolution with the supplied filter settings applied, writing
+// <tempDir>/page-N-enhanced.png.
+func (h *tiffHandler) Enhance(
+	ctx context.Context,
+	tempDir string,
+	page *state.ClassificationPage,
+	settings *state.EnhanceSettings,
+) (string, error) {
+	tiffPath := filepath.Join(tempDir, sourceTIFF)
+	imgPath := filepath.Join(tempDir, fmt.Sprintf("page-%d-enhanced.png", page.PageNumber))
+
+	if err := h.opts.renderer().RenderPage(
+		ctx,
+		tiffPath,
+		page.PageNumber,
+		imgPath,
+		Raster{},
+		settings,
+	); err != nil {
+		return "", fmt.Errorf("enhance frame %d: %w", page.PageNumber, err)
+	}
+
+	return imgPath, nil
+}
+
+// Restore writes the source TIFF to <tempDir>/source.tiff, so Enhance can
+// re-render from it, and renders only the given pages.
+func (h *tiffHandler) Restore(
+	ctx context.Context,
+	src SourceReader,
+	tempDir string,
+	pages []*state.ClassificationPage,
+) error {
+	tiffPath := filepath.Join(tempDir, sourceTIFF)
+
+	if _, err := h.stage(ctx, src, tiffPath); err != nil {
+		return err
+	}
+
+	return h.render(ctx, tiffPath, tempDir, pages)
+}
+
+// stage reads the TIFF from src and writes it to tiffPath, returning its
+// bytes.
+func (h *tiffHandler) stage(ctx context.Context, src SourceReader, tiffPath string) ([]byte, error) {
+	data, err := readAll(ctx, src)
+	if err != nil {
+		return nil, fmt.Errorf("read tiff source: %w", err)
+	}
+
+	if err := os.WriteFile(tiffPath, data, 0600); err != nil {
+		return nil, fmt.Errorf("write tiff source: %w", err)
+	}
+
+	return data, nil
+}
+
+// render rasterizes each page's frame of tiffPath to <tempDir>/page-N.png
+// in parallel and sets the page's ImagePath, and in banner crop mode also
+// its bands to <tempDir>/page-N-banners.png and the page's BannerPath.
+func (h *tiffHandler) render(
+	ctx context.Context,
+	tiffPath string,
+	tempDir string,
+	pages []*state.ClassificationPage,
+) error {
+	renderer := h.opts.renderer()
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(core.WorkerCount(len(pages)))
+
+	for _, page := range pages {
+		page.ImagePath = filepath.Join(tempDir, fmt.Sprintf("page-%d.png", page.PageNumber))
+
+		g.Go(func() error {
+			if gctx.Err() != nil {
+				return gctx.Err()
+			}
+			raster := Raster{Scale: scale(h.opts.pageDensity())}
+			return renderer.RenderPage(gctx, tiffPath, page.PageNumber, page.ImagePath, raster, nil)
+		})
+
+		if !h.opts.crops() {
+			continue
+		}
+
+		page.BannerPath = filepath.Join(tempDir, fmt.Sprintf("page-%d-banners.png", page.PageNumber))
+
+		g.Go(func() error {
+			if gctx.Err() != nil {
+				return gctx.Err()
+			}
+			raster := Raster{Scale: scale(h.opts.cropDensity()), Bands: h.opts.CropHeight}
+			return renderer.RenderPage(gctx, tiffPath, page.PageNumber, page.BannerPath, raster, nil)
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return fmt.Errorf("render tiff frames: %w", err)
+	}
+
+	return nil
+}
+
+// TIFFFrameCount returns the number of frames (pages) in the TIFF data.
+func TIFFFrameCount(data []byte) (int, error) {
+	_, ifds, err := tiffDirectories(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	return len(ifds), nil
+}
+
+// isTIFF reports whether header begins with a classic little- or big-endian
+// TIFF signature.
+func isTIFF(header []byte) bool {
+	return bytes.HasPrefix(header, []byte("II*\x00")) || bytes.HasPrefix(header, []byte("MM\x00*"))
+}
+
+// tiffDirectories walks the image file directory chain of a classic TIFF,
+// returning its byte order and the offset of each directory, one per frame.
+func tiffDirectories(r io.ReaderAt) (binary.ByteOrder, []uint32, error) {
+	var header [8]byte
+	if _, err := r.ReadAt(header[:], 0); err != nil {
+		return nil, nil, fmt.Errorf("%w: read header: %v", ErrInvalidTIFF, err)
+	}
+	if !isTIFF(header[:]) {
+		return nil, nil, fmt.Errorf("%w: bad signature", ErrInvalidTIFF)
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	if header[0] == 'M' {
+		order = binary.BigEndian
+	}
+
+	var ifds []uint32
+	seen := make(map[uint32]bool)
+	for offset := order.Uint32(header[4:]); offset != 0; {
+		if seen[offset] {
+			return nil, nil, fmt.Errorf("%w: directory chain loops at %d", ErrInvalidTIFF, offset)
+		}
+		if len(ifds) == maxTIFFFrames {
+			return nil, nil, fmt.Errorf("%w: more than %d frames", ErrInvalidTIFF, maxTIFFFrames)
+		}
+		seen[offset] = true
+		ifds = append(ifds, offset)
+
+		var count [2]byte
+		if _, err := r.ReadAt(count[:], int64(offset)); err != nil {
+			return nil, nil, fmt.Errorf("%w: read directory at %d: %v", ErrInvalidTIFF, offset, err)
+		}
+
+		var next [4]byte
+		nextAt := int64(offset) + 2 + 12*int64(order.Uint16(count[:]))
+		if _, err := r.ReadAt(next[:], nextAt); err != nil {
+			return nil, nil, fmt.Errorf("%w: read directory at %d: %v", ErrInvalidTIFF, offset, err)
+		}
+		offset = order.Uint32(next[:])
+	}
+
+	if len(ifds) == 0 {
+		return nil, nil, fmt.Errorf("%w: no frames", ErrInvalidTIFF)
+	}
+	return order, ifds, nil
+}
+
+// decodeTIFFFrame decodes frame n (1-indexed) of the TIFF read through r.
+// The tiff package decodes only the first directory, so it reads the file
+// through a view whose header points at frame n's directory instead.
+func decodeTIFFFrame(r io.ReaderAt, n int) (image.Image, error) {
+	order, ifds, err := tiffDirectories(r)
+	if err != nil {
+		return nil, err
+	}
+	if n < 1 || n > len(ifds) {
+		return nil, fmt.Errorf("frame %d out of range 1-%d", n, len(ifds))
+	}
+
+	frame := &tiffFrame{r: r}
+	if _, err := r.ReadAt(frame.header[:], 0); err != nil {
+		return nil, fmt.Errorf("%w: read header: %v", ErrInvalidTIFF, err)
+	}
+	order.PutUint32(frame.header[4:], ifds[n-1])
+
+	return tiff.Decode(frame)
+}
+
+// tiffFrame reads a TIFF with its header replaced, so the first directory
+// offset names the frame to decode.
+type tiffFrame struct {
+	r      io.ReaderAt
+	header [8]byte
+	pos    int64
+}
+
+func (f *tiffFrame) ReadAt(p []byte, off int64) (int, error) {
+	n, err := f.r.ReadAt(p, off)
+	if off < int64(len(f.header)) {
+		copy(p[:n], f.header[off:])
+	}
+	return n, err
+}
+
+func (f *tiffFrame) Read(p []byte) (int, error) {
+	n, err := f.ReadAt(p, f.pos)
+	f.pos += int64(n)
+	return n, err
+}
@@ -6,12 +6,17 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
+	"slices"
 	"sync"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 
+	"github.com/JaimeStill/herald/internal/agents"
 	"github.com/JaimeStill/herald/internal/config"
+	"github.com/JaimeStill/herald/internal/metrics"
+	"github.com/JaimeStill/herald/internal/tracing"
 	"github.com/JaimeStill/herald/pkg/database"
 	"github.com/JaimeStill/herald/pkg/lifecycle"
 	"github.com/JaimeStill/herald/pkg/storage"
@@ -40,29 +45,40 @@ func registerAgentBackends() {
 
 // Infrastructure holds the core systems required by all domain modules.
 // It provides a single point of initialization for lifecycle coordination,
-// logging, database access, file storage, and agent configuration.
+// logging, database access, file storage, and agent configuration. Agents
+// holds the default agent and every named agent; NewAgent creates the default.
 type Infrastructure struct {
 	Lifecycle  *lifecycle.Coordinator
 	Logger     *slog.Logger
 	Database   database.System
 	Storage    storage.System
 	Agent      tauconfig.AgentConfig
+	Agents     *agents.Registry
 	Credential azcore.TokenCredential
-	NewAgent   func(ctx context.Context) (agent.Agent, error)
+	NewAgent   agents.Factory
+	Tracing    *tracing.Provider
 }
 
 // New creates an Infrastructure from the application configuration.
 // It initializes all systems but does not start them; call Start separately.
-// Agent configuration is validated by constructing a single agent via
+// Each agent configuration is validated by constructing an agent via
 // provider.Create + format.Create + agent.New, which exercises the full
 // tau pipeline (factory lookup, option extraction, credential wiring) before
-// any request is served.
+// any request is served. The "replay" provider bypasses tau and answers from
+// recorded fixtures instead. Storage and agents are instrumented for the
+// Prometheus metrics, and the database pool's statistics are exported.
+// Storage is also traced when cfg.Tracing enables an exporter.
 func New(cfg *config.Config) (*Infrastructure, error) {
 	registerAgentBackends()
 
 	lc := lifecycle.New()
 	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
 
+	tp, err := tracing.New(&cfg.Tracing, cfg.Version, logger)
+	if err != nil {
+		return nil, fmt.Errorf("tracing init failed: %w", err)
+	}
+
 	cred, err := cfg.Auth.TokenCredential()
 	if err != nil {
 		return nil, fmt.Errorf("credential init failed: %w", err)
@@ -73,37 +89,135 @@ func New(cfg *config.Config) (*Infrastructure, error) {
 		return nil, err
 	}
 
-	agentCfg := cfg.Agent
-	newAgent := func(ctx context.Context) (agent.Agent, error) {
-		p, perr := provider.Create(agentCfg.Provider)
-		if perr != nil {
-			return nil, fmt.Errorf("create provider: %w", perr)
-		}
-		f, ferr := format.Create(agentCfg.Format)
-		if ferr != nil {
-			return nil, fmt.Errorf("create format: %w", ferr)
-		}
-		return agent.New(&agentCfg, p, f), nil
+	if err := metrics.RegisterDatabase(db.Connection()); err != nil {
+		return nil, fmt.Errorf("database metrics init failed: %w", err)
 	}
 
-	if _, err := newAgent(context.Background()); err != nil {
-		return nil, fmt.Errorf("agent validation failed: %w", err)
+	registry, err := NewAgentRegistry(cfg, logger)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Infrastructure{
 		Lifecycle:  lc,
 		Logger:     logger,
 		Database:   db,
-		Storage:    store,
+		Storage:    tracing.Storage(metrics.Storage(store)),
 		Agent:      cfg.Agent,
+		Agents:     registry,
 		Credential: cred,
-		NewAgent:   newAgent,
+		NewAgent:   registry.Default().New,
+		Tracing:    tp,
 	}, nil
 }
 
+// NewAgentFactory builds and validates the agent factory for the configured
+// provider without initializing any other system, for tools such as cmd/eval
+// that run the workflow outside the server. Replay agents serve fixtures from
+// cfg.Replay.Fixtures; live agents are wrapped in a recorder writing there
+// when cfg.Replay.Record is set. Every agent the factory creates shares one
+// throttle, which applies cfg.Throttle's rate limits and retries.
+func NewAgentFactory(cfg *config.Config, logger *slog.Logger) (agents.Factory, error) {
+	registerAgentBackends()
+
+	newAgent := agentFactory(cfg, cfg.Agent)
+	if _, err := newAgent(context.Background()); err != nil {
+		return nil, fmt.Errorf("agent validation failed: %w", err)
+	}
+	return newThrottle(&cfg.Throttle, logger).Wrap(newAgent), nil
+}
+
+// NewAgentRegistry builds and validates a factory for the default agent and
+// for every named agent in cfg.Agents. All of them share one throttle, whose
+// per-deployment limits are keyed by model name, and are instrumented for the
+// Prometheus metrics.
+func NewAgentRegistry(cfg *config.Config, logger *slog.Logger) (*agents.Registry, error) {
+	registerAgentBackends()
+
+	throttle := newThrottle(&cfg.Throttle, logger)
+
+	entry := func(name string, agentCfg tauconfig.AgentConfig) (agents.Entry, error) {
+		newAgent := agentFactory(cfg, agentCfg)
+		if _, err := newAgent(context.Background()); err != nil {
+			return agents.Entry{}, fmt.Errorf("agent %s validation failed: %w", name, err)
+		}
+		return agents.Entry{
+			Name:     name,
+			Model:    agentCfg.Model.Name,
+			Provider: agentCfg.Provider.Name,
+			New:      metrics.Agents(throttle.Wrap(newAgent)),
+		}, nil
+	}
+
+	def, err := entry(cfg.Agent.Name, cfg.Agent)
+	if err != nil {
+		return nil, err
+	}
+
+	others := make([]agents.Entry, 0, len(cfg.Agents))
+	for _, name := range slices.Sorted(maps.Keys(cfg.Agents)) {
+		e, err := entry(name, cfg.Agents[name])
+		if err != nil {
+			return nil, err
+		}
+		others = append(others, e)
+	}
+
+	return agents.NewRegistry(def, others...), nil
+}
+
+func newThrottle(cfg *config.ThrottleConfig, logger *slog.Logger) *agents.Throttle {
+	return agents.NewThrottle(
+		func(model string) agents.Limits {
+			return agents.Limits(cfg.Limits(model))
+		},
+		agents.Backoff{
+			MaxAttempts: cfg.MaxAttempts,
+			Min:         cfg.MinBackoffDuration(),
+			Max:         cfg.MaxBackoffDuration(),
+		},
+		logger,
+	)
+}
+
+func agentFactory(cfg *config.Config, agentCfg tauconfig.AgentConfig) agents.Factory {
+	if agentCfg.Provider != nil && agentCfg.Provider.Name == agents.ProviderReplay {
+		replay := agents.NewReplay(cfg.Replay.Fixtures, agentCfg.Model.Name, cfg.Replay.Fallback)
+		return func(ctx context.Context) (agents.Agent, error) {
+			return replay, nil
+		}
+	}
+
+	var recorder *agents.Recorder
+	if cfg.Replay.Record {
+		recorder = agents.NewRecorder(cfg.Replay.Fixtures)
+	}
+
+	return func(ctx context.Context) (agents.Agent, error) {
+		p, perr := provider.Create(agentCfg.Provider)
+		if perr != nil {
+			return nil, fmt.Errorf("create provider: %w", perr)
+		}
+		f, ferr := format.Create(agentCfg.Format)
+		if ferr != nil {
+			return nil, fmt.Errorf("create format: %w", ferr)
+		}
+
+		a := agents.Wrap(agent.New(&agentCfg, p, f))
+		if recorder != nil {
+			a = recorder.Wrap(a)
+		}
+		return a, nil
+	}
+}
+
 // Start registers all infrastructure systems with the lifecycle coordinator.
-// Database and storage hooks are registered for startup and shutdown coordination.
+// Database and storage hooks are registered for startup and shutdown coordination,
+// along with the tracer flush on shutdown.
 func (i *Infrastructure) Start() error {
+	if err := i.Tracing.Start(i.Lifecycle); err != nil {
+		return fmt.Errorf("tracing start failed: %w", err)
+	}
 	if err := i.Database.Start(i.Lifecycle); err != nil {
 		return fmt.Errorf("database start failed: %w", err)
 	}
@@ -145,7 +259,12 @@ func initManagedSystems(
 		return nil, nil, fmt.Errorf("database credential init failed: %w", err)
 	}
 
-	store, err := storage.NewWithCredential(&cfg.Storage, cred, logger)
+	var store storage.System
+	if cfg.Storage.Backend == storage.BackendAzure {
+		store, err = storage.NewWithCredential(&cfg.Storage, cred, logger)
+	} else {
+		store, err = storage.New(&cfg.Storage, logger)
+	}
 	if err != nil {
 		return nil, nil, fmt.Errorf("storage credential init failed: %w", err)
 	}
@@ -0,0 +1,7 @@
+package markings
+
+import "errors"
+
+// ErrInvalidMarking indicates a marking string that does not parse against the
+// taxonomy. Parse errors wrap it with the offending token.
+var ErrInvalidMarking = errors.New("invalid classification marking")
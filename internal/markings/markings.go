@@ -0,0 +1,254 @@
+// Package markings parses CAPCO-style classification banner strings into their
+// structured parts and renders them in a canonical form, so that spellings such
+// as "SECRET//NOFORN", "Secret // NOFORN", and "S//NF" compare equal. The set of
+// recognized levels and controls is defined by a configurable Taxonomy.
+package markings
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Marking is a parsed banner marking. Every field holds canonical spellings in
+// taxonomy order. ReleasableTo lists the countries of a REL TO control, USA
+// first, and Canonical is the normalized banner string.
+type Marking struct {
+	Canonical        string   `json:"canonical"`
+	Level            string   `json:"level"`
+	SCI              []string `json:"sci"`
+	SAP              []string `json:"sap"`
+	Dissemination    []string `json:"dissemination"`
+	ReleasableTo     []string `json:"releasable_to"`
+	Declassification string   `json:"declassification,omitempty"`
+}
+
+// String renders the marking as LEVEL//SCI//SAP//DISSEM//DECLASS, omitting
+// empty segments.
+func (m Marking) String() string {
+	segments := []string{m.Level}
+	if len(m.SCI) > 0 {
+		segments = append(segments, strings.Join(m.SCI, "/"))
+	}
+	if len(m.SAP) > 0 {
+		segments = append(segments, strings.Join(m.SAP, "/"))
+	}
+	if len(m.Dissemination) > 0 {
+		segments = append(segments, strings.Join(m.Dissemination, "/"))
+	}
+	if m.Declassification != "" {
+		segments = append(segments, m.Declassification)
+	}
+	return strings.Join(segments, "//")
+}
+
+var (
+	separatorPattern = regexp.MustCompile(`\s*/\s*`)
+	commaPattern     = regexp.MustCompile(`\s*,\s*`)
+	countryPattern   = regexp.MustCompile(`^[A-Z]{3,4}$`)
+	datePattern      = regexp.MustCompile(`^\d{8}$`)
+	declassPattern   = regexp.MustCompile(`^(25X[1-9](, 25X[1-9])*|50X1-HUM|50X2-WMD|MR)$`)
+)
+
+// Parser normalizes banner markings against a Taxonomy. It is safe for
+// concurrent use.
+type Parser struct {
+	levels      map[string]int
+	levelNames  []string
+	sci         map[string]int
+	sciNames    []string
+	dissem      map[string]int
+	dissemNames []string
+	relTo       int
+}
+
+// NewParser builds a Parser for t. The taxonomy should already be finalized;
+// when a spelling appears more than once the last term wins.
+func NewParser(t Taxonomy) *Parser {
+	p := &Parser{relTo: -1}
+	p.levels, p.levelNames = index(t.Levels)
+	p.sci, p.sciNames = index(t.SCI)
+	p.dissem, p.dissemNames = index(t.Dissemination)
+	if i, ok := p.dissem[RelTo]; ok {
+		p.relTo = i
+	}
+	return p
+}
+
+func index(terms []Term) (map[string]int, []string) {
+	lookup := make(map[string]int)
+	names := make([]string, len(terms))
+	for i, term := range terms {
+		names[i] = normalizeSpace(strings.ToUpper(term.Name))
+		lookup[names[i]] = i
+		for _, alias := range term.Aliases {
+			lookup[normalizeSpace(strings.ToUpper(alias))] = i
+		}
+	}
+	return lookup, names
+}
+
+// Rank returns the position of a canonical level in the taxonomy, where a
+// higher rank is more restrictive. It returns -1 for unknown levels.
+func (p *Parser) Rank(level string) int {
+	if i, ok := p.levels[normalizeSpace(strings.ToUpper(level))]; ok {
+		return i
+	}
+	return -1
+}
+
+// Levels returns the canonical level names from least to most restrictive.
+func (p *Parser) Levels() []string {
+	return slices.Clone(p.levelNames)
+}
+
+// Parse normalizes raw into a Marking. Case, spacing around separators, and
+// enclosing parentheses are ignored, and aliases resolve to canonical terms.
+// Errors wrap ErrInvalidMarking and describe the first token that failed.
+func (p *Parser) Parse(raw string) (Marking, error) {
+	s := normalizeSpace(strings.ToUpper(raw))
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "("), ")")
+	s = separatorPattern.ReplaceAllString(s, "/")
+	s = commaPattern.ReplaceAllString(s, ", ")
+
+	if s == "" {
+		return Marking{}, fmt.Errorf("%w: empty marking", ErrInvalidMarking)
+	}
+
+	segments := strings.Split(s, "//")
+
+	level, ok := p.levels[segments[0]]
+	if !ok {
+		return Marking{}, fmt.Errorf("%w: unknown classification level %q", ErrInvalidMarking, segments[0])
+	}
+
+	var (
+		sci       = map[int][]string{}
+		sap       []string
+		dissem    = map[int]bool{}
+		countries []string
+		declass   string
+	)
+
+	for _, segment := range segments[1:] {
+		for token := range strings.SplitSeq(segment, "/") {
+			switch {
+			case token == "":
+				return Marking{}, fmt.Errorf("%w: empty control in %q", ErrInvalidMarking, raw)
+			case strings.HasPrefix(token, "SAR-"), strings.HasPrefix(token, "SPECIAL ACCESS REQUIRED-"):
+				_, program, _ := strings.Cut(token, "-")
+				if program == "" {
+					return Marking{}, fmt.Errorf("%w: special access program without a name", ErrInvalidMarking)
+				}
+				sap = appendUnique(sap, "SAR-"+program)
+			case p.relTo >= 0 && (strings.HasPrefix(token, RelTo+" ") || strings.HasPrefix(token, "REL ")):
+				list := strings.TrimPrefix(strings.TrimPrefix(token, RelTo+" "), "REL ")
+				for country := range strings.SplitSeq(list, ", ") {
+					if !countryPattern.MatchString(country) {
+						return Marking{}, fmt.Errorf("%w: invalid REL TO country %q", ErrInvalidMarking, country)
+					}
+					countries = appendUnique(countries, country)
+				}
+				dissem[p.relTo] = true
+			case datePattern.MatchString(token):
+				if _, err := time.Parse("20060102", token); err != nil {
+					return Marking{}, fmt.Errorf("%w: invalid declassification date %q", ErrInvalidMarking, token)
+				}
+				declass = token
+			case declassPattern.MatchString(token):
+				declass = token
+			default:
+				if i, suffix, ok := p.sciControl(token); ok {
+					sci[i] = appendUnique(sci[i], p.sciNames[i]+suffix)
+					continue
+				}
+				if i, ok := p.dissem[token]; ok && i != p.relTo {
+					dissem[i] = true
+					continue
+				}
+				return Marking{}, fmt.Errorf("%w: unrecognized control %q", ErrInvalidMarking, token)
+			}
+		}
+	}
+
+	m := Marking{
+		Level:            p.levelNames[level],
+		SCI:              []string{},
+		SAP:              sap,
+		Dissemination:    []string{},
+		ReleasableTo:     orderCountries(countries),
+		Declassification: declass,
+	}
+	if m.SAP == nil {
+		m.SAP = []string{}
+	}
+
+	for i := range p.sciNames {
+		m.SCI = append(m.SCI, sci[i]...)
+	}
+	for i, name := range p.dissemNames {
+		if !dissem[i] {
+			continue
+		}
+		if i == p.relTo {
+			name = RelTo + " " + strings.Join(m.ReleasableTo, ", ")
+		}
+		m.Dissemination = append(m.Dissemination, name)
+	}
+
+	// The least restrictive level is unclassified.
+	if level == 0 && (len(m.SCI) > 0 || len(m.SAP) > 0) {
+		return Marking{}, fmt.Errorf("%w: %s cannot carry SCI or SAP controls", ErrInvalidMarking, m.Level)
+	}
+	if p.relTo >= 0 && dissem[p.relTo] {
+		if i, ok := p.dissem["NOFORN"]; ok && dissem[i] {
+			return Marking{}, fmt.Errorf("%w: NOFORN conflicts with REL TO", ErrInvalidMarking)
+		}
+	}
+
+	m.Canonical = m.String()
+	return m, nil
+}
+
+// sciControl resolves an SCI token to its control and any compartment
+// suffix, such as "-G ABCD" in "SI-G ABCD".
+func (p *Parser) sciControl(token string) (int, string, bool) {
+	if i, ok := p.sci[token]; ok {
+		return i, "", true
+	}
+	if j := strings.IndexAny(token, "- "); j > 0 {
+		if i, ok := p.sci[token[:j]]; ok {
+			return i, token[j:], true
+		}
+	}
+	return 0, "", false
+}
+
+// orderCountries places USA first followed by the remaining codes in
+// alphabetical order, as REL TO lists are written.
+func orderCountries(countries []string) []string {
+	out := []string{}
+	rest := []string{}
+	for _, c := range countries {
+		if c == "USA" {
+			out = append(out, c)
+		} else {
+			rest = append(rest, c)
+		}
+	}
+	slices.Sort(rest)
+	return append(out, rest...)
+}
+
+func appendUnique(list []string, value string) []string {
+	if slices.Contains(list, value) {
+		return list
+	}
+	return append(list, value)
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,142 @@
+package markings
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// RollUp derives the overall marking for a document from the markings found on
+// its pages: the highest level wins, SCI, SAP, and dissemination controls are
+// unioned, and the most restrictive declassification instruction is kept.
+// NOFORN on any page overrides REL TO; otherwise REL TO keeps only the
+// countries every REL TO page shares, falling back to NOFORN when none remain.
+// Markings that do not parse are skipped. It reports false when none parse.
+func (p *Parser) RollUp(raw []string) (Marking, bool) {
+	var parsed []Marking
+	for _, r := range raw {
+		if m, err := p.Parse(r); err == nil {
+			parsed = append(parsed, m)
+		}
+	}
+	if len(parsed) == 0 {
+		return Marking{}, false
+	}
+
+	level := parsed[0].Level
+	var (
+		controls []string
+		relTo    []string
+		released bool
+		noforn   bool
+		declass  string
+	)
+
+	for _, m := range parsed {
+		if p.Rank(m.Level) > p.Rank(level) {
+			level = m.Level
+		}
+		for _, c := range slices.Concat(m.SCI, m.SAP, m.Dissemination) {
+			switch {
+			case strings.HasPrefix(c, RelTo+" "):
+				if !released {
+					relTo, released = m.ReleasableTo, true
+				} else {
+					relTo = slices.DeleteFunc(slices.Clone(relTo), func(country string) bool {
+						return !slices.Contains(m.ReleasableTo, country)
+					})
+				}
+			case c == "NOFORN":
+				noforn = true
+			default:
+				controls = appendUnique(controls, c)
+			}
+		}
+		declass = restrictiveDeclass(declass, m.Declassification)
+	}
+
+	_, hasNoforn := p.dissem["NOFORN"]
+	switch {
+	case released && !noforn && len(relTo) > 1:
+		controls = append(controls, RelTo+" "+strings.Join(relTo, ", "))
+	case (released || noforn) && hasNoforn:
+		controls = append(controls, "NOFORN")
+	}
+
+	segments := []string{level}
+	if len(controls) > 0 {
+		segments = append(segments, strings.Join(controls, "/"))
+	}
+	if declass != "" {
+		segments = append(segments, declass)
+	}
+
+	m, err := p.Parse(strings.Join(segments, "//"))
+	if err != nil {
+		return Marking{}, false
+	}
+	return m, true
+}
+
+// restrictiveDeclass returns the more restrictive of two declassification
+// instructions. Exemptions outrank dates and the later date outranks the
+// earlier; between two exemptions the first is kept.
+func restrictiveDeclass(current, next string) string {
+	switch {
+	case next == "":
+		return current
+	case current == "":
+		return next
+	}
+
+	currentDate := datePattern.MatchString(current)
+	nextDate := datePattern.MatchString(next)
+
+	switch {
+	case currentDate && nextDate:
+		return max(current, next)
+	case currentDate:
+		return next
+	default:
+		return current
+	}
+}
+
+// Differences lists the parts of got that disagree with want, one entry per
+// differing part. Declassification is compared only when got carries one, so
+// an answer that omits it is not counted against it. An empty result means the
+// markings agree.
+func Differences(want, got Marking) []string {
+	var diffs []string
+
+	add := func(part string, equal bool, w, g string) {
+		if !equal {
+			diffs = append(diffs, fmt.Sprintf("%s: expected %s, got %s", part, orNone(w), orNone(g)))
+		}
+	}
+
+	add("level", want.Level == got.Level, want.Level, got.Level)
+	add("sci", slices.Equal(want.SCI, got.SCI), strings.Join(want.SCI, "/"), strings.Join(got.SCI, "/"))
+	add("sap", slices.Equal(want.SAP, got.SAP), strings.Join(want.SAP, "/"), strings.Join(got.SAP, "/"))
+	add("dissemination",
+		slices.Equal(want.Dissemination, got.Dissemination),
+		strings.Join(want.Dissemination, "/"),
+		strings.Join(got.Dissemination, "/"),
+	)
+	if got.Declassification != "" {
+		add("declassification",
+			want.Declassification == got.Declassification,
+			want.Declassification,
+			got.Declassification,
+		)
+	}
+
+	return diffs
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,118 @@
+package markings
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RelTo is the dissemination control term that carries a country list. Its
+// position in Taxonomy.Dissemination orders it among the other controls.
+const RelTo = "REL TO"
+
+// Term is a canonical marking token with the alternate spellings that
+// normalize to it, such as "NF" for "NOFORN".
+type Term struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// Taxonomy lists the tokens the parser recognizes. Levels are ordered from
+// least to most restrictive, which defines Rank; the first level is treated as
+// unclassified and may not carry SCI or SAP controls. SCI and Dissemination
+// order the controls within their banner segments. Empty lists fall back to
+// the defaults when the taxonomy is finalized.
+type Taxonomy struct {
+	Levels        []Term `json:"levels"`
+	SCI           []Term `json:"sci"`
+	Dissemination []Term `json:"dissemination"`
+}
+
+// DefaultTaxonomy returns the US classification levels with the SCI and
+// dissemination controls most common in CAPCO banner markings.
+func DefaultTaxonomy() Taxonomy {
+	return Taxonomy{
+		Levels: []Term{
+			{Name: "UNCLASSIFIED", Aliases: []string{"U", "UNCLASS"}},
+			{Name: "CONFIDENTIAL", Aliases: []string{"C"}},
+			{Name: "SECRET", Aliases: []string{"S"}},
+			{Name: "TOP SECRET", Aliases: []string{"TS"}},
+		},
+		SCI: []Term{
+			{Name: "HCS"},
+			{Name: "KDK", Aliases: []string{"KLONDIKE"}},
+			{Name: "RSV", Aliases: []string{"RESERVE"}},
+			{Name: "SI"},
+			{Name: "TK"},
+		},
+		Dissemination: []Term{
+			{Name: "RSEN"},
+			{Name: "FOUO", Aliases: []string{"FOR OFFICIAL USE ONLY"}},
+			{Name: "ORCON", Aliases: []string{"OC", "ORIGINATOR CONTROLLED"}},
+			{Name: "IMCON", Aliases: []string{"CONTROLLED IMAGERY"}},
+			{Name: "NOFORN", Aliases: []string{"NF", "NOT RELEASABLE TO FOREIGN NATIONALS"}},
+			{Name: "PROPIN", Aliases: []string{"PR", "CAUTION-PROPRIETARY INFORMATION INVOLVED"}},
+			{Name: RelTo},
+			{Name: "RELIDO"},
+			{Name: "FISA"},
+			{Name: "LIMDIS", Aliases: []string{"LIMITED DISTRIBUTION"}},
+		},
+	}
+}
+
+// Merge overwrites each non-empty list from overlay.
+func (t *Taxonomy) Merge(overlay *Taxonomy) {
+	if len(overlay.Levels) > 0 {
+		t.Levels = overlay.Levels
+	}
+	if len(overlay.SCI) > 0 {
+		t.SCI = overlay.SCI
+	}
+	if len(overlay.Dissemination) > 0 {
+		t.Dissemination = overlay.Dissemination
+	}
+}
+
+// Finalize fills empty lists from DefaultTaxonomy and validates the result.
+func (t *Taxonomy) Finalize() error {
+	defaults := DefaultTaxonomy()
+	if len(t.Levels) == 0 {
+		t.Levels = defaults.Levels
+	}
+	if len(t.SCI) == 0 {
+		t.SCI = defaults.SCI
+	}
+	if len(t.Dissemination) == 0 {
+		t.Dissemination = defaults.Dissemination
+	}
+	return t.validate()
+}
+
+// validate rejects blank names and any spelling that maps to two terms within
+// a category, which would make normalization ambiguous.
+func (t *Taxonomy) validate() error {
+	categories := []struct {
+		name  string
+		terms []Term
+	}{
+		{"levels", t.Levels},
+		{"sci", t.SCI},
+		{"dissemination", t.Dissemination},
+	}
+
+	for _, c := range categories {
+		seen := make(map[string]string)
+		for _, term := range c.terms {
+			if strings.TrimSpace(term.Name) == "" {
+				return fmt.Errorf("%s: term name required", c.name)
+			}
+			for _, spelling := range append([]string{term.Name}, term.Aliases...) {
+				key := normalizeSpace(strings.ToUpper(spelling))
+				if prev, ok := seen[key]; ok && prev != term.Name {
+					return fmt.Errorf("%s: %q maps to both %s and %s", c.name, spelling, prev, term.Name)
+				}
+				seen[key] = term.Name
+			}
+		}
+	}
+	return nil
+}
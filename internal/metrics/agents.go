@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/JaimeStill/herald/internal/agents"
+)
+
+// Agents returns a factory whose agents record the latency and outcome of
+// every vision call.
+func Agents(newAgent agents.Factory) agents.Factory {
+	return func(ctx context.Context) (agents.Agent, error) {
+		a, err := newAgent(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &instrumentedAgent{Agent: a}, nil
+	}
+}
+
+type instrumentedAgent struct {
+	agents.Agent
+}
+
+func (a *instrumentedAgent) Vision(ctx context.Context, prompt string, image []byte) (agents.Response, error) {
+	start := time.Now()
+	resp, err := a.Agent.Vision(ctx, prompt, image)
+	observeVision(a.Model(), time.Since(start), err)
+	return resp, err
+}
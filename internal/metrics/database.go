@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+var (
+	dbMu    sync.Mutex
+	dbStats prometheus.Collector
+)
+
+// RegisterDatabase exports the connection pool statistics of db as the
+// go_sql_* metrics with db_name="herald". A later call replaces the
+// previously registered pool.
+func RegisterDatabase(db *sql.DB) error {
+	dbMu.Lock()
+	defer dbMu.Unlock()
+
+	if dbStats != nil {
+		registry.Unregister(dbStats)
+	}
+
+	dbStats = collectors.NewDBStatsCollector(db, namespace)
+	return registry.Register(dbStats)
+}
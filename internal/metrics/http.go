@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RouteUnmatched labels requests that matched no registered route.
+const RouteUnmatched = "unmatched"
+
+// HTTP returns middleware that records request durations per route. The
+// route label is the ServeMux pattern the request matched, as registered
+// from the module's routes.Groups, prefixed with the module prefix so the
+// label reads as the public path template. The middleware must be the
+// innermost of the module's stack: the mux sets the matched pattern on the
+// request it receives, so any middleware that replaces the request (such as
+// Auth) has to run outside it.
+func HTTP(prefix string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(rw, r)
+
+			route := RouteUnmatched
+			if _, path, ok := strings.Cut(r.Pattern, " "); ok {
+				route = prefix + path
+			} else if r.Pattern != "" {
+				route = prefix + r.Pattern
+			}
+
+			httpDuration.
+				WithLabelValues(r.Method, route, strconv.Itoa(rw.status)).
+				Observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// statusWriter captures the response status. It implements http.Flusher so
+// SSE handlers that type-assert for it keep streaming.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,130 @@
+// Package metrics exposes Herald's Prometheus metrics. Collectors are
+// package-level and registered on a private registry served by Handler, so
+// any package can record into them without threading a metrics object
+// through its constructors. Subsystems from pkg/ that cannot import this
+// package are instrumented by wrapping them at composition time.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "herald"
+
+// Outcome label values.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Render operation label values. Extract is the first rasterization of a
+// page; Enhance is a re-render with enhancement filters applied; Crop renders
+// the header and footer bands of a page in banner crop mode.
+const (
+	RenderExtract = "extract"
+	RenderEnhance = "enhance"
+	RenderCrop    = "crop"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "API request duration by method, route pattern, and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	nodeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "workflow",
+		Name:      "node_duration_seconds",
+		Help:      "Classification workflow node duration, including failed runs.",
+		Buckets:   []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600},
+	}, []string{"node"})
+
+	nodeErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "workflow",
+		Name:      "node_errors_total",
+		Help:      "Classification workflow node failures.",
+	}, []string{"node"})
+
+	visionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "agent",
+		Name:      "vision_call_duration_seconds",
+		Help:      "Vision model call latency by model and outcome.",
+		Buckets:   []float64{0.5, 1, 2.5, 5, 10, 20, 30, 60, 120},
+	}, []string{"model", "outcome"})
+
+	renderDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "render",
+		Name:      "duration_seconds",
+		Help:      "ImageMagick page render duration by operation and outcome.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"operation", "outcome"})
+
+	storageBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "storage",
+		Name:      "bytes_total",
+		Help:      "Blob bytes transferred by operation (upload or download).",
+	}, []string{"operation"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpDuration,
+		nodeDuration,
+		nodeErrors,
+		visionDuration,
+		renderDuration,
+		storageBytes,
+	)
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// ObserveNode records one workflow node execution.
+func ObserveNode(node string, d time.Duration, failed bool) {
+	nodeDuration.WithLabelValues(node).Observe(d.Seconds())
+	if failed {
+		nodeErrors.WithLabelValues(node).Inc()
+	}
+}
+
+// ObserveRender records one ImageMagick render.
+func ObserveRender(operation string, d time.Duration, err error) {
+	renderDuration.WithLabelValues(operation, outcome(err)).Observe(d.Seconds())
+}
+
+func observeVision(model string, d time.Duration, err error) {
+	visionDuration.WithLabelValues(model, outcome(err)).Observe(d.Seconds())
+}
+
+func addStorageBytes(operation string, n int64) {
+	if n > 0 {
+		storageBytes.WithLabelValues(operation).Add(float64(n))
+	}
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return OutcomeError
+	}
+	return OutcomeSuccess
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"context"
+	"io"
+
+	"github.com/JaimeStill/herald/pkg/storage"
+)
+
+// Storage operation label values.
+const (
+	StorageUpload   = "upload"
+	StorageDownload = "download"
+)
+
+// Storage returns s with the bytes of every upload and download counted.
+// Bytes are counted as they stream, so partial transfers are included.
+func Storage(s storage.System) storage.System {
+	return &instrumentedStorage{System: s}
+}
+
+type instrumentedStorage struct {
+	storage.System
+}
+
+func (s *instrumentedStorage) Upload(ctx context.Context, key string, reader io.Reader, contentType string) error {
+	cr := &countingReader{Reader: reader}
+	err := s.System.Upload(ctx, key, cr, contentType)
+	addStorageBytes(StorageUpload, cr.n)
+	return err
+}
+
+func (s *instrumentedStorage) Download(ctx context.Context, key string) (*storage.BlobResult, error) {
+	result, err := s.System.Download(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	result.Body = &countingBody{ReadCloser: result.Body}
+	return result, nil
+}
+
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// countingBody reports downloaded bytes as they are read, since callers
+// may stop reading before EOF.
+type countingBody struct {
+	io.ReadCloser
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	addStorageBytes(StorageDownload, int64(n))
+	return n, err
+}
@@ -3,7 +3,12 @@
 // named prompt instruction overrides per workflow stage.
 package prompts
 
-import "github.com/google/uuid"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/google/uuid"
+)
 
 // Prompt represents a named instruction override for a workflow stage.
 type Prompt struct {
@@ -30,3 +35,29 @@ type UpdateCommand struct {
 	Instructions string  `json:"instructions"`
 	Description  *string `json:"description"`
 }
+
+// Version identifies the prompt text a workflow stage runs with. PromptID and
+// Name are set when an active override supplies the instructions and nil when
+// the built-in defaults are used. Digest is the SHA-256 of the instructions and
+// specification, so edits to an override produce a new version.
+type Version struct {
+	Stage    Stage      `json:"stage"`
+	PromptID *uuid.UUID `json:"prompt_id"`
+	Name     *string    `json:"name"`
+	Digest   string     `json:"digest"`
+}
+
+// NewVersion builds the Version for a stage from its effective instructions
+// and specification. active is the override in effect, or nil for defaults.
+func NewVersion(stage Stage, active *Prompt, instructions, spec string) Version {
+	sum := sha256.Sum256([]byte(instructions + "\n\n" + spec))
+	v := Version{
+		Stage:  stage,
+		Digest: hex.EncodeToString(sum[:]),
+	}
+	if active != nil {
+		v.PromptID = &active.ID
+		v.Name = &active.Name
+	}
+	return v
+}
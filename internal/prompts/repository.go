@@ -100,6 +100,44 @@ func (r *repo) Spec(ctx context.Context, stage Stage) (string, error) {
 	return Spec(stage)
 }
 
+func (r *repo) Versions(ctx context.Context) ([]Version, error) {
+	q, args := query.NewBuilder(projection).
+		WhereEquals("Active", true).
+		Build()
+
+	active, err := repository.QueryMany(ctx, r.db, q, args, scanPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("query active prompts: %w", err)
+	}
+
+	versions := make([]Version, 0, len(stages))
+	for _, stage := range stages {
+		var override *Prompt
+		for i := range active {
+			if active[i].Stage == stage {
+				override = &active[i]
+				break
+			}
+		}
+
+		instructions := ""
+		if override != nil {
+			instructions = override.Instructions
+		} else if instructions, err = Instructions(stage); err != nil {
+			return nil, err
+		}
+
+		spec, err := Spec(stage)
+		if err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, NewVersion(stage, override, instructions, spec))
+	}
+
+	return versions, nil
+}
+
 func (r *repo) Create(ctx context.Context, cmd CreateCommand) (*Prompt, error) {
 	q := `
 		INSERT INTO prompts(name, stage, instructions, description)
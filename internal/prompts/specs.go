@@ -83,7 +83,9 @@ Behavioral constraints:
 - Apply the highest classification encountered across all pages
 - Never downgrade based on pages with lower or missing markings
 - Confidence reflects the overall clarity and consistency across all pages,
-  not just the most recent page analyzed`
+  not just the most recent page analyzed
+- Pages listed in failed_pages could not be analyzed; never infer their
+  markings, and name them in the rationale as unreviewed`
 
 var specs = map[Stage]string{
 	StageClassify: classifySpec,
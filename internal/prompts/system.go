@@ -22,6 +22,9 @@ type System interface {
 	Instructions(ctx context.Context, stage Stage) (string, error)
 	Spec(ctx context.Context, stage Stage) (string, error)
 
+	// Versions reports the prompt version currently in effect for every stage.
+	Versions(ctx context.Context) ([]Version, error)
+
 	Create(ctx context.Context, cmd CreateCommand) (*Prompt, error)
 	Update(ctx context.Context, id uuid.UUID, cmd UpdateCommand) (*Prompt, error)
 	Delete(ctx context.Context, id uuid.UUID) error
@@ -16,11 +16,19 @@ const (
 	KeyTempDir = "temp_dir"
 	// KeyFilename carries the original uploaded filename.
 	KeyFilename = "filename"
+	// KeyDigest carries the hex SHA-256 of the source file's content.
+	KeyDigest = "digest"
 	// KeyPageCount carries the int count of pages produced during extraction.
 	KeyPageCount = "page_count"
 	// KeyClassState carries the ClassificationState value accumulated across
 	// workflow nodes (init, classify, enhance, finalize).
 	KeyClassState = "classification_state"
+	// KeyRunID carries a uuid.UUID identifying the run, under which progress
+	// is checkpointed.
+	KeyRunID = "run_id"
+	// KeyClassifiedPages carries the []int page numbers a resumed run's
+	// classify node already completed before the run was interrupted.
+	KeyClassifiedPages = "classified_pages"
 )
 
 // Confidence represents a categorical assessment of classification certainty.
@@ -33,6 +41,25 @@ const (
 	ConfidenceLow    Confidence = "LOW"
 )
 
+// AtOrBelow reports whether c is no more certain than threshold. A
+// confidence outside the known levels ranks below LOW.
+func (c Confidence) AtOrBelow(threshold Confidence) bool {
+	return c.rank() <= threshold.rank()
+}
+
+func (c Confidence) rank() int {
+	switch c {
+	case ConfidenceLow:
+		return 1
+	case ConfidenceMedium:
+		return 2
+	case ConfidenceHigh:
+		return 3
+	default:
+		return 0
+	}
+}
+
 // EnhanceSettings captures the semantic intent of a page-level enhancement
 // pass — what kind of visual adjustment to apply — without encoding how any
 // particular rasterizer consumes them. Pointer fields distinguish "not set"
@@ -45,15 +72,64 @@ type EnhanceSettings struct {
 	Saturation *int `json:"saturation,omitempty"`
 }
 
+// Usage tallies the model calls made during classification and the tokens
+// they consumed. Token counts are zero when the provider does not report them.
+type Usage struct {
+	VisionCalls      int `json:"vision_calls"`
+	ChatCalls        int `json:"chat_calls"`
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// Add accumulates other into u.
+func (u *Usage) Add(other Usage) {
+	u.VisionCalls += other.VisionCalls
+	u.ChatCalls += other.ChatCalls
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+}
+
+// TotalTokens returns the sum of prompt and completion tokens.
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
 // ClassificationPage holds per-page data accumulated during classification.
-// ImagePath references the rendered page image in a temp directory.
+// ImagePath references the rendered page image in a temp directory, and
+// BannerPath, when the format handler runs in banner crop mode, an image of
+// just the page's header and footer bands. BannersOnly is set when the page
+// was classified from its banner crop without the full page image.
 // Enhance signals that this page should be re-rendered with adjusted settings.
+// EnhancedWith records the settings a completed enhancement pass applied, so the
+// history survives clearing the Enhancements flag. Usage counts the vision
+// calls made for the page across the classify and enhance stages. Error is set
+// when the page could not be analyzed and the run tolerated the failure; the
+// page's findings are then whatever an earlier stage produced, if anything.
+// Text is the page's text layer, when the source format has one, offered to
+// the classify stage as corroborating evidence. TextOnly is set when the page
+// was classified from its text layer alone, without a vision call.
+// Margins is header and footer text the format handler read from the source
+// document itself rather than the rendered page, such as an Office file's
+// header and footer parts, offered alongside Text as corroborating evidence.
 type ClassificationPage struct {
 	PageNumber    int              `json:"page_number"`
 	ImagePath     string           `json:"image_path"`
+	BannerPath    string           `json:"banner_path,omitempty"`
+	BannersOnly   bool             `json:"banners_only,omitempty"`
+	Text          string           `json:"text,omitempty"`
+	TextOnly      bool             `json:"text_only,omitempty"`
+	Margins       string           `json:"margins,omitempty"`
 	MarkingsFound []string         `json:"markings_found"`
 	Rationale     string           `json:"rationale"`
 	Enhancements  *EnhanceSettings `json:"enhancements,omitempty"`
+	EnhancedWith  *EnhanceSettings `json:"enhanced_with,omitempty"`
+	Usage         Usage            `json:"usage"`
+	Error         string           `json:"error,omitempty"`
+}
+
+// Failed reports whether the page could not be analyzed.
+func (p *ClassificationPage) Failed() bool {
+	return p.Error != ""
 }
 
 // Enhance reports whether this page is flagged for enhancement.
@@ -61,12 +137,68 @@ func (p *ClassificationPage) Enhance() bool {
 	return p.Enhancements != nil
 }
 
+// Enhanced reports whether this page was re-rendered by an enhancement pass.
+func (p *ClassificationPage) Enhanced() bool {
+	return p.EnhancedWith != nil
+}
+
+// Escalation records that a run's first answer fell at or below the
+// escalation threshold and the document was classified again by a stronger
+// agent. Agent, Model, and Provider name the agent the run escalated to; the
+// remaining fields keep the replaced answer and the usage it cost.
+type Escalation struct {
+	Agent          string     `json:"agent"`
+	Model          string     `json:"model"`
+	Provider       string     `json:"provider"`
+	Classification string     `json:"classification"`
+	Confidence     Confidence `json:"confidence"`
+	Rationale      string     `json:"rationale"`
+	FailedPages    []int      `json:"failed_pages,omitempty"`
+	Usage          Usage      `json:"usage"`
+}
+
 // ClassificationState holds the running document classification accumulated across pages.
+// RollUp is the marking derived deterministically from every page's markings by
+// the finalize stage; Discrepancy describes how the synthesized Classification
+// disagrees with it and is empty when they agree. Usage counts the document-level
+// calls not tied to a page, such as finalize; TotalUsage includes every page.
+// FailedPages lists the page numbers that could not be analyzed, set by the
+// finalize stage so its synthesis knows which pages are missing. Escalation is
+// set when the answer replaced a low-confidence first attempt; the usage of
+// that attempt is kept there rather than in TotalUsage.
 type ClassificationState struct {
 	Classification string               `json:"classification"`
 	Confidence     Confidence           `json:"confidence"`
 	Rationale      string               `json:"rationale"`
 	Pages          []ClassificationPage `json:"pages"`
+	RollUp         string               `json:"roll_up,omitempty"`
+	Discrepancy    string               `json:"discrepancy,omitempty"`
+	FailedPages    []int                `json:"failed_pages,omitempty"`
+	Usage          Usage                `json:"usage"`
+	Escalation     *Escalation          `json:"escalation,omitempty"`
+}
+
+// Failed returns the page numbers of the pages that could not be analyzed,
+// in page order.
+func (s *ClassificationState) Failed() []int {
+	var pages []int
+	for _, p := range s.Pages {
+		if p.Failed() {
+			pages = append(pages, p.PageNumber)
+		}
+	}
+	return pages
+}
+
+// TotalUsage returns the usage of the attempt that produced the answer: the
+// document-level calls plus every page's calls. An escalated run's first
+// attempt is counted on its Escalation.
+func (s *ClassificationState) TotalUsage() Usage {
+	total := s.Usage
+	for _, p := range s.Pages {
+		total.Add(p.Usage)
+	}
+	return total
 }
 
 // NeedsEnhance reports whether any page is flagged for enhancement.
@@ -0,0 +1,25 @@
+package tracing
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// HTTP returns middleware that traces each request, continuing any trace
+// propagated by the caller. Spans are named by method and the matched
+// route pattern, prefixed with the module prefix. Like metrics.HTTP, it
+// must run inside any middleware that replaces the request, or the pattern
+// set by the mux is not visible to it.
+func HTTP(prefix string) func(http.Handler) http.Handler {
+	return otelhttp.NewMiddleware(
+		ServiceName,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			if method, path, ok := strings.Cut(r.Pattern, " "); ok {
+				return method + " " + prefix + path
+			}
+			return r.Method
+		}),
+	)
+}
@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"context"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/JaimeStill/herald/pkg/storage"
+)
+
+var storageTracer = otel.Tracer("github.com/JaimeStill/herald/pkg/storage")
+
+// Storage returns s with a span around every blob operation. A Download
+// span covers opening the blob; reading the body happens under the
+// caller's span.
+func Storage(s storage.System) storage.System {
+	return &tracedStorage{System: s}
+}
+
+type tracedStorage struct {
+	storage.System
+}
+
+func startStorage(ctx context.Context, op string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return storageTracer.Start(ctx, "storage."+op, trace.WithAttributes(attrs...))
+}
+
+func (s *tracedStorage) List(ctx context.Context, prefix string, marker string, maxResults int32) (*storage.BlobList, error) {
+	ctx, span := startStorage(ctx, "List", attribute.String("storage.prefix", prefix))
+	list, err := s.System.List(ctx, prefix, marker, maxResults)
+	End(span, err)
+	return list, err
+}
+
+func (s *tracedStorage) Find(ctx context.Context, key string) (*storage.BlobMeta, error) {
+	ctx, span := startStorage(ctx, "Find", attribute.String("storage.key", key))
+	meta, err := s.System.Find(ctx, key)
+	End(span, err)
+	return meta, err
+}
+
+func (s *tracedStorage) Upload(ctx context.Context, key string, reader io.Reader, contentType string) error {
+	ctx, span := startStorage(ctx, "Upload",
+		attribute.String("storage.key", key),
+		attribute.String("storage.content_type", contentType),
+	)
+	err := s.System.Upload(ctx, key, reader, contentType)
+	End(span, err)
+	return err
+}
+
+func (s *tracedStorage) Download(ctx context.Context, key string) (*storage.BlobResult, error) {
+	ctx, span := startStorage(ctx, "Download", attribute.String("storage.key", key))
+	result, err := s.System.Download(ctx, key)
+	if err == nil {
+		span.SetAttributes(attribute.Int64("storage.content_length", result.ContentLength))
+	}
+	End(span, err)
+	return result, err
+}
+
+func (s *tracedStorage) Delete(ctx context.Context, key string) error {
+	ctx, span := startStorage(ctx, "Delete", attribute.String("storage.key", key))
+	err := s.System.Delete(ctx, key)
+	End(span, err)
+	return err
+}
+
+func (s *tracedStorage) Exists(ctx context.Context, key string) (bool, error) {
+	ctx, span := startStorage(ctx, "Exists", attribute.String("storage.key", key))
+	ok, err := s.System.Exists(ctx, key)
+	End(span, err)
+	return ok, err
+}
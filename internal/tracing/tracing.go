@@ -0,0 +1,122 @@
+// Package tracing configures OpenTelemetry tracing. New installs the global
+// tracer provider and W3C trace-context propagation; instrumented packages
+// obtain tracers from the global provider, so their spans are no-ops until
+// an exporter is configured. Subsystems from pkg/ are traced by wrapping
+// them at composition time, as with Storage.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/JaimeStill/herald/internal/config"
+	"github.com/JaimeStill/herald/pkg/lifecycle"
+)
+
+// ServiceName identifies Herald's spans in the tracing backend.
+const ServiceName = "herald"
+
+// shutdownTimeout bounds the final export of buffered spans.
+const shutdownTimeout = 5 * time.Second
+
+// Provider owns the process's tracer provider. Start is a no-op when
+// tracing is disabled.
+type Provider struct {
+	tp     *sdktrace.TracerProvider
+	logger *slog.Logger
+}
+
+// New installs the global propagator and, when cfg enables an exporter, the
+// global tracer provider. Spans are exported in batches until Start's
+// shutdown hook flushes them.
+func New(cfg *config.TracingConfig, version string, logger *slog.Logger) (*Provider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	p := &Provider{logger: logger.With("system", "tracing")}
+	if !cfg.Enabled() {
+		return p, nil
+	}
+
+	exporter, err := newExporter(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create %s exporter: %w", cfg.Exporter, err)
+	}
+
+	p.tp = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName(ServiceName),
+			semconv.ServiceVersion(version),
+		)),
+		sdktrace.WithSampler(sdktrace.ParentBased(
+			sdktrace.TraceIDRatioBased(cfg.SampleRatio),
+		)),
+	)
+	otel.SetTracerProvider(p.tp)
+
+	return p, nil
+}
+
+func newExporter(cfg *config.TracingConfig) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case config.TracingStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case config.TracingOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		return otlptracehttp.New(context.Background(), opts...)
+	default:
+		return nil, fmt.Errorf("unsupported exporter %q", cfg.Exporter)
+	}
+}
+
+// Start registers a shutdown hook that flushes buffered spans once requests
+// and workers have drained.
+func (p *Provider) Start(lc *lifecycle.Coordinator) error {
+	if p.tp == nil {
+		return nil
+	}
+
+	p.logger.Info("tracing enabled")
+
+	lc.OnShutdown(func() {
+		<-lc.Drained()
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := p.tp.Shutdown(ctx); err != nil {
+			p.logger.Error("tracer shutdown failed", "error", err)
+			return
+		}
+		p.logger.Info("tracer shutdown complete")
+	})
+
+	return nil
+}
+
+// End records err on span, when non-nil, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
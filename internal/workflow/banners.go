@@ -0,0 +1,87 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/JaimeStill/herald/internal/agents"
+	"github.com/JaimeStill/herald/internal/prompts"
+	"github.com/JaimeStill/herald/internal/state"
+	"github.com/JaimeStill/herald/pkg/core"
+)
+
+// stageBanners keys the banner crop calls of the classify stage for replay
+// and recording, apart from the full-page calls that may follow them.
+const stageBanners prompts.Stage = "banners"
+
+// bannersNote tells the model the image is a banner crop rather than a page.
+const bannersNote = "\n\nThe image is not the full page: it shows only the page's header band " +
+	"above its footer band, separated by a gray rule, cropped at high resolution. " +
+	"Report the markings visible in them. If neither band carries a legible marking, " +
+	"return an empty markings_found; the full page will then be reviewed."
+
+// classifyBanners classifies page from its banner crop when the runtime uses
+// the banners strategy and the page has one, reporting whether the crop
+// sufficed. It does not when the model finds no marking it can read there,
+// asks for enhancement, or returns a response that does not parse; the
+// caller then sends the full page. Only a failed vision call is an error.
+func classifyBanners(
+	ctx context.Context,
+	rt *Runtime,
+	a agents.Agent,
+	page *state.ClassificationPage,
+	prompt string,
+	document sourceID,
+) (bool, error) {
+	if rt.Strategy != StrategyBanners || page.BannerPath == "" {
+		return false, nil
+	}
+
+	imgData, err := readPageImage(page.BannerPath)
+	if err != nil {
+		return false, err
+	}
+
+	callCtx := withCall(ctx, document, stageBanners, page.PageNumber)
+	resp, err := a.Vision(callCtx, pagePrompt(prompt, page)+bannersNote, imgData)
+	if err != nil {
+		return false, fmt.Errorf("banners vision call: %w", err)
+	}
+	page.Usage.Add(visionUsage(resp))
+
+	parsed, err := core.Parse[pageResponse](resp.Text)
+	if err != nil {
+		rt.Logger.WarnContext(
+			ctx, "failed to parse banners response, sending full page",
+			"page", page.PageNumber,
+			"error", err,
+		)
+		return false, nil
+	}
+
+	if parsed.Enhancements != nil || !bannersLegible(rt, parsed.MarkingsFound) {
+		rt.Logger.DebugContext(
+			ctx, "banner crop inconclusive, sending full page",
+			"page", page.PageNumber,
+		)
+		return false, nil
+	}
+
+	applyPageResponse(page, parsed)
+	page.BannersOnly = true
+	return true, nil
+}
+
+// bannersLegible reports whether the markings read from a banner crop carry
+// a classification: one parses, or any was found when the runtime has no
+// marking parser.
+func bannersLegible(rt *Runtime, found []string) bool {
+	if rt.Markings == nil {
+		return len(found) > 0
+	}
+	return slices.ContainsFunc(found, func(m string) bool {
+		_, err := rt.Markings.Parse(m)
+		return err == nil
+	})
+}
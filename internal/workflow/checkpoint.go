@@ -0,0 +1,324 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/JaimeStill/herald/internal/state"
+
+	taustate "github.com/tailored-agentic-units/orchestrate/state"
+)
+
+// Checkpoint is the saved progress of a classification run. One is written
+// after every graph node and after every page the classify node completes,
+// so a run interrupted by a crash or redeploy resumes without repeating the
+// model calls it already made. Node names the last node that finished;
+// ClassifiedPages lists the pages the classify node had finished while it
+// was still running. Filename and Digest keep the source's replay identity.
+type Checkpoint struct {
+	RunID           uuid.UUID
+	DocumentID      uuid.UUID
+	Node            string
+	Filename        string
+	Digest          string
+	PageCount       int
+	ClassifiedPages []int
+	State           state.ClassificationState
+}
+
+// CheckpointStore persists run checkpoints. Save replaces the run's previous
+// checkpoint; Load returns ErrNoCheckpoint when the run has none.
+type CheckpointStore interface {
+	Load(ctx context.Context, runID uuid.UUID) (*Checkpoint, error)
+	Save(ctx context.Context, cp *Checkpoint) error
+}
+
+// loadCheckpoint returns the run's last checkpoint, or nil when the run has
+// none or the runtime does not checkpoint.
+func loadCheckpoint(ctx context.Context, rt *Runtime, runID, documentID uuid.UUID) (*Checkpoint, error) {
+	if rt.Checkpoints == nil {
+		return nil, nil
+	}
+
+	cp, err := rt.Checkpoints.Load(ctx, runID)
+	if errors.Is(err, ErrNoCheckpoint) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load checkpoint: %w", err)
+	}
+
+	if cp.DocumentID != documentID {
+		return nil, fmt.Errorf("checkpoint for run %s belongs to document %s", runID, cp.DocumentID)
+	}
+	return cp, nil
+}
+
+// resume seeds s with the checkpointed state and returns the node the run
+// continues from, or "" when the checkpoint already holds a finalized
+// result. The temp directory of the interrupted run is gone, so the source
+// is staged again and only the pages the classify node has yet to see are
+// re-rendered, or every page when the run escalates next.
+func resume(
+	ctx context.Context,
+	rt *Runtime,
+	cp *Checkpoint,
+	s taustate.State,
+	tempDir string,
+) (string, taustate.State, error) {
+	cs := cp.State
+	cs.Pages = slices.Clone(cp.State.Pages)
+	for i := range cs.Pages {
+		cs.Pages[i].ImagePath = ""
+		cs.Pages[i].BannerPath = ""
+	}
+
+	next := nextNode(rt, cp.Node, &cs)
+
+	var render []*state.ClassificationPage
+	switch next {
+	case nodeClassify:
+		for i := range cs.Pages {
+			if !slices.Contains(cp.ClassifiedPages, cs.Pages[i].PageNumber) {
+				render = append(render, &cs.Pages[i])
+			}
+		}
+	case nodeEscalate:
+		for i := range cs.Pages {
+			render = append(render, &cs.Pages[i])
+		}
+	}
+
+	if next == nodeClassify || next == nodeEnhance || next == nodeEscalate {
+		if err := restorePages(ctx, rt, cp.DocumentID, tempDir, render); err != nil {
+			return "", s, err
+		}
+	}
+
+	rt.Logger.InfoContext(
+		ctx, "resuming classification from checkpoint",
+		"run_id", cp.RunID,
+		"document_id", cp.DocumentID,
+		"node", cp.Node,
+		"next", next,
+		"classified_pages", len(cp.ClassifiedPages),
+		"rendered_pages", len(render),
+	)
+
+	s = s.Set(state.KeyClassState, cs)
+	s = s.Set(state.KeyFilename, cp.Filename)
+	s = s.Set(state.KeyDigest, cp.Digest)
+	s = s.Set(state.KeyPageCount, cp.PageCount)
+	s = s.Set(state.KeyClassifiedPages, cp.ClassifiedPages)
+
+	return next, s, nil
+}
+
+// nextNode returns the node that follows node, mirroring the edges of
+// buildGraph. An unrecognized node restarts the run from init.
+func nextNode(rt *Runtime, node string, cs *state.ClassificationState) string {
+	switch node {
+	case nodeInit:
+		return nodeClassify
+	case nodeClassify:
+		if cs.NeedsEnhance() {
+			return nodeEnhance
+		}
+		return nodeFinalize
+	case nodeEnhance:
+		return nodeFinalize
+	case nodeFinalize:
+		if rt.escalates(cs) {
+			return nodeEscalate
+		}
+		return ""
+	case nodeEscalate:
+		return ""
+	default:
+		return nodeInit
+	}
+}
+
+func restorePages(
+	ctx context.Context,
+	rt *Runtime,
+	documentID uuid.UUID,
+	tempDir string,
+	pages []*state.ClassificationPage,
+) error {
+	doc, err := rt.Documents.Find(ctx, documentID)
+	if err != nil {
+		return fmt.Errorf("resume: %w: %w", ErrDocumentNotFound, err)
+	}
+
+	handler, err := rt.Formats.Lookup(doc.ContentType)
+	if err != nil {
+		return fmt.Errorf("resume: %w: %w", ErrRenderFailed, err)
+	}
+
+	src := &blobSource{
+		rt:          rt,
+		storageKey:  doc.StorageKey,
+		contentType: doc.ContentType,
+		filename:    doc.Filename,
+	}
+
+	if err := handler.Restore(ctx, src, tempDir, pages); err != nil {
+		return fmt.Errorf("resume: %w: %w", ErrRenderFailed, err)
+	}
+	return nil
+}
+
+// checkpointed wraps node so the run's progress is saved each time it
+// completes.
+func checkpointed(rt *Runtime, name string, node taustate.StateNode) taustate.StateNode {
+	if rt.Checkpoints == nil {
+		return node
+	}
+
+	return taustate.NewFunctionNode(func(ctx context.Context, s taustate.State) (taustate.State, error) {
+		s, err := node.Execute(ctx, s)
+		if err != nil {
+			return s, err
+		}
+
+		cs, err := extractClassState(s)
+		if err != nil {
+			return s, err
+		}
+
+		saveCheckpoint(ctx, rt, s, name, *cs, nil)
+		return s, nil
+	})
+}
+
+// saveCheckpoint records the run's progress. A checkpoint only saves work,
+// so failing to write one is logged rather than failing the run, and the
+// write is not cancelled with the run so that a page completed during
+// shutdown is kept.
+func saveCheckpoint(
+	ctx context.Context,
+	rt *Runtime,
+	s taustate.State,
+	node string,
+	cs state.ClassificationState,
+	classified []int,
+) {
+	runIDVal, _ := s.Get(state.KeyRunID)
+	runID, _ := runIDVal.(uuid.UUID)
+	if runID == uuid.Nil {
+		return
+	}
+
+	docIDVal, _ := s.Get(state.KeyDocumentID)
+	documentID, _ := docIDVal.(uuid.UUID)
+	pageCountVal, _ := s.Get(state.KeyPageCount)
+	pageCount, _ := pageCountVal.(int)
+	source := extractSourceID(s)
+
+	cp := &Checkpoint{
+		RunID:           runID,
+		DocumentID:      documentID,
+		Node:            node,
+		Filename:        source.filename,
+		Digest:          source.digest,
+		PageCount:       pageCount,
+		ClassifiedPages: classified,
+		State:           cs,
+	}
+
+	if err := rt.Checkpoints.Save(context.WithoutCancel(ctx), cp); err != nil {
+		rt.Logger.WarnContext(
+			ctx, "save checkpoint failed",
+			"run_id", runID,
+			"node", node,
+			"error", err,
+		)
+	}
+}
+
+// extractClassifiedPages returns the pages a resumed run's classify node
+// already completed, or nil for a fresh run.
+func extractClassifiedPages(s taustate.State) []int {
+	val, _ := s.Get(state.KeyClassifiedPages)
+	pages, _ := val.([]int)
+	return pages
+}
+
+// classifyProgress commits pages to the classification state as the
+// classify node completes them and checkpoints the run after each one.
+// Pages are committed under mu so a checkpoint never observes a page that
+// another goroutine is still writing. Checkpoints are written from a
+// snapshot outside mu, one at a time under saveMu, and a snapshot older than
+// the last one written is dropped, so concurrent completions coalesce into
+// the latest state instead of queueing a write each.
+type classifyProgress struct {
+	rt *Runtime
+	s  taustate.State
+	cs *state.ClassificationState
+
+	mu   sync.Mutex
+	done []int
+	seq  int
+
+	saveMu sync.Mutex
+	saved  int
+}
+
+func newClassifyProgress(rt *Runtime, s taustate.State, cs *state.ClassificationState) *classifyProgress {
+	return &classifyProgress{
+		rt:   rt,
+		s:    s,
+		cs:   cs,
+		done: slices.Clone(extractClassifiedPages(s)),
+	}
+}
+
+// classified reports whether page was completed before the run resumed.
+func (p *classifyProgress) classified(page int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Contains(p.done, page)
+}
+
+// complete stores the finished page at index i and checkpoints the run. It
+// returns once a checkpoint including the page has been written, by this
+// call or a concurrent one.
+func (p *classifyProgress) complete(ctx context.Context, i int, page state.ClassificationPage) {
+	p.mu.Lock()
+	p.cs.Pages[i] = page
+	p.done = append(p.done, page.PageNumber)
+	p.seq++
+	seq := p.seq
+	p.mu.Unlock()
+
+	if p.rt.Checkpoints == nil {
+		return
+	}
+
+	p.saveMu.Lock()
+	defer p.saveMu.Unlock()
+	if seq <= p.saved {
+		return
+	}
+
+	seq, cs, done := p.snapshot()
+	saveCheckpoint(ctx, p.rt, p.s, nodeInit, cs, done)
+	p.saved = seq
+}
+
+// snapshot copies the latest committed state and completed pages, with the
+// sequence number of the last page they include.
+func (p *classifyProgress) snapshot() (int, state.ClassificationState, []int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cs := *p.cs
+	cs.Pages = slices.Clone(p.cs.Pages)
+	return p.seq, cs, slices.Clone(p.done)
+}
@@ -7,11 +7,10 @@ import (
 
 	"golang.org/x/sync/errgroup"
 
-	"github.com/tailored-agentic-units/format"
-	"github.com/tailored-agentic-units/protocol"
-
+	"github.com/JaimeStill/herald/internal/agents"
 	"github.com/JaimeStill/herald/internal/prompts"
 	"github.com/JaimeStill/herald/internal/state"
+	"github.com/JaimeStill/herald/internal/tracing"
 	"github.com/JaimeStill/herald/pkg/core"
 
 	taustate "github.com/tailored-agentic-units/orchestrate/state"
@@ -29,6 +28,13 @@ type pageResponse struct {
 // own agent, encodes the page image to a data URI, and sends it to the
 // vision model. Pages are classified independently (no accumulated context);
 // document-level classification synthesis is deferred to the finalize node.
+// A page's text layer, when it has one, is sent with its image as
+// corroborating evidence, and with the text fast path enabled a page whose
+// text carries unambiguous markings is classified without a vision call.
+// Under the banners strategy a page's banner crop is sent before its full
+// image, which is only sent when the crop is inconclusive.
+// Each completed page is checkpointed, and pages a resumed run already
+// classified are skipped.
 func ClassifyNode(rt *Runtime) taustate.StateNode {
 	return taustate.NewFunctionNode(func(ctx context.Context, s taustate.State) (taustate.State, error) {
 		classState, err := extractClassState(s)
@@ -36,7 +42,8 @@ func ClassifyNode(rt *Runtime) taustate.StateNode {
 			return s, fmt.Errorf("classify: %w", err)
 		}
 
-		if err := classifyPages(ctx, rt, classState); err != nil {
+		progress := newClassifyProgress(rt, s, classState)
+		if err := classifyPages(ctx, rt, classState, extractSourceID(s), progress); err != nil {
 			return s, fmt.Errorf("classify: %w", err)
 		}
 
@@ -64,7 +71,47 @@ func extractClassState(s taustate.State) (*state.ClassificationState, error) {
 	return &cs, nil
 }
 
-func classifyPages(ctx context.Context, rt *Runtime, cs *state.ClassificationState) error {
+// extractFilename returns the source filename recorded by the init node, or
+// an empty string when it is absent.
+func extractFilename(s taustate.State) string {
+	val, _ := s.Get(state.KeyFilename)
+	filename, _ := val.(string)
+	return filename
+}
+
+// sourceID identifies the source file of a run to replay and recording
+// agents: its filename and the hex SHA-256 of its content.
+type sourceID struct {
+	filename string
+	digest   string
+}
+
+// extractSourceID returns the source identity recorded by the init node, with
+// empty fields when it is absent.
+func extractSourceID(s taustate.State) sourceID {
+	val, _ := s.Get(state.KeyDigest)
+	digest, _ := val.(string)
+	return sourceID{filename: extractFilename(s), digest: digest}
+}
+
+// withCall tags ctx with the identity of a model call so replay and recording
+// agents can key its response.
+func withCall(ctx context.Context, document sourceID, stage prompts.Stage, page int) context.Context {
+	return agents.WithCall(ctx, agents.Call{
+		Document: document.filename,
+		Digest:   document.digest,
+		Stage:    string(stage),
+		Page:     page,
+	})
+}
+
+func classifyPages(
+	ctx context.Context,
+	rt *Runtime,
+	cs *state.ClassificationState,
+	document sourceID,
+	progress *classifyProgress,
+) error {
 	prompt, err := ComposePrompt(ctx, rt.Prompts, prompts.StageClassify, nil)
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrClassifyFailed, err)
@@ -74,37 +121,22 @@ func classifyPages(ctx context.Context, rt *Runtime, cs *state.ClassificationSta
 	g.SetLimit(core.WorkerCount(len(cs.Pages)))
 
 	for i := range cs.Pages {
+		if progress.classified(cs.Pages[i].PageNumber) {
+			continue
+		}
+
 		g.Go(func() error {
 			if gctx.Err() != nil {
 				return gctx.Err()
 			}
 
-			a, err := rt.NewAgent(gctx)
-			if err != nil {
-				return fmt.Errorf("page %d: create agent: %w", i+1, err)
-			}
-
-			imgData, err := readPageImage(cs.Pages[i].ImagePath)
-			if err != nil {
-				return fmt.Errorf("page %d: %w", i+1, err)
-			}
-
-			resp, err := a.Vision(
-				gctx,
-				[]protocol.Message{protocol.UserMessage(prompt)},
-				[]format.Image{{Data: imgData, Format: "png"}},
-			)
-
-			if err != nil {
-				return fmt.Errorf("page %d: vision call: %w", i+1, err)
+			page := cs.Pages[i]
+			err := classifyPage(gctx, rt, &page, prompt, document)
+			if err := tolerate(gctx, rt, prompts.StageClassify, &page, err); err != nil {
+				return err
 			}
 
-			parsed, err := core.Parse[pageResponse](resp.Text())
-			if err != nil {
-				return fmt.Errorf("page %d: parse response: %w", i+1, err)
-			}
-
-			applyPageResponse(&cs.Pages[i], parsed)
+			progress.complete(gctx, i, page)
 			return nil
 		})
 	}
@@ -113,9 +145,97 @@ func classifyPages(ctx context.Context, rt *Runtime, cs *state.ClassificationSta
 		return fmt.Errorf("%w: %w", ErrClassifyFailed, err)
 	}
 
+	if failed := cs.Failed(); len(failed) > 0 && len(failed) == len(cs.Pages) {
+		return fmt.Errorf("%w: all %d pages failed", ErrClassifyFailed, len(failed))
+	}
+
+	return nil
+}
+
+func classifyPage(
+	ctx context.Context,
+	rt *Runtime,
+	page *state.ClassificationPage,
+	prompt string,
+	document sourceID,
+) (err error) {
+	ctx, span := startPage(ctx, prompts.StageClassify, page.PageNumber)
+	defer func() { tracing.End(span, err) }()
+
+	if classifyFromText(rt, page) {
+		return nil
+	}
+
+	a, err := rt.NewAgent(ctx)
+	if err != nil {
+		return fmt.Errorf("create agent: %w", err)
+	}
+
+	if ok, err := classifyBanners(ctx, rt, a, page, prompt, document); ok || err != nil {
+		return err
+	}
+
+	imgData, err := readPageImage(page.ImagePath)
+	if err != nil {
+		return err
+	}
+
+	callCtx := withCall(ctx, document, prompts.StageClassify, page.PageNumber)
+	resp, err := a.Vision(callCtx, pagePrompt(prompt, page), imgData)
+	if err != nil {
+		return fmt.Errorf("vision call: %w", err)
+	}
+	page.Usage.Add(visionUsage(resp))
+
+	parsed, err := core.Parse[pageResponse](resp.Text)
+	if err != nil {
+		return fmt.Errorf("parse response: %w", err)
+	}
+
+	applyPageResponse(page, parsed)
+	return nil
+}
+
+// tolerate decides the outcome of a page that failed a stage. When the
+// runtime allows partial pages, the failure is recorded on the page, any
+// pending enhancement is dropped, and the run continues; otherwise, or when
+// the run itself was cancelled, the error fails the stage.
+func tolerate(ctx context.Context, rt *Runtime, stage prompts.Stage, page *state.ClassificationPage, err error) error {
+	if err == nil {
+		return nil
+	}
+	if !rt.PartialPages || ctx.Err() != nil {
+		return fmt.Errorf("page %d: %w", page.PageNumber, err)
+	}
+
+	page.Error = fmt.Sprintf("%s: %s", stage, err)
+	page.Enhancements = nil
+	rt.Logger.WarnContext(
+		ctx, "page failed, continuing without it",
+		"stage", stage,
+		"page", page.PageNumber,
+		"error", err,
+	)
 	return nil
 }
 
+// visionUsage and chatUsage convert the usage reported by one model call.
+func visionUsage(resp agents.Response) state.Usage {
+	return state.Usage{
+		VisionCalls:      1,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+	}
+}
+
+func chatUsage(resp agents.Response) state.Usage {
+	return state.Usage{
+		ChatCalls:        1,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+	}
+}
+
 func readPageImage(imagePath string) ([]byte, error) {
 	data, err := os.ReadFile(imagePath)
 	if err != nil {
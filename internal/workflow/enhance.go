@@ -7,14 +7,13 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/google/uuid"
-	"github.com/tailored-agentic-units/protocol"
 
 	"github.com/JaimeStill/herald/internal/format"
 	"github.com/JaimeStill/herald/internal/prompts"
 	"github.com/JaimeStill/herald/internal/state"
+	"github.com/JaimeStill/herald/internal/tracing"
 	"github.com/JaimeStill/herald/pkg/core"
 
-	tauformat "github.com/tailored-agentic-units/format"
 	taustate "github.com/tailored-agentic-units/orchestrate/state"
 )
 
@@ -27,8 +26,9 @@ type enhanceResponse struct {
 // ImageMagick settings and reclassifies them via vision. For each page with
 // non-nil Enhancements, it re-renders from the source PDF using the specified
 // brightness/contrast/saturation adjustments, sends the enhanced image to the
-// vision model, and updates the per-page findings. Clears Enhancements after
-// processing so the page is no longer flagged.
+// vision model, and updates the per-page findings. Moves Enhancements to
+// EnhancedWith after processing so the page is no longer flagged but the
+// applied settings are retained.
 func EnhanceNode(rt *Runtime) taustate.StateNode {
 	return taustate.NewFunctionNode(func(
 		ctx context.Context,
@@ -61,7 +61,7 @@ func EnhanceNode(rt *Runtime) taustate.StateNode {
 
 		enhanced := cs.EnhancePages()
 
-		if err := enhancePages(ctx, rt, handler, cs, tempDir); err != nil {
+		if err := enhancePages(ctx, rt, handler, cs, tempDir, extractSourceID(s)); err != nil {
 			return s, fmt.Errorf("enhance: %w", err)
 		}
 
@@ -81,6 +81,7 @@ func enhancePages(
 	handler format.Handler,
 	cs *state.ClassificationState,
 	tempDir string,
+	document sourceID,
 ) error {
 	enhanced := cs.EnhancePages()
 
@@ -98,52 +99,63 @@ func enhancePages(
 				return gctx.Err()
 			}
 
-			a, err := rt.NewAgent(gctx)
-			if err != nil {
-				return fmt.Errorf("page %d: create agent: %w", cs.Pages[i].PageNumber, err)
-			}
+			page := &cs.Pages[i]
+			err := enhancePage(gctx, rt, handler, page, prompt, tempDir, document)
+			return tolerate(gctx, rt, prompts.StageEnhance, page, err)
+		})
+	}
 
-			imgPath, err := handler.Enhance(
-				gctx,
-				tempDir,
-				&cs.Pages[i],
-				cs.Pages[i].Enhancements,
-			)
-			if err != nil {
-				return fmt.Errorf("page %d: %w", cs.Pages[i].PageNumber, err)
-			}
-			cs.Pages[i].ImagePath = imgPath
+	if err := g.Wait(); err != nil {
+		return fmt.Errorf("%w: %w", ErrEnhanceFailed, err)
+	}
 
-			imgData, err := readPageImage(imgPath)
-			if err != nil {
-				return fmt.Errorf("page %d: %w", cs.Pages[i].PageNumber, err)
-			}
+	return nil
+}
 
-			resp, err := a.Vision(
-				gctx,
-				[]protocol.Message{protocol.UserMessage(prompt)},
-				[]tauformat.Image{{Data: imgData, Format: "png"}},
-			)
-			if err != nil {
-				return fmt.Errorf("page %d: vision call: %w", cs.Pages[i].PageNumber, err)
-			}
+func enhancePage(
+	ctx context.Context,
+	rt *Runtime,
+	handler format.Handler,
+	page *state.ClassificationPage,
+	prompt string,
+	tempDir string,
+	document sourceID,
+) (err error) {
+	ctx, span := startPage(ctx, prompts.StageEnhance, page.PageNumber)
+	defer func() { tracing.End(span, err) }()
 
-			parsed, err := core.Parse[enhanceResponse](resp.Text())
-			if err != nil {
-				return fmt.Errorf("page %d: parse response: %w", cs.Pages[i].PageNumber, err)
-			}
+	a, err := rt.NewAgent(ctx)
+	if err != nil {
+		return fmt.Errorf("create agent: %w", err)
+	}
 
-			cs.Pages[i].MarkingsFound = parsed.MarkingsFound
-			cs.Pages[i].Rationale = parsed.Rationale
-			cs.Pages[i].Enhancements = nil
+	imgPath, err := handler.Enhance(ctx, tempDir, page, page.Enhancements)
+	if err != nil {
+		return err
+	}
+	page.ImagePath = imgPath
 
-			return nil
-		})
+	imgData, err := readPageImage(imgPath)
+	if err != nil {
+		return err
 	}
 
-	if err := g.Wait(); err != nil {
-		return fmt.Errorf("%w: %w", ErrEnhanceFailed, err)
+	callCtx := withCall(ctx, document, prompts.StageEnhance, page.PageNumber)
+	resp, err := a.Vision(callCtx, pagePrompt(prompt, page), imgData)
+	if err != nil {
+		return fmt.Errorf("vision call: %w", err)
 	}
+	page.Usage.Add(visionUsage(resp))
+
+	parsed, err := core.Parse[enhanceResponse](resp.Text)
+	if err != nil {
+		return fmt.Errorf("parse response: %w", err)
+	}
+
+	page.MarkingsFound = parsed.MarkingsFound
+	page.Rationale = parsed.Rationale
+	page.EnhancedWith = page.Enhancements
+	page.Enhancements = nil
 
 	return nil
 }
@@ -12,4 +12,5 @@ var (
 	ErrClassifyFailed   = errors.New("classification failed")
 	ErrEnhanceFailed    = errors.New("enhancement failed")
 	ErrFinalizeFailed   = errors.New("finalize failed")
+	ErrNoCheckpoint     = errors.New("no checkpoint")
 )
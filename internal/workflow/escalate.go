@@ -0,0 +1,118 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/JaimeStill/herald/internal/state"
+
+	taustate "github.com/tailored-agentic-units/orchestrate/state"
+)
+
+// EscalateNode returns a state node that classifies the document again with
+// the runtime's escalation agent. The first answer is kept on the state's
+// Escalation, the page findings are cleared, and classify, enhance when a
+// page asks for it, and finalize run again under the escalation agent. The
+// pages keep the images the first attempt rendered. The inner stages are not
+// checkpointed; a run interrupted while escalating resumes from finalize and
+// escalates again.
+func EscalateNode(rt *Runtime) taustate.StateNode {
+	target := rt.Escalation.Agent
+
+	esc := *rt
+	esc.NewAgent = target.New
+	esc.Model = target.Model
+	esc.Provider = target.Provider
+	esc.Checkpoints = nil
+	esc.Escalation = nil
+	esc.Logger = rt.Logger.With("escalated_to", target.Name)
+
+	classify := ClassifyNode(&esc)
+	enhance := EnhanceNode(&esc)
+	finalize := FinalizeNode(&esc)
+
+	return taustate.NewFunctionNode(func(ctx context.Context, s taustate.State) (taustate.State, error) {
+		cs, err := extractClassState(s)
+		if err != nil {
+			return s, fmt.Errorf("escalate: %w", err)
+		}
+
+		first := state.Escalation{
+			Agent:          target.Name,
+			Model:          target.Model,
+			Provider:       target.Provider,
+			Classification: cs.Classification,
+			Confidence:     cs.Confidence,
+			Rationale:      cs.Rationale,
+			FailedPages:    cs.FailedPages,
+			Usage:          cs.TotalUsage(),
+		}
+
+		rt.Logger.InfoContext(
+			ctx, "escalating classification",
+			"agent", target.Name,
+			"classification", cs.Classification,
+			"confidence", cs.Confidence,
+			"threshold", rt.Escalation.Threshold,
+		)
+
+		fresh := state.ClassificationState{
+			Pages: make([]state.ClassificationPage, len(cs.Pages)),
+		}
+		for i, p := range cs.Pages {
+			fresh.Pages[i] = state.ClassificationPage{
+				PageNumber: p.PageNumber,
+				ImagePath:  p.ImagePath,
+				BannerPath: p.BannerPath,
+				Text:       p.Text,
+				Margins:    p.Margins,
+			}
+		}
+
+		s = s.Set(state.KeyClassState, fresh)
+		s = s.Set(state.KeyClassifiedPages, []int(nil))
+
+		if s, err = classify.Execute(ctx, s); err != nil {
+			return s, err
+		}
+
+		if needsEnhance(s) {
+			if s, err = enhance.Execute(ctx, s); err != nil {
+				return s, err
+			}
+		}
+
+		if s, err = finalize.Execute(ctx, s); err != nil {
+			return s, err
+		}
+
+		cs, err = extractClassState(s)
+		if err != nil {
+			return s, fmt.Errorf("escalate: %w", err)
+		}
+		cs.Escalation = &first
+
+		rt.Logger.InfoContext(
+			ctx, "escalate node complete",
+			"agent", target.Name,
+			"classification", cs.Classification,
+			"confidence", cs.Confidence,
+		)
+
+		s = s.Set(state.KeyClassState, *cs)
+		return s, nil
+	})
+}
+
+// needsEscalation returns the predicate that routes a finalized run to the
+// escalate node: escalation is configured, the run has not escalated
+// already, and its confidence is at or below the threshold.
+func needsEscalation(rt *Runtime) taustate.TransitionPredicate {
+	return func(s taustate.State) bool {
+		cs, err := extractClassState(s)
+		if err != nil {
+			return false
+		}
+		return rt.escalates(cs)
+	}
+}
@@ -16,8 +16,9 @@ const (
 
 // ExecutionEvent is a lean progress event emitted by StreamingObserver.
 // Data contains only controlled fields — orchestration framework internals
-// are never passed through.
+// are never passed through. ID is assigned when the event is published to a Hub.
 type ExecutionEvent struct {
+	ID        uint64             `json:"id,omitempty"`
 	Type      ExecutionEventType `json:"type"`
 	Timestamp time.Time          `json:"timestamp"`
 	Data      map[string]any     `json:"data"`
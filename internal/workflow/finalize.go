@@ -3,9 +3,9 @@ package workflow
 import (
 	"context"
 	"fmt"
+	"strings"
 
-	"github.com/tailored-agentic-units/protocol"
-
+	"github.com/JaimeStill/herald/internal/markings"
 	"github.com/JaimeStill/herald/internal/prompts"
 	"github.com/JaimeStill/herald/internal/state"
 	"github.com/JaimeStill/herald/pkg/core"
@@ -22,7 +22,10 @@ type finalizeResponse struct {
 // FinalizeNode returns a state node that synthesizes the document-level
 // classification from all per-page findings. It performs a single Chat
 // inference (not Vision — no images needed) that reviews all page data
-// and produces the authoritative classification, confidence, and rationale.
+// and produces the authoritative classification, confidence, and rationale,
+// then checks the answer against the deterministic roll-up of page markings.
+// Pages that failed earlier stages are listed in FailedPages for the
+// synthesis, and their absence forces the confidence to LOW.
 func FinalizeNode(rt *Runtime) taustate.StateNode {
 	return taustate.NewFunctionNode(func(ctx context.Context, s taustate.State) (taustate.State, error) {
 		cs, err := extractClassState(s)
@@ -30,10 +33,34 @@ func FinalizeNode(rt *Runtime) taustate.StateNode {
 			return s, fmt.Errorf("finalize: %w", err)
 		}
 
-		if err := synthesize(ctx, rt, cs); err != nil {
+		cs.FailedPages = cs.Failed()
+
+		callCtx := withCall(ctx, extractSourceID(s), prompts.StageFinalize, 0)
+		if err := synthesize(callCtx, rt, cs); err != nil {
 			return s, fmt.Errorf("finalize: %w", err)
 		}
 
+		if rt.Markings != nil {
+			Reconcile(rt.Markings, cs)
+		}
+
+		if len(cs.FailedPages) > 0 {
+			cs.Confidence = state.ConfidenceLow
+			rt.Logger.WarnContext(
+				ctx, "finalize ran without failed pages",
+				"failed_pages", cs.FailedPages,
+			)
+		}
+
+		if cs.Discrepancy != "" {
+			rt.Logger.WarnContext(
+				ctx, "finalize disagrees with marking roll-up",
+				"classification", cs.Classification,
+				"roll_up", cs.RollUp,
+				"discrepancy", cs.Discrepancy,
+			)
+		}
+
 		rt.Logger.InfoContext(
 			ctx, "finalize node complete",
 			"classification", cs.Classification,
@@ -56,12 +83,13 @@ func synthesize(ctx context.Context, rt *Runtime, cs *state.ClassificationState)
 		return fmt.Errorf("%w: %w", ErrFinalizeFailed, err)
 	}
 
-	resp, err := a.Chat(ctx, []protocol.Message{protocol.UserMessage(prompt)})
+	resp, err := a.Chat(ctx, prompt)
 	if err != nil {
 		return fmt.Errorf("%w: chat call: %w", ErrFinalizeFailed, err)
 	}
+	cs.Usage.Add(chatUsage(resp))
 
-	parsed, err := core.Parse[finalizeResponse](resp.Text())
+	parsed, err := core.Parse[finalizeResponse](resp.Text)
 	if err != nil {
 		return fmt.Errorf("%w: parse response: %w", ErrFinalizeFailed, err)
 	}
@@ -72,3 +100,43 @@ func synthesize(ctx context.Context, rt *Runtime, cs *state.ClassificationState)
 
 	return nil
 }
+
+// Reconcile compares the synthesized classification in cs against the
+// rule-based roll-up of every page's markings. It records the roll-up in
+// cs.RollUp and, when the two disagree or the synthesized classification does
+// not parse, downgrades cs.Confidence to LOW and describes the disagreement in
+// cs.Discrepancy. When no page marking parses there is nothing to compare and
+// both fields are cleared.
+func Reconcile(p *markings.Parser, cs *state.ClassificationState) {
+	cs.RollUp = ""
+	cs.Discrepancy = ""
+
+	var found []string
+	for _, page := range cs.Pages {
+		found = append(found, page.MarkingsFound...)
+	}
+
+	rolled, ok := p.RollUp(found)
+	if !ok {
+		return
+	}
+	cs.RollUp = rolled.Canonical
+
+	var diffs []string
+	answer, err := p.Parse(cs.Classification)
+	if err != nil {
+		diffs = []string{err.Error()}
+	} else {
+		diffs = markings.Differences(rolled, answer)
+	}
+
+	if len(diffs) == 0 {
+		return
+	}
+
+	cs.Confidence = state.ConfidenceLow
+	cs.Discrepancy = fmt.Sprintf(
+		"classification %q disagrees with roll-up %q: %s",
+		cs.Classification, rolled.Canonical, strings.Join(diffs, "; "),
+	)
+}
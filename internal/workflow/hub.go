@@ -0,0 +1,201 @@
+package workflow
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Hub fans out ExecutionEvents to any number of subscribers per document and
+// buffers the events of the current run so that late or reconnecting clients can
+// replay what they missed. Every published event receives an ID that increases
+// monotonically across the hub, so IDs never repeat between runs, and clients
+// echo the last one back through the SSE Last-Event-ID header to resume a stream.
+//
+// A run begins with Begin, which clears the replay buffer while keeping attached
+// subscribers, and ends with Finish, which closes every subscriber after the
+// terminal event. A finished run stays replayable for the retention period.
+type Hub struct {
+	mu         sync.Mutex
+	seq        uint64
+	topics     map[uuid.UUID]*topic
+	bufferSize int
+	retention  time.Duration
+}
+
+type topic struct {
+	events   []ExecutionEvent
+	subs     map[*Subscription]struct{}
+	finished bool
+	evict    *time.Timer
+}
+
+// Subscription is a single client's view of a document's event stream.
+// Events is closed when the run finishes, when the subscriber falls too far
+// behind to keep up, or when Cancel is called.
+type Subscription struct {
+	events chan ExecutionEvent
+	key    uuid.UUID
+	hub    *Hub
+	closed bool
+}
+
+// Events returns the live event channel for the subscription.
+func (s *Subscription) Events() <-chan ExecutionEvent {
+	return s.events
+}
+
+// Cancel detaches the subscription from the hub. Safe to call multiple times.
+func (s *Subscription) Cancel() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	t, ok := s.hub.topics[s.key]
+	if !ok {
+		s.close()
+		return
+	}
+
+	delete(t.subs, s)
+	s.close()
+
+	if len(t.subs) == 0 && len(t.events) == 0 && !t.finished {
+		delete(s.hub.topics, s.key)
+	}
+}
+
+func (s *Subscription) close() {
+	if !s.closed {
+		s.closed = true
+		close(s.events)
+	}
+}
+
+// NewHub creates a Hub that keeps up to bufferSize events per run and retains
+// finished runs for the given duration.
+func NewHub(bufferSize int, retention time.Duration) *Hub {
+	return &Hub{
+		topics:     make(map[uuid.UUID]*topic),
+		bufferSize: bufferSize,
+		retention:  retention,
+	}
+}
+
+// Begin starts a new run for key, discarding the previous run's replay buffer.
+// Subscribers already attached (for example, waiting on a queued job) stay
+// attached and receive the new run's events.
+func (h *Hub) Begin(key uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t := h.topic(key)
+	if t.evict != nil {
+		t.evict.Stop()
+		t.evict = nil
+	}
+	t.events = nil
+	t.finished = false
+}
+
+// Publish assigns the next event ID for key, appends the event to the replay
+// buffer, and delivers it to every subscriber. A subscriber whose channel is
+// full is closed rather than blocking the run; its client reconnects with
+// Last-Event-ID and catches up from the buffer.
+func (h *Hub) Publish(key uuid.UUID, event ExecutionEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t := h.topic(key)
+	h.seq++
+	event.ID = h.seq
+
+	t.events = append(t.events, event)
+	if len(t.events) > h.bufferSize {
+		t.events = t.events[len(t.events)-h.bufferSize:]
+	}
+
+	for sub := range t.subs {
+		select {
+		case sub.events <- event:
+		default:
+			delete(t.subs, sub)
+			sub.close()
+		}
+	}
+}
+
+// Finish ends the current run for key, closing every subscriber. The run's
+// events remain replayable until the retention period elapses.
+func (h *Hub) Finish(key uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t, ok := h.topics[key]
+	if !ok {
+		return
+	}
+
+	t.finished = true
+	for sub := range t.subs {
+		delete(t.subs, sub)
+		sub.close()
+	}
+
+	t.evict = time.AfterFunc(h.retention, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.topics[key] == t && t.finished {
+			delete(h.topics, key)
+		}
+	})
+}
+
+// Active reports whether key has buffered events or attached subscribers.
+func (h *Hub) Active(key uuid.UUID) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, ok := h.topics[key]
+	return ok
+}
+
+// Subscribe attaches a subscriber to key. It returns the buffered events with
+// an ID greater than lastEventID (every buffered event when lastEventID is 0)
+// followed by a subscription for live events. When the run has already
+// finished, the subscription's channel is closed immediately after the replay.
+func (h *Hub) Subscribe(key uuid.UUID, lastEventID uint64) ([]ExecutionEvent, *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t := h.topic(key)
+
+	var replay []ExecutionEvent
+	for _, e := range t.events {
+		if e.ID > lastEventID {
+			replay = append(replay, e)
+		}
+	}
+
+	sub := &Subscription{
+		events: make(chan ExecutionEvent, h.bufferSize),
+		key:    key,
+		hub:    h,
+	}
+
+	if t.finished {
+		sub.close()
+	} else {
+		t.subs[sub] = struct{}{}
+	}
+
+	return replay, sub
+}
+
+func (h *Hub) topic(key uuid.UUID) *topic {
+	t, ok := h.topics[key]
+	if !ok {
+		t = &topic{subs: make(map[*Subscription]struct{})}
+		h.topics[key] = t
+	}
+	return t
+}
@@ -2,7 +2,10 @@ package workflow
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 
 	"github.com/google/uuid"
@@ -37,6 +40,7 @@ func InitNode(rt *Runtime) taustate.StateNode {
 			storageKey:  doc.StorageKey,
 			contentType: doc.ContentType,
 			filename:    doc.Filename,
+			digest:      sha256.New(),
 		}
 
 		pages, err := handler.Extract(ctx, src, tempDir)
@@ -44,6 +48,18 @@ func InitNode(rt *Runtime) taustate.StateNode {
 			return s, fmt.Errorf("init: %w: %w", ErrRenderFailed, err)
 		}
 
+		// The count read at upload is only an estimate for formats such as
+		// Office documents, which are converted here rather than at upload.
+		if doc.PageCount == nil || *doc.PageCount != len(pages) {
+			if err := rt.Documents.UpdatePageCount(ctx, documentID, len(pages)); err != nil {
+				rt.Logger.WarnContext(
+					ctx, "update document page count failed",
+					"document_id", documentID,
+					"error", err,
+				)
+			}
+		}
+
 		rt.Logger.InfoContext(
 			ctx, "iinit node complete",
 			"document_id", documentID,
@@ -53,6 +69,7 @@ func InitNode(rt *Runtime) taustate.StateNode {
 
 		s = s.Set(state.KeyClassState, state.ClassificationState{Pages: pages})
 		s = s.Set(state.KeyFilename, doc.Filename)
+		s = s.Set(state.KeyDigest, hex.EncodeToString(src.digest.Sum(nil)))
 		s = s.Set(state.KeyPageCount, len(pages))
 
 		return s, nil
@@ -83,11 +100,15 @@ func extractInitState(s taustate.State) (uuid.UUID, string, error) {
 	return documentID, tempDir, nil
 }
 
+// blobSource reads a document from storage. When digest is set, the bytes
+// read are hashed into it; handlers read the source in full, so after
+// extraction it holds the digest of the whole file.
 type blobSource struct {
 	rt          *Runtime
 	storageKey  string
 	contentType string
 	filename    string
+	digest      hash.Hash
 }
 
 func (b *blobSource) Open(ctx context.Context) (io.ReadCloser, error) {
@@ -95,7 +116,15 @@ func (b *blobSource) Open(ctx context.Context) (io.ReadCloser, error) {
 	if err != nil {
 		return nil, err
 	}
-	return blob.Body, nil
+	if b.digest == nil {
+		return blob.Body, nil
+	}
+
+	b.digest.Reset()
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.TeeReader(blob.Body, b.digest), blob.Body}, nil
 }
 
 func (b *blobSource) ContentType() string { return b.contentType }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/tailored-agentic-units/orchestrate/observability"
 
+	"github.com/JaimeStill/herald/internal/metrics"
 	"github.com/JaimeStill/herald/pkg/core"
 
 	taustate "github.com/tailored-agentic-units/orchestrate/state"
@@ -17,19 +18,22 @@ import (
 // ExecutionEvents on a buffered channel. It implements observability.Observer and
 // acts as a filtering boundary: only node.start and node.complete events pass
 // through with controlled data fields. Non-blocking sends prevent slow consumers
-// from stalling graph execution.
+// from stalling graph execution. Node durations and failures are recorded in
+// the workflow metrics as node.complete events arrive.
 type StreamingObserver struct {
-	events chan ExecutionEvent
-	logger *slog.Logger
-	mu     sync.Mutex
-	closed bool
+	events  chan ExecutionEvent
+	logger  *slog.Logger
+	mu      sync.Mutex
+	closed  bool
+	started map[string]time.Time
 }
 
 // NewStreamingObserver creates a StreamingObserver with the given channel buffer size.
 func NewStreamingObserver(bufferSize int, logger *slog.Logger) *StreamingObserver {
 	return &StreamingObserver{
-		events: make(chan ExecutionEvent, bufferSize),
-		logger: logger,
+		events:  make(chan ExecutionEvent, bufferSize),
+		logger:  logger,
+		started: make(map[string]time.Time),
 	}
 }
 
@@ -61,7 +65,7 @@ func (o *StreamingObserver) OnEvent(ctx context.Context, event observability.Eve
 
 	switch event.Type {
 	case taustate.EventNodeStart:
-		execEvent = handleNodeStart(event)
+		execEvent = o.handleNodeStart(event)
 	case taustate.EventNodeComplete:
 		execEvent = o.handleNodeComplete(event)
 	}
@@ -113,11 +117,12 @@ func (o *StreamingObserver) SendError(err error, nodeName string) {
 	}
 }
 
-func handleNodeStart(event observability.Event) *ExecutionEvent {
+func (o *StreamingObserver) handleNodeStart(event observability.Event) *ExecutionEvent {
 	data, err := core.FromMap[NodeStartData](event.Data)
 	if err != nil {
 		return nil
 	}
+	o.started[data.Node] = event.Timestamp
 	return &ExecutionEvent{
 		Type:      NodeStart,
 		Timestamp: event.Timestamp,
@@ -133,6 +138,10 @@ func (o *StreamingObserver) handleNodeComplete(event observability.Event) *Execu
 	if err != nil {
 		return nil
 	}
+	if start, ok := o.started[data.Node]; ok {
+		metrics.ObserveNode(data.Node, event.Timestamp.Sub(start), data.Error)
+		delete(o.started, data.Node)
+	}
 	if data.Error {
 		o.logger.Error("node failed", "node", data.Node, "error", data.ErrorMessage)
 		return &ExecutionEvent{
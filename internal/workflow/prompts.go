@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/JaimeStill/herald/internal/prompts"
@@ -13,7 +14,8 @@ import (
 // ComposePrompt builds a system prompt by combining tunable instructions,
 // immutable specifications, and the running classification state for a given
 // workflow stage. When state is nil (first page), the prompt contains only
-// instructions and spec.
+// instructions and spec. Page text layers are left out of the state; the
+// classify and enhance stages send each page's text with its own image.
 func ComposePrompt(
 	ctx context.Context,
 	ps prompts.System,
@@ -36,7 +38,7 @@ func ComposePrompt(
 	sb.WriteString(spec)
 
 	if state != nil {
-		stateJSON, err := json.MarshalIndent(state, "", "  ")
+		stateJSON, err := json.MarshalIndent(withoutText(state), "", "  ")
 		if err != nil {
 			return "", fmt.Errorf("serialize classification state: %w", err)
 		}
@@ -47,3 +49,15 @@ func ComposePrompt(
 
 	return sb.String(), nil
 }
+
+// withoutText returns a copy of cs whose pages carry no text layer or
+// margin text.
+func withoutText(cs *state.ClassificationState) state.ClassificationState {
+	c := *cs
+	c.Pages = slices.Clone(cs.Pages)
+	for i := range c.Pages {
+		c.Pages[i].Text = ""
+		c.Pages[i].Margins = ""
+	}
+	return c
+}
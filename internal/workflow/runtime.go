@@ -1,26 +1,80 @@
 package workflow
 
 import (
-	"context"
 	"log/slog"
 
-	"github.com/tailored-agentic-units/agent"
-
+	"github.com/JaimeStill/herald/internal/agents"
 	"github.com/JaimeStill/herald/internal/documents"
 	"github.com/JaimeStill/herald/internal/format"
+	"github.com/JaimeStill/herald/internal/markings"
 	"github.com/JaimeStill/herald/internal/prompts"
+	"github.com/JaimeStill/herald/internal/state"
 	"github.com/JaimeStill/herald/pkg/storage"
 )
 
-// Runtime bundles the dependencies that workflow nodes require.
-// It is constructed by higher-level composition code from Infrastructure and Domain systems.
+// Runtime bundles the dependencies that workflow nodes require. It is
+// constructed by higher-level composition code from Infrastructure and Domain
+// systems.
 type Runtime struct {
-	NewAgent  func(ctx context.Context) (agent.Agent, error)
+	NewAgent  agents.Factory
 	Model     string
 	Provider  string
 	Storage   storage.System
 	Documents documents.System
 	Prompts   prompts.System
 	Formats   *format.Registry
-	Logger    *slog.Logger
+
+	// Markings drives the finalize stage's roll-up check, which is skipped
+	// when it is nil.
+	Markings *markings.Parser
+
+	// PartialPages lets a run continue past pages that fail to classify or
+	// enhance, recording the failures on the pages.
+	PartialPages bool
+
+	// Checkpoints saves each run's progress so an interrupted run can
+	// resume; runs are not checkpointed when it is nil.
+	Checkpoints CheckpointStore
+
+	// Escalation re-runs results whose confidence is at or below its
+	// threshold with a stronger agent; runs do not escalate when it is nil.
+	Escalation *Escalation
+
+	// TextFastPath classifies pages whose text layer carries unambiguous
+	// markings without a vision call. It needs Markings.
+	TextFastPath bool
+
+	// Strategy selects what the classify stage sends the vision model first.
+	Strategy Strategy
+
+	Logger *slog.Logger
+}
+
+// Strategy selects what the classify stage sends the vision model for each
+// page.
+type Strategy string
+
+const (
+	// StrategyPage sends the full page image. It is the default.
+	StrategyPage Strategy = "page"
+	// StrategyBanners sends the page's banner crop first and falls back to
+	// the full page when the crop shows no legible marking or the model asks
+	// for enhancement. Pages without a banner crop, because their format
+	// handler is not in crop mode, are sent in full.
+	StrategyBanners Strategy = "banners"
+)
+
+// Escalation names the agent that classifies a document again when the
+// first answer's confidence is at or below Threshold.
+type Escalation struct {
+	Agent     agents.Entry
+	Threshold state.Confidence
+}
+
+// escalates reports whether a finalized run with state cs should be
+// classified again by the escalation agent.
+func (rt *Runtime) escalates(cs *state.ClassificationState) bool {
+	return rt.Escalation != nil &&
+		cs.Escalation == nil &&
+		cs.Confidence.AtOrBelow(rt.Escalation.Threshold)
 }
@@ -0,0 +1,107 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/JaimeStill/herald/internal/markings"
+	"github.com/JaimeStill/herald/internal/state"
+)
+
+// portionPattern matches a parenthesized portion marking candidate such as
+// "(U)" or "(S//NF)". Only upper-case candidates are considered, so
+// enumerators and asides such as "(a)" or "(see below)" are never read as
+// markings.
+var portionPattern = regexp.MustCompile(`\(([A-Z][A-Z0-9 ,/-]*)\)`)
+
+// TextMarkings reads a page's markings from its text layer. It reports true
+// only when the text is unambiguous: the first and last lines are the same
+// banner marking, the page carries at least one portion marking, every
+// parenthesized token whose level parses is a valid portion marking, and the
+// portions roll up to exactly the banner. The returned markings are the
+// banner in canonical form followed by the distinct portion markings as they
+// appear in the text.
+func TextMarkings(p *markings.Parser, text string) ([]string, bool) {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if len(lines) < 2 {
+		return nil, false
+	}
+
+	top := strings.TrimSpace(lines[0])
+	bottom := strings.TrimSpace(lines[len(lines)-1])
+
+	banner, err := p.Parse(top)
+	if err != nil {
+		return nil, false
+	}
+	if m, err := p.Parse(bottom); err != nil || m.Canonical != banner.Canonical {
+		return nil, false
+	}
+
+	var portions []string
+	for _, match := range portionPattern.FindAllStringSubmatch(strings.Join(lines[1:len(lines)-1], "\n"), -1) {
+		level, _, _ := strings.Cut(match[1], "//")
+		if _, err := p.Parse(level); err != nil {
+			continue
+		}
+		if _, err := p.Parse(match[1]); err != nil {
+			return nil, false
+		}
+		if !slices.Contains(portions, match[0]) {
+			portions = append(portions, match[0])
+		}
+	}
+	if len(portions) == 0 {
+		return nil, false
+	}
+
+	rolled, ok := p.RollUp(portions)
+	if !ok || rolled.Canonical != banner.Canonical {
+		return nil, false
+	}
+
+	return append([]string{banner.Canonical}, portions...), true
+}
+
+// classifyFromText classifies page from its text layer when the runtime
+// allows it and the text is unambiguous, reporting whether it did. A page
+// classified this way makes no vision call.
+func classifyFromText(rt *Runtime, page *state.ClassificationPage) bool {
+	if !rt.TextFastPath || rt.Markings == nil || page.Text == "" {
+		return false
+	}
+
+	found, ok := TextMarkings(rt.Markings, page.Text)
+	if !ok {
+		return false
+	}
+
+	page.MarkingsFound = found
+	page.Rationale = fmt.Sprintf(
+		"Read from the page's text layer: the banner %s matches the roll-up of the portion markings %s.",
+		found[0], strings.Join(found[1:], ", "),
+	)
+	page.Enhancements = nil
+	page.TextOnly = true
+	return true
+}
+
+// pagePrompt appends the page's text layer and margin text to prompt as
+// corroborating evidence. Pages with neither use prompt unchanged.
+func pagePrompt(prompt string, page *state.ClassificationPage) string {
+	if page.Text != "" {
+		prompt += "\n\nText layer extracted from this page, offered as corroborating evidence. " +
+			"It may be incomplete or out of reading order; where it disagrees with the image, " +
+			"report what the image shows:\n\n" + page.Text
+	}
+
+	if page.Margins != "" {
+		prompt += "\n\nHeader and footer text read from the source document, offered as " +
+			"corroborating evidence for the banner markings. It may apply to more pages than " +
+			"this one; where it disagrees with the image, report what the image shows:\n\n" + page.Margins
+	}
+
+	return prompt
+}
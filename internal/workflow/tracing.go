@@ -0,0 +1,91 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/tailored-agentic-units/orchestrate/observability"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/JaimeStill/herald/internal/prompts"
+	"github.com/JaimeStill/herald/internal/tracing"
+	"github.com/JaimeStill/herald/pkg/core"
+
+	taustate "github.com/tailored-agentic-units/orchestrate/state"
+)
+
+var tracer = otel.Tracer("github.com/JaimeStill/herald/internal/workflow")
+
+// TracingObserver opens a span for each state-graph node, from its
+// node.start event to the matching node.complete. Spans are parented to the
+// context the graph reports events under, which Execute places inside the
+// workflow.execute span. Failed nodes end with an error status.
+type TracingObserver struct {
+	mu    sync.Mutex
+	spans map[string]trace.Span
+}
+
+// NewTracingObserver creates a TracingObserver.
+func NewTracingObserver() *TracingObserver {
+	return &TracingObserver{spans: make(map[string]trace.Span)}
+}
+
+// OnEvent implements observability.Observer.
+func (o *TracingObserver) OnEvent(ctx context.Context, event observability.Event) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	switch event.Type {
+	case taustate.EventNodeStart:
+		data, err := core.FromMap[NodeStartData](event.Data)
+		if err != nil {
+			return
+		}
+		_, span := tracer.Start(ctx, "workflow.node "+data.Node,
+			trace.WithTimestamp(event.Timestamp),
+			trace.WithAttributes(
+				attribute.String("workflow.node", data.Node),
+				attribute.Int("workflow.iteration", data.Iteration),
+			),
+		)
+		o.spans[data.Node] = span
+	case taustate.EventNodeComplete:
+		data, err := core.FromMap[NodeCompleteData](event.Data)
+		if err != nil {
+			return
+		}
+		span, ok := o.spans[data.Node]
+		if !ok {
+			return
+		}
+		delete(o.spans, data.Node)
+
+		var nodeErr error
+		if data.Error {
+			nodeErr = errors.New(data.ErrorMessage)
+		}
+		tracing.End(span, nodeErr)
+	}
+}
+
+// observers fans each event out to every observer in order.
+type observers []observability.Observer
+
+func (obs observers) OnEvent(ctx context.Context, event observability.Event) {
+	for _, o := range obs {
+		o.OnEvent(ctx, event)
+	}
+}
+
+// startPage opens the span covering one page's work within a stage.
+func startPage(ctx context.Context, stage prompts.Stage, page int) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "workflow.page",
+		trace.WithAttributes(
+			attribute.String("workflow.stage", string(stage)),
+			attribute.Int("workflow.page", page),
+		),
+	)
+}
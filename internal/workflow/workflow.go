@@ -7,33 +7,82 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/JaimeStill/herald/internal/state"
+	"github.com/JaimeStill/herald/internal/tracing"
 
 	tauconfig "github.com/tailored-agentic-units/orchestrate/config"
 	taustate "github.com/tailored-agentic-units/orchestrate/state"
 )
 
+// Node names of the classification state graph.
+const (
+	nodeInit     = "init"
+	nodeClassify = "classify"
+	nodeEnhance  = "enhance"
+	nodeFinalize = "finalize"
+	nodeEscalate = "escalate"
+	nodeDone     = "done"
+)
+
 // Execute runs the classification workflow for a single document. It creates
 // a temp directory for page images (cleaned up via defer), builds the state
-// graph (init → classify → enhance? → finalize), executes it, and extracts
-// the WorkflowResult from the final state.
-func Execute(ctx context.Context, rt *Runtime, documentID uuid.UUID, observer *StreamingObserver) (*WorkflowResult, error) {
+// graph (init → classify → enhance? → finalize → escalate?), executes it, and
+// extracts the WorkflowResult from the final state. The run is traced as a
+// workflow.execute span enclosing the node and page spans.
+//
+// Progress is checkpointed under runID when the runtime has a checkpoint
+// store. If runID already has a checkpoint, the run resumes after the last
+// node it completed instead of starting over.
+func Execute(
+	ctx context.Context,
+	rt *Runtime,
+	runID uuid.UUID,
+	documentID uuid.UUID,
+	observer *StreamingObserver,
+) (result *WorkflowResult, err error) {
+	ctx, span := tracer.Start(ctx, "workflow.execute",
+		trace.WithAttributes(
+			attribute.String("run.id", runID.String()),
+			attribute.String("document.id", documentID.String()),
+		),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	tempDir, err := os.MkdirTemp("", "herald-classify-*")
 	if err != nil {
 		return nil, fmt.Errorf("create temp directory: %w", err)
 	}
 	defer os.RemoveAll(tempDir)
 
-	graph, err := buildGraph(rt, observer)
-	if err != nil {
-		return nil, fmt.Errorf("build graph: %w", err)
-	}
-
 	initialState := taustate.New(nil)
+	initialState = initialState.Set(state.KeyRunID, runID)
 	initialState = initialState.Set(state.KeyDocumentID, documentID)
 	initialState = initialState.Set(state.KeyTempDir, tempDir)
 
+	entry := nodeInit
+
+	cp, err := loadCheckpoint(ctx, rt, runID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if cp != nil {
+		entry, initialState, err = resume(ctx, rt, cp, initialState, tempDir)
+		if err != nil {
+			return nil, err
+		}
+		if entry == "" {
+			return extractResult(initialState)
+		}
+	}
+
+	graph, err := buildGraph(rt, observer, entry)
+	if err != nil {
+		return nil, fmt.Errorf("build graph: %w", err)
+	}
+
 	finalState, err := graph.Execute(ctx, initialState)
 	if err != nil {
 		return nil, fmt.Errorf("execute graph: %w", err)
@@ -42,61 +91,101 @@ func Execute(ctx context.Context, rt *Runtime, documentID uuid.UUID, observer *S
 	return extractResult(finalState)
 }
 
-func buildGraph(rt *Runtime, observer *StreamingObserver) (taustate.StateGraph, error) {
+// buildGraph assembles the classification graph, entering at entry so a
+// resumed run skips the nodes its checkpoint already completed. When the
+// runtime escalates, finalize is followed by escalate for low-confidence
+// results, and both paths end at a done node that passes the state through.
+func buildGraph(rt *Runtime, observer *StreamingObserver, entry string) (taustate.StateGraph, error) {
 	cfg := tauconfig.DefaultGraphConfig("herald-classify")
 
-	graph, err := taustate.NewGraphWithDeps(cfg, observer, nil)
+	graph, err := taustate.NewGraphWithDeps(cfg, observers{observer, NewTracingObserver()}, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := graph.AddNode("init", InitNode(rt)); err != nil {
+	if err := graph.AddNode(nodeInit, checkpointed(rt, nodeInit, InitNode(rt))); err != nil {
 		return nil, err
 	}
 
-	if err := graph.AddNode("classify", ClassifyNode(rt)); err != nil {
+	if err := graph.AddNode(nodeClassify, checkpointed(rt, nodeClassify, ClassifyNode(rt))); err != nil {
 		return nil, err
 	}
 
-	if err := graph.AddNode("enhance", EnhanceNode(rt)); err != nil {
+	if err := graph.AddNode(nodeEnhance, checkpointed(rt, nodeEnhance, EnhanceNode(rt))); err != nil {
 		return nil, err
 	}
 
-	if err := graph.AddNode("finalize", FinalizeNode(rt)); err != nil {
+	if err := graph.AddNode(nodeFinalize, checkpointed(rt, nodeFinalize, FinalizeNode(rt))); err != nil {
 		return nil, err
 	}
 
 	// init → classify (unconditional)
-	if err := graph.AddEdge("init", "classify", nil); err != nil {
+	if err := graph.AddEdge(nodeInit, nodeClassify, nil); err != nil {
 		return nil, err
 	}
 
 	// classify → enhance (when any page needs enhancement)
-	if err := graph.AddEdge("classify", "enhance", needsEnhance); err != nil {
+	if err := graph.AddEdge(nodeClassify, nodeEnhance, needsEnhance); err != nil {
 		return nil, err
 	}
 
 	// classify → finalize (when no enhancement needed)
-	if err := graph.AddEdge("classify", "finalize", taustate.Not(needsEnhance)); err != nil {
+	if err := graph.AddEdge(nodeClassify, nodeFinalize, taustate.Not(needsEnhance)); err != nil {
 		return nil, err
 	}
 
 	// enhance → finalize (unconditional)
-	if err := graph.AddEdge("enhance", "finalize", nil); err != nil {
+	if err := graph.AddEdge(nodeEnhance, nodeFinalize, nil); err != nil {
 		return nil, err
 	}
 
-	if err := graph.SetEntryPoint("init"); err != nil {
+	exit := nodeFinalize
+	if rt.Escalation != nil {
+		if err := addEscalation(rt, graph); err != nil {
+			return nil, err
+		}
+		exit = nodeDone
+	}
+
+	if err := graph.SetEntryPoint(entry); err != nil {
 		return nil, err
 	}
 
-	if err := graph.SetExitPoint("finalize"); err != nil {
+	if err := graph.SetExitPoint(exit); err != nil {
 		return nil, err
 	}
 
 	return graph, nil
 }
 
+// addEscalation adds the escalate and done nodes and routes finalize through
+// them.
+func addEscalation(rt *Runtime, graph taustate.StateGraph) error {
+	if err := graph.AddNode(nodeEscalate, checkpointed(rt, nodeEscalate, EscalateNode(rt))); err != nil {
+		return err
+	}
+
+	done := taustate.NewFunctionNode(func(_ context.Context, s taustate.State) (taustate.State, error) {
+		return s, nil
+	})
+	if err := graph.AddNode(nodeDone, done); err != nil {
+		return err
+	}
+
+	// finalize → escalate (when confidence is at or below the threshold)
+	if err := graph.AddEdge(nodeFinalize, nodeEscalate, needsEscalation(rt)); err != nil {
+		return err
+	}
+
+	// finalize → done (when confident enough)
+	if err := graph.AddEdge(nodeFinalize, nodeDone, taustate.Not(needsEscalation(rt))); err != nil {
+		return err
+	}
+
+	// escalate → done (unconditional)
+	return graph.AddEdge(nodeEscalate, nodeDone, nil)
+}
+
 func extractResult(s taustate.State) (*WorkflowResult, error) {
 	val, ok := s.Get(state.KeyClassState)
 	if !ok {
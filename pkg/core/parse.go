@@ -42,3 +42,15 @@ func FromMap[T any](data map[string]any) (T, error) {
 	err = json.Unmarshal(b, &result)
 	return result, err
 }
+
+// ToMap encodes a value into a map[string]any via JSON round-trip, the inverse
+// of FromMap. Useful for attaching domain types to event data.
+func ToMap(v any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]any
+	err = json.Unmarshal(b, &result)
+	return result, err
+}
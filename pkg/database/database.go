@@ -4,14 +4,18 @@ package database
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/XSAM/otelsql"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/stdlib"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/JaimeStill/herald/pkg/lifecycle"
 )
@@ -30,11 +34,27 @@ type database struct {
 	connTimeout time.Duration
 }
 
+// traceOptions configures OpenTelemetry spans for queries issued through
+// the pool. Spans are only created under an existing trace, so background
+// pool maintenance and untraced callers produce no root spans.
+func traceOptions() []otelsql.Option {
+	return []otelsql.Option{
+		otelsql.WithAttributes(semconv.DBSystemNamePostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+			SpanFilter: func(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+				return trace.SpanContextFromContext(ctx).IsValid()
+			},
+		}),
+	}
+}
+
 // New creates a database system with the given configuration.
 // It calls sql.Open to validate the DSN and configure pool parameters,
 // but does not establish a connection until Start is called.
 func New(cfg *Config, logger *slog.Logger) (System, error) {
-	db, err := sql.Open("pgx", cfg.Dsn())
+	db, err := otelsql.Open("pgx", cfg.Dsn(), traceOptions()...)
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
@@ -78,7 +98,7 @@ func NewWithCredential(
 		},
 	)
 
-	db := stdlib.OpenDB(*connConfig, beforeConnect)
+	db := otelsql.OpenDB(stdlib.GetConnector(*connConfig, beforeConnect), traceOptions()...)
 
 	db.SetMaxOpenConns(cfg.MaxOpenConns)
 	db.SetMaxIdleConns(cfg.MaxIdleConns)
@@ -110,8 +130,10 @@ func (d *database) Start(lc *lifecycle.Coordinator) error {
 		d.logger.Info("database connection established")
 	})
 
+	// Requests and workers drain first; closing under them fails their
+	// final writes.
 	lc.OnShutdown(func() {
-		<-lc.Context().Done()
+		<-lc.Drained()
 		d.logger.Info("closing database connection")
 
 		if err := d.conn.Close(); err != nil {
@@ -17,7 +17,10 @@ type Coordinator struct {
 	ctx        context.Context
 	cancel     context.CancelFunc
 	startupWg  sync.WaitGroup
+	drainWg    sync.WaitGroup
 	shutdownWg sync.WaitGroup
+	drained    chan struct{}
+	drainOnce  sync.Once
 	ready      bool
 	readyMu    sync.RWMutex
 }
@@ -26,8 +29,9 @@ type Coordinator struct {
 func New() *Coordinator {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Coordinator{
-		ctx:    ctx,
-		cancel: cancel,
+		ctx:     ctx,
+		cancel:  cancel,
+		drained: make(chan struct{}),
 	}
 }
 
@@ -41,12 +45,27 @@ func (c *Coordinator) OnStartup(fn func()) {
 	c.startupWg.Go(fn)
 }
 
+// OnDrain registers a function to run concurrently during shutdown that
+// stops work using shared resources, such as in-flight requests and
+// background workers. Drain hooks should block on <-c.Context().Done()
+// before stopping that work.
+func (c *Coordinator) OnDrain(fn func()) {
+	c.drainWg.Go(fn)
+}
+
 // OnShutdown registers a function to run concurrently during shutdown.
-// Shutdown hooks should block on <-c.Context().Done() before executing cleanup.
+// Shutdown hooks should block on <-c.Context().Done() before executing cleanup,
+// or on <-c.Drained() when they release resources that drain hooks still use.
 func (c *Coordinator) OnShutdown(fn func()) {
 	c.shutdownWg.Go(fn)
 }
 
+// Drained returns a channel closed once shutdown has begun and every drain
+// hook has returned.
+func (c *Coordinator) Drained() <-chan struct{} {
+	return c.drained
+}
+
 // Ready returns true after all startup hooks have completed.
 func (c *Coordinator) Ready() bool {
 	c.readyMu.RLock()
@@ -62,13 +81,21 @@ func (c *Coordinator) WaitForStartup() {
 	c.readyMu.Unlock()
 }
 
-// Shutdown cancels the context and waits for shutdown hooks to complete
-// within the given timeout.
+// Shutdown cancels the context and waits for drain and shutdown hooks to
+// complete within the given timeout.
 func (c *Coordinator) Shutdown(timeout time.Duration) error {
 	c.cancel()
 
+	c.drainOnce.Do(func() {
+		go func() {
+			c.drainWg.Wait()
+			close(c.drained)
+		}()
+	})
+
 	done := make(chan struct{})
 	go func() {
+		<-c.drained
 		c.shutdownWg.Wait()
 		close(done)
 	}()
@@ -85,6 +85,27 @@ func (b *Builder) Build() (string, []any) {
 	return sql, args
 }
 
+// BuildSelect returns an unordered SELECT of only the given logical fields with the
+// current conditions. It is suited to subqueries such as INSERT ... SELECT where
+// the full projection and ordering are unnecessary.
+func (b *Builder) BuildSelect(fields ...string) (string, []any) {
+	where, args, _ := b.buildWhere(1)
+
+	cols := make([]string, len(fields))
+	for i, f := range fields {
+		cols[i] = b.projection.Column(f)
+	}
+
+	sql := fmt.Sprintf(
+		"SELECT %s FROM %s%s",
+		strings.Join(cols, ", "),
+		b.projection.From(),
+		where,
+	)
+
+	return sql, args
+}
+
 // BuildCount returns a COUNT(*) query with the current conditions.
 func (b *Builder) BuildCount() (string, []any) {
 	where, args, _ := b.buildWhere(1)
@@ -184,6 +205,20 @@ func (b *Builder) WhereIn(field string, values []any) *Builder {
 	return b
 }
 
+// WhereJSONContains adds a JSONB containment condition matching rows whose
+// array column includes value. No-op for nil or empty values.
+func (b *Builder) WhereJSONContains(field string, value *string) *Builder {
+	if value == nil || *value == "" {
+		return b
+	}
+	col := b.projection.Column(field)
+	b.conditions = append(b.conditions, condition{
+		clause: fmt.Sprintf("%s @> jsonb_build_array($%%d::text)", col),
+		args:   []any{*value},
+	})
+	return b
+}
+
 // WhereNullable adds an equality or IS NULL condition depending on whether value is nil.
 func (b *Builder) WhereNullable(column string, val any) *Builder {
 	col := b.projection.Column(column)
@@ -202,6 +237,21 @@ func (b *Builder) WhereNullable(column string, val any) *Builder {
 	return b
 }
 
+// WherePresent adds an IS NOT NULL condition when present is true and an
+// IS NULL condition when it is false. No-op for nil values.
+func (b *Builder) WherePresent(field string, present *bool) *Builder {
+	if present == nil {
+		return b
+	}
+	col := b.projection.Column(field)
+	clause := col + " IS NULL"
+	if *present {
+		clause = col + " IS NOT NULL"
+	}
+	b.conditions = append(b.conditions, condition{clause: clause})
+	return b
+}
+
 // WhereSearch adds an OR condition across multiple fields with ILIKE. No-op for nil or empty search.
 func (b *Builder) WhereSearch(search *string, fields ...string) *Builder {
 	if search == nil || *search == "" || len(fields) == 0 {
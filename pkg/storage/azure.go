@@ -0,0 +1,262 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+
+	"github.com/JaimeStill/herald/pkg/lifecycle"
+)
+
+type azure struct {
+	client    *azblob.Client
+	container string
+	logger    *slog.Logger
+}
+
+// NewAzure creates an Azure Blob Storage system from the given configuration.
+// It validates the connection string and creates the Azure client
+// but does not establish a connection until Start is called.
+func NewAzure(cfg *Config, logger *slog.Logger) (System, error) {
+	if cfg.ConnectionString == "" {
+		return nil, fmt.Errorf("connection_string required for connection string auth")
+	}
+
+	client, err := azblob.NewClientFromConnectionString(cfg.ConnectionString, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create storage client: %w", err)
+	}
+
+	return &azure{
+		client:    client,
+		container: cfg.ContainerName,
+		logger:    logger.With("system", "storage"),
+	}, nil
+}
+
+// NewWithCredential creates a storage system using an Azure token credential.
+// It requires ServiceURL in the config and creates the client via azblob.NewClient.
+// Connection is not established until Start is called.
+func NewWithCredential(cfg *Config, cred azcore.TokenCredential, logger *slog.Logger) (System, error) {
+	if cfg.ServiceURL == "" {
+		return nil, fmt.Errorf("service_url required for credential auth")
+	}
+
+	client, err := azblob.NewClient(cfg.ServiceURL, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create storage client: %w", err)
+	}
+
+	return &azure{
+		client:    client,
+		container: cfg.ContainerName,
+		logger:    logger.With("system", "storage"),
+	}, nil
+}
+
+func (a *azure) Start(lc *lifecycle.Coordinator) error {
+	a.logger.Info("starting storage system")
+
+	lc.OnStartup(func() {
+		_, err := a.client.CreateContainer(lc.Context(), a.container, nil)
+		if err != nil {
+			if !bloberror.HasCode(err, bloberror.ContainerAlreadyExists) {
+				a.logger.Error("storage container initialization failed", "error", err)
+				return
+			}
+		}
+
+		a.logger.Info("storage container ready", "container", a.container)
+	})
+
+	return nil
+}
+
+func (a *azure) List(
+	ctx context.Context,
+	prefix string,
+	marker string,
+	maxResults int32,
+) (*BlobList, error) {
+	containerClient := a.client.ServiceClient().NewContainerClient(a.container)
+
+	opts := &container.ListBlobsFlatOptions{
+		MaxResults: &maxResults,
+	}
+	if prefix != "" {
+		opts.Prefix = &prefix
+	}
+	if marker != "" {
+		opts.Marker = &marker
+	}
+
+	pager := containerClient.NewListBlobsFlatPager(opts)
+	if !pager.More() {
+		return &BlobList{Blobs: []BlobMeta{}}, nil
+	}
+
+	resp, err := pager.NextPage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list blobs: %w", err)
+	}
+
+	blobs := make([]BlobMeta, 0, len(resp.Segment.BlobItems))
+	for _, b := range resp.Segment.BlobItems {
+		var meta BlobMeta
+		if b.Name != nil {
+			meta.Name = *b.Name
+		}
+		if b.Properties != nil {
+			if b.Properties.ContentType != nil {
+				meta.ContentType = *b.Properties.ContentType
+			}
+			if b.Properties.ContentLength != nil {
+				meta.ContentLength = *b.Properties.ContentLength
+			}
+			if b.Properties.LastModified != nil {
+				meta.LastModified = *b.Properties.LastModified
+			}
+			if b.Properties.ETag != nil {
+				meta.ETag = string(*b.Properties.ETag)
+			}
+			if b.Properties.CreationTime != nil {
+				meta.CreatedAt = *b.Properties.CreationTime
+			}
+		}
+		blobs = append(blobs, meta)
+	}
+
+	result := &BlobList{Blobs: blobs}
+	if resp.NextMarker != nil && *resp.NextMarker != "" {
+		result.NextMarker = *resp.NextMarker
+	}
+	return result, nil
+}
+
+func (a *azure) Find(ctx context.Context, key string) (*BlobMeta, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	blobClient := a.client.
+		ServiceClient().
+		NewContainerClient(a.container).
+		NewBlobClient(key)
+
+	resp, err := blobClient.GetProperties(ctx, nil)
+	if err != nil {
+		if bloberror.HasCode(err, bloberror.BlobNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get blob properties %s: %w", key, err)
+	}
+
+	meta := &BlobMeta{Name: key}
+	if resp.ContentType != nil {
+		meta.ContentType = *resp.ContentType
+	}
+	if resp.ContentLength != nil {
+		meta.ContentLength = *resp.ContentLength
+	}
+	if resp.LastModified != nil {
+		meta.LastModified = *resp.LastModified
+	}
+	if resp.ETag != nil {
+		meta.ETag = string(*resp.ETag)
+	}
+	if resp.CreationTime != nil {
+		meta.CreatedAt = *resp.CreationTime
+	}
+	return meta, nil
+}
+
+func (a *azure) Upload(ctx context.Context, key string, reader io.Reader, contentType string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	opts := &azblob.UploadStreamOptions{
+		HTTPHeaders: &blob.HTTPHeaders{
+			BlobContentType: &contentType,
+		},
+	}
+
+	_, err := a.client.UploadStream(ctx, a.container, key, reader, opts)
+	if err != nil {
+		return fmt.Errorf("upload blob %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (a *azure) Download(ctx context.Context, key string) (*BlobResult, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	resp, err := a.client.DownloadStream(ctx, a.container, key, nil)
+	if err != nil {
+		if bloberror.HasCode(err, bloberror.BlobNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("download blob %s: %w", key, err)
+	}
+
+	result := &BlobResult{
+		BlobMeta: BlobMeta{Name: key},
+		Body:     resp.Body,
+	}
+
+	if resp.ContentType != nil {
+		result.ContentType = *resp.ContentType
+	}
+	if resp.ContentLength != nil {
+		result.ContentLength = *resp.ContentLength
+	}
+
+	return result, nil
+}
+
+func (a *azure) Delete(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	_, err := a.client.DeleteBlob(ctx, a.container, key, nil)
+	if err != nil {
+		if bloberror.HasCode(err, bloberror.BlobNotFound) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("delete blob %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (a *azure) Exists(ctx context.Context, key string) (bool, error) {
+	if err := validateKey(key); err != nil {
+		return false, err
+	}
+
+	blobClient := a.client.
+		ServiceClient().
+		NewContainerClient(a.container).
+		NewBlobClient(key)
+
+	_, err := blobClient.GetProperties(ctx, nil)
+	if err != nil {
+		if bloberror.HasCode(err, bloberror.BlobNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("check blob existence %s: %w", key, err)
+	}
+
+	return true, nil
+}
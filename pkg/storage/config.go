@@ -6,19 +6,42 @@ import (
 	"strconv"
 )
 
-// Config holds Azure Blob Storage connection parameters.
+// Storage backend names accepted by Config.Backend.
+const (
+	BackendAzure      = "azure"
+	BackendFilesystem = "filesystem"
+	BackendS3         = "s3"
+)
+
+// Config holds blob storage parameters. Backend selects the implementation;
+// ContainerName is the Azure container, the S3 bucket, or the directory under
+// Root for the filesystem backend. ConnectionString and ServiceURL apply to
+// Azure, Root to the filesystem, and Endpoint, Region, AccessKeyID, and
+// SecretAccessKey to S3-compatible services.
 type Config struct {
+	Backend          string `json:"backend"`
 	ContainerName    string `json:"container_name"`
 	ConnectionString string `json:"connection_string"`
 	ServiceURL       string `json:"service_url"`
+	Root             string `json:"root"`
+	Endpoint         string `json:"endpoint"`
+	Region           string `json:"region"`
+	AccessKeyID      string `json:"access_key_id"`
+	SecretAccessKey  string `json:"secret_access_key"`
 	MaxListSize      int32  `json:"max_list_size"`
 }
 
 // Env maps config fields to environment variable names for override injection.
 type Env struct {
+	Backend          string
 	ContainerName    string
 	ConnectionString string
 	ServiceURL       string
+	Root             string
+	Endpoint         string
+	Region           string
+	AccessKeyID      string
+	SecretAccessKey  string
 	MaxListSize      string
 }
 
@@ -33,6 +56,9 @@ func (c *Config) Finalize(env *Env) error {
 
 // Merge overwrites non-zero fields from overlay.
 func (c *Config) Merge(overlay *Config) {
+	if overlay.Backend != "" {
+		c.Backend = overlay.Backend
+	}
 	if overlay.ContainerName != "" {
 		c.ContainerName = overlay.ContainerName
 	}
@@ -42,16 +68,39 @@ func (c *Config) Merge(overlay *Config) {
 	if overlay.ServiceURL != "" {
 		c.ServiceURL = overlay.ServiceURL
 	}
+	if overlay.Root != "" {
+		c.Root = overlay.Root
+	}
+	if overlay.Endpoint != "" {
+		c.Endpoint = overlay.Endpoint
+	}
+	if overlay.Region != "" {
+		c.Region = overlay.Region
+	}
+	if overlay.AccessKeyID != "" {
+		c.AccessKeyID = overlay.AccessKeyID
+	}
+	if overlay.SecretAccessKey != "" {
+		c.SecretAccessKey = overlay.SecretAccessKey
+	}
 	if overlay.MaxListSize != 0 {
 		c.MaxListSize = overlay.MaxListSize
 	}
-
 }
 
 func (c *Config) loadDefaults() {
+	if c.Backend == "" {
+		c.Backend = BackendAzure
+	}
 	if c.ContainerName == "" {
 		c.ContainerName = "documents"
 	}
+	if c.Root == "" {
+		c.Root = ".storage"
+	}
+	if c.Region == "" {
+		c.Region = "us-east-1"
+	}
 	if c.MaxListSize == 0 {
 		c.MaxListSize = 50
 	}
@@ -61,6 +110,11 @@ func (c *Config) loadDefaults() {
 }
 
 func (c *Config) loadEnv(env *Env) {
+	if env.Backend != "" {
+		if v := os.Getenv(env.Backend); v != "" {
+			c.Backend = v
+		}
+	}
 	if env.ContainerName != "" {
 		if v := os.Getenv(env.ContainerName); v != "" {
 			c.ContainerName = v
@@ -76,6 +130,31 @@ func (c *Config) loadEnv(env *Env) {
 			c.ServiceURL = v
 		}
 	}
+	if env.Root != "" {
+		if v := os.Getenv(env.Root); v != "" {
+			c.Root = v
+		}
+	}
+	if env.Endpoint != "" {
+		if v := os.Getenv(env.Endpoint); v != "" {
+			c.Endpoint = v
+		}
+	}
+	if env.Region != "" {
+		if v := os.Getenv(env.Region); v != "" {
+			c.Region = v
+		}
+	}
+	if env.AccessKeyID != "" {
+		if v := os.Getenv(env.AccessKeyID); v != "" {
+			c.AccessKeyID = v
+		}
+	}
+	if env.SecretAccessKey != "" {
+		if v := os.Getenv(env.SecretAccessKey); v != "" {
+			c.SecretAccessKey = v
+		}
+	}
 	if env.MaxListSize != "" {
 		if v := os.Getenv(env.MaxListSize); v != "" {
 			if n, err := strconv.Atoi(v); err == nil && n > 0 {
@@ -86,6 +165,11 @@ func (c *Config) loadEnv(env *Env) {
 }
 
 func (c *Config) validate() error {
+	switch c.Backend {
+	case BackendAzure, BackendFilesystem, BackendS3:
+	default:
+		return fmt.Errorf("unsupported backend %q", c.Backend)
+	}
 	if c.ContainerName == "" {
 		return fmt.Errorf("container_name required")
 	}
@@ -0,0 +1,415 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/JaimeStill/herald/pkg/lifecycle"
+)
+
+// Directories under the filesystem container root. Blob content lives under
+// blobs, sidecar metadata under meta at the same relative path, and uploads are
+// staged under tmp so partially written files are never listed.
+const (
+	fsBlobsDir = "blobs"
+	fsMetaDir  = "meta"
+	fsTmpDir   = "tmp"
+)
+
+type filesystem struct {
+	root   string
+	logger *slog.Logger
+}
+
+// fileMeta is the sidecar record for properties the filesystem does not keep.
+type fileMeta struct {
+	ContentType string    `json:"content_type"`
+	ETag        string    `json:"etag"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NewFilesystem creates a storage system that keeps blobs as files under
+// Root/ContainerName. Directories are created when Start is called.
+func NewFilesystem(cfg *Config, logger *slog.Logger) (System, error) {
+	if cfg.Root == "" {
+		return nil, fmt.Errorf("root required for filesystem storage")
+	}
+
+	root, err := filepath.Abs(filepath.Join(cfg.Root, cfg.ContainerName))
+	if err != nil {
+		return nil, fmt.Errorf("resolve storage root: %w", err)
+	}
+
+	return &filesystem{
+		root:   root,
+		logger: logger.With("system", "storage"),
+	}, nil
+}
+
+func (f *filesystem) Start(lc *lifecycle.Coordinator) error {
+	f.logger.Info("starting storage system")
+
+	lc.OnStartup(func() {
+		for _, dir := range []string{fsBlobsDir, fsMetaDir, fsTmpDir} {
+			if err := os.MkdirAll(filepath.Join(f.root, dir), 0o755); err != nil {
+				f.logger.Error("storage directory initialization failed", "error", err)
+				return
+			}
+		}
+
+		f.logger.Info("storage directory ready", "root", f.root)
+	})
+
+	return nil
+}
+
+func (f *filesystem) List(
+	ctx context.Context,
+	prefix string,
+	marker string,
+	maxResults int32,
+) (*BlobList, error) {
+	if maxResults <= 0 || maxResults > MaxListCap {
+		maxResults = MaxListCap
+	}
+
+	keys, err := f.keys(ctx, prefix, marker, int(maxResults)+1)
+	if err != nil {
+		return nil, fmt.Errorf("list blobs: %w", err)
+	}
+
+	result := &BlobList{}
+	if len(keys) > int(maxResults) {
+		keys = keys[:maxResults]
+		result.NextMarker = keys[len(keys)-1]
+	}
+
+	result.Blobs = make([]BlobMeta, 0, len(keys))
+	for _, key := range keys {
+		info, err := os.Stat(f.blobPath(key))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("list blobs: %w", err)
+		}
+
+		meta, err := f.blobMeta(key, info)
+		if err != nil {
+			return nil, fmt.Errorf("list blobs: %w", err)
+		}
+		result.Blobs = append(result.Blobs, *meta)
+	}
+
+	return result, nil
+}
+
+func (f *filesystem) Find(ctx context.Context, key string) (*BlobMeta, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(f.blobPath(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("stat blob %s: %w", key, err)
+	}
+	if info.IsDir() {
+		return nil, ErrNotFound
+	}
+
+	return f.blobMeta(key, info)
+}
+
+func (f *filesystem) Upload(ctx context.Context, key string, reader io.Reader, contentType string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	target := f.blobPath(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("upload blob %s: %w", key, err)
+	}
+
+	hash := md5.New()
+	if err := f.writeFile(target, io.TeeReader(reader, hash)); err != nil {
+		return fmt.Errorf("upload blob %s: %w", key, err)
+	}
+
+	createdAt := time.Now().UTC()
+	if prev, err := f.readMeta(key); err == nil && !prev.CreatedAt.IsZero() {
+		createdAt = prev.CreatedAt
+	}
+
+	meta := fileMeta{
+		ContentType: contentType,
+		ETag:        `"` + hex.EncodeToString(hash.Sum(nil)) + `"`,
+		CreatedAt:   createdAt,
+	}
+	if err := f.writeMeta(key, meta); err != nil {
+		return fmt.Errorf("upload blob %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (f *filesystem) Download(ctx context.Context, key string) (*BlobResult, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(f.blobPath(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("download blob %s: %w", key, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("download blob %s: %w", key, err)
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, ErrNotFound
+	}
+
+	meta, err := f.blobMeta(key, info)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &BlobResult{BlobMeta: *meta, Body: file}, nil
+}
+
+func (f *filesystem) Delete(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	target := f.blobPath(key)
+	info, err := os.Stat(target)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("delete blob %s: %w", key, err)
+	}
+	if info.IsDir() {
+		return ErrNotFound
+	}
+
+	if err := os.Remove(target); err != nil {
+		return fmt.Errorf("delete blob %s: %w", key, err)
+	}
+
+	metaPath := f.metaPath(key)
+	if err := os.Remove(metaPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("delete blob metadata %s: %w", key, err)
+	}
+
+	f.prune(filepath.Dir(target), filepath.Join(f.root, fsBlobsDir))
+	f.prune(filepath.Dir(metaPath), filepath.Join(f.root, fsMetaDir))
+
+	return nil
+}
+
+func (f *filesystem) Exists(ctx context.Context, key string) (bool, error) {
+	if err := validateKey(key); err != nil {
+		return false, err
+	}
+
+	info, err := os.Stat(f.blobPath(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("check blob existence %s: %w", key, err)
+	}
+
+	return !info.IsDir(), nil
+}
+
+func (f *filesystem) blobPath(key string) string {
+	return filepath.Join(f.root, fsBlobsDir, filepath.FromSlash(key))
+}
+
+func (f *filesystem) metaPath(key string) string {
+	return filepath.Join(f.root, fsMetaDir, filepath.FromSlash(key)+".json")
+}
+
+// keys returns up to limit blob keys beginning with prefix that sort after
+// marker, in lexical order. Each directory's entries are visited in key order
+// and the walk stops once limit keys are found, so a page reads only the
+// entries up to its end. Directories that cannot contain a matching key are
+// skipped. filepath.WalkDir is not used because it orders entries by name,
+// which differs from key order when a name holds a byte below '/'.
+func (f *filesystem) keys(ctx context.Context, prefix, marker string, limit int) ([]string, error) {
+	var keys []string
+
+	var walk func(dir, parent string) error
+	walk = func(dir, parent string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+			return strings.Compare(entryKey(a), entryKey(b))
+		})
+
+		for _, e := range entries {
+			key := parent + entryKey(e)
+
+			if e.IsDir() {
+				if !strings.HasPrefix(key, prefix) && !strings.HasPrefix(prefix, key) {
+					continue
+				}
+				if key < marker && !strings.HasPrefix(marker, key) {
+					continue
+				}
+				if err := walk(filepath.Join(dir, e.Name()), key); err != nil {
+					return err
+				}
+			} else if strings.HasPrefix(key, prefix) && key > marker {
+				keys = append(keys, key)
+			}
+
+			if len(keys) >= limit {
+				return nil
+			}
+		}
+		return nil
+	}
+
+	if err := walk(filepath.Join(f.root, fsBlobsDir), ""); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// entryKey returns the key segment of a directory entry: its name, with a
+// trailing slash for directories so entries sort as the keys beneath them do.
+func entryKey(e fs.DirEntry) string {
+	if e.IsDir() {
+		return e.Name() + "/"
+	}
+	return e.Name()
+}
+
+// blobMeta combines file properties with the sidecar metadata for key. Blobs
+// without a sidecar fall back to the content type implied by their extension.
+func (f *filesystem) blobMeta(key string, info fs.FileInfo) (*BlobMeta, error) {
+	meta := &BlobMeta{
+		Name:          key,
+		ContentLength: info.Size(),
+		LastModified:  info.ModTime().UTC(),
+		CreatedAt:     info.ModTime().UTC(),
+	}
+
+	stored, err := f.readMeta(key)
+	switch {
+	case err == nil:
+		meta.ContentType = stored.ContentType
+		meta.ETag = stored.ETag
+		if !stored.CreatedAt.IsZero() {
+			meta.CreatedAt = stored.CreatedAt
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		meta.ContentType = mime.TypeByExtension(path.Ext(key))
+	default:
+		return nil, fmt.Errorf("read blob metadata %s: %w", key, err)
+	}
+
+	return meta, nil
+}
+
+func (f *filesystem) readMeta(key string) (*fileMeta, error) {
+	data, err := os.ReadFile(f.metaPath(key))
+	if err != nil {
+		return nil, err
+	}
+
+	var meta fileMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+func (f *filesystem) writeMeta(key string, meta fileMeta) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	target := f.metaPath(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	return f.writeFile(target, bytes.NewReader(data))
+}
+
+// writeFile stages r in the tmp directory and renames it over target, so
+// readers observe either the previous content or the complete new content.
+func (f *filesystem) writeFile(target string, r io.Reader) error {
+	tmpDir := filepath.Join(f.root, fsTmpDir)
+	if err := os.MkdirAll(tmpDir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(tmpDir, "upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), target)
+}
+
+// prune removes empty directories from dir up to, but not including, stop.
+func (f *filesystem) prune(dir, stop string) {
+	for dir != stop && strings.HasPrefix(dir, stop) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
@@ -0,0 +1,461 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JaimeStill/herald/pkg/lifecycle"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty request body.
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+type s3 struct {
+	client    *http.Client
+	endpoint  *url.URL
+	bucket    string
+	pathStyle bool
+	region    string
+	accessKey string
+	secretKey string
+	logger    *slog.Logger
+}
+
+// NewS3 creates a storage system backed by an S3-compatible object store, using
+// ContainerName as the bucket. With no Endpoint, requests go to AWS S3 in Region
+// using virtual-hosted addressing; a configured Endpoint (MinIO, Ceph, and
+// similar services) is addressed path-style. Requests are signed with AWS
+// Signature Version 4 when AccessKeyID is set and sent anonymously otherwise.
+// No request is made until Start is called.
+func NewS3(cfg *Config, logger *slog.Logger) (System, error) {
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("region required for s3 storage")
+	}
+	if (cfg.AccessKeyID == "") != (cfg.SecretAccessKey == "") {
+		return nil, fmt.Errorf("access_key_id and secret_access_key must be set together")
+	}
+
+	raw := cfg.Endpoint
+	pathStyle := raw != ""
+	if raw == "" {
+		raw = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", cfg.ContainerName, cfg.Region)
+	}
+
+	endpoint, err := url.Parse(raw)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", raw)
+	}
+
+	return &s3{
+		client:    &http.Client{},
+		endpoint:  endpoint,
+		bucket:    cfg.ContainerName,
+		pathStyle: pathStyle,
+		region:    cfg.Region,
+		accessKey: cfg.AccessKeyID,
+		secretKey: cfg.SecretAccessKey,
+		logger:    logger.With("system", "storage"),
+	}, nil
+}
+
+func (s *s3) Start(lc *lifecycle.Coordinator) error {
+	s.logger.Info("starting storage system")
+
+	lc.OnStartup(func() {
+		resp, err := s.do(lc.Context(), http.MethodPut, "", nil, nil, emptyPayloadHash, nil)
+		if err != nil {
+			s.logger.Error("storage bucket initialization failed", "error", err)
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			if code := errorCode(resp); code != "BucketAlreadyOwnedByYou" && code != "BucketAlreadyExists" {
+				s.logger.Error("storage bucket initialization failed", "status", resp.StatusCode, "code", code)
+				return
+			}
+		}
+
+		s.logger.Info("storage bucket ready", "bucket", s.bucket)
+	})
+
+	return nil
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+		ETag         string    `xml:"ETag"`
+		Size         int64     `xml:"Size"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (s *s3) List(
+	ctx context.Context,
+	prefix string,
+	marker string,
+	maxResults int32,
+) (*BlobList, error) {
+	if maxResults <= 0 || maxResults > MaxListCap {
+		maxResults = MaxListCap
+	}
+
+	query := url.Values{
+		"list-type": {"2"},
+		"max-keys":  {strconv.Itoa(int(maxResults))},
+	}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	if marker != "" {
+		query.Set("continuation-token", marker)
+	}
+
+	resp, err := s.do(ctx, http.MethodGet, "", query, nil, emptyPayloadHash, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list blobs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("list blobs: %w", responseError(resp))
+	}
+
+	var out listBucketResult
+	if err := xml.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decode blob list: %w", err)
+	}
+
+	blobs := make([]BlobMeta, 0, len(out.Contents))
+	for _, c := range out.Contents {
+		blobs = append(blobs, BlobMeta{
+			Name:          c.Key,
+			ContentLength: c.Size,
+			LastModified:  c.LastModified,
+			ETag:          c.ETag,
+			CreatedAt:     c.LastModified,
+		})
+	}
+
+	result := &BlobList{Blobs: blobs}
+	if out.IsTruncated {
+		result.NextMarker = out.NextContinuationToken
+	}
+	return result, nil
+}
+
+func (s *s3) Find(ctx context.Context, key string) (*BlobMeta, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(ctx, http.MethodHead, key, nil, nil, emptyPayloadHash, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get blob properties %s: %w", key, err)
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return objectMeta(key, resp), nil
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("get blob properties %s: %w", key, responseError(resp))
+	}
+}
+
+// Upload spools reader to a temporary file before sending it, because S3
+// requires the content length and payload hash up front.
+func (s *s3) Upload(ctx context.Context, key string, reader io.Reader, contentType string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	spool, err := os.CreateTemp("", "herald-upload-*")
+	if err != nil {
+		return fmt.Errorf("upload blob %s: %w", key, err)
+	}
+	defer func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(spool, hash), reader)
+	if err != nil {
+		return fmt.Errorf("upload blob %s: %w", key, err)
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("upload blob %s: %w", key, err)
+	}
+
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, key, nil, &sizedBody{spool, size}, hex.EncodeToString(hash.Sum(nil)), header)
+	if err != nil {
+		return fmt.Errorf("upload blob %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("upload blob %s: %w", key, responseError(resp))
+	}
+
+	return nil
+}
+
+func (s *s3) Download(ctx context.Context, key string) (*BlobResult, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil, emptyPayloadHash, nil)
+	if err != nil {
+		return nil, fmt.Errorf("download blob %s: %w", key, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return &BlobResult{BlobMeta: *objectMeta(key, resp), Body: resp.Body}, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("download blob %s: %w", key, responseError(resp))
+	}
+}
+
+// Delete checks for the object first because S3 reports success when deleting
+// a key that does not exist.
+func (s *s3) Delete(ctx context.Context, key string) error {
+	if _, err := s.Find(ctx, key); err != nil {
+		return err
+	}
+
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil, emptyPayloadHash, nil)
+	if err != nil {
+		return fmt.Errorf("delete blob %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return ErrNotFound
+	default:
+		return fmt.Errorf("delete blob %s: %w", key, responseError(resp))
+	}
+}
+
+func (s *s3) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := s.Find(ctx, key)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// sizedBody is a request body whose length is known before sending.
+type sizedBody struct {
+	io.Reader
+	size int64
+}
+
+// do sends a signed request for key (or the bucket itself when key is empty).
+// payloadHash is the hex SHA-256 of body.
+func (s *s3) do(
+	ctx context.Context,
+	method string,
+	key string,
+	query url.Values,
+	body *sizedBody,
+	payloadHash string,
+	header http.Header,
+) (*http.Response, error) {
+	u := *s.endpoint
+	objectPath := "/"
+	if s.pathStyle {
+		objectPath = strings.TrimSuffix(u.Path, "/") + "/" + s.bucket
+		if key != "" {
+			objectPath += "/"
+		}
+	}
+	objectPath += key
+	u.Path = objectPath
+	u.RawPath = uriEncode(objectPath, false)
+	u.RawQuery = canonicalQuery(query)
+
+	var reader io.Reader
+	if body != nil {
+		reader = body.Reader
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = body.size
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	s.sign(req, payloadHash, time.Now().UTC())
+
+	return s.client.Do(req)
+}
+
+// sign adds AWS Signature Version 4 headers to req.
+func (s *s3) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	if s.accessKey == "" {
+		return
+	}
+
+	signed := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if req.Header.Get("Content-Type") != "" {
+		signed = append(signed, "content-type")
+		slices.Sort(signed)
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, h := range signed {
+		value := req.Header.Get(h)
+		if h == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(value) + "\n")
+	}
+	signedHeaders := strings.Join(signed, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature,
+	))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalQuery encodes query parameters sorted by key, as both S3 and the
+// signature require.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes every byte except unreserved characters (and '/'
+// unless encodeSlash is set), following the SigV4 canonical encoding rules.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func objectMeta(key string, resp *http.Response) *BlobMeta {
+	meta := &BlobMeta{
+		Name:          key,
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentLength: resp.ContentLength,
+		ETag:          resp.Header.Get("ETag"),
+	}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		meta.LastModified = t
+		meta.CreatedAt = t
+	}
+	return meta
+}
+
+// errorCode reads the S3 error code from a failed response body.
+func errorCode(resp *http.Response) string {
+	var e struct {
+		Code string `xml:"Code"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&e); err != nil {
+		return ""
+	}
+	return e.Code
+}
+
+func responseError(resp *http.Response) error {
+	if code := errorCode(resp); code != "" {
+		return fmt.Errorf("s3 %s: %s", resp.Status, code)
+	}
+	return fmt.Errorf("s3 %s", resp.Status)
+}
@@ -0,0 +1,342 @@
+package agents_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/JaimeStill/herald/internal/agents"
+)
+
+type stubAgent struct {
+	resp agents.Response
+	err  error
+}
+
+func (s *stubAgent) Chat(context.Context, string) (agents.Response, error) { return s.resp, s.err }
+func (s *stubAgent) Vision(context.Context, string, []byte) (agents.Response, error) {
+	return s.resp, s.err
+}
+func (s *stubAgent) Model() string    { return "gpt-test" }
+func (s *stubAgent) Provider() string { return "stub" }
+
+func callCtx(document, stage string, page int) context.Context {
+	return agents.WithCall(context.Background(), agents.Call{
+		Document: document,
+		Stage:    stage,
+		Page:     page,
+	})
+}
+
+func text(s string) agents.Response {
+	return agents.Response{Text: s}
+}
+
+func writeFixture(t *testing.T, dir, document string, f *agents.Fixture) {
+	t.Helper()
+	if err := f.Save(agents.FixturePath(dir, document, f.Digest)); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+}
+
+func TestFixturePath(t *testing.T) {
+	digest := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+	tests := []struct {
+		document string
+		digest   string
+		want     string
+	}{
+		{"report.pdf", digest, filepath.Join("fixtures", "report.pdf.9f86d081884c7d65.json")},
+		{"nested/dir/report.pdf", digest, filepath.Join("fixtures", "report.pdf.9f86d081884c7d65.json")},
+		{"report.pdf", "", filepath.Join("fixtures", "report.pdf.json")},
+		{"", "", filepath.Join("fixtures", agents.DefaultFixture)},
+	}
+
+	for _, tt := range tests {
+		if got := agents.FixturePath("fixtures", tt.document, tt.digest); got != tt.want {
+			t.Errorf("FixturePath(%q, %q) = %q, want %q", tt.document, tt.digest, got, tt.want)
+		}
+	}
+}
+
+func TestFixtureLookup(t *testing.T) {
+	var f agents.Fixture
+	f.Set("classify", 1, agents.Response{
+		Text:  `{"markings_found": ["SECRET"]}`,
+		Usage: agents.Usage{PromptTokens: 1200, CompletionTokens: 80},
+	})
+	f.Set("classify", 0, text("not json"))
+	f.Set("enhance", 2, text(`{"markings_found":[]}`))
+	f.Responses["enhance"][agents.AnyPage] = []byte(`{"markings_found":["CONFIDENTIAL"]}`)
+
+	tests := []struct {
+		name  string
+		stage string
+		page  int
+		want  string
+		usage int
+		ok    bool
+	}{
+		{"json is compacted", "classify", 1, `{"markings_found":["SECRET"]}`, 1280, true},
+		{"text round-trips", "classify", 0, "not json", 0, true},
+		{"exact page wins", "enhance", 2, `{"markings_found":[]}`, 0, true},
+		{"any page fallback", "enhance", 7, `{"markings_found":["CONFIDENTIAL"]}`, 0, true},
+		{"missing page", "classify", 3, "", 0, false},
+		{"missing stage", "finalize", 0, "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := f.Lookup(tt.stage, tt.page)
+			if ok != tt.ok || got.Text != tt.want {
+				t.Errorf("Lookup(%q, %d) = %q, %v; want %q, %v", tt.stage, tt.page, got.Text, ok, tt.want, tt.ok)
+			}
+			if got.Usage.Total() != tt.usage {
+				t.Errorf("Lookup(%q, %d) usage = %d, want %d", tt.stage, tt.page, got.Usage.Total(), tt.usage)
+			}
+		})
+	}
+}
+
+func TestLoadFixture(t *testing.T) {
+	dir := t.TempDir()
+
+	f, err := agents.LoadFixture(filepath.Join(dir, "missing.json"))
+	if err != nil || f != nil {
+		t.Errorf("LoadFixture(missing) = %v, %v; want nil, nil", f, err)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := agents.LoadFixture(bad); err == nil {
+		t.Error("LoadFixture(bad) expected error")
+	}
+
+	want := &agents.Fixture{Document: "report.pdf", Model: "gpt-test"}
+	want.Set("finalize", 0, text(`{"classification":"SECRET"}`))
+	writeFixture(t, dir, "report.pdf", want)
+
+	got, err := agents.LoadFixture(agents.FixturePath(dir, "report.pdf", ""))
+	if err != nil {
+		t.Fatalf("LoadFixture() error = %v", err)
+	}
+	if got.Document != "report.pdf" || got.Model != "gpt-test" {
+		t.Errorf("LoadFixture() = %+v", got)
+	}
+	if resp, _ := got.Lookup("finalize", 0); resp.Text != `{"classification":"SECRET"}` {
+		t.Errorf("Lookup(finalize, 0) = %q", resp.Text)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	doc := &agents.Fixture{Document: "report.pdf"}
+	doc.Set("classify", 1, text(`{"markings_found":["SECRET"]}`))
+	writeFixture(t, dir, "report.pdf", doc)
+
+	def := &agents.Fixture{}
+	def.Set("classify", 1, text(`{"markings_found":[]}`))
+	def.Set("finalize", 0, text(`{"classification":"UNCLASSIFIED"}`))
+	writeFixture(t, dir, "", def)
+
+	r := agents.NewReplay(dir, "gpt-test", false)
+
+	if r.Model() != "gpt-test" || r.Provider() != agents.ProviderReplay {
+		t.Errorf("Model(), Provider() = %q, %q", r.Model(), r.Provider())
+	}
+
+	t.Run("document fixture", func(t *testing.T) {
+		got, err := r.Vision(callCtx("report.pdf", "classify", 1), "prompt", nil)
+		if err != nil {
+			t.Fatalf("Vision() error = %v", err)
+		}
+		if want := `{"markings_found":["SECRET"]}`; got.Text != want {
+			t.Errorf("Vision() = %q, want %q", got.Text, want)
+		}
+	})
+
+	for _, tt := range []struct {
+		name string
+		ctx  context.Context
+	}{
+		{"missing call is not served from default", callCtx("report.pdf", "finalize", 0)},
+		{"unknown document is not served from default", callCtx("other.pdf", "classify", 1)},
+		{"no recorded response", callCtx("report.pdf", "enhance", 2)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := r.Chat(tt.ctx, "prompt")
+			if !errors.Is(err, agents.ErrFixtureNotFound) {
+				t.Errorf("Chat() error = %v, want ErrFixtureNotFound", err)
+			}
+		})
+	}
+
+	t.Run("no call in context", func(t *testing.T) {
+		_, err := r.Chat(context.Background(), "prompt")
+		if !errors.Is(err, agents.ErrNoCall) {
+			t.Errorf("Chat() error = %v, want ErrNoCall", err)
+		}
+	})
+}
+
+func TestReplayFallback(t *testing.T) {
+	dir := t.TempDir()
+
+	doc := &agents.Fixture{Document: "report.pdf"}
+	doc.Set("classify", 1, text(`{"markings_found":["SECRET"]}`))
+	writeFixture(t, dir, "report.pdf", doc)
+
+	def := &agents.Fixture{}
+	def.Set("classify", 1, text(`{"markings_found":[]}`))
+	def.Set("finalize", 0, text(`{"classification":"UNCLASSIFIED"}`))
+	writeFixture(t, dir, "", def)
+
+	r := agents.NewReplay(dir, "gpt-test", true)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"document fixture", callCtx("report.pdf", "classify", 1), `{"markings_found":["SECRET"]}`},
+		{"falls back to default for missing call", callCtx("report.pdf", "finalize", 0), `{"classification":"UNCLASSIFIED"}`},
+		{"falls back to default for unknown document", callCtx("other.pdf", "classify", 1), `{"markings_found":[]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Vision(tt.ctx, "prompt", nil)
+			if err != nil {
+				t.Fatalf("Vision() error = %v", err)
+			}
+			if got.Text != tt.want {
+				t.Errorf("Vision() = %q, want %q", got.Text, tt.want)
+			}
+		})
+	}
+
+	t.Run("no recorded response", func(t *testing.T) {
+		_, err := r.Chat(callCtx("report.pdf", "enhance", 2), "prompt")
+		if !errors.Is(err, agents.ErrFixtureNotFound) {
+			t.Errorf("Chat() error = %v, want ErrFixtureNotFound", err)
+		}
+	})
+}
+
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+	rec := agents.NewRecorder(dir)
+
+	a := rec.Wrap(&stubAgent{resp: agents.Response{
+		Text:  `{"markings_found": ["SECRET//NOFORN"]}`,
+		Usage: agents.Usage{PromptTokens: 900, CompletionTokens: 40},
+	}})
+	if _, err := a.Vision(callCtx("report.pdf", "classify", 1), "prompt", nil); err != nil {
+		t.Fatalf("Vision() error = %v", err)
+	}
+
+	b := rec.Wrap(&stubAgent{resp: text(`{"classification": "SECRET//NOFORN"}`)})
+	if _, err := b.Chat(callCtx("report.pdf", "finalize", 0), "prompt"); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	replay := agents.NewReplay(dir, "gpt-test", false)
+	for _, call := range []struct {
+		stage string
+		page  int
+		want  string
+		usage agents.Usage
+	}{
+		{"classify", 1, `{"markings_found":["SECRET//NOFORN"]}`, agents.Usage{PromptTokens: 900, CompletionTokens: 40}},
+		{"finalize", 0, `{"classification":"SECRET//NOFORN"}`, agents.Usage{}},
+	} {
+		got, err := replay.Chat(callCtx("report.pdf", call.stage, call.page), "prompt")
+		if err != nil {
+			t.Fatalf("replay %s/%d error = %v", call.stage, call.page, err)
+		}
+		if got.Text != call.want {
+			t.Errorf("replay %s/%d = %q, want %q", call.stage, call.page, got.Text, call.want)
+		}
+		if got.Usage != call.usage {
+			t.Errorf("replay %s/%d usage = %+v, want %+v", call.stage, call.page, got.Usage, call.usage)
+		}
+	}
+
+	f, err := agents.LoadFixture(agents.FixturePath(dir, "report.pdf", ""))
+	if err != nil {
+		t.Fatalf("LoadFixture() error = %v", err)
+	}
+	if f.Model != "gpt-test" || f.Document != "report.pdf" {
+		t.Errorf("recorded fixture = %+v", f)
+	}
+
+	t.Run("agent errors are not recorded", func(t *testing.T) {
+		failing := rec.Wrap(&stubAgent{err: errors.New("boom")})
+		if _, err := failing.Chat(callCtx("failed.pdf", "finalize", 0), "prompt"); err == nil {
+			t.Fatal("Chat() expected error")
+		}
+		if _, err := os.Stat(agents.FixturePath(dir, "failed.pdf", "")); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("fixture written for failed call: %v", err)
+		}
+	})
+
+	t.Run("no call in context", func(t *testing.T) {
+		c := rec.Wrap(&stubAgent{resp: text("{}")})
+		if _, err := c.Chat(context.Background(), "prompt"); !errors.Is(err, agents.ErrNoCall) {
+			t.Errorf("Chat() error = %v, want ErrNoCall", err)
+		}
+	})
+}
+
+func TestRecorderSameFilename(t *testing.T) {
+	dir := t.TempDir()
+	rec := agents.NewRecorder(dir)
+
+	scan := func(digest string) context.Context {
+		return agents.WithCall(context.Background(), agents.Call{
+			Document: "scan.pdf",
+			Digest:   digest,
+			Stage:    "finalize",
+		})
+	}
+
+	documents := []struct {
+		digest string
+		want   string
+	}{
+		{"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", `{"classification":"SECRET"}`},
+		{"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", `{"classification":"UNCLASSIFIED"}`},
+	}
+
+	for _, d := range documents {
+		a := rec.Wrap(&stubAgent{resp: text(d.want)})
+		if _, err := a.Chat(scan(d.digest), "prompt"); err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+	}
+
+	replay := agents.NewReplay(dir, "gpt-test", false)
+	for _, d := range documents {
+		got, err := replay.Chat(scan(d.digest), "prompt")
+		if err != nil {
+			t.Fatalf("replay %s error = %v", d.digest[:8], err)
+		}
+		if got.Text != d.want {
+			t.Errorf("replay %s = %q, want %q", d.digest[:8], got.Text, d.want)
+		}
+
+		f, err := agents.LoadFixture(agents.FixturePath(dir, "scan.pdf", d.digest))
+		if err != nil || f == nil {
+			t.Fatalf("LoadFixture() = %v, %v", f, err)
+		}
+		if f.Digest != d.digest {
+			t.Errorf("fixture digest = %q, want %q", f.Digest, d.digest)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package agents_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/JaimeStill/herald/internal/agents"
+)
+
+func entry(name, model string) agents.Entry {
+	return agents.Entry{
+		Name:     name,
+		Model:    model,
+		Provider: "stub",
+		New: func(context.Context) (agents.Agent, error) {
+			return &stubAgent{}, nil
+		},
+	}
+}
+
+func TestRegistryLookup(t *testing.T) {
+	r := agents.NewRegistry(
+		entry("mini", "gpt-5-mini"),
+		entry("local", "llama3.2:3b"),
+		entry("large", "gpt-5"),
+	)
+
+	if got := r.Default().Name; got != "mini" {
+		t.Errorf("Default() = %q, want mini", got)
+	}
+
+	e, err := r.Lookup("")
+	if err != nil || e.Name != "mini" {
+		t.Errorf("Lookup(\"\") = %q, %v, want the default", e.Name, err)
+	}
+
+	e, err = r.Lookup("large")
+	if err != nil {
+		t.Fatalf("Lookup(large) error = %v", err)
+	}
+	if e.Model != "gpt-5" {
+		t.Errorf("large model = %q, want gpt-5", e.Model)
+	}
+
+	if _, err := r.Lookup("missing"); !errors.Is(err, agents.ErrUnknownAgent) {
+		t.Errorf("Lookup(missing) error = %v, want ErrUnknownAgent", err)
+	}
+
+	if got, want := r.Names(), []string{"mini", "large", "local"}; !slices.Equal(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+
+	entries := r.Entries()
+	if len(entries) != 3 || entries[0].Name != "mini" || entries[2].Name != "local" {
+		t.Errorf("Entries() order = %v, want default first then sorted", entries)
+	}
+}
@@ -0,0 +1,180 @@
+package agents_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+
+	"github.com/JaimeStill/herald/internal/agents"
+)
+
+// scriptedAgent fails with each error in errs in turn, then succeeds.
+type scriptedAgent struct {
+	errs  []error
+	calls atomic.Int32
+}
+
+func (s *scriptedAgent) Chat(ctx context.Context, prompt string) (agents.Response, error) {
+	return s.Vision(ctx, prompt, nil)
+}
+
+func (s *scriptedAgent) Vision(context.Context, string, []byte) (agents.Response, error) {
+	n := int(s.calls.Add(1))
+	if n <= len(s.errs) {
+		return agents.Response{}, s.errs[n-1]
+	}
+	return agents.Response{
+		Text:  "ok",
+		Usage: agents.Usage{PromptTokens: 90, CompletionTokens: 10},
+	}, nil
+}
+
+func (s *scriptedAgent) Model() string    { return "gpt-test" }
+func (s *scriptedAgent) Provider() string { return "stub" }
+
+func throttled(limits agents.Limits, backoff agents.Backoff, a agents.Agent) agents.Factory {
+	throttle := agents.NewThrottle(
+		func(string) agents.Limits { return limits },
+		backoff,
+		slog.New(slog.DiscardHandler),
+	)
+	return throttle.Wrap(func(context.Context) (agents.Agent, error) {
+		return a, nil
+	})
+}
+
+var fastBackoff = agents.Backoff{MaxAttempts: 4, Min: time.Millisecond, Max: 4 * time.Millisecond}
+
+func TestThrottleRetriesTransientErrors(t *testing.T) {
+	rateLimited := &azcore.ResponseError{
+		StatusCode:  http.StatusTooManyRequests,
+		RawResponse: &http.Response{Header: http.Header{}},
+	}
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"azure response error", rateLimited},
+		{"status in message", errors.New("request failed with status 503: upstream unavailable")},
+		{"rate limit message", errors.New("Requests have exceeded token rate limit of your current pricing tier.")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &scriptedAgent{errs: []error{tt.err, tt.err}}
+			a, _ := throttled(agents.Limits{}, fastBackoff, stub)(context.Background())
+
+			resp, err := a.Vision(context.Background(), "prompt", nil)
+			if err != nil {
+				t.Fatalf("Vision() error = %v", err)
+			}
+			if resp.Text != "ok" {
+				t.Errorf("text = %q, want ok", resp.Text)
+			}
+			if got := stub.calls.Load(); got != 3 {
+				t.Errorf("calls = %d, want 3", got)
+			}
+		})
+	}
+}
+
+func TestThrottleDoesNotRetryPermanentErrors(t *testing.T) {
+	stub := &scriptedAgent{errs: []error{errors.New("request failed with status 400: invalid image")}}
+	a, _ := throttled(agents.Limits{}, fastBackoff, stub)(context.Background())
+
+	if _, err := a.Vision(context.Background(), "prompt", nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := stub.calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want 1", got)
+	}
+}
+
+func TestThrottleStopsAfterMaxAttempts(t *testing.T) {
+	unavailable := errors.New("status code: 502")
+	stub := &scriptedAgent{errs: []error{unavailable, unavailable, unavailable, unavailable, unavailable}}
+	a, _ := throttled(agents.Limits{}, fastBackoff, stub)(context.Background())
+
+	_, err := a.Vision(context.Background(), "prompt", nil)
+	if !errors.Is(err, unavailable) {
+		t.Fatalf("error = %v, want wrapped provider error", err)
+	}
+	if !strings.Contains(err.Error(), "4 attempts") {
+		t.Errorf("error = %q, want attempt count", err)
+	}
+	if got := stub.calls.Load(); got != 4 {
+		t.Errorf("calls = %d, want 4", got)
+	}
+}
+
+func TestThrottleHonorsRetryAfter(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"header", &azcore.ResponseError{
+			StatusCode:  http.StatusTooManyRequests,
+			RawResponse: &http.Response{Header: http.Header{"Retry-After": {"1"}}},
+		}},
+		{"message", errors.New("status 429: Please retry after 1 second.")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &scriptedAgent{errs: []error{tt.err}}
+			a, _ := throttled(agents.Limits{}, fastBackoff, stub)(context.Background())
+
+			start := time.Now()
+			if _, err := a.Vision(context.Background(), "prompt", nil); err != nil {
+				t.Fatalf("Vision() error = %v", err)
+			}
+			if elapsed := time.Since(start); elapsed < time.Second {
+				t.Errorf("retried after %v, want at least 1s", elapsed)
+			}
+		})
+	}
+}
+
+func TestThrottleSharesRequestLimitAcrossAgents(t *testing.T) {
+	// Six requests per minute allow a burst of one; the next request must
+	// wait ten seconds, longer than the deadline.
+	newAgent := throttled(agents.Limits{RequestsPerMinute: 6}, fastBackoff, &scriptedAgent{})
+
+	first, _ := newAgent(context.Background())
+	second, _ := newAgent(context.Background())
+
+	if _, err := first.Chat(context.Background(), "prompt"); err != nil {
+		t.Fatalf("first Chat() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := second.Chat(ctx, "prompt"); err == nil {
+		t.Fatal("second Chat() succeeded, want rate limit wait to exceed deadline")
+	}
+}
+
+func TestThrottleChargesTokenUsage(t *testing.T) {
+	// The first call reports 100 tokens against a budget of 100 per minute,
+	// leaving the next caller to wait for the bucket to refill.
+	newAgent := throttled(agents.Limits{TokensPerMinute: 100}, fastBackoff, &scriptedAgent{})
+	a, _ := newAgent(context.Background())
+
+	if _, err := a.Vision(context.Background(), "prompt", nil); err != nil {
+		t.Fatalf("first Vision() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := a.Vision(ctx, "prompt", nil); err == nil {
+		t.Fatal("second Vision() succeeded, want token budget wait to exceed deadline")
+	}
+}
@@ -65,6 +65,14 @@ func validConfig() *config.Config {
 				MaxPageSize:     100,
 			},
 		},
+		Jobs: config.JobsConfig{
+			Workers:       2,
+			PollInterval:  "2s",
+			LeaseDuration: "5m",
+			MaxAttempts:   3,
+			RetryBackoff:  "30s",
+			MaxRunning:    8,
+		},
 		ShutdownTimeout: "30s",
 		Version:         "0.1.0",
 	}
@@ -105,6 +113,9 @@ func TestNewRuntime(t *testing.T) {
 	if runtime.Pagination.MaxPageSize != 100 {
 		t.Errorf("pagination max page size: got %d, want 100", runtime.Pagination.MaxPageSize)
 	}
+	if runtime.Jobs.Workers != 2 {
+		t.Errorf("jobs workers: got %d, want 2", runtime.Jobs.Workers)
+	}
 	if runtime.Logger == nil {
 		t.Error("runtime logger is nil")
 	}
@@ -6,10 +6,13 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/JaimeStill/herald/internal/classifications"
+	"github.com/JaimeStill/herald/internal/markings"
+	"github.com/JaimeStill/herald/internal/state"
 	"github.com/JaimeStill/herald/pkg/query"
 )
 
@@ -24,6 +27,12 @@ func TestMapHTTPStatus(t *testing.T) {
 		{"not found", classifications.ErrNotFound, http.StatusNotFound},
 		{"duplicate", classifications.ErrDuplicate, http.StatusConflict},
 		{"invalid status", classifications.ErrInvalidStatus, http.StatusConflict},
+		{"job not found", classifications.ErrJobNotFound, http.StatusNotFound},
+		{"batch not found", classifications.ErrBatchNotFound, http.StatusNotFound},
+		{"revision not found", classifications.ErrRevisionNotFound, http.StatusNotFound},
+		{"invalid revision", classifications.ErrInvalidRevision, http.StatusBadRequest},
+		{"invalid usage filter", classifications.ErrInvalidUsageFilter, http.StatusBadRequest},
+		{"invalid marking", fmt.Errorf("%w: unknown level", markings.ErrInvalidMarking), http.StatusBadRequest},
 		{"unknown error", errors.New("something else"), http.StatusInternalServerError},
 		{"wrapped not found", fmt.Errorf("find failed: %w", classifications.ErrNotFound), http.StatusNotFound},
 		{"wrapped duplicate", fmt.Errorf("insert failed: %w", classifications.ErrDuplicate), http.StatusConflict},
@@ -83,6 +92,30 @@ func TestFiltersFromQuery(t *testing.T) {
 		}
 	})
 
+	t.Run("marking params", func(t *testing.T) {
+		values := url.Values{
+			"marking":       {"SECRET//NOFORN"},
+			"level":         {"SECRET"},
+			"sci":           {"SI"},
+			"dissemination": {"NOFORN"},
+		}
+
+		f := classifications.FiltersFromQuery(values)
+
+		if f.Marking == nil || *f.Marking != "SECRET//NOFORN" {
+			t.Errorf("Marking = %v, want SECRET//NOFORN", f.Marking)
+		}
+		if f.Level == nil || *f.Level != "SECRET" {
+			t.Errorf("Level = %v, want SECRET", f.Level)
+		}
+		if f.SCI == nil || *f.SCI != "SI" {
+			t.Errorf("SCI = %v, want SI", f.SCI)
+		}
+		if f.Dissemination == nil || *f.Dissemination != "NOFORN" {
+			t.Errorf("Dissemination = %v, want NOFORN", f.Dissemination)
+		}
+	})
+
 	t.Run("invalid document_id ignored", func(t *testing.T) {
 		values := url.Values{"document_id": {"not-a-uuid"}}
 		f := classifications.FiltersFromQuery(values)
@@ -197,5 +230,345 @@ func TestFiltersApply(t *testing.T) {
 			t.Errorf("args length = %d, want 3", len(args))
 		}
 	})
+	t.Run("marking filters", func(t *testing.T) {
+		markingProj := query.
+			NewProjectionMap("public", "classifications", "c").
+			Project("marking", "Marking").
+			Project("marking_level", "MarkingLevel").
+			Project("marking_sci", "MarkingSCI").
+			Project("marking_dissemination", "MarkingDissemination")
+
+		b := query.NewBuilder(markingProj)
+		f := classifications.Filters{
+			Level:         ptr("SECRET"),
+			Dissemination: ptr("NOFORN"),
+		}
+		f.Apply(b)
+		sql, args := b.Build()
+
+		wantSQL := "SELECT c.marking, c.marking_level, c.marking_sci, c.marking_dissemination " +
+			"FROM public.classifications c " +
+			"WHERE c.marking_level = $1 AND c.marking_dissemination @> jsonb_build_array($2::text)"
+		if sql != wantSQL {
+			t.Errorf("sql = %q, want %q", sql, wantSQL)
+		}
+		if len(args) != 2 {
+			t.Errorf("args length = %d, want 2", len(args))
+		}
+	})
+	t.Run("discrepant filter", func(t *testing.T) {
+		discrepancyProj := query.
+			NewProjectionMap("public", "classifications", "c").
+			Project("discrepancy", "Discrepancy")
+
+		b := query.NewBuilder(discrepancyProj)
+		f := classifications.FiltersFromQuery(url.Values{"discrepant": {"true"}})
+		f.Apply(b)
+		sql, args := b.Build()
+
+		wantSQL := "SELECT c.discrepancy FROM public.classifications c WHERE c.discrepancy IS NOT NULL"
+		if sql != wantSQL {
+			t.Errorf("sql = %q, want %q", sql, wantSQL)
+		}
+		if len(args) != 0 {
+			t.Errorf("args = %v, want empty", args)
+		}
+	})
+	t.Run("escalated filter", func(t *testing.T) {
+		escalationProj := query.
+			NewProjectionMap("public", "classifications", "c").
+			Project("escalation", "Escalation")
+
+		b := query.NewBuilder(escalationProj)
+		f := classifications.FiltersFromQuery(url.Values{"escalated": {"false"}})
+		f.Apply(b)
+		sql, _ := b.Build()
+
+		wantSQL := "SELECT c.escalation FROM public.classifications c WHERE c.escalation IS NULL"
+		if sql != wantSQL {
+			t.Errorf("sql = %q, want %q", sql, wantSQL)
+		}
+	})
 }
 
+func TestJobFiltersFromQuery(t *testing.T) {
+	t.Run("all params present", func(t *testing.T) {
+		id := uuid.New()
+		batchID := uuid.New()
+		values := url.Values{
+			"document_id": {id.String()},
+			"batch_id":    {batchID.String()},
+			"status":      {"running"},
+		}
+
+		f := classifications.JobFiltersFromQuery(values)
+
+		if f.DocumentID == nil || *f.DocumentID != id {
+			t.Errorf("DocumentID = %v, want %s", f.DocumentID, id)
+		}
+		if f.BatchID == nil || *f.BatchID != batchID {
+			t.Errorf("BatchID = %v, want %s", f.BatchID, batchID)
+		}
+		if f.Status == nil || *f.Status != classifications.JobRunning {
+			t.Errorf("Status = %v, want running", f.Status)
+		}
+	})
+
+	t.Run("empty params yield nil fields", func(t *testing.T) {
+		f := classifications.JobFiltersFromQuery(url.Values{})
+
+		if f.DocumentID != nil {
+			t.Errorf("DocumentID = %v, want nil", f.DocumentID)
+		}
+		if f.Status != nil {
+			t.Errorf("Status = %v, want nil", f.Status)
+		}
+	})
+
+	t.Run("invalid document_id ignored", func(t *testing.T) {
+		f := classifications.JobFiltersFromQuery(url.Values{"document_id": {"not-a-uuid"}})
+
+		if f.DocumentID != nil {
+			t.Errorf("DocumentID = %v, want nil for invalid UUID", f.DocumentID)
+		}
+	})
+}
+
+func TestJobTerminal(t *testing.T) {
+	tests := []struct {
+		status classifications.JobStatus
+		want   bool
+	}{
+		{classifications.JobQueued, false},
+		{classifications.JobRunning, false},
+		{classifications.JobSucceeded, true},
+		{classifications.JobFailed, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			j := classifications.Job{Status: tt.status}
+			if got := j.Terminal(); got != tt.want {
+				t.Errorf("Terminal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBatchDone(t *testing.T) {
+	tests := []struct {
+		name  string
+		batch classifications.Batch
+		want  bool
+	}{
+		{"empty batch", classifications.Batch{}, true},
+		{"jobs outstanding", classifications.Batch{Total: 3, Queued: 1, Running: 1, Succeeded: 1}, false},
+		{"all terminal", classifications.Batch{Total: 3, Succeeded: 2, Failed: 1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.batch.Done(); got != tt.want {
+				t.Errorf("Done() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	validatedBy := "admin"
+	validatedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	base := classifications.Snapshot{
+		Classification: "SECRET",
+		Confidence:     "HIGH",
+		MarkingsFound:  []string{"SECRET"},
+		Rationale:      "Banner markings.",
+	}
+
+	t.Run("identical revisions have no changes", func(t *testing.T) {
+		a := classifications.Revision{Revision: 1, After: base}
+		b := classifications.Revision{Revision: 2, After: base}
+
+		d := classifications.Diff(a, b)
+		if len(d.Changes) != 0 {
+			t.Errorf("changes = %+v, want none", d.Changes)
+		}
+		if d.From != 1 || d.To != 2 {
+			t.Errorf("from, to = %d, %d, want 1, 2", d.From, d.To)
+		}
+	})
+
+	t.Run("reports changed fields in order", func(t *testing.T) {
+		next := base
+		next.Classification = "TOP SECRET"
+		next.MarkingsFound = []string{"TOP SECRET", "NOFORN"}
+		next.ValidatedBy = &validatedBy
+		next.ValidatedAt = &validatedAt
+
+		d := classifications.Diff(
+			classifications.Revision{Revision: 1, After: base},
+			classifications.Revision{Revision: 3, After: next},
+		)
+
+		want := []string{"classification", "markings_found", "validated_by", "validated_at"}
+		if len(d.Changes) != len(want) {
+			t.Fatalf("changes = %+v, want fields %v", d.Changes, want)
+		}
+		for i, field := range want {
+			if d.Changes[i].Field != field {
+				t.Errorf("changes[%d].Field = %q, want %q", i, d.Changes[i].Field, field)
+			}
+		}
+		if d.Changes[0].From != "SECRET" || d.Changes[0].To != "TOP SECRET" {
+			t.Errorf("classification change = %v -> %v", d.Changes[0].From, d.Changes[0].To)
+		}
+	})
+
+	t.Run("equal validation times in different zones match", func(t *testing.T) {
+		a := base
+		a.ValidatedAt = &validatedAt
+		local := validatedAt.In(time.FixedZone("EST", -5*60*60))
+		b := base
+		b.ValidatedAt = &local
+
+		d := classifications.Diff(
+			classifications.Revision{Revision: 1, After: a},
+			classifications.Revision{Revision: 2, After: b},
+		)
+		if len(d.Changes) != 0 {
+			t.Errorf("changes = %+v, want none", d.Changes)
+		}
+	})
+}
+
+func TestClassificationSnapshot(t *testing.T) {
+	by := "reviewer"
+	c := classifications.Classification{
+		Classification: "SECRET",
+		Confidence:     "MEDIUM",
+		MarkingsFound:  []string{"SECRET"},
+		Rationale:      "Portion markings.",
+		ModelName:      "gpt-5-mini",
+		ValidatedBy:    &by,
+	}
+
+	s := c.Snapshot()
+	if s.Classification != c.Classification || s.Confidence != c.Confidence || s.Rationale != c.Rationale {
+		t.Errorf("snapshot = %+v, want values from %+v", s, c)
+	}
+	if s.ValidatedBy == nil || *s.ValidatedBy != by {
+		t.Errorf("ValidatedBy = %v, want %q", s.ValidatedBy, by)
+	}
+}
+func TestUsageFiltersFromQuery(t *testing.T) {
+	t.Run("all filters", func(t *testing.T) {
+		f, err := classifications.UsageFiltersFromQuery(url.Values{
+			"from":              {"2026-03-01"},
+			"to":                {"2026-03-31"},
+			"model_name":        {"gpt-5-mini"},
+			"external_platform": {"HQ"},
+		})
+		if err != nil {
+			t.Fatalf("UsageFiltersFromQuery() error = %v", err)
+		}
+		if f.From == nil || !f.From.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("From = %v, want 2026-03-01", f.From)
+		}
+		if f.To == nil || !f.To.Equal(time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("To = %v, want 2026-03-31", f.To)
+		}
+		if f.ModelName == nil || *f.ModelName != "gpt-5-mini" {
+			t.Errorf("ModelName = %v, want gpt-5-mini", f.ModelName)
+		}
+		if f.ExternalPlatform == nil || *f.ExternalPlatform != "HQ" {
+			t.Errorf("ExternalPlatform = %v, want HQ", f.ExternalPlatform)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		f, err := classifications.UsageFiltersFromQuery(url.Values{})
+		if err != nil {
+			t.Fatalf("UsageFiltersFromQuery() error = %v", err)
+		}
+		if f.From != nil || f.To != nil || f.ModelName != nil || f.ExternalPlatform != nil {
+			t.Errorf("filters = %+v, want all nil", f)
+		}
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		_, err := classifications.UsageFiltersFromQuery(url.Values{"to": {"03/31/2026"}})
+		if !errors.Is(err, classifications.ErrInvalidUsageFilter) {
+			t.Errorf("error = %v, want ErrInvalidUsageFilter", err)
+		}
+	})
+}
+
+func TestPricingCost(t *testing.T) {
+	pricing := classifications.Pricing{
+		Currency: "USD",
+		Prices: map[string]classifications.Price{
+			"gpt-5-mini": {PromptPerMillion: 0.25, CompletionPerMillion: 2},
+		},
+	}
+
+	cost, ok := pricing.Cost("gpt-5-mini", state.Usage{PromptTokens: 2_000_000, CompletionTokens: 500_000})
+	if !ok {
+		t.Fatal("Cost() ok = false, want true")
+	}
+	if cost != 1.5 {
+		t.Errorf("Cost() = %v, want 1.5", cost)
+	}
+
+	if _, ok := pricing.Cost("unknown", state.Usage{PromptTokens: 10}); ok {
+		t.Error("Cost() ok = true for unpriced model")
+	}
+}
+
+func TestNewUsageReport(t *testing.T) {
+	pricing := classifications.Pricing{
+		Currency: "USD",
+		Prices: map[string]classifications.Price{
+			"gpt-5-mini": {PromptPerMillion: 1, CompletionPerMillion: 4},
+		},
+	}
+
+	rows := []classifications.UsageRow{
+		{Day: "2026-03-02", ModelName: "gpt-5-mini", ExternalPlatform: "HQ", Runs: 3,
+			Usage: state.Usage{VisionCalls: 6, ChatCalls: 3, PromptTokens: 1_000_000, CompletionTokens: 250_000}},
+		{Day: "2026-03-02", ModelName: "llava", ExternalPlatform: "HQ", Runs: 1,
+			Usage: state.Usage{VisionCalls: 1, ChatCalls: 1, PromptTokens: 900, CompletionTokens: 80}},
+		{Day: "2026-03-01", ModelName: "gpt-5-mini", ExternalPlatform: "Field", Runs: 2,
+			Usage: state.Usage{VisionCalls: 2, ChatCalls: 2, PromptTokens: 500_000, CompletionTokens: 0}},
+	}
+
+	report := classifications.NewUsageReport(rows, pricing)
+
+	if report.Currency != "USD" {
+		t.Errorf("Currency = %q, want USD", report.Currency)
+	}
+	if report.Runs != 6 {
+		t.Errorf("Runs = %d, want 6", report.Runs)
+	}
+	want := state.Usage{VisionCalls: 9, ChatCalls: 6, PromptTokens: 1_500_900, CompletionTokens: 250_080}
+	if report.Usage != want {
+		t.Errorf("Usage = %+v, want %+v", report.Usage, want)
+	}
+	if report.Cost != 2.5 {
+		t.Errorf("Cost = %v, want 2.5", report.Cost)
+	}
+	if report.Rows[0].Cost == nil || *report.Rows[0].Cost != 2 {
+		t.Errorf("Rows[0].Cost = %v, want 2", report.Rows[0].Cost)
+	}
+	if report.Rows[1].Cost != nil {
+		t.Errorf("Rows[1].Cost = %v, want nil for unpriced model", *report.Rows[1].Cost)
+	}
+	if len(report.UnpricedModels) != 1 || report.UnpricedModels[0] != "llava" {
+		t.Errorf("UnpricedModels = %v, want [llava]", report.UnpricedModels)
+	}
+
+	empty := classifications.NewUsageReport(nil, pricing)
+	if empty.Rows == nil || empty.UnpricedModels == nil {
+		t.Error("empty report should carry empty, not nil, lists")
+	}
+}
@@ -1,10 +1,10 @@
 package classifications_test
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -15,9 +15,13 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/JaimeStill/herald/internal/agents"
 	"github.com/JaimeStill/herald/internal/classifications"
+	"github.com/JaimeStill/herald/internal/documents"
+	"github.com/JaimeStill/herald/internal/state"
 	"github.com/JaimeStill/herald/internal/workflow"
 	"github.com/JaimeStill/herald/pkg/auth"
+	"github.com/JaimeStill/herald/pkg/lifecycle"
 	"github.com/JaimeStill/herald/pkg/pagination"
 )
 
@@ -25,10 +29,21 @@ type mockSystem struct {
 	listFn           func(ctx context.Context, page pagination.PageRequest, filters classifications.Filters) (*pagination.PageResult[classifications.Classification], error)
 	findFn           func(ctx context.Context, id uuid.UUID) (*classifications.Classification, error)
 	findByDocumentFn func(ctx context.Context, documentID uuid.UUID) (*classifications.Classification, error)
-	classifyFn       func(ctx context.Context, documentID uuid.UUID) (<-chan workflow.ExecutionEvent, error)
+	pagesFn          func(ctx context.Context, id uuid.UUID) ([]classifications.Page, error)
+	historyFn        func(ctx context.Context, id uuid.UUID) ([]classifications.Revision, error)
+	diffFn           func(ctx context.Context, id uuid.UUID, from, to int) (*classifications.RevisionDiff, error)
+	usageFn          func(ctx context.Context, filters classifications.UsageFilters) (*classifications.UsageReport, error)
+	resultsFn        func(ctx context.Context, id uuid.UUID) ([]classifications.Result, error)
+	agentsFn         func() []classifications.Agent
+	classifyFn       func(ctx context.Context, documentID uuid.UUID, agents []string) (*classifications.Job, error)
 	validateFn       func(ctx context.Context, id uuid.UUID, cmd classifications.ValidateCommand) (*classifications.Classification, error)
 	updateFn         func(ctx context.Context, id uuid.UUID, cmd classifications.UpdateCommand) (*classifications.Classification, error)
 	deleteFn         func(ctx context.Context, id uuid.UUID) error
+	listJobsFn       func(ctx context.Context, page pagination.PageRequest, filters classifications.JobFilters) (*pagination.PageResult[classifications.Job], error)
+	findJobFn        func(ctx context.Context, id uuid.UUID) (*classifications.Job, error)
+	classifyBulkFn   func(ctx context.Context, filters documents.Filters, agents []string) (*classifications.Batch, error)
+	findBatchFn      func(ctx context.Context, id uuid.UUID) (*classifications.Batch, error)
+	eventsFn         func(ctx context.Context, documentID uuid.UUID, lastEventID uint64) ([]workflow.ExecutionEvent, *workflow.Subscription, error)
 }
 
 func (m *mockSystem) Handler() *classifications.Handler {
@@ -47,8 +62,32 @@ func (m *mockSystem) FindByDocument(ctx context.Context, documentID uuid.UUID) (
 	return m.findByDocumentFn(ctx, documentID)
 }
 
-func (m *mockSystem) Classify(ctx context.Context, documentID uuid.UUID) (<-chan workflow.ExecutionEvent, error) {
-	return m.classifyFn(ctx, documentID)
+func (m *mockSystem) Pages(ctx context.Context, id uuid.UUID) ([]classifications.Page, error) {
+	return m.pagesFn(ctx, id)
+}
+
+func (m *mockSystem) History(ctx context.Context, id uuid.UUID) ([]classifications.Revision, error) {
+	return m.historyFn(ctx, id)
+}
+
+func (m *mockSystem) DiffRevisions(ctx context.Context, id uuid.UUID, from, to int) (*classifications.RevisionDiff, error) {
+	return m.diffFn(ctx, id, from, to)
+}
+
+func (m *mockSystem) Usage(ctx context.Context, filters classifications.UsageFilters) (*classifications.UsageReport, error) {
+	return m.usageFn(ctx, filters)
+}
+
+func (m *mockSystem) Results(ctx context.Context, id uuid.UUID) ([]classifications.Result, error) {
+	return m.resultsFn(ctx, id)
+}
+
+func (m *mockSystem) Agents() []classifications.Agent {
+	return m.agentsFn()
+}
+
+func (m *mockSystem) Classify(ctx context.Context, documentID uuid.UUID, agents []string) (*classifications.Job, error) {
+	return m.classifyFn(ctx, documentID, agents)
 }
 
 func (m *mockSystem) Validate(ctx context.Context, id uuid.UUID, cmd classifications.ValidateCommand) (*classifications.Classification, error) {
@@ -63,6 +102,30 @@ func (m *mockSystem) Delete(ctx context.Context, id uuid.UUID) error {
 	return m.deleteFn(ctx, id)
 }
 
+func (m *mockSystem) ListJobs(ctx context.Context, page pagination.PageRequest, filters classifications.JobFilters) (*pagination.PageResult[classifications.Job], error) {
+	return m.listJobsFn(ctx, page, filters)
+}
+
+func (m *mockSystem) FindJob(ctx context.Context, id uuid.UUID) (*classifications.Job, error) {
+	return m.findJobFn(ctx, id)
+}
+
+func (m *mockSystem) ClassifyBulk(ctx context.Context, filters documents.Filters, agents []string) (*classifications.Batch, error) {
+	return m.classifyBulkFn(ctx, filters, agents)
+}
+
+func (m *mockSystem) FindBatch(ctx context.Context, id uuid.UUID) (*classifications.Batch, error) {
+	return m.findBatchFn(ctx, id)
+}
+
+func (m *mockSystem) Events(ctx context.Context, documentID uuid.UUID, lastEventID uint64) ([]workflow.ExecutionEvent, *workflow.Subscription, error) {
+	return m.eventsFn(ctx, documentID, lastEventID)
+}
+
+func (m *mockSystem) Start(_ *lifecycle.Coordinator) error {
+	return nil
+}
+
 func newTestHandler(sys *mockSystem) *classifications.Handler {
 	return classifications.NewHandler(
 		sys,
@@ -96,6 +159,19 @@ func sampleClassification() classifications.Classification {
 	}
 }
 
+func sampleJob() classifications.Job {
+	now := time.Now().Truncate(time.Second)
+	return classifications.Job{
+		ID:          uuid.MustParse("770e8400-e29b-41d4-a716-446655440000"),
+		DocumentID:  uuid.MustParse("660e8400-e29b-41d4-a716-446655440000"),
+		Status:      classifications.JobQueued,
+		MaxAttempts: 3,
+		RunAfter:    now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
 func TestHandlerList(t *testing.T) {
 	c := sampleClassification()
 	sys := &mockSystem{
@@ -360,20 +436,350 @@ func TestHandlerSearch(t *testing.T) {
 	})
 }
 
+func TestHandlerPages(t *testing.T) {
+	c := sampleClassification()
+
+	t.Run("returns pages for classification", func(t *testing.T) {
+		brightness := 130
+		sys := &mockSystem{
+			pagesFn: func(_ context.Context, id uuid.UUID) ([]classifications.Page, error) {
+				if id != c.ID {
+					t.Errorf("id = %v, want %v", id, c.ID)
+				}
+				return []classifications.Page{
+					{ClassificationID: c.ID, PageNumber: 1, MarkingsFound: []string{"SECRET"}, Rationale: "Banner."},
+					{
+						ClassificationID: c.ID,
+						PageNumber:       2,
+						MarkingsFound:    []string{"SECRET//NOFORN"},
+						Rationale:        "Faded banner.",
+						Enhanced:         true,
+						EnhanceSettings:  &state.EnhanceSettings{Brightness: &brightness},
+					},
+				}, nil
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/pages/"+c.ID.String(), nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+
+		var got []classifications.Page
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("pages = %d, want 2", len(got))
+		}
+		if got[0].Enhanced || got[0].EnhanceSettings != nil {
+			t.Errorf("page 1 enhanced = %v settings = %v, want false/nil", got[0].Enhanced, got[0].EnhanceSettings)
+		}
+		if !got[1].Enhanced || got[1].EnhanceSettings == nil || *got[1].EnhanceSettings.Brightness != 130 {
+			t.Errorf("page 2 enhancement = %v %+v, want brightness 130", got[1].Enhanced, got[1].EnhanceSettings)
+		}
+	})
+
+	t.Run("invalid uuid returns 400", func(t *testing.T) {
+		sys := &mockSystem{}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/pages/not-a-uuid", nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+
+	t.Run("classification not found returns 404", func(t *testing.T) {
+		sys := &mockSystem{
+			pagesFn: func(_ context.Context, _ uuid.UUID) ([]classifications.Page, error) {
+				return nil, classifications.ErrNotFound
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/pages/"+uuid.New().String(), nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+	})
+}
+
+func TestHandlerResults(t *testing.T) {
+	c := sampleClassification()
+
+	t.Run("returns agent results for classification", func(t *testing.T) {
+		failure := "vision call failed"
+		sys := &mockSystem{
+			resultsFn: func(_ context.Context, id uuid.UUID) ([]classifications.Result, error) {
+				if id != c.ID {
+					t.Errorf("id = %v, want %v", id, c.ID)
+				}
+				return []classifications.Result{
+					{ClassificationID: c.ID, AgentName: "mini", Primary: true, Classification: "SECRET", Agrees: true},
+					{ClassificationID: c.ID, AgentName: "large", Classification: "TOP SECRET"},
+					{ClassificationID: c.ID, AgentName: "local", Error: &failure},
+				}, nil
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/results/"+c.ID.String(), nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+
+		var got []classifications.Result
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if len(got) != 3 {
+			t.Fatalf("results = %d, want 3", len(got))
+		}
+		if !got[0].Primary || !got[0].Agrees {
+			t.Errorf("result 0 primary = %v agrees = %v, want true/true", got[0].Primary, got[0].Agrees)
+		}
+		if got[1].Agrees {
+			t.Error("result 1 agrees = true, want false")
+		}
+		if got[2].Error == nil || *got[2].Error != failure {
+			t.Errorf("result 2 error = %v, want %q", got[2].Error, failure)
+		}
+	})
+
+	t.Run("classification not found returns 404", func(t *testing.T) {
+		sys := &mockSystem{
+			resultsFn: func(_ context.Context, _ uuid.UUID) ([]classifications.Result, error) {
+				return nil, classifications.ErrNotFound
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/results/"+uuid.New().String(), nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+	})
+}
+
+func TestHandlerAgents(t *testing.T) {
+	sys := &mockSystem{
+		agentsFn: func() []classifications.Agent {
+			return []classifications.Agent{
+				{Name: "mini", ModelName: "gpt-5-mini", ProviderName: "azure", Default: true},
+				{Name: "local", ModelName: "llama3.2:3b", ProviderName: "ollama"},
+			}
+		},
+	}
+	mux := setupMux(newTestHandler(sys))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/classifications/agents", nil)
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var got []classifications.Agent
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got) != 2 || got[0].Name != "mini" || !got[0].Default || got[1].Default {
+		t.Errorf("agents = %+v, want default mini then local", got)
+	}
+}
+
+func TestHandlerHistory(t *testing.T) {
+	c := sampleClassification()
+
+	t.Run("returns revisions", func(t *testing.T) {
+		sys := &mockSystem{
+			historyFn: func(_ context.Context, id uuid.UUID) ([]classifications.Revision, error) {
+				return []classifications.Revision{
+					{ClassificationID: id, Revision: 1, Action: classifications.RevisionClassify, Actor: classifications.SystemActor, After: c.Snapshot()},
+					{ClassificationID: id, Revision: 2, Action: classifications.RevisionValidate, Actor: "admin", After: c.Snapshot()},
+				}, nil
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/history/"+c.ID.String(), nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+
+		var got []classifications.Revision
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if len(got) != 2 || got[1].Action != classifications.RevisionValidate {
+			t.Errorf("revisions = %+v, want classify then validate", got)
+		}
+	})
+
+	t.Run("no history returns 404", func(t *testing.T) {
+		sys := &mockSystem{
+			historyFn: func(_ context.Context, _ uuid.UUID) ([]classifications.Revision, error) {
+				return nil, classifications.ErrNotFound
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/history/"+uuid.New().String(), nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+	})
+}
+
+func TestHandlerDiff(t *testing.T) {
+	c := sampleClassification()
+
+	t.Run("passes revision numbers", func(t *testing.T) {
+		var gotFrom, gotTo int
+		sys := &mockSystem{
+			diffFn: func(_ context.Context, id uuid.UUID, from, to int) (*classifications.RevisionDiff, error) {
+				gotFrom, gotTo = from, to
+				return &classifications.RevisionDiff{
+					ClassificationID: id,
+					From:             from,
+					To:               to,
+					Changes: []classifications.FieldChange{
+						{Field: "classification", From: "SECRET", To: "TOP SECRET"},
+					},
+				}, nil
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/history/"+c.ID.String()+"/diff?from=1&to=3", nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+		if gotFrom != 1 || gotTo != 3 {
+			t.Errorf("from, to = %d, %d, want 1, 3", gotFrom, gotTo)
+		}
+	})
+
+	t.Run("missing revision numbers return 400", func(t *testing.T) {
+		sys := &mockSystem{}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/history/"+c.ID.String()+"/diff?from=1", nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+
+	t.Run("unknown revision returns 404", func(t *testing.T) {
+		sys := &mockSystem{
+			diffFn: func(_ context.Context, _ uuid.UUID, _, _ int) (*classifications.RevisionDiff, error) {
+				return nil, classifications.ErrRevisionNotFound
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/history/"+c.ID.String()+"/diff?from=1&to=9", nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+	})
+}
+
+func TestHandlerUsage(t *testing.T) {
+	t.Run("passes filters", func(t *testing.T) {
+		var got classifications.UsageFilters
+		sys := &mockSystem{
+			usageFn: func(_ context.Context, filters classifications.UsageFilters) (*classifications.UsageReport, error) {
+				got = filters
+				report := classifications.NewUsageReport([]classifications.UsageRow{
+					{Day: "2026-03-02", ModelName: "gpt-5-mini", ExternalPlatform: "HQ", Runs: 1,
+						Usage: state.Usage{VisionCalls: 2, ChatCalls: 1, PromptTokens: 2400, CompletionTokens: 120}},
+				}, classifications.Pricing{Currency: "USD"})
+				return &report, nil
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/usage?from=2026-03-01&model_name=gpt-5-mini", nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+		if got.From == nil || got.From.Format(time.DateOnly) != "2026-03-01" {
+			t.Errorf("From = %v, want 2026-03-01", got.From)
+		}
+		if got.ModelName == nil || *got.ModelName != "gpt-5-mini" {
+			t.Errorf("ModelName = %v, want gpt-5-mini", got.ModelName)
+		}
+
+		var report classifications.UsageReport
+		if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if report.Runs != 1 || report.Usage.PromptTokens != 2400 {
+			t.Errorf("report = %+v, want 1 run with 2400 prompt tokens", report)
+		}
+	})
+
+	t.Run("invalid date returns 400", func(t *testing.T) {
+		sys := &mockSystem{}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/usage?from=yesterday", nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+}
+
 func TestHandlerClassify(t *testing.T) {
 	docID := uuid.MustParse("660e8400-e29b-41d4-a716-446655440000")
 
-	t.Run("streams SSE events", func(t *testing.T) {
+	t.Run("enqueues job and returns 202", func(t *testing.T) {
 		var capturedDocID uuid.UUID
 		sys := &mockSystem{
-			classifyFn: func(_ context.Context, id uuid.UUID) (<-chan workflow.ExecutionEvent, error) {
+			classifyFn: func(_ context.Context, id uuid.UUID, _ []string) (*classifications.Job, error) {
 				capturedDocID = id
-				ch := make(chan workflow.ExecutionEvent, 3)
-				ch <- workflow.ExecutionEvent{Type: workflow.NodeStart, Timestamp: time.Now(), Data: map[string]any{"node": "init", "iteration": 1}}
-				ch <- workflow.ExecutionEvent{Type: workflow.NodeComplete, Timestamp: time.Now(), Data: map[string]any{"node": "init", "iteration": 1}}
-				ch <- workflow.ExecutionEvent{Type: workflow.Complete, Timestamp: time.Now(), Data: map[string]any{"classification": "SECRET"}}
-				close(ch)
-				return ch, nil
+				j := sampleJob()
+				return &j, nil
 			},
 		}
 		mux := setupMux(newTestHandler(sys))
@@ -382,53 +788,62 @@ func TestHandlerClassify(t *testing.T) {
 		req := httptest.NewRequest("POST", "/classifications/"+docID.String(), nil)
 		mux.ServeHTTP(rec, req)
 
-		if rec.Code != http.StatusOK {
-			t.Fatalf("status = %d, want 200", rec.Code)
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("status = %d, want 202", rec.Code)
 		}
 		if capturedDocID != docID {
 			t.Errorf("documentId = %v, want %v", capturedDocID, docID)
 		}
-		if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
-			t.Errorf("Content-Type = %q, want text/event-stream", ct)
+
+		var got classifications.Job
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatalf("decode: %v", err)
 		}
-		if cc := rec.Header().Get("Cache-Control"); cc != "no-cache" {
-			t.Errorf("Cache-Control = %q, want no-cache", cc)
-		}
-
-		// Parse SSE events from response body
-		var events []struct {
-			eventType string
-			data      string
-		}
-		scanner := bufio.NewScanner(rec.Body)
-		var currentType, currentData string
-		for scanner.Scan() {
-			line := scanner.Text()
-			if strings.HasPrefix(line, "event: ") {
-				currentType = strings.TrimPrefix(line, "event: ")
-			} else if strings.HasPrefix(line, "data: ") {
-				currentData = strings.TrimPrefix(line, "data: ")
-			} else if line == "" && currentType != "" {
-				events = append(events, struct {
-					eventType string
-					data      string
-				}{currentType, currentData})
-				currentType = ""
-				currentData = ""
-			}
+		if got.ID != sampleJob().ID {
+			t.Errorf("id = %v, want %v", got.ID, sampleJob().ID)
 		}
+		if got.Status != classifications.JobQueued {
+			t.Errorf("status = %q, want queued", got.Status)
+		}
+	})
 
-		if len(events) != 3 {
-			t.Fatalf("event count = %d, want 3", len(events))
+	t.Run("agent parameters select the agents", func(t *testing.T) {
+		var captured []string
+		sys := &mockSystem{
+			classifyFn: func(_ context.Context, _ uuid.UUID, names []string) (*classifications.Job, error) {
+				captured = names
+				j := sampleJob()
+				return &j, nil
+			},
 		}
-		if events[0].eventType != "node.start" {
-			t.Errorf("event[0].type = %q, want node.start", events[0].eventType)
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/classifications/"+docID.String()+"?agent=large&agent=mini", nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("status = %d, want 202", rec.Code)
 		}
-		if events[1].eventType != "node.complete" {
-			t.Errorf("event[1].type = %q, want node.complete", events[1].eventType)
+		if len(captured) != 2 || captured[0] != "large" || captured[1] != "mini" {
+			t.Errorf("agents = %v, want [large mini]", captured)
 		}
-		if events[2].eventType != "complete" {
-			t.Errorf("event[2].type = %q, want complete", events[2].eventType)
+	})
+
+	t.Run("unknown agent returns 400", func(t *testing.T) {
+		sys := &mockSystem{
+			classifyFn: func(_ context.Context, _ uuid.UUID, names []string) (*classifications.Job, error) {
+				return nil, fmt.Errorf("%w: %s", agents.ErrUnknownAgent, names[0])
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/classifications/"+docID.String()+"?agent=missing", nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
 		}
 	})
 
@@ -445,9 +860,9 @@ func TestHandlerClassify(t *testing.T) {
 		}
 	})
 
-	t.Run("pre-stream error returns JSON", func(t *testing.T) {
+	t.Run("enqueue error returns JSON", func(t *testing.T) {
 		sys := &mockSystem{
-			classifyFn: func(_ context.Context, _ uuid.UUID) (<-chan workflow.ExecutionEvent, error) {
+			classifyFn: func(_ context.Context, _ uuid.UUID, _ []string) (*classifications.Job, error) {
 				return nil, classifications.ErrNotFound
 			},
 		}
@@ -461,7 +876,337 @@ func TestHandlerClassify(t *testing.T) {
 			t.Errorf("status = %d, want 404", rec.Code)
 		}
 		if ct := rec.Header().Get("Content-Type"); !strings.Contains(ct, "application/json") {
-			t.Errorf("Content-Type = %q, want application/json for pre-stream error", ct)
+			t.Errorf("Content-Type = %q, want application/json", ct)
+		}
+	})
+}
+
+func TestHandlerEvents(t *testing.T) {
+	docID := uuid.MustParse("660e8400-e29b-41d4-a716-446655440000")
+
+	t.Run("replays buffered events then streams until finished", func(t *testing.T) {
+		hub := workflow.NewHub(8, time.Minute)
+		hub.Begin(docID)
+		hub.Publish(docID, workflow.ExecutionEvent{Type: workflow.NodeStart, Data: map[string]any{"node": "init"}})
+		hub.Publish(docID, workflow.ExecutionEvent{Type: workflow.NodeComplete, Data: map[string]any{"node": "init"}})
+		hub.Publish(docID, workflow.ExecutionEvent{Type: workflow.Complete, Data: map[string]any{}})
+		hub.Finish(docID)
+
+		var capturedLast uint64
+		sys := &mockSystem{
+			eventsFn: func(_ context.Context, id uuid.UUID, lastEventID uint64) ([]workflow.ExecutionEvent, *workflow.Subscription, error) {
+				capturedLast = lastEventID
+				replay, sub := hub.Subscribe(id, lastEventID)
+				return replay, sub, nil
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/document/"+docID.String()+"/events", nil)
+		req.Header.Set("Last-Event-ID", "1")
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("Content-Type = %q, want text/event-stream", ct)
+		}
+		if capturedLast != 1 {
+			t.Errorf("lastEventID = %d, want 1", capturedLast)
+		}
+
+		body := rec.Body.String()
+		if strings.Contains(body, "event: node.start") {
+			t.Error("body replays event at or before Last-Event-ID")
+		}
+		for _, want := range []string{"id: 2\nevent: node.complete", "id: 3\nevent: complete"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("body missing %q:\n%s", want, body)
+			}
+		}
+	})
+
+	t.Run("last_event_id query param is accepted", func(t *testing.T) {
+		var capturedLast uint64
+		sys := &mockSystem{
+			eventsFn: func(_ context.Context, id uuid.UUID, lastEventID uint64) ([]workflow.ExecutionEvent, *workflow.Subscription, error) {
+				capturedLast = lastEventID
+				hub := workflow.NewHub(8, time.Minute)
+				hub.Begin(id)
+				hub.Finish(id)
+				replay, sub := hub.Subscribe(id, lastEventID)
+				return replay, sub, nil
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/document/"+docID.String()+"/events?last_event_id=7", nil)
+		mux.ServeHTTP(rec, req)
+
+		if capturedLast != 7 {
+			t.Errorf("lastEventID = %d, want 7", capturedLast)
+		}
+	})
+
+	t.Run("invalid Last-Event-ID returns 400", func(t *testing.T) {
+		sys := &mockSystem{}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/document/"+docID.String()+"/events", nil)
+		req.Header.Set("Last-Event-ID", "abc")
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+
+	t.Run("no active classification returns 404", func(t *testing.T) {
+		sys := &mockSystem{
+			eventsFn: func(_ context.Context, _ uuid.UUID, _ uint64) ([]workflow.ExecutionEvent, *workflow.Subscription, error) {
+				return nil, nil, classifications.ErrJobNotFound
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/document/"+docID.String()+"/events", nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+	})
+}
+
+func TestHandlerClassifyBulk(t *testing.T) {
+	t.Run("enqueues batch and returns 202", func(t *testing.T) {
+		var captured documents.Filters
+		batchID := uuid.MustParse("880e8400-e29b-41d4-a716-446655440000")
+		sys := &mockSystem{
+			classifyBulkFn: func(_ context.Context, filters documents.Filters, _ []string) (*classifications.Batch, error) {
+				captured = filters
+				return &classifications.Batch{
+					ID:      batchID,
+					Filters: filters,
+					Total:   3,
+					Skipped: 1,
+					Queued:  3,
+				}, nil
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		body := `{"status":"pending","content_type":"application/pdf"}`
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/classifications/bulk", strings.NewReader(body))
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("status = %d, want 202", rec.Code)
+		}
+		if captured.Status == nil || *captured.Status != "pending" {
+			t.Errorf("status filter = %v, want pending", captured.Status)
+		}
+		if captured.ContentType == nil || *captured.ContentType != "application/pdf" {
+			t.Errorf("content_type filter = %v, want application/pdf", captured.ContentType)
+		}
+
+		var got classifications.Batch
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if got.ID != batchID {
+			t.Errorf("id = %v, want %v", got.ID, batchID)
+		}
+		if got.Total != 3 || got.Skipped != 1 || got.Queued != 3 {
+			t.Errorf("counts = total %d skipped %d queued %d, want 3/1/3", got.Total, got.Skipped, got.Queued)
+		}
+	})
+
+	t.Run("invalid body returns 400", func(t *testing.T) {
+		sys := &mockSystem{}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/classifications/bulk", strings.NewReader("{"))
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+}
+
+func TestHandlerFindBatch(t *testing.T) {
+	batchID := uuid.MustParse("880e8400-e29b-41d4-a716-446655440000")
+
+	t.Run("returns batch by id", func(t *testing.T) {
+		sys := &mockSystem{
+			findBatchFn: func(_ context.Context, id uuid.UUID) (*classifications.Batch, error) {
+				if id != batchID {
+					t.Errorf("id = %v, want %v", id, batchID)
+				}
+				return &classifications.Batch{ID: id, Total: 2, Succeeded: 1, Running: 1}, nil
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/batches/"+batchID.String(), nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+
+		var got classifications.Batch
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if got.Succeeded != 1 || got.Running != 1 {
+			t.Errorf("counts = succeeded %d running %d, want 1/1", got.Succeeded, got.Running)
+		}
+	})
+
+	t.Run("invalid uuid returns 400", func(t *testing.T) {
+		sys := &mockSystem{}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/batches/not-a-uuid", nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+
+	t.Run("not found returns 404", func(t *testing.T) {
+		sys := &mockSystem{
+			findBatchFn: func(_ context.Context, _ uuid.UUID) (*classifications.Batch, error) {
+				return nil, classifications.ErrBatchNotFound
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/batches/"+uuid.New().String(), nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+	})
+}
+
+func TestHandlerListJobs(t *testing.T) {
+	j := sampleJob()
+
+	t.Run("returns paginated list", func(t *testing.T) {
+		sys := &mockSystem{
+			listJobsFn: func(_ context.Context, page pagination.PageRequest, _ classifications.JobFilters) (*pagination.PageResult[classifications.Job], error) {
+				result := pagination.NewPageResult([]classifications.Job{j}, 1, page.Page, page.PageSize)
+				return &result, nil
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/jobs", nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+
+		var result pagination.PageResult[classifications.Job]
+		if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if len(result.Data) != 1 {
+			t.Errorf("data length = %d, want 1", len(result.Data))
+		}
+	})
+
+	t.Run("passes query filters", func(t *testing.T) {
+		var captured classifications.JobFilters
+		sys := &mockSystem{
+			listJobsFn: func(_ context.Context, page pagination.PageRequest, filters classifications.JobFilters) (*pagination.PageResult[classifications.Job], error) {
+				captured = filters
+				result := pagination.NewPageResult([]classifications.Job{}, 0, page.Page, page.PageSize)
+				return &result, nil
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/jobs?status=failed&document_id="+j.DocumentID.String(), nil)
+		mux.ServeHTTP(rec, req)
+
+		if captured.Status == nil || *captured.Status != classifications.JobFailed {
+			t.Errorf("status filter = %v, want failed", captured.Status)
+		}
+		if captured.DocumentID == nil || *captured.DocumentID != j.DocumentID {
+			t.Errorf("document_id filter = %v, want %v", captured.DocumentID, j.DocumentID)
+		}
+	})
+}
+
+func TestHandlerFindJob(t *testing.T) {
+	j := sampleJob()
+
+	t.Run("returns job by id", func(t *testing.T) {
+		sys := &mockSystem{
+			findJobFn: func(_ context.Context, id uuid.UUID) (*classifications.Job, error) {
+				if id != j.ID {
+					t.Errorf("id = %v, want %v", id, j.ID)
+				}
+				return &j, nil
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/jobs/"+j.ID.String(), nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+	})
+
+	t.Run("invalid uuid returns 400", func(t *testing.T) {
+		sys := &mockSystem{}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/jobs/not-a-uuid", nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+
+	t.Run("not found returns 404", func(t *testing.T) {
+		sys := &mockSystem{
+			findJobFn: func(_ context.Context, _ uuid.UUID) (*classifications.Job, error) {
+				return nil, classifications.ErrJobNotFound
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/classifications/jobs/"+uuid.New().String(), nil)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
 		}
 	})
 }
@@ -833,7 +1578,18 @@ func TestHandlerRoutes(t *testing.T) {
 		{"GET", ""},
 		{"GET", "/{id}"},
 		{"GET", "/document/{id}"},
+		{"GET", "/document/{id}/events"},
+		{"GET", "/pages/{id}"},
+		{"GET", "/results/{id}"},
+		{"GET", "/history/{id}"},
+		{"GET", "/history/{id}/diff"},
+		{"GET", "/usage"},
+		{"GET", "/agents"},
 		{"POST", "/search"},
+		{"POST", "/bulk"},
+		{"GET", "/batches/{id}"},
+		{"GET", "/jobs"},
+		{"GET", "/jobs/{id}"},
 		{"POST", "/{documentId}"},
 		{"POST", "/{id}/validate"},
 		{"PUT", "/{id}"},
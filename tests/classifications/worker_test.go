@@ -0,0 +1,176 @@
+package classifications_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"image"
+	"image/png"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/JaimeStill/herald/internal/agents"
+	"github.com/JaimeStill/herald/internal/classifications"
+	"github.com/JaimeStill/herald/internal/documents"
+	"github.com/JaimeStill/herald/internal/format"
+	"github.com/JaimeStill/herald/internal/prompts"
+	"github.com/JaimeStill/herald/pkg/database"
+	"github.com/JaimeStill/herald/pkg/lifecycle"
+	"github.com/JaimeStill/herald/pkg/pagination"
+	"github.com/JaimeStill/herald/pkg/storage"
+)
+
+// testDatabaseEnv points the worker tests at a migrated database, for
+// example the compose stack's after `go run ./cmd/migrate -up`.
+var testDatabaseEnv = &database.Env{
+	Host:     "HERALD_TEST_DB_HOST",
+	Port:     "HERALD_TEST_DB_PORT",
+	Name:     "HERALD_TEST_DB_NAME",
+	User:     "HERALD_TEST_DB_USER",
+	Password: "HERALD_TEST_DB_PASSWORD",
+	SSLMode:  "HERALD_TEST_DB_SSL_MODE",
+}
+
+// blockingAgent answers vision calls only once their context is cancelled,
+// closing started when the first call arrives, so a job can be held
+// mid-classification.
+type blockingAgent struct {
+	agents.Agent
+	started chan struct{}
+	once    sync.Once
+}
+
+func (a *blockingAgent) Vision(ctx context.Context, _ string, _ []byte) (agents.Response, error) {
+	a.once.Do(func() { close(a.started) })
+	<-ctx.Done()
+	return agents.Response{}, ctx.Err()
+}
+
+func (a *blockingAgent) Model() string    { return "blocking-model" }
+func (a *blockingAgent) Provider() string { return "test" }
+
+// TestWorkerShutdownReleasesJob shuts the process down while a worker is
+// mid-classification and checks the job is released back to the queue
+// before the database closes. It runs when HERALD_TEST_DB_NAME is set.
+func TestWorkerShutdownReleasesJob(t *testing.T) {
+	if os.Getenv(testDatabaseEnv.Name) == "" {
+		t.Skip("HERALD_TEST_DB_NAME not set")
+	}
+
+	logger := slog.New(slog.DiscardHandler)
+	ctx := context.Background()
+
+	cfg := &database.Config{}
+	if err := cfg.Finalize(testDatabaseEnv); err != nil {
+		t.Fatalf("database config: %v", err)
+	}
+	dbSys, err := database.New(cfg, logger)
+	if err != nil {
+		t.Fatalf("database.New() error = %v", err)
+	}
+
+	store, err := storage.New(&storage.Config{
+		Backend:       storage.BackendFilesystem,
+		Root:          t.TempDir(),
+		ContainerName: "documents",
+	}, logger)
+	if err != nil {
+		t.Fatalf("storage.New() error = %v", err)
+	}
+
+	lc := lifecycle.New()
+	for _, s := range []interface {
+		Start(*lifecycle.Coordinator) error
+	}{dbSys, store} {
+		if err := s.Start(lc); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+	}
+
+	db := dbSys.Connection()
+	page := pagination.Config{DefaultPageSize: 20, MaxPageSize: 100}
+	formats := format.NewRegistry(format.NewImageHandler(format.Options{Renderer: format.NativeRenderer()}))
+	docs := documents.New(db, store, logger, page, formats)
+
+	agent := &blockingAgent{started: make(chan struct{})}
+	registry := agents.NewRegistry(agents.Entry{
+		Name:  "blocking",
+		Model: agent.Model(),
+		New:   func(context.Context) (agents.Agent, error) { return agent, nil },
+	})
+
+	sys := classifications.New(
+		db, registry, logger, page, store, docs, prompts.New(db, logger, page), formats,
+		classifications.MarkingConfig{},
+		classifications.Pricing{},
+		classifications.WorkflowConfig{},
+		classifications.WorkerConfig{
+			Workers:       1,
+			PollInterval:  20 * time.Millisecond,
+			LeaseDuration: time.Minute,
+			MaxAttempts:   3,
+			RetryBackoff:  time.Second,
+			MaxRunning:    100,
+		},
+	)
+	if err := sys.Start(lc); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	lc.WaitForStartup()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 32, 32))); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := docs.Create(ctx, documents.CreateCommand{
+		Data:             buf.Bytes(),
+		Filename:         "shutdown.png",
+		ContentType:      "image/png",
+		ExternalID:       1,
+		ExternalPlatform: "TEST",
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	verify, err := sql.Open("pgx", cfg.Dsn())
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	t.Cleanup(func() {
+		verify.Exec("DELETE FROM classification_jobs WHERE document_id = $1", doc.ID)
+		verify.Exec("DELETE FROM documents WHERE id = $1", doc.ID)
+		verify.Close()
+	})
+
+	job, err := sys.Classify(ctx, doc.ID, nil)
+	if err != nil {
+		t.Fatalf("Classify() error = %v", err)
+	}
+
+	select {
+	case <-agent.started:
+	case <-time.After(10 * time.Second):
+		t.Fatal("job was not claimed")
+	}
+
+	if err := lc.Shutdown(10 * time.Second); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	var status string
+	var attempts int
+	if err := verify.QueryRow(
+		"SELECT status, attempts FROM classification_jobs WHERE id = $1", job.ID,
+	).Scan(&status, &attempts); err != nil {
+		t.Fatalf("read job: %v", err)
+	}
+	if status != "queued" || attempts != 0 {
+		t.Errorf("job status = %s with %d attempts, want queued with 0", status, attempts)
+	}
+}
@@ -375,6 +375,185 @@ func TestMaxUploadSizeEnvOverride(t *testing.T) {
 	}
 }
 
+func TestJobsDefaults(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "config.json", minimalConfig)
+	chdir(t, dir)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	if cfg.Jobs.Workers != 2 {
+		t.Errorf("jobs workers: got %d, want 2", cfg.Jobs.Workers)
+	}
+	if cfg.Jobs.MaxAttempts != 3 {
+		t.Errorf("jobs max_attempts: got %d, want 3", cfg.Jobs.MaxAttempts)
+	}
+	if cfg.Jobs.PollIntervalDuration() != 2*time.Second {
+		t.Errorf("jobs poll_interval: got %v, want 2s", cfg.Jobs.PollIntervalDuration())
+	}
+	if cfg.Jobs.LeaseDurationValue() != 5*time.Minute {
+		t.Errorf("jobs lease_duration: got %v, want 5m", cfg.Jobs.LeaseDurationValue())
+	}
+	if cfg.Jobs.RetryBackoffDuration() != 30*time.Second {
+		t.Errorf("jobs retry_backoff: got %v, want 30s", cfg.Jobs.RetryBackoffDuration())
+	}
+	if cfg.Jobs.MaxRunning != 8 {
+		t.Errorf("jobs max_running: got %d, want 8", cfg.Jobs.MaxRunning)
+	}
+}
+
+func TestJobsEnvOverrides(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "config.json", baseConfig)
+	chdir(t, dir)
+
+	t.Setenv("HERALD_JOBS_WORKERS", "8")
+	t.Setenv("HERALD_JOBS_MAX_ATTEMPTS", "5")
+	t.Setenv("HERALD_JOBS_LEASE_DURATION", "10m")
+	t.Setenv("HERALD_JOBS_MAX_RUNNING", "32")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	if cfg.Jobs.Workers != 8 {
+		t.Errorf("jobs workers: got %d, want 8", cfg.Jobs.Workers)
+	}
+	if cfg.Jobs.MaxAttempts != 5 {
+		t.Errorf("jobs max_attempts: got %d, want 5", cfg.Jobs.MaxAttempts)
+	}
+	if cfg.Jobs.LeaseDurationValue() != 10*time.Minute {
+		t.Errorf("jobs lease_duration: got %v, want 10m", cfg.Jobs.LeaseDurationValue())
+	}
+	if cfg.Jobs.MaxRunning != 32 {
+		t.Errorf("jobs max_running: got %d, want 32", cfg.Jobs.MaxRunning)
+	}
+}
+
+func TestJobsValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		jobs    string
+		wantErr string
+	}{
+		{
+			name:    "negative retry_backoff",
+			jobs:    `{"retry_backoff": "-1s"}`,
+			wantErr: "retry_backoff must not be negative",
+		},
+		{
+			name:    "lease shorter than three polls",
+			jobs:    `{"poll_interval": "2s", "lease_duration": "5s"}`,
+			wantErr: "lease_duration must be at least 3x poll_interval",
+		},
+		{
+			name:    "sub-nanosecond heartbeat",
+			jobs:    `{"poll_interval": "1ns", "lease_duration": "2ns"}`,
+			wantErr: "lease_duration must be at least 3x poll_interval",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeConfig(t, dir, "config.json", `{
+				"database": {"name": "herald", "user": "herald"},
+				"storage": {"connection_string": "conn"},
+				"jobs": `+tt.jobs+`
+			}`)
+			chdir(t, dir)
+
+			_, err := config.Load()
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %q does not contain %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMarkingsDefaults(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "config.json", minimalConfig)
+	chdir(t, dir)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	if cfg.Markings.Strict {
+		t.Error("markings strict: got true, want false")
+	}
+	if len(cfg.Markings.Taxonomy.Levels) != 4 {
+		t.Errorf("markings levels: got %d, want 4", len(cfg.Markings.Taxonomy.Levels))
+	}
+	if len(cfg.Markings.Taxonomy.Dissemination) == 0 {
+		t.Error("markings dissemination: got none, want defaults")
+	}
+}
+
+func TestMarkingsOverlayAndEnv(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "config.json", minimalConfig)
+	writeConfig(t, dir, "config.test.json", `{
+  "markings": {
+    "taxonomy": {
+      "levels": [
+        { "name": "OFFICIAL" },
+        { "name": "OFFICIAL-SENSITIVE", "aliases": ["OS"] }
+      ]
+    }
+  }
+}`)
+	chdir(t, dir)
+
+	t.Setenv("HERALD_ENV", "test")
+	t.Setenv("HERALD_MARKINGS_STRICT", "true")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	if !cfg.Markings.Strict {
+		t.Error("markings strict: got false, want true")
+	}
+	if got := cfg.Markings.Taxonomy.Levels[1].Name; got != "OFFICIAL-SENSITIVE" {
+		t.Errorf("markings levels[1]: got %q, want OFFICIAL-SENSITIVE", got)
+	}
+	if len(cfg.Markings.Taxonomy.SCI) == 0 {
+		t.Error("markings sci: got none, want defaults")
+	}
+}
+
+func TestMarkingsInvalidTaxonomy(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "config.json", `{
+  "database": { "name": "herald", "user": "herald" },
+  "storage": { "connection_string": "conn" },
+  "markings": {
+    "taxonomy": {
+      "levels": [
+        { "name": "SECRET", "aliases": ["S"] },
+        { "name": "SENSITIVE", "aliases": ["S"] }
+      ]
+    }
+  }
+}`)
+	chdir(t, dir)
+
+	if _, err := config.Load(); err == nil {
+		t.Fatal("expected error for ambiguous taxonomy alias")
+	}
+}
+
 func TestServerValidation(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -574,3 +753,497 @@ func TestAgentTokenNotRequired(t *testing.T) {
 		t.Error("token should not be set when env var is absent")
 	}
 }
+
+func TestReplayDefaultsAndEnv(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "config.json", minimalConfig)
+	chdir(t, dir)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	if cfg.Replay.Fixtures != "_project/marked-documents/replay" {
+		t.Errorf("replay fixtures: got %q, want _project/marked-documents/replay", cfg.Replay.Fixtures)
+	}
+	if cfg.Replay.Record {
+		t.Error("replay record: got true, want false")
+	}
+	if cfg.Replay.Fallback {
+		t.Error("replay fallback: got true, want false")
+	}
+
+	t.Setenv("HERALD_REPLAY_FIXTURES", "/srv/fixtures")
+	t.Setenv("HERALD_REPLAY_RECORD", "true")
+	t.Setenv("HERALD_REPLAY_FALLBACK", "true")
+
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	if cfg.Replay.Fixtures != "/srv/fixtures" {
+		t.Errorf("replay fixtures: got %q, want /srv/fixtures", cfg.Replay.Fixtures)
+	}
+	if !cfg.Replay.Record {
+		t.Error("replay record: got false, want true")
+	}
+	if !cfg.Replay.Fallback {
+		t.Error("replay fallback: got false, want true")
+	}
+}
+
+func TestUsagePricing(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "config.json", minimalConfig)
+	chdir(t, dir)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	if cfg.Usage.Currency != "USD" {
+		t.Errorf("usage currency: got %q, want USD", cfg.Usage.Currency)
+	}
+	if len(cfg.Usage.Prices) != 0 {
+		t.Errorf("usage prices: got %v, want none", cfg.Usage.Prices)
+	}
+
+	writeConfig(t, dir, "config.json", strings.Replace(minimalConfig, "{", `{
+		"usage": {
+			"prices": {
+				"gpt-5-mini": {"prompt_per_million": 0.25, "completion_per_million": 2}
+			}
+		},`, 1))
+	writeConfig(t, dir, "config.prod.json", `{
+		"usage": {
+			"currency": "EUR",
+			"prices": {
+				"gpt-5": {"prompt_per_million": 1.25, "completion_per_million": 10}
+			}
+		}
+	}`)
+	t.Setenv("HERALD_ENV", "prod")
+
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	if cfg.Usage.Currency != "EUR" {
+		t.Errorf("usage currency: got %q, want EUR", cfg.Usage.Currency)
+	}
+	if len(cfg.Usage.Prices) != 2 {
+		t.Fatalf("usage prices: got %v, want base and overlay models", cfg.Usage.Prices)
+	}
+	if got := cfg.Usage.Prices["gpt-5-mini"].CompletionPerMillion; got != 2 {
+		t.Errorf("gpt-5-mini completion price: got %v, want 2", got)
+	}
+
+	t.Setenv("HERALD_USAGE_CURRENCY", "GBP")
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if cfg.Usage.Currency != "GBP" {
+		t.Errorf("usage currency: got %q, want GBP", cfg.Usage.Currency)
+	}
+}
+
+func TestUsageNegativePrice(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "config.json", strings.Replace(minimalConfig, "{", `{
+		"usage": {"prices": {"gpt-5-mini": {"prompt_per_million": -1}}},`, 1))
+	chdir(t, dir)
+
+	if _, err := config.Load(); err == nil {
+		t.Fatal("expected error for negative price")
+	}
+}
+
+func TestTracingDefaultsAndEnv(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "config.json", minimalConfig)
+	chdir(t, dir)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	if cfg.Tracing.Exporter != config.TracingNone {
+		t.Errorf("tracing exporter: got %q, want none", cfg.Tracing.Exporter)
+	}
+	if cfg.Tracing.Enabled() {
+		t.Error("tracing enabled: got true, want false")
+	}
+	if cfg.Tracing.SampleRatio != 1 {
+		t.Errorf("tracing sample ratio: got %v, want 1", cfg.Tracing.SampleRatio)
+	}
+
+	t.Setenv("HERALD_TRACING_EXPORTER", "otlp")
+	t.Setenv("HERALD_TRACING_ENDPOINT", "http://collector:4318")
+	t.Setenv("HERALD_TRACING_SAMPLE_RATIO", "0.25")
+
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	if !cfg.Tracing.Enabled() {
+		t.Error("tracing enabled: got false, want true")
+	}
+	if cfg.Tracing.Endpoint != "http://collector:4318" {
+		t.Errorf("tracing endpoint: got %q, want http://collector:4318", cfg.Tracing.Endpoint)
+	}
+	if cfg.Tracing.SampleRatio != 0.25 {
+		t.Errorf("tracing sample ratio: got %v, want 0.25", cfg.Tracing.SampleRatio)
+	}
+}
+
+func TestTracingValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		tracing string
+	}{
+		{"unknown exporter", `{"exporter": "jaeger"}`},
+		{"ratio above one", `{"exporter": "stdout", "sample_ratio": 1.5}`},
+		{"negative ratio", `{"exporter": "stdout", "sample_ratio": -0.1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeConfig(t, dir, "config.json", strings.Replace(minimalConfig, "{", `{
+				"tracing": `+tt.tracing+`,`, 1))
+			chdir(t, dir)
+
+			if _, err := config.Load(); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
+func TestThrottleLimits(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "config.json", strings.Replace(minimalConfig, "{", `{
+		"throttle": {
+			"requests_per_minute": 60,
+			"tokens_per_minute": 80000,
+			"deployments": {
+				"gpt-5": {"requests_per_minute": 10, "tokens_per_minute": 20000}
+			}
+		},`, 1))
+	chdir(t, dir)
+	t.Setenv("HERALD_THROTTLE_MAX_ATTEMPTS", "3")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	if got := cfg.Throttle.Limits("gpt-5-mini"); got.RequestsPerMinute != 60 || got.TokensPerMinute != 80000 {
+		t.Errorf("default limits: got %+v, want 60 rpm and 80000 tpm", got)
+	}
+	if got := cfg.Throttle.Limits("gpt-5"); got.RequestsPerMinute != 10 || got.TokensPerMinute != 20000 {
+		t.Errorf("gpt-5 limits: got %+v, want 10 rpm and 20000 tpm", got)
+	}
+	if cfg.Throttle.MaxAttempts != 3 {
+		t.Errorf("max attempts: got %d, want 3", cfg.Throttle.MaxAttempts)
+	}
+	if cfg.Throttle.MinBackoffDuration() != time.Second || cfg.Throttle.MaxBackoffDuration() != time.Minute {
+		t.Errorf("backoff: got %s to %s, want 1s to 1m", cfg.Throttle.MinBackoff, cfg.Throttle.MaxBackoff)
+	}
+}
+
+func TestThrottleValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		throttle string
+	}{
+		{"negative rate", `{"requests_per_minute": -1}`},
+		{"negative deployment rate", `{"deployments": {"gpt-5": {"tokens_per_minute": -5}}}`},
+		{"backoff inverted", `{"min_backoff": "10s", "max_backoff": "1s"}`},
+		{"bad backoff", `{"min_backoff": "soon"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeConfig(t, dir, "config.json", strings.Replace(minimalConfig, "{", `{
+				"throttle": `+tt.throttle+`,`, 1))
+			chdir(t, dir)
+
+			if _, err := config.Load(); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
+func TestWorkflowPartialPages(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "config.json", minimalConfig)
+	chdir(t, dir)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if cfg.Workflow.PartialPages {
+		t.Error("partial pages: got true, want false")
+	}
+
+	t.Setenv("HERALD_WORKFLOW_PARTIAL_PAGES", "true")
+
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if !cfg.Workflow.PartialPages {
+		t.Error("partial pages: got false, want true")
+	}
+}
+
+func TestWorkflowTextFastPath(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "config.json", minimalConfig)
+	chdir(t, dir)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if cfg.Workflow.TextFastPath {
+		t.Error("text fast path: got true, want false")
+	}
+
+	t.Setenv("HERALD_WORKFLOW_TEXT_FAST_PATH", "true")
+
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if !cfg.Workflow.TextFastPath {
+		t.Error("text fast path: got false, want true")
+	}
+}
+
+func TestWorkflowClassifyStrategy(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "config.json", minimalConfig)
+	chdir(t, dir)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if cfg.Workflow.ClassifyStrategy != "page" {
+		t.Errorf("classify strategy: got %q, want page", cfg.Workflow.ClassifyStrategy)
+	}
+
+	t.Setenv("HERALD_WORKFLOW_CLASSIFY_STRATEGY", "Banners")
+
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if cfg.Workflow.ClassifyStrategy != "banners" {
+		t.Errorf("classify strategy: got %q, want banners", cfg.Workflow.ClassifyStrategy)
+	}
+
+	t.Setenv("HERALD_WORKFLOW_CLASSIFY_STRATEGY", "thumbnails")
+	if _, err := config.Load(); err == nil {
+		t.Fatal("expected validation error")
+	}
+}
+
+func TestFormats(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "config.json", strings.Replace(minimalConfig, "{", `{
+		"formats": {
+			"pdf": {"crop": true, "page_density": 120},
+			"office": {"crop": true, "converter_timeout": "30s"}
+		},`, 1))
+	chdir(t, dir)
+	t.Setenv("HERALD_FORMATS_IMAGE_CROP", "true")
+	t.Setenv("HERALD_FORMATS_IMAGE_CROP_HEIGHT", "0.15")
+	t.Setenv("HERALD_FORMATS_OFFICE_CONVERTER", "unoconvert --convert-to pdf {input} {outdir}/source.pdf")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	want := config.FormatConfig{Crop: true, CropHeight: 0.1, CropDensity: 300, PageDensity: 120}
+	if cfg.Formats.PDF != want {
+		t.Errorf("pdf: got %+v, want %+v", cfg.Formats.PDF, want)
+	}
+	want = config.FormatConfig{Crop: true, CropHeight: 0.15, CropDensity: 300, PageDensity: 150}
+	if cfg.Formats.Image != want {
+		t.Errorf("image: got %+v, want %+v", cfg.Formats.Image, want)
+	}
+	want = config.FormatConfig{CropHeight: 0.1, CropDensity: 300, PageDensity: 150}
+	if cfg.Formats.TIFF != want {
+		t.Errorf("tiff: got %+v, want %+v", cfg.Formats.TIFF, want)
+	}
+	want = config.FormatConfig{Crop: true, CropHeight: 0.1, CropDensity: 300, PageDensity: 150}
+	if cfg.Formats.Office.FormatConfig != want {
+		t.Errorf("office: got %+v, want %+v", cfg.Formats.Office.FormatConfig, want)
+	}
+	if args := cfg.Formats.Office.ConverterArgs(); len(args) != 5 || args[0] != "unoconvert" {
+		t.Errorf("office converter args: got %q", args)
+	}
+	if d := cfg.Formats.Office.ConverterTimeoutDuration(); d != 30*time.Second {
+		t.Errorf("office converter timeout: got %v, want 30s", d)
+	}
+	if cfg.Formats.Renderer != "magick" {
+		t.Errorf("renderer: got %q, want magick", cfg.Formats.Renderer)
+	}
+
+	t.Setenv("HERALD_FORMATS_RENDERER", "Auto")
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if cfg.Formats.Renderer != "auto" {
+		t.Errorf("renderer: got %q, want auto", cfg.Formats.Renderer)
+	}
+
+	tests := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{"crop height too large", "HERALD_FORMATS_PDF_CROP_HEIGHT", "0.6"},
+		{"crop density too low", "HERALD_FORMATS_PDF_CROP_DENSITY", "10"},
+		{"page density too high", "HERALD_FORMATS_IMAGE_PAGE_DENSITY", "5000"},
+		{"unknown renderer", "HERALD_FORMATS_RENDERER", "poppler"},
+		{"native renderer alone", "HERALD_FORMATS_RENDERER", "native"},
+		{"converter without input", "HERALD_FORMATS_OFFICE_CONVERTER", "soffice --headless"},
+		{"bad converter timeout", "HERALD_FORMATS_OFFICE_CONVERTER_TIMEOUT", "soon"},
+		{"office crop height too large", "HERALD_FORMATS_OFFICE_CROP_HEIGHT", "0.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.key, tt.value)
+			if _, err := config.Load(); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
+func TestWorkflowEscalation(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "config.json", strings.Replace(minimalConfig, "{", `{
+		"agents": {"large": {"model": {"name": "gpt-5"}}},`, 1))
+	chdir(t, dir)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if cfg.Workflow.EscalationAgent != "" {
+		t.Errorf("escalation agent: got %q, want empty", cfg.Workflow.EscalationAgent)
+	}
+	if cfg.Workflow.EscalationThreshold != "LOW" {
+		t.Errorf("escalation threshold: got %q, want LOW", cfg.Workflow.EscalationThreshold)
+	}
+
+	t.Setenv("HERALD_WORKFLOW_ESCALATION_AGENT", "large")
+	t.Setenv("HERALD_WORKFLOW_ESCALATION_THRESHOLD", "medium")
+
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if cfg.Workflow.EscalationAgent != "large" {
+		t.Errorf("escalation agent: got %q, want large", cfg.Workflow.EscalationAgent)
+	}
+	if cfg.Workflow.EscalationThreshold != "MEDIUM" {
+		t.Errorf("escalation threshold: got %q, want MEDIUM", cfg.Workflow.EscalationThreshold)
+	}
+
+	tests := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{"unknown agent", "HERALD_WORKFLOW_ESCALATION_AGENT", "missing"},
+		{"invalid threshold", "HERALD_WORKFLOW_ESCALATION_THRESHOLD", "SOMETIMES"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.key, tt.value)
+			if _, err := config.Load(); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
+func TestNamedAgents(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "config.json", strings.Replace(minimalConfig, "{", `{
+		"agents": {
+			"large": {
+				"provider": {"name": "azure", "base_url": "https://example.openai.azure.com"},
+				"model": {"name": "gpt-5"}
+			}
+		},`, 1))
+	writeConfig(t, dir, "config.local.json", `{
+		"agents": {
+			"large": {"model": {"name": "gpt-5-chat"}},
+			"local": {"model": {"name": "llama3.2:3b"}}
+		}
+	}`)
+	chdir(t, dir)
+	t.Setenv("HERALD_ENV", "local")
+	t.Setenv("HERALD_AGENT_MODEL_NAME", "gpt-5-mini")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	if len(cfg.Agents) != 2 {
+		t.Fatalf("agents: got %d, want 2", len(cfg.Agents))
+	}
+
+	large := cfg.Agents["large"]
+	if large.Name != "large" {
+		t.Errorf("large name: got %q, want large", large.Name)
+	}
+	if large.Provider.Name != "azure" || large.Model.Name != "gpt-5-chat" {
+		t.Errorf("large: got %s/%s, want azure/gpt-5-chat", large.Provider.Name, large.Model.Name)
+	}
+
+	local := cfg.Agents["local"]
+	if local.Provider.Name == "" || local.Format == "" {
+		t.Errorf("local defaults not applied: provider %q format %q", local.Provider.Name, local.Format)
+	}
+	if local.Model.Name != "llama3.2:3b" {
+		t.Errorf("local model: got %q, want llama3.2:3b (env applies only to the default agent)", local.Model.Name)
+	}
+	if cfg.Agent.Model.Name != "gpt-5-mini" {
+		t.Errorf("default model: got %q, want gpt-5-mini", cfg.Agent.Model.Name)
+	}
+}
+
+func TestNamedAgentShadowsDefault(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "config.json", strings.Replace(minimalConfig, "{", `{
+		"agent": {"name": "mini"},
+		"agents": {"mini": {"model": {"name": "gpt-5"}}},`, 1))
+	chdir(t, dir)
+
+	if _, err := config.Load(); err == nil {
+		t.Fatal("expected validation error")
+	}
+}
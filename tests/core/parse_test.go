@@ -57,6 +57,39 @@ func TestFromMap(t *testing.T) {
 	})
 }
 
+func TestToMap(t *testing.T) {
+	t.Run("encodes struct fields by json tag", func(t *testing.T) {
+		got, err := core.ToMap(sample{Name: "test", Value: 42})
+		if err != nil {
+			t.Fatalf("ToMap error: %v", err)
+		}
+		if got["name"] != "test" || got["value"] != float64(42) {
+			t.Errorf("ToMap = %v, want map[name:test value:42]", got)
+		}
+	})
+
+	t.Run("round-trips through FromMap", func(t *testing.T) {
+		want := sample{Name: "round", Value: 7}
+		m, err := core.ToMap(want)
+		if err != nil {
+			t.Fatalf("ToMap error: %v", err)
+		}
+		got, err := core.FromMap[sample](m)
+		if err != nil {
+			t.Fatalf("FromMap error: %v", err)
+		}
+		if got != want {
+			t.Errorf("round trip = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("non-object value returns error", func(t *testing.T) {
+		if _, err := core.ToMap([]int{1, 2}); err == nil {
+			t.Error("ToMap error = nil, want error for array")
+		}
+	})
+}
+
 func TestParse(t *testing.T) {
 	t.Run("direct JSON", func(t *testing.T) {
 		got, err := core.Parse[sample](`{"name":"test","value":42}`)
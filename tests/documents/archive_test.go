@@ -0,0 +1,268 @@
+package documents_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/JaimeStill/herald/internal/documents"
+	"github.com/JaimeStill/herald/pkg/pagination"
+)
+
+var (
+	fakePDF = []byte("%PDF-1.4\nfake pdf content")
+	fakePNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+)
+
+type archiveFile struct {
+	name    string
+	content []byte
+}
+
+func TestHandlerUploadArchive(t *testing.T) {
+	t.Run("zip with json manifest in a folder", func(t *testing.T) {
+		var created []documents.CreateCommand
+		mux := setupMux(newTestHandler(recordingSystem(&created)))
+
+		manifest := `[
+			{"filename": "a.pdf", "external_id": 1, "external_platform": "HQ"},
+			{"filename": "scans/b.png", "external_id": 2, "external_platform": "FIELD"},
+			{"filename": "c.pdf", "external_id": 3, "external_platform": "HQ"}
+		]`
+		archive := zipArchive(t,
+			archiveFile{"batch/a.pdf", fakePDF},
+			archiveFile{"batch/scans/b.png", fakePNG},
+			archiveFile{"batch/notes.pdf", fakePDF},
+			archiveFile{"batch/.DS_Store", []byte("junk")},
+			archiveFile{"__MACOSX/batch/._a.pdf", []byte("junk")},
+			archiveFile{"batch/manifest.json", []byte(manifest)},
+		)
+
+		results := postArchive(t, mux, archive, http.StatusOK)
+
+		want := []struct {
+			filename string
+			ok       bool
+		}{
+			{"batch/a.pdf", true},
+			{"batch/scans/b.png", true},
+			{"batch/notes.pdf", false},
+			{"batch/c.pdf", false},
+		}
+		if len(results) != len(want) {
+			t.Fatalf("results = %+v, want %d", results, len(want))
+		}
+		for i, w := range want {
+			r := results[i]
+			if r.Filename != w.filename || (r.Error == "") != w.ok || (r.Document != nil) != w.ok {
+				t.Errorf("result[%d] = %+v, want %s ok=%v", i, r, w.filename, w.ok)
+			}
+		}
+
+		if len(created) != 2 {
+			t.Fatalf("created = %d, want 2", len(created))
+		}
+		if c := created[0]; c.Filename != "a.pdf" || c.ContentType != "application/pdf" || c.ExternalID != 1 || c.ExternalPlatform != "HQ" {
+			t.Errorf("first document = %+v", c)
+		}
+		if c := created[1]; c.Filename != "b.png" || c.ContentType != "image/png" || c.ExternalID != 2 || c.ExternalPlatform != "FIELD" {
+			t.Errorf("second document = %+v", c)
+		}
+	})
+
+	t.Run("tar.gz with csv manifest", func(t *testing.T) {
+		var created []documents.CreateCommand
+		mux := setupMux(newTestHandler(recordingSystem(&created)))
+
+		archive := tarGzArchive(t,
+			archiveFile{"manifest.csv", []byte("external_platform,filename,external_id\nHQ,memo.pdf,7\nHQ,readme.txt,8\n")},
+			archiveFile{"memo.pdf", fakePDF},
+			archiveFile{"readme.txt", []byte("plain text")},
+		)
+
+		results := postArchive(t, mux, archive, http.StatusOK)
+		if len(results) != 2 {
+			t.Fatalf("results = %+v, want 2", results)
+		}
+		if results[0].Error != "" || results[0].Document == nil {
+			t.Errorf("memo.pdf result = %+v, want a document", results[0])
+		}
+		if !strings.Contains(results[1].Error, documents.ErrUnsupportedContentType.Error()) {
+			t.Errorf("readme.txt error = %q, want unsupported content type", results[1].Error)
+		}
+		if len(created) != 1 || created[0].ExternalID != 7 {
+			t.Errorf("created = %+v, want memo.pdf with external_id 7", created)
+		}
+	})
+
+	t.Run("oversized entry is rejected alone", func(t *testing.T) {
+		var created []documents.CreateCommand
+		h := documents.NewHandler(
+			recordingSystem(&created),
+			slog.New(slog.NewTextHandler(io.Discard, nil)),
+			pagination.Config{DefaultPageSize: 20, MaxPageSize: 100},
+			4096,
+			testRegistry(),
+		)
+
+		big := append(append([]byte{}, fakePDF...), make([]byte, 64*1024)...)
+		archive := zipArchive(t,
+			archiveFile{"manifest.csv", []byte("filename,external_id,external_platform\nbig.pdf,1,HQ\nsmall.pdf,2,HQ\n")},
+			archiveFile{"big.pdf", big},
+			archiveFile{"small.pdf", fakePDF},
+		)
+
+		results := postArchive(t, setupMux(h), archive, http.StatusOK)
+		if len(results) != 2 || results[0].Error != documents.ErrFileTooLarge.Error() || results[1].Error != "" {
+			t.Errorf("results = %+v, want big.pdf too large and small.pdf created", results)
+		}
+	})
+
+	for name, tt := range map[string]struct {
+		archive []byte
+		status  int
+	}{
+		"no manifest": {
+			zipArchive(t, archiveFile{"a.pdf", fakePDF}),
+			http.StatusBadRequest,
+		},
+		"two manifests": {
+			zipArchive(t,
+				archiveFile{"manifest.csv", []byte("filename,external_id,external_platform\n")},
+				archiveFile{"manifest.json", []byte("[]")},
+			),
+			http.StatusBadRequest,
+		},
+		"bad external_id": {
+			zipArchive(t, archiveFile{"manifest.csv", []byte("filename,external_id,external_platform\na.pdf,x,HQ\n")}),
+			http.StatusBadRequest,
+		},
+		"missing platform": {
+			zipArchive(t, archiveFile{"manifest.json", []byte(`[{"filename": "a.pdf", "external_id": 1}]`)}),
+			http.StatusBadRequest,
+		},
+		"not an archive": {
+			fakePDF,
+			http.StatusBadRequest,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mux := setupMux(newTestHandler(&mockSystem{}))
+			postArchive(t, mux, tt.archive, tt.status)
+		})
+	}
+
+	t.Run("archive over the size limit", func(t *testing.T) {
+		h := documents.NewHandler(
+			&mockSystem{},
+			slog.New(slog.NewTextHandler(io.Discard, nil)),
+			pagination.Config{DefaultPageSize: 20, MaxPageSize: 100},
+			512,
+			testRegistry(),
+		)
+
+		postArchive(t, setupMux(h), bytes.Repeat([]byte("x"), 2048), http.StatusRequestEntityTooLarge)
+	})
+}
+
+// recordingSystem returns a mockSystem that appends each create command to
+// created and returns a document for it. It is safe for concurrent use.
+func recordingSystem(created *[]documents.CreateCommand) *mockSystem {
+	var mu sync.Mutex
+	return &mockSystem{
+		createFn: func(_ context.Context, cmd documents.CreateCommand) (*documents.Document, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			*created = append(*created, cmd)
+			doc := sampleDoc()
+			doc.Filename = cmd.Filename
+			return &doc, nil
+		},
+	}
+}
+
+// postArchive uploads archive to POST /documents/archive, checks the status,
+// and decodes the results of a successful upload.
+func postArchive(t *testing.T, mux *http.ServeMux, archive []byte, status int) []documents.BatchResult {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreateFormFile("file", "bundle")
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	part.Write(archive)
+	writer.Close()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/documents/archive", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, status, rec.Body.String())
+	}
+	if status != http.StatusOK {
+		return nil
+	}
+
+	var results []documents.BatchResult
+	if err := json.NewDecoder(rec.Body).Decode(&results); err != nil {
+		t.Fatalf("decode results: %v", err)
+	}
+	return results
+}
+
+func zipArchive(t *testing.T, files ...archiveFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(f.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tarGzArchive(t *testing.T, files ...archiveFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		hdr := &tar.Header{Name: f.name, Mode: 0600, Size: int64(len(f.content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(f.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
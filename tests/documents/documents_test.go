@@ -23,6 +23,8 @@ func TestMapHTTPStatus(t *testing.T) {
 		{"duplicate", documents.ErrDuplicate, http.StatusConflict},
 		{"file too large", documents.ErrFileTooLarge, http.StatusRequestEntityTooLarge},
 		{"invalid file", documents.ErrInvalidFile, http.StatusBadRequest},
+		{"invalid archive", documents.ErrInvalidArchive, http.StatusBadRequest},
+		{"invalid manifest", documents.ErrInvalidManifest, http.StatusBadRequest},
 		{"unknown error", errors.New("something else"), http.StatusInternalServerError},
 		{"wrapped not found", fmt.Errorf("find failed: %w", documents.ErrNotFound), http.StatusNotFound},
 		{"wrapped duplicate", fmt.Errorf("insert failed: %w", documents.ErrDuplicate), http.StatusConflict},
@@ -227,3 +229,33 @@ func TestFiltersApply(t *testing.T) {
 		}
 	})
 }
+
+func TestFiltersSelectIDs(t *testing.T) {
+	t.Run("no filters selects all document ids", func(t *testing.T) {
+		sql, args := documents.Filters{}.SelectIDs()
+
+		wantSQL := "SELECT d.id FROM public.documents d LEFT JOIN public.classifications c ON d.id = c.document_id"
+		if sql != wantSQL {
+			t.Errorf("sql = %q, want %q", sql, wantSQL)
+		}
+		if len(args) != 0 {
+			t.Errorf("args = %v, want empty", args)
+		}
+	})
+
+	t.Run("filters become WHERE conditions", func(t *testing.T) {
+		f := documents.Filters{
+			Status:           ptr("pending"),
+			ExternalPlatform: ptr("sharepoint"),
+		}
+		sql, args := f.SelectIDs()
+
+		wantSQL := "SELECT d.id FROM public.documents d LEFT JOIN public.classifications c ON d.id = c.document_id WHERE d.status = $1 AND d.external_platform = $2"
+		if sql != wantSQL {
+			t.Errorf("sql = %q, want %q", sql, wantSQL)
+		}
+		if len(args) != 2 {
+			t.Errorf("args length = %d, want 2", len(args))
+		}
+	})
+}
@@ -1,14 +1,18 @@
 package documents_test
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
+	"encoding/binary"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -21,8 +25,10 @@ import (
 
 func testRegistry() *format.Registry {
 	return format.NewRegistry(
-		format.NewPDFHandler(),
-		format.NewImageHandler(),
+		format.NewPDFHandler(format.Options{}),
+		format.NewImageHandler(format.Options{}),
+		format.NewTIFFHandler(format.Options{}),
+		format.NewOfficeHandler(format.Options{}, format.CommandConverter(nil, 0)),
 	)
 }
 
@@ -53,6 +59,10 @@ func (m *mockSystem) Delete(ctx context.Context, id uuid.UUID) error {
 	return m.deleteFn(ctx, id)
 }
 
+func (m *mockSystem) UpdatePageCount(context.Context, uuid.UUID, int) error {
+	return nil
+}
+
 func newTestHandler(sys *mockSystem) *documents.Handler {
 	return documents.NewHandler(
 		sys,
@@ -325,6 +335,62 @@ func TestHandlerUpload(t *testing.T) {
 		}
 	})
 
+	t.Run("counts tiff frames as pages", func(t *testing.T) {
+		var capturedCmd documents.CreateCommand
+		sys := &mockSystem{
+			createFn: func(_ context.Context, cmd documents.CreateCommand) (*documents.Document, error) {
+				capturedCmd = cmd
+				return &doc, nil
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		body, contentType := createMultipartForm(t, "scan.tiff", grayTIFF(3), "12345", "HQ")
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/documents", body)
+		req.Header.Set("Content-Type", contentType)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("status = %d, want 201", rec.Code)
+		}
+		if capturedCmd.ContentType != "image/tiff" {
+			t.Errorf("content type = %q, want image/tiff", capturedCmd.ContentType)
+		}
+		if capturedCmd.PageCount == nil || *capturedCmd.PageCount != 3 {
+			t.Errorf("page count = %v, want 3", capturedCmd.PageCount)
+		}
+	})
+
+	t.Run("detects office documents and counts their pages", func(t *testing.T) {
+		var capturedCmd documents.CreateCommand
+		sys := &mockSystem{
+			createFn: func(_ context.Context, cmd documents.CreateCommand) (*documents.Document, error) {
+				capturedCmd = cmd
+				return &doc, nil
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		body, contentType := createMultipartForm(t, "memo.docx", wordDocument(t, 4), "12345", "HQ")
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/documents", body)
+		req.Header.Set("Content-Type", contentType)
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("status = %d, want 201", rec.Code)
+		}
+		if capturedCmd.ContentType != format.ContentTypeDOCX {
+			t.Errorf("content type = %q, want %s", capturedCmd.ContentType, format.ContentTypeDOCX)
+		}
+		if capturedCmd.PageCount == nil || *capturedCmd.PageCount != 4 {
+			t.Errorf("page count = %v, want 4", capturedCmd.PageCount)
+		}
+	})
+
 	t.Run("missing external_id returns 400", func(t *testing.T) {
 		sys := &mockSystem{}
 		mux := setupMux(newTestHandler(sys))
@@ -430,6 +496,147 @@ func TestHandlerUpload(t *testing.T) {
 	})
 }
 
+func TestHandlerUploadBatch(t *testing.T) {
+	t.Run("registers each file with its metadata", func(t *testing.T) {
+		var created []documents.CreateCommand
+		mux := setupMux(newTestHandler(recordingSystem(&created)))
+
+		files := []archiveFile{{"a.pdf", fakePDF}, {"b.png", fakePNG}, {"c.txt", []byte("plain text")}}
+		results := postBatch(t, mux, files, []string{"1", "2", "3"}, []string{"HQ"}, http.StatusOK)
+
+		if len(results) != 3 {
+			t.Fatalf("results = %+v, want 3", results)
+		}
+		for i, f := range files {
+			if results[i].Filename != f.name {
+				t.Errorf("result[%d] filename = %q, want %q", i, results[i].Filename, f.name)
+			}
+		}
+		if results[0].Document == nil || results[1].Document == nil {
+			t.Errorf("supported files not created: %+v", results)
+		}
+		if !strings.Contains(results[2].Error, documents.ErrUnsupportedContentType.Error()) {
+			t.Errorf("c.txt error = %q, want unsupported content type", results[2].Error)
+		}
+
+		ids := make(map[string]int)
+		for _, cmd := range created {
+			if cmd.ExternalPlatform != "HQ" {
+				t.Errorf("%s platform = %q, want HQ", cmd.Filename, cmd.ExternalPlatform)
+			}
+			ids[cmd.Filename] = cmd.ExternalID
+		}
+		if len(ids) != 2 || ids["a.pdf"] != 1 || ids["b.png"] != 2 {
+			t.Errorf("created ids = %v, want a.pdf=1 b.png=2", ids)
+		}
+	})
+
+	t.Run("rejects files individually", func(t *testing.T) {
+		sys := &mockSystem{
+			createFn: func(_ context.Context, cmd documents.CreateCommand) (*documents.Document, error) {
+				if cmd.Filename == "dup.pdf" {
+					return nil, documents.ErrDuplicate
+				}
+				doc := sampleDoc()
+				return &doc, nil
+			},
+		}
+		mux := setupMux(newTestHandler(sys))
+
+		files := []archiveFile{{"ok.pdf", fakePDF}, {"dup.pdf", fakePDF}, {"bad-id.pdf", fakePDF}, {"no-platform.pdf", fakePDF}}
+		results := postBatch(t, mux, files, []string{"1", "2", "x", "4"}, []string{"HQ", "HQ", "HQ", ""}, http.StatusOK)
+
+		if len(results) != 4 || results[0].Error != "" {
+			t.Fatalf("results = %+v, want ok.pdf created", results)
+		}
+		if !strings.Contains(results[1].Error, documents.ErrDuplicate.Error()) {
+			t.Errorf("dup.pdf error = %q, want duplicate", results[1].Error)
+		}
+		for _, r := range results[2:] {
+			if !strings.Contains(r.Error, documents.ErrInvalidFile.Error()) {
+				t.Errorf("%s error = %q, want invalid file", r.Filename, r.Error)
+			}
+		}
+	})
+
+	for name, tt := range map[string]struct {
+		files     []archiveFile
+		ids       []string
+		platforms []string
+	}{
+		"no files":             {nil, nil, []string{"HQ"}},
+		"missing ids":          {[]archiveFile{{"a.pdf", fakePDF}, {"b.pdf", fakePDF}}, []string{"1"}, []string{"HQ"}},
+		"mismatched platforms": {[]archiveFile{{"a.pdf", fakePDF}, {"b.pdf", fakePDF}, {"c.pdf", fakePDF}}, []string{"1", "2", "3"}, []string{"HQ", "FIELD"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mux := setupMux(newTestHandler(&mockSystem{}))
+			postBatch(t, mux, tt.files, tt.ids, tt.platforms, http.StatusBadRequest)
+		})
+	}
+
+	t.Run("total over the size limit", func(t *testing.T) {
+		h := documents.NewHandler(
+			&mockSystem{},
+			slog.New(slog.NewTextHandler(io.Discard, nil)),
+			pagination.Config{DefaultPageSize: 20, MaxPageSize: 100},
+			2048,
+			testRegistry(),
+		)
+
+		half := append(append([]byte{}, fakePDF...), make([]byte, 1200)...)
+		files := []archiveFile{{"a.pdf", half}, {"b.pdf", half}}
+		postBatch(t, setupMux(h), files, []string{"1", "2"}, []string{"HQ"}, http.StatusRequestEntityTooLarge)
+	})
+}
+
+// postBatch uploads files to POST /documents/batch with the given repeated
+// metadata fields, checks the status, and decodes the results of a
+// successful upload.
+func postBatch(
+	t *testing.T,
+	mux *http.ServeMux,
+	files []archiveFile,
+	ids, platforms []string,
+	status int,
+) []documents.BatchResult {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, f := range files {
+		part, err := writer.CreateFormFile("file", f.name)
+		if err != nil {
+			t.Fatalf("create form file: %v", err)
+		}
+		part.Write(f.content)
+	}
+	for _, id := range ids {
+		writer.WriteField("external_id", id)
+	}
+	for _, platform := range platforms {
+		writer.WriteField("external_platform", platform)
+	}
+	writer.Close()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/documents/batch", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, status, rec.Body.String())
+	}
+	if status != http.StatusOK {
+		return nil
+	}
+
+	var results []documents.BatchResult
+	if err := json.NewDecoder(rec.Body).Decode(&results); err != nil {
+		t.Fatalf("decode results: %v", err)
+	}
+	return results
+}
+
 func TestHandlerDelete(t *testing.T) {
 	docID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
 
@@ -502,6 +709,8 @@ func TestHandlerRoutes(t *testing.T) {
 		{"GET", ""},
 		{"GET", "/{id}"},
 		{"POST", ""},
+		{"POST", "/archive"},
+		{"POST", "/batch"},
 		{"POST", "/search"},
 		{"DELETE", "/{id}"},
 	}
@@ -541,3 +750,55 @@ func createMultipartForm(t *testing.T, filename string, content []byte, external
 	writer.Close()
 	return &buf, writer.FormDataContentType()
 }
+
+// grayTIFF builds a little-endian TIFF of the given number of 1x1 gray
+// frames.
+func grayTIFF(frames int) []byte {
+	le := binary.LittleEndian
+	buf := []byte("II*\x00\x00\x00\x00\x00")
+	prev := 4
+	for range frames {
+		pixel := len(buf)
+		buf = append(buf, 0x80)
+
+		le.PutUint32(buf[prev:], uint32(len(buf)))
+		entries := [][3]uint32{
+			{256, 3, 1}, {257, 3, 1}, {258, 3, 8}, {259, 3, 1}, {262, 3, 1},
+			{273, 4, uint32(pixel)}, {277, 3, 1}, {278, 3, 1}, {279, 4, 1},
+		}
+		buf = le.AppendUint16(buf, uint16(len(entries)))
+		for _, e := range entries {
+			buf = le.AppendUint16(buf, uint16(e[0]))
+			buf = le.AppendUint16(buf, uint16(e[1]))
+			buf = le.AppendUint32(buf, 1)
+			buf = le.AppendUint32(buf, e[2])
+		}
+		prev = len(buf)
+		buf = le.AppendUint32(buf, 0)
+	}
+	return buf
+}
+
+// wordDocument builds a minimal Word package whose extended properties
+// record the given page count.
+func wordDocument(t *testing.T, pages int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"word/document.xml": `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"/>`,
+		"docProps/app.xml":  fmt.Sprintf("<Properties><Pages>%d</Pages></Properties>", pages),
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
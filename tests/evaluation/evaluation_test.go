@@ -0,0 +1,501 @@
+package evaluation_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/JaimeStill/herald/internal/agents"
+	"github.com/JaimeStill/herald/internal/evaluation"
+	"github.com/JaimeStill/herald/internal/format"
+	"github.com/JaimeStill/herald/internal/markings"
+	"github.com/JaimeStill/herald/internal/prompts"
+	"github.com/JaimeStill/herald/pkg/lifecycle"
+	"github.com/JaimeStill/herald/pkg/storage"
+)
+
+func defaultParser() *markings.Parser {
+	return markings.NewParser(markings.DefaultTaxonomy())
+}
+
+// projectPath resolves a path under _project/ relative to the repository root.
+func projectPath(t *testing.T, relative string) string {
+	t.Helper()
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	for {
+		candidate := filepath.Join(dir, relative)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			t.Fatalf("could not resolve %s", relative)
+		}
+		dir = parent
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile(%s) error = %v", path, err)
+	}
+}
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "memo.pdf"), "%PDF")
+	writeFile(t, filepath.Join(dir, "scan.png"), "png")
+
+	path := filepath.Join(dir, "manifest.json")
+	writeFile(t, path, `{"documents":[
+		{"file":"memo.pdf","classification":"SECRET//NOFORN"},
+		{"file":"scan.png","classification":"UNCLASSIFIED","content_type":"image/x-custom"}
+	]}`)
+
+	m, err := evaluation.LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+	if len(m.Documents) != 2 {
+		t.Fatalf("len(Documents) = %d, want 2", len(m.Documents))
+	}
+	if got := m.Documents[0].ContentType; got != "application/pdf" {
+		t.Errorf("Documents[0].ContentType = %q, want application/pdf", got)
+	}
+	if got := m.Documents[1].ContentType; got != "image/x-custom" {
+		t.Errorf("Documents[1].ContentType = %q, want image/x-custom", got)
+	}
+	if got := m.Path(m.Documents[0]); got != filepath.Join(dir, "memo.pdf") {
+		t.Errorf("Path() = %q, want file beside the manifest", got)
+	}
+	if err := m.Validate(defaultParser()); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestLoadManifestInvalid(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "memo.pdf"), "%PDF")
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"malformed", `{"documents":`},
+		{"empty", `{"documents":[]}`},
+		{"missing file", `{"documents":[{"file":"absent.pdf","classification":"SECRET"}]}`},
+		{"no file", `{"documents":[{"classification":"SECRET"}]}`},
+		{"no classification", `{"documents":[{"file":"memo.pdf","classification":" "}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "manifest.json")
+			writeFile(t, path, tt.content)
+
+			if _, err := evaluation.LoadManifest(path); !errors.Is(err, evaluation.ErrInvalidManifest) {
+				t.Errorf("LoadManifest() error = %v, want ErrInvalidManifest", err)
+			}
+		})
+	}
+}
+
+func TestManifestValidateRejectsUnknownMarking(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "memo.pdf"), "%PDF")
+	path := filepath.Join(dir, "manifest.json")
+	writeFile(t, path, `{"documents":[{"file":"memo.pdf","classification":"SUPER SECRET"}]}`)
+
+	m, err := evaluation.LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+
+	err = m.Validate(defaultParser())
+	if !errors.Is(err, evaluation.ErrInvalidManifest) {
+		t.Fatalf("Validate() error = %v, want ErrInvalidManifest", err)
+	}
+	if !strings.Contains(err.Error(), "memo.pdf") {
+		t.Errorf("Validate() error = %q, want file name", err)
+	}
+}
+
+func TestCorpusManifest(t *testing.T) {
+	m, err := evaluation.LoadManifest(projectPath(t, "_project/marked-documents/manifest.json"))
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+	if err := m.Validate(defaultParser()); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestScoreClassification(t *testing.T) {
+	p := defaultParser()
+
+	tests := []struct {
+		name    string
+		want    string
+		got     string
+		correct bool
+		level   string
+		matched []string
+		missing []string
+		extra   []string
+	}{
+		{
+			name:    "exact",
+			want:    "SECRET//NOFORN",
+			got:     "S//NF",
+			correct: true,
+			level:   "SECRET",
+			matched: []string{"NOFORN"},
+		},
+		{
+			name:    "missing caveat",
+			want:    "SECRET//NOFORN",
+			got:     "SECRET",
+			level:   "SECRET",
+			missing: []string{"NOFORN"},
+		},
+		{
+			name:  "extra caveat",
+			want:  "SECRET",
+			got:   "SECRET//NOFORN",
+			level: "SECRET",
+			extra: []string{"NOFORN"},
+		},
+		{
+			name:  "wrong level",
+			want:  "SECRET",
+			got:   "CONFIDENTIAL",
+			level: "CONFIDENTIAL",
+		},
+		{
+			name:    "declassification ignored when unstated",
+			want:    "SECRET//NOFORN",
+			got:     "SECRET//NOFORN//20291001",
+			correct: true,
+			level:   "SECRET",
+			matched: []string{"NOFORN"},
+		},
+		{
+			name:    "declassification required when stated",
+			want:    "SECRET//NOFORN//20291001",
+			got:     "SECRET//NOFORN",
+			level:   "SECRET",
+			matched: []string{"NOFORN"},
+		},
+		{
+			name:    "unparseable",
+			want:    "SECRET//NOFORN",
+			got:     "not a marking",
+			missing: []string{"NOFORN"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := p.Parse(tt.want)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.want, err)
+			}
+
+			s := evaluation.ScoreClassification(p, want, tt.got)
+
+			if s.Correct != tt.correct {
+				t.Errorf("Correct = %v, want %v (differences %v)", s.Correct, tt.correct, s.Differences)
+			}
+			if !s.Correct && len(s.Differences) == 0 {
+				t.Error("incorrect score carries no differences")
+			}
+			if s.Level != tt.level {
+				t.Errorf("Level = %q, want %q", s.Level, tt.level)
+			}
+			for _, c := range []struct {
+				name      string
+				got, want []string
+			}{
+				{"Matched", s.Matched, tt.matched},
+				{"Missing", s.Missing, tt.missing},
+				{"Extra", s.Extra, tt.extra},
+			} {
+				if !slices.Equal(c.got, c.want) {
+					t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadPromptSet(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "prompts.json")
+	writeFile(t, path, `{"name":"terse","instructions":{"classify":"Read the banners."}}`)
+
+	ps, err := evaluation.LoadPromptSet(path)
+	if err != nil {
+		t.Fatalf("LoadPromptSet() error = %v", err)
+	}
+
+	sys := ps.System()
+	ctx := context.Background()
+
+	got, err := sys.Instructions(ctx, prompts.StageClassify)
+	if err != nil || got != "Read the banners." {
+		t.Errorf("Instructions(classify) = %q, %v, want override", got, err)
+	}
+
+	builtin, _ := prompts.Instructions(prompts.StageFinalize)
+	if got, _ := sys.Instructions(ctx, prompts.StageFinalize); got != builtin {
+		t.Error("Instructions(finalize) did not fall back to the built-in instructions")
+	}
+
+	versions, err := sys.Versions(ctx)
+	if err != nil {
+		t.Fatalf("Versions() error = %v", err)
+	}
+	defaults, err := evaluation.DefaultPromptSet().System().Versions(ctx)
+	if err != nil {
+		t.Fatalf("default Versions() error = %v", err)
+	}
+
+	for i, v := range versions {
+		if v.Stage == prompts.StageClassify {
+			if v.Name == nil || *v.Name != "terse" {
+				t.Errorf("classify version name = %v, want terse", v.Name)
+			}
+			if v.Digest == defaults[i].Digest {
+				t.Error("classify digest unchanged by override")
+			}
+			continue
+		}
+		if v.Name != nil {
+			t.Errorf("%s version name = %q, want nil", v.Stage, *v.Name)
+		}
+		if v.Digest != defaults[i].Digest {
+			t.Errorf("%s digest differs from built-in", v.Stage)
+		}
+	}
+}
+
+func TestLoadPromptSetInvalid(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, content := range map[string]string{
+		"malformed":     `{"name":`,
+		"no name":       `{"instructions":{"classify":"x"}}`,
+		"unknown stage": `{"name":"x","instructions":{"triage":"x"}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, "prompts.json")
+			writeFile(t, path, content)
+
+			if _, err := evaluation.LoadPromptSet(path); !errors.Is(err, evaluation.ErrInvalidPromptSet) {
+				t.Errorf("LoadPromptSet() error = %v, want ErrInvalidPromptSet", err)
+			}
+		})
+	}
+}
+
+type fixedAgent struct {
+	usage agents.Usage
+}
+
+func (a *fixedAgent) Chat(context.Context, string) (agents.Response, error) {
+	return agents.Response{Text: "{}", Usage: a.usage}, nil
+}
+
+func (a *fixedAgent) Vision(context.Context, string, []byte) (agents.Response, error) {
+	return agents.Response{}, errors.New("vision unavailable")
+}
+
+func (a *fixedAgent) Model() string    { return "fixed" }
+func (a *fixedAgent) Provider() string { return "test" }
+
+func TestMeter(t *testing.T) {
+	var m evaluation.Meter
+	newAgent := m.Wrap(func(context.Context) (agents.Agent, error) {
+		return &fixedAgent{usage: agents.Usage{PromptTokens: 10, CompletionTokens: 4}}, nil
+	})
+
+	ctx := context.Background()
+	for range 2 {
+		a, err := newAgent(ctx)
+		if err != nil {
+			t.Fatalf("factory error = %v", err)
+		}
+		if _, err := a.Chat(ctx, "prompt"); err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+		if _, err := a.Vision(ctx, "prompt", nil); err == nil {
+			t.Fatal("Vision() error = nil")
+		}
+	}
+
+	calls, usage := m.Take()
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2 (failed calls are not counted)", calls)
+	}
+	if usage != (agents.Usage{PromptTokens: 20, CompletionTokens: 8}) {
+		t.Errorf("usage = %+v, want 20 prompt / 8 completion", usage)
+	}
+
+	if calls, usage := m.Take(); calls != 0 || usage.Total() != 0 {
+		t.Errorf("second Take() = %d, %+v, want zero", calls, usage)
+	}
+}
+
+// TestReplayManifestRecorded checks that every document in the replay
+// manifest has a recording, so an offline evaluation never depends on the
+// default fixture.
+func TestReplayManifestRecorded(t *testing.T) {
+	path := projectPath(t, "_project/marked-documents/replay-manifest.json")
+	m, err := evaluation.LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+	if err := m.Validate(defaultParser()); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	fixtures := projectPath(t, "_project/marked-documents/replay")
+	for _, e := range m.Documents {
+		data, err := os.ReadFile(m.Path(e))
+		if err != nil {
+			t.Fatal(err)
+		}
+		digest := sha256.Sum256(data)
+
+		f, err := agents.LoadFixture(agents.FixturePath(fixtures, e.File, hex.EncodeToString(digest[:])))
+		if err != nil {
+			t.Fatalf("%s: LoadFixture() error = %v", e.File, err)
+		}
+		if f == nil {
+			t.Errorf("%s: no recorded fixture", e.File)
+		}
+	}
+}
+
+func TestRunReplay(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+
+	store, err := storage.New(&storage.Config{
+		Backend:       storage.BackendFilesystem,
+		Root:          t.TempDir(),
+		ContainerName: "documents",
+	}, logger)
+	if err != nil {
+		t.Fatalf("storage.New() error = %v", err)
+	}
+	lc := lifecycle.New()
+	if err := store.Start(lc); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	lc.WaitForStartup()
+
+	images := projectPath(t, "_project/marked-documents/images")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "manifest.json")
+	writeFile(t, path, `{"documents":[
+		{"file":"`+filepath.Join(images, "marked-document.3.png")+`","classification":"SECRET//NOFORN//20280901"},
+		{"file":"`+filepath.Join(images, "marked-document.2.png")+`","classification":"SECRET"}
+	]}`)
+
+	m, err := evaluation.LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+
+	// marked-document.2.png has no recording; the default fixture answers it
+	// as unmarked, giving the report a known miss.
+	replay := agents.NewReplay(projectPath(t, "_project/marked-documents/replay"), "replay-model", true)
+	report, err := evaluation.Run(context.Background(), evaluation.Options{
+		NewAgent: func(context.Context) (agents.Agent, error) { return replay, nil },
+		Markings: defaultParser(),
+		Formats:  format.NewRegistry(format.NewPDFHandler(format.Options{}), format.NewImageHandler(format.Options{})),
+		Storage:  store,
+		Logger:   logger,
+	}, m, path)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if report.Model != "replay-model" || report.PromptSet != "default" {
+		t.Errorf("report model/prompt set = %q/%q", report.Model, report.PromptSet)
+	}
+	if len(report.Prompts) != len(prompts.Stages()) {
+		t.Errorf("len(Prompts) = %d, want %d", len(report.Prompts), len(prompts.Stages()))
+	}
+
+	if len(report.Documents) != 2 {
+		t.Fatalf("len(Documents) = %d, want 2", len(report.Documents))
+	}
+	for _, d := range report.Documents {
+		if d.Error != "" {
+			t.Fatalf("%s: error = %s", d.File, d.Error)
+		}
+		if d.Calls != 2 {
+			t.Errorf("%s: calls = %d, want 2 (classify + finalize)", d.File, d.Calls)
+		}
+	}
+
+	hit, miss := report.Documents[0], report.Documents[1]
+	if !hit.Score.Correct {
+		t.Errorf("marked-document.3: Correct = false, differences %v", hit.Score.Differences)
+	}
+	if miss.Score.Correct || miss.Classification != "UNCLASSIFIED" {
+		t.Errorf("marked-document.2: classification %q correct %v, want incorrect UNCLASSIFIED",
+			miss.Classification, miss.Score.Correct)
+	}
+
+	s := report.Summary
+	if s.Documents != 2 || s.Correct != 1 || s.Failed != 0 || s.Accuracy != 0.5 {
+		t.Errorf("summary = %+v, want 1 of 2 correct", s)
+	}
+	if s.CaveatPrecision == nil || *s.CaveatPrecision != 1 {
+		t.Errorf("CaveatPrecision = %v, want 1", s.CaveatPrecision)
+	}
+	if s.CaveatRecall == nil || *s.CaveatRecall != 1 {
+		t.Errorf("CaveatRecall = %v, want 1", s.CaveatRecall)
+	}
+
+	count := func(expected, column string) int {
+		row := slices.Index(report.Confusion.Levels, expected)
+		col := slices.Index(report.Confusion.Columns, column)
+		if row < 0 || col < 0 {
+			t.Fatalf("confusion cell %s/%s not found", expected, column)
+		}
+		return report.Confusion.Counts[row][col]
+	}
+	if got := count("SECRET", "SECRET"); got != 1 {
+		t.Errorf("confusion SECRET/SECRET = %d, want 1", got)
+	}
+	if got := count("SECRET", "UNCLASSIFIED"); got != 1 {
+		t.Errorf("confusion SECRET/UNCLASSIFIED = %d, want 1", got)
+	}
+
+	md := report.Markdown()
+	for _, want := range []string{
+		"# Herald Evaluation",
+		"| Accuracy | 1 / 2 (50.0%) |",
+		"## Confusion Matrix",
+		"marked-document.3.png",
+		"level: expected SECRET, got UNCLASSIFIED",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown() missing %q", want)
+		}
+	}
+}
@@ -16,7 +16,7 @@ import (
 var pngSignature = []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}
 
 func TestImageHandlerMetadata(t *testing.T) {
-	h := format.NewImageHandler()
+	h := format.NewImageHandler(format.Options{})
 	if h.ID() != "image" {
 		t.Errorf("ID = %q, want image", h.ID())
 	}
@@ -38,7 +38,7 @@ func TestImageHandlerMetadata(t *testing.T) {
 }
 
 func TestImageHandlerExtractPNGPassthrough(t *testing.T) {
-	h := format.NewImageHandler()
+	h := format.NewImageHandler(format.Options{})
 	tempDir := t.TempDir()
 
 	src := &fixtureSource{
@@ -80,7 +80,7 @@ func TestImageHandlerExtractPNGPassthrough(t *testing.T) {
 func TestImageHandlerExtractJPEGNormalization(t *testing.T) {
 	requireMagick(t)
 
-	h := format.NewImageHandler()
+	h := format.NewImageHandler(format.Options{})
 	tempDir := t.TempDir()
 
 	src := &fixtureSource{
@@ -116,7 +116,7 @@ func TestImageHandlerExtractJPEGNormalization(t *testing.T) {
 func TestImageHandlerEnhance(t *testing.T) {
 	requireMagick(t)
 
-	h := format.NewImageHandler()
+	h := format.NewImageHandler(format.Options{})
 	tempDir := t.TempDir()
 
 	// Enhance reads from <tempDir>/page-1.png, so seed it via Extract first.
@@ -156,7 +156,7 @@ func TestImageHandlerEnhance(t *testing.T) {
 }
 
 func TestImageHandlerExtractUnsupportedContentType(t *testing.T) {
-	h := format.NewImageHandler()
+	h := format.NewImageHandler(format.Options{})
 	tempDir := t.TempDir()
 
 	src := &fixtureSource{
@@ -0,0 +1,290 @@
+package format_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JaimeStill/herald/internal/format"
+	"github.com/JaimeStill/herald/internal/state"
+)
+
+const (
+	wordNS   = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`
+	slideNS  = `xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"`
+	relNS    = `xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+	sheetNS  = `xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"`
+	appProps = `<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/extended-properties">%s</Properties>`
+)
+
+func TestOfficeContentType(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"docx", docxPackage(t), format.ContentTypeDOCX},
+		{"pptx", pptxPackage(t, "SECRET"), format.ContentTypePPTX},
+		{"xlsx", xlsxPackage(t, "&CSECRET"), format.ContentTypeXLSX},
+		{"plain zip", ooxmlPackage(t, map[string]string{"readme.txt": "hello"}), ""},
+		{"not a zip", []byte("%PDF-1.4"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := format.OfficeContentType(tt.data); got != tt.want {
+				t.Errorf("OfficeContentType = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOfficePageCount(t *testing.T) {
+	for name, tt := range map[string]struct {
+		props string
+		want  int
+	}{
+		"document pages":      {"<Pages>3</Pages><Words>120</Words>", 3},
+		"presentation slides": {"<Slides>2</Slides>", 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data := ooxmlPackage(t, map[string]string{"docProps/app.xml": strings.Replace(appProps, "%s", tt.props, 1)})
+			n, err := format.OfficePageCount(data)
+			if err != nil {
+				t.Fatalf("OfficePageCount: %v", err)
+			}
+			if n != tt.want {
+				t.Errorf("count = %d, want %d", n, tt.want)
+			}
+		})
+	}
+
+	for name, data := range map[string][]byte{
+		"workbook":    xlsxPackage(t, ""),
+		"no app.xml":  ooxmlPackage(t, map[string]string{"word/document.xml": "<w:document/>"}),
+		"not a zip":   []byte("%PDF-1.4"),
+		"bad count":   ooxmlPackage(t, map[string]string{"docProps/app.xml": strings.Replace(appProps, "%s", "<Pages>x</Pages>", 1)}),
+		"zero slides": ooxmlPackage(t, map[string]string{"docProps/app.xml": strings.Replace(appProps, "%s", "<Slides>0</Slides>", 1)}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := format.OfficePageCount(data); !errors.Is(err, format.ErrInvalidOffice) {
+				t.Errorf("error = %v, want ErrInvalidOffice", err)
+			}
+		})
+	}
+}
+
+func TestOfficeHandlerExtract(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+		margins     string
+	}{
+		{
+			name:        "docx headers and footers",
+			contentType: format.ContentTypeDOCX,
+			data:        docxPackage(t),
+			margins:     "Header: SECRET//NOFORN\nFooter: SECRET//NOFORN Page",
+		},
+		{
+			name:        "pptx footer placeholder",
+			contentType: format.ContentTypePPTX,
+			data:        pptxPackage(t, "CONFIDENTIAL"),
+			margins:     "Footer: CONFIDENTIAL",
+		},
+		{
+			name:        "xlsx header codes stripped",
+			contentType: format.ContentTypeXLSX,
+			data:        xlsxPackage(t, `&L&"Arial,Bold"&12TOP SECRET&R&P of &N && more`),
+			margins:     "Header: TOP SECRET\nHeader: of & more",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := writeOffice(t, tt.data, tt.contentType)
+			converter := &copyConverter{pdf: fixturePath(t, "_project/marked-documents/single-unclassified.pdf")}
+
+			h := format.NewOfficeHandler(format.Options{Renderer: format.NativeRenderer()}, converter)
+			tempDir := t.TempDir()
+
+			pages, err := h.Extract(context.Background(), src, tempDir)
+			if err != nil {
+				t.Fatalf("Extract: %v", err)
+			}
+			if len(pages) != 1 {
+				t.Fatalf("pages = %d, want 1", len(pages))
+			}
+
+			if filepath.Ext(converter.src) != filepath.Ext(src.path) {
+				t.Errorf("converter source = %s, want %s extension", converter.src, filepath.Ext(src.path))
+			}
+			if pages[0].Margins != tt.margins {
+				t.Errorf("margins = %q, want %q", pages[0].Margins, tt.margins)
+			}
+			if b := decodePNG(t, pages[0].ImagePath).Bounds(); b.Dx() != 2550 || b.Dy() != 3300 {
+				t.Errorf("page size = %v, want 2550x3300", b)
+			}
+
+			enhanced, err := h.Enhance(context.Background(), tempDir, &pages[0], &state.EnhanceSettings{Brightness: intPtr(10)})
+			if err != nil {
+				t.Fatalf("Enhance: %v", err)
+			}
+			if filepath.Base(enhanced) != "page-1-enhanced.png" {
+				t.Errorf("enhanced path = %s", enhanced)
+			}
+		})
+	}
+}
+
+func TestOfficeHandlerRestore(t *testing.T) {
+	src := writeOffice(t, docxPackage(t), format.ContentTypeDOCX)
+	converter := &copyConverter{pdf: fixturePath(t, "_project/marked-documents/single-unclassified.pdf")}
+
+	h := format.NewOfficeHandler(format.Options{Renderer: format.NativeRenderer()}, converter)
+	tempDir := t.TempDir()
+
+	page := &state.ClassificationPage{PageNumber: 1}
+	if err := h.Restore(context.Background(), src, tempDir, []*state.ClassificationPage{page}); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "source.pdf")); err != nil {
+		t.Errorf("converted pdf not staged: %v", err)
+	}
+	if page.ImagePath == "" || meanGray(decodePNG(t, page.ImagePath)) > 254 {
+		t.Error("restored page not rendered")
+	}
+}
+
+func TestCommandConverter(t *testing.T) {
+	fixture := fixturePath(t, "_project/marked-documents/single-unclassified.pdf")
+	dir := t.TempDir()
+	src := filepath.Join(dir, "memo.docx")
+	if err := os.WriteFile(src, docxPackage(t), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("substitutes placeholders", func(t *testing.T) {
+		c := format.CommandConverter([]string{"cp", fixture, "{outdir}/memo.pdf"}, 0)
+		out, err := c.Convert(context.Background(), src, dir)
+		if err != nil {
+			t.Fatalf("Convert: %v", err)
+		}
+		if out != filepath.Join(dir, "memo.pdf") {
+			t.Errorf("out = %s, want memo.pdf in outDir", out)
+		}
+	})
+
+	for name, args := range map[string][]string{
+		"command fails": {"false"},
+		"writes no pdf": {"true", "{input}"},
+		"no command":    nil,
+	} {
+		t.Run(name, func(t *testing.T) {
+			out := t.TempDir()
+			if _, err := format.CommandConverter(args, 0).Convert(context.Background(), src, out); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+// copyConverter stands in for an Office converter by copying a fixture PDF
+// to where the converter would write it.
+type copyConverter struct {
+	pdf string
+	src string
+}
+
+func (c *copyConverter) Convert(_ context.Context, src, outDir string) (string, error) {
+	c.src = src
+	data, err := os.ReadFile(c.pdf)
+	if err != nil {
+		return "", err
+	}
+	out := filepath.Join(outDir, strings.TrimSuffix(filepath.Base(src), filepath.Ext(src))+".pdf")
+	return out, os.WriteFile(out, data, 0600)
+}
+
+func writeOffice(t *testing.T, data []byte, contentType string) *fixtureSource {
+	t.Helper()
+	ext := map[string]string{
+		format.ContentTypeDOCX: ".docx",
+		format.ContentTypePPTX: ".pptx",
+		format.ContentTypeXLSX: ".xlsx",
+	}[contentType]
+	path := filepath.Join(t.TempDir(), "upload"+ext)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return &fixtureSource{path: path, contentType: contentType}
+}
+
+// docxPackage builds a Word document whose header and two footers carry
+// banner markings, one footer repeating the other.
+func docxPackage(t *testing.T) []byte {
+	header := `<w:hdr ` + wordNS + `><w:p><w:r><w:t>SECRET</w:t></w:r><w:r><w:t>//NOFORN</w:t></w:r></w:p><w:p/></w:hdr>`
+	footer := `<w:ftr ` + wordNS + `><w:p><w:r><w:t>SECRET//NOFORN</w:t></w:r><w:r><w:tab/><w:t>Page</w:t></w:r></w:p></w:ftr>`
+	return ooxmlPackage(t, map[string]string{
+		"word/document.xml": `<w:document ` + wordNS + `><w:body><w:p><w:r><w:t>Body text</w:t></w:r></w:p></w:body></w:document>`,
+		"word/header1.xml":  header,
+		"word/footer1.xml":  footer,
+		"word/footer2.xml":  footer,
+	})
+}
+
+// pptxPackage builds a one-slide presentation with a body shape and a
+// footer placeholder reading footer.
+func pptxPackage(t *testing.T, footer string) []byte {
+	return ooxmlPackage(t, map[string]string{
+		"ppt/presentation.xml": `<p:presentation ` + slideNS + ` ` + relNS + `><p:sldIdLst><p:sldId id="256" r:id="rId2"/></p:sldIdLst></p:presentation>`,
+		"ppt/_rels/presentation.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="slides/slide1.xml"/>` +
+			`</Relationships>`,
+		"ppt/slides/slide1.xml": `<p:sld ` + slideNS + `><p:cSld><p:spTree>` +
+			`<p:sp><p:nvSpPr><p:nvPr><p:ph type="body"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>Agenda</a:t></a:r></a:p></p:txBody></p:sp>` +
+			`<p:sp><p:nvSpPr><p:nvPr><p:ph type="ftr" idx="11"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>` + footer + `</a:t></a:r></a:p></p:txBody></p:sp>` +
+			`</p:spTree></p:cSld></p:sld>`,
+	})
+}
+
+// xlsxPackage builds a one-sheet workbook whose odd-page header is header.
+func xlsxPackage(t *testing.T, header string) []byte {
+	var sb strings.Builder
+	sb.WriteString(`<worksheet ` + sheetNS + `><sheetData/><headerFooter><oddHeader>`)
+	if err := xml.EscapeText(&sb, []byte(header)); err != nil {
+		t.Fatal(err)
+	}
+	sb.WriteString(`</oddHeader></headerFooter></worksheet>`)
+	return ooxmlPackage(t, map[string]string{
+		"xl/workbook.xml":          `<workbook ` + sheetNS + `/>`,
+		"xl/worksheets/sheet1.xml": sb.String(),
+	})
+}
+
+func ooxmlPackage(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -59,7 +59,7 @@ func fixturePath(t *testing.T, relative string) string {
 func TestPDFHandlerExtract(t *testing.T) {
 	requireMagick(t)
 
-	h := format.NewPDFHandler()
+	h := format.NewPDFHandler(format.Options{})
 	if h.ID() != "pdf" {
 		t.Errorf("ID = %q, want pdf", h.ID())
 	}
@@ -111,7 +111,7 @@ func TestPDFHandlerExtract(t *testing.T) {
 func TestPDFHandlerEnhance(t *testing.T) {
 	requireMagick(t)
 
-	h := format.NewPDFHandler()
+	h := format.NewPDFHandler(format.Options{})
 	tempDir := t.TempDir()
 
 	// Enhance reads from <tempDir>/source.pdf, so seed it first via Extract.
@@ -149,3 +149,84 @@ func TestPDFHandlerEnhance(t *testing.T) {
 		t.Error("enhanced page is empty")
 	}
 }
+
+func TestPDFHandlerRestore(t *testing.T) {
+	requireMagick(t)
+
+	h := format.NewPDFHandler(format.Options{})
+	tempDir := t.TempDir()
+	src := &fixtureSource{
+		path:        fixturePath(t, "_project/marked-documents/escalation-unclass-to-secret.pdf"),
+		contentType: "application/pdf",
+	}
+
+	page := &state.ClassificationPage{PageNumber: 3}
+	if err := h.Restore(context.Background(), src, tempDir, []*state.ClassificationPage{page}); err != nil {
+		t.Fatalf("Restore error: %v", err)
+	}
+
+	if want := filepath.Join(tempDir, "page-3.png"); page.ImagePath != want {
+		t.Errorf("ImagePath = %q, want %q", page.ImagePath, want)
+	}
+	if _, err := os.Stat(page.ImagePath); err != nil {
+		t.Errorf("restored page not rendered: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "page-1.png")); !os.IsNotExist(err) {
+		t.Errorf("page-1.png rendered, want only the requested page")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "source.pdf")); err != nil {
+		t.Errorf("source.pdf not written: %v", err)
+	}
+}
+
+func TestPDFHandlerExtractCrop(t *testing.T) {
+	requireMagick(t)
+
+	full := format.NewPDFHandler(format.Options{})
+	cropped := format.NewPDFHandler(format.Options{
+		Crop:        true,
+		CropHeight:  0.1,
+		CropDensity: 300,
+		PageDensity: 150,
+	})
+	src := &fixtureSource{
+		path:        fixturePath(t, "_project/marked-documents/single-unclassified.pdf"),
+		contentType: "application/pdf",
+	}
+
+	fullPages, err := full.Extract(context.Background(), src, t.TempDir())
+	if err != nil {
+		t.Fatalf("Extract error: %v", err)
+	}
+	pages, err := cropped.Extract(context.Background(), src, t.TempDir())
+	if err != nil {
+		t.Fatalf("Extract (crop) error: %v", err)
+	}
+
+	if fullPages[0].BannerPath != "" {
+		t.Errorf("BannerPath = %q outside crop mode, want empty", fullPages[0].BannerPath)
+	}
+	if filepath.Base(pages[0].BannerPath) != "page-1-banners.png" {
+		t.Fatalf("BannerPath = %q, want page-1-banners.png", pages[0].BannerPath)
+	}
+
+	fullInfo, err := os.Stat(fullPages[0].ImagePath)
+	if err != nil {
+		t.Fatalf("stat full page: %v", err)
+	}
+	pageInfo, err := os.Stat(pages[0].ImagePath)
+	if err != nil {
+		t.Fatalf("stat crop-mode page: %v", err)
+	}
+	if pageInfo.Size() >= fullInfo.Size() {
+		t.Errorf("crop-mode page is %d bytes, want smaller than the %d-byte full page", pageInfo.Size(), fullInfo.Size())
+	}
+
+	bannerInfo, err := os.Stat(pages[0].BannerPath)
+	if err != nil {
+		t.Fatalf("stat banners: %v", err)
+	}
+	if bannerInfo.Size() == 0 || bannerInfo.Size() >= fullInfo.Size() {
+		t.Errorf("banners are %d bytes, want non-empty and smaller than the full page", bannerInfo.Size())
+	}
+}
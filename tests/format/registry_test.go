@@ -10,7 +10,7 @@ import (
 )
 
 // stubHandler is a minimal Handler implementation for registry tests.
-// Extract and Enhance are not exercised here — those are covered in
+// Extract, Enhance, and Restore are not exercised here — those are covered in
 // pdf_test.go and image_test.go against real magick.
 type stubHandler struct {
 	id           string
@@ -25,6 +25,9 @@ func (s *stubHandler) Extract(context.Context, format.SourceReader, string) ([]s
 func (s *stubHandler) Enhance(context.Context, string, *state.ClassificationPage, *state.EnhanceSettings) (string, error) {
 	return "", nil
 }
+func (s *stubHandler) Restore(context.Context, format.SourceReader, string, []*state.ClassificationPage) error {
+	return nil
+}
 
 func newStubRegistry() *format.Registry {
 	return format.NewRegistry(
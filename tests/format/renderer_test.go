@@ -0,0 +1,497 @@
+package format_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+
+	"github.com/JaimeStill/herald/internal/format"
+	"github.com/JaimeStill/herald/internal/state"
+)
+
+func TestNewRenderer(t *testing.T) {
+	for _, name := range []string{format.RendererMagick, format.RendererAuto} {
+		r, err := format.NewRenderer(name)
+		if err != nil {
+			t.Fatalf("NewRenderer(%q): %v", name, err)
+		}
+		if r.ID() != name {
+			t.Errorf("NewRenderer(%q).ID() = %q", name, r.ID())
+		}
+	}
+
+	if r, err := format.NewRenderer(""); err != nil || r.ID() != format.RendererMagick {
+		t.Errorf("NewRenderer(\"\") = %v, %v; want magick", r, err)
+	}
+	for _, name := range []string{format.RendererNative, "poppler"} {
+		if _, err := format.NewRenderer(name); !errors.Is(err, format.ErrUnknownRenderer) {
+			t.Errorf("NewRenderer(%q) error = %v, want ErrUnknownRenderer", name, err)
+		}
+	}
+}
+
+func TestNativeRendererPage(t *testing.T) {
+	pdfPath := fixturePath(t, "_project/marked-documents/single-unclassified.pdf")
+	dir := t.TempDir()
+	r := format.NativeRenderer()
+
+	tests := []struct {
+		name   string
+		raster format.Raster
+		width  int
+		height int
+	}{
+		{"page", format.Raster{Density: 150}, 1275, 1650},
+		{"banners", format.Raster{Density: 300, Bands: 0.1}, 2550, 2*330 + 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := filepath.Join(dir, tt.name+".png")
+			if err := r.RenderPage(context.Background(), pdfPath, 1, dst, tt.raster, nil); err != nil {
+				t.Fatalf("RenderPage: %v", err)
+			}
+
+			img := decodePNG(t, dst)
+			if b := img.Bounds(); b.Dx() != tt.width || b.Dy() != tt.height {
+				t.Errorf("size = %dx%d, want %dx%d", b.Dx(), b.Dy(), tt.width, tt.height)
+			}
+			if meanGray(img) > 254 {
+				t.Error("rendered page is blank")
+			}
+		})
+	}
+}
+
+func TestNativeRendererUnsupportedPage(t *testing.T) {
+	tests := []struct {
+		name string
+		pdf  []byte
+	}{
+		{"visible text", textPDF("BT /F1 24 Tf 72 720 Td (SECRET) Tj ET")},
+		{"stamp annotation", stampPDF(t)},
+		{
+			"soft mask",
+			scanPDF(t, "q /GS1 gs "+drawScan+" Q", "/GS1 << /SMask << /S /Luminosity /G 7 0 R >> >>", "",
+				pdfStream("/Type /XObject /Subtype /Form /BBox [0 0 612 792] /Group << /S /Transparency /CS /DeviceGray >>", []byte("0 g 0 0 612 396 re f")),
+			),
+		},
+		{"constant alpha", scanPDF(t, "q /GS1 gs "+drawScan+" Q", "/GS1 << /ca 0.5 >>", "")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			pdfPath := filepath.Join(dir, "page.pdf")
+			if err := os.WriteFile(pdfPath, tt.pdf, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			dst := filepath.Join(dir, "page.png")
+			err := format.NativeRenderer().RenderPage(context.Background(), pdfPath, 1, dst, format.Raster{Density: 72}, nil)
+			if !errors.Is(err, format.ErrUnsupportedPage) {
+				t.Fatalf("RenderPage error = %v, want ErrUnsupportedPage", err)
+			}
+			if _, err := os.Stat(dst); !os.IsNotExist(err) {
+				t.Error("declined page still wrote an image")
+			}
+		})
+	}
+
+	t.Run("opaque graphics state", func(t *testing.T) {
+		dir := t.TempDir()
+		pdfPath := filepath.Join(dir, "page.pdf")
+		if err := os.WriteFile(pdfPath, scanPDF(t, "q /GS1 gs "+drawScan+" Q", "/GS1 << /ca 1 /BM /Normal /SMask /None >>", ""), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := format.NativeRenderer().RenderPage(context.Background(), pdfPath, 1, filepath.Join(dir, "page.png"), format.Raster{Density: 72}, nil); err != nil {
+			t.Fatalf("RenderPage: %v", err)
+		}
+	})
+}
+
+func TestNativeRendererInvisibleText(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "ocr.pdf")
+	content := "0 0 1 rg 0 0 306 396 re f BT 3 Tr /F1 24 Tf 72 720 Td (SECRET) Tj ET"
+	if err := os.WriteFile(pdfPath, textPDF(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(dir, "page.png")
+	if err := format.NativeRenderer().RenderPage(context.Background(), pdfPath, 1, dst, format.Raster{Density: 72}, nil); err != nil {
+		t.Fatalf("RenderPage: %v", err)
+	}
+
+	img := decodePNG(t, dst)
+	if got := color.RGBAModel.Convert(img.At(10, 780)).(color.RGBA); got != (color.RGBA{0, 0, 255, 255}) {
+		t.Errorf("filled corner = %v, want blue", got)
+	}
+	if got := color.RGBAModel.Convert(img.At(600, 10)).(color.RGBA); got != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("unfilled corner = %v, want white", got)
+	}
+}
+
+func TestNativeRendererEnhance(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "source.png")
+	writePNG(t, src, []color.RGBA{{100, 100, 100, 255}, {200, 100, 50, 255}})
+
+	tests := []struct {
+		name     string
+		settings state.EnhanceSettings
+		want     []color.RGBA
+	}{
+		{
+			// slope tan(π/4) = 1, intercept 0.2: v + 51.
+			name:     "brightness",
+			settings: state.EnhanceSettings{Brightness: intPtr(20)},
+			want:     []color.RGBA{{151, 151, 151, 255}, {251, 151, 101, 255}},
+		},
+		{
+			// slope tan(3π/8) ≈ 2.414, intercept ≈ -0.707.
+			name:     "contrast",
+			settings: state.EnhanceSettings{Contrast: intPtr(50)},
+			want:     []color.RGBA{{61, 61, 61, 255}, {255, 61, 0, 255}},
+		},
+		{
+			// Fully desaturated to its HSL lightness, (200+50)/2.
+			name:     "saturation",
+			settings: state.EnhanceSettings{Saturation: intPtr(0)},
+			want:     []color.RGBA{{100, 100, 100, 255}, {125, 125, 125, 255}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := filepath.Join(dir, tt.name+".png")
+			if err := format.NativeRenderer().RenderImage(context.Background(), src, dst, format.Raster{}, &tt.settings); err != nil {
+				t.Fatalf("RenderImage: %v", err)
+			}
+
+			img := decodePNG(t, dst)
+			for x, want := range tt.want {
+				got := color.RGBAModel.Convert(img.At(x, 0)).(color.RGBA)
+				if !near(got, want, 1) {
+					t.Errorf("pixel %d = %v, want %v", x, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFallbackRenderer(t *testing.T) {
+	tests := []struct {
+		name         string
+		primary      error
+		wantFallback bool
+		wantErr      bool
+	}{
+		{"primary succeeds", nil, false, false},
+		{"unsupported page falls back", format.ErrUnsupportedPage, true, false},
+		{"other errors return", errors.New("disk full"), false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &stubRenderer{err: tt.primary}
+			fallback := &stubRenderer{}
+			r := format.FallbackRenderer(primary, fallback)
+
+			err := r.RenderPage(context.Background(), "source.pdf", 1, "page-1.png", format.Raster{}, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RenderPage error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (fallback.calls > 0) != tt.wantFallback {
+				t.Errorf("fallback calls = %d, wantFallback %v", fallback.calls, tt.wantFallback)
+			}
+		})
+	}
+}
+
+// TestRenderersAgree renders every page of the marked-documents fixtures,
+// all scanned, with both backends and checks the native output matches
+// ImageMagick's, with and without enhancement, within the tolerance of their
+// resampling differences. A born-digital text and vector page and a scanned
+// page carrying a stamp annotation must be declined by the native backend
+// and rendered through auto as ImageMagick renders them.
+func TestRenderersAgree(t *testing.T) {
+	requireMagick(t)
+
+	fixtures, err := filepath.Glob(filepath.Join(filepath.Dir(fixturePath(t, "_project/marked-documents/manifest.json")), "*.pdf"))
+	if err != nil || len(fixtures) == 0 {
+		t.Fatalf("no fixtures: %v", err)
+	}
+
+	settings := []*state.EnhanceSettings{
+		nil,
+		{Brightness: intPtr(10), Contrast: intPtr(30), Saturation: intPtr(120)},
+	}
+
+	dir := t.TempDir()
+	magick, native := format.MagickRenderer(), format.NativeRenderer()
+	auto := format.FallbackRenderer(native, magick)
+
+	for _, pdfPath := range fixtures {
+		pages, err := api.PageCountFile(pdfPath)
+		if err != nil {
+			t.Fatalf("page count %s: %v", filepath.Base(pdfPath), err)
+		}
+
+		for page := 1; page <= pages; page++ {
+			for i, s := range settings {
+				t.Run(fmt.Sprintf("%s/page-%d/%d", filepath.Base(pdfPath), page, i), func(t *testing.T) {
+					compareRenderers(t, dir, magick, native, pdfPath, page, s)
+				})
+			}
+		}
+	}
+
+	declined := []struct {
+		name string
+		pdf  []byte
+	}{
+		{"text and vector page", textPDF("BT /F1 36 Tf 72 700 Td (SECRET//NOFORN) Tj ET 4 w 72 680 m 540 680 l S 72 100 468 500 re S")},
+		{"scanned page with a stamp annotation", stampPDF(t)},
+	}
+
+	for _, tt := range declined {
+		t.Run(tt.name, func(t *testing.T) {
+			pdfPath := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-")+".pdf")
+			if err := os.WriteFile(pdfPath, tt.pdf, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			err := native.RenderPage(context.Background(), pdfPath, 1, filepath.Join(dir, "declined.png"), format.Raster{Density: 100}, nil)
+			if !errors.Is(err, format.ErrUnsupportedPage) {
+				t.Fatalf("native error = %v, want ErrUnsupportedPage", err)
+			}
+
+			for i, s := range settings {
+				t.Run(fmt.Sprint(i), func(t *testing.T) {
+					compareRenderers(t, dir, magick, auto, pdfPath, 1, s)
+				})
+			}
+		})
+	}
+}
+
+// compareRenderers renders page of pdfPath with want and got at 100 DPI and
+// fails unless the images have the same size, within a pixel, and a mean
+// gray difference of at most 12.
+func compareRenderers(
+	t *testing.T,
+	dir string,
+	want, got format.Renderer,
+	pdfPath string,
+	page int,
+	settings *state.EnhanceSettings,
+) {
+	t.Helper()
+	raster := format.Raster{Density: 100}
+	name := strings.ReplaceAll(t.Name(), "/", "-")
+	wantPath := filepath.Join(dir, "want-"+name+".png")
+	gotPath := filepath.Join(dir, "got-"+name+".png")
+
+	if err := want.RenderPage(context.Background(), pdfPath, page, wantPath, raster, settings); err != nil {
+		t.Fatalf("%s: %v", want.ID(), err)
+	}
+	if err := got.RenderPage(context.Background(), pdfPath, page, gotPath, raster, settings); err != nil {
+		t.Fatalf("%s: %v", got.ID(), err)
+	}
+
+	a, b := decodePNG(t, wantPath), decodePNG(t, gotPath)
+	if dx, dy := a.Bounds().Dx()-b.Bounds().Dx(), a.Bounds().Dy()-b.Bounds().Dy(); abs(dx) > 1 || abs(dy) > 1 {
+		t.Fatalf("size: %s %v, %s %v", want.ID(), a.Bounds(), got.ID(), b.Bounds())
+	}
+	if d := meanDiff(a, b); d > 12 {
+		t.Errorf("mean gray difference %.2f exceeds 12", d)
+	}
+}
+
+type stubRenderer struct {
+	err   error
+	calls int
+}
+
+func (s *stubRenderer) ID() string { return "stub" }
+
+func (s *stubRenderer) RenderPage(context.Context, string, int, string, format.Raster, *state.EnhanceSettings) error {
+	s.calls++
+	return s.err
+}
+
+func (s *stubRenderer) RenderImage(context.Context, string, string, format.Raster, *state.EnhanceSettings) error {
+	s.calls++
+	return s.err
+}
+
+// textPDF builds a one-page, 612x792 PDF with the given content stream and
+// a Helvetica font resource named F1.
+func textPDF(content string) []byte {
+	return buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		pdfStream("", []byte(content)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	)
+}
+
+// scanPDF builds a one-page, 612x792 PDF with the given content stream,
+// whose resources name a gray JPEG scan Im1, a Helvetica font F1, and the
+// graphics states in extGState. page is appended to the page dictionary;
+// objects follow the six scanPDF writes, numbered from 7.
+func scanPDF(t *testing.T, content, extGState, page string, objects ...string) []byte {
+	t.Helper()
+
+	scan := image.NewGray(image.Rect(0, 0, 153, 198))
+	for y := range 198 {
+		for x := range 153 {
+			scan.SetGray(x, y, color.Gray{Y: uint8(200 + (x*y)%40)})
+		}
+	}
+	var jpg bytes.Buffer
+	if err := jpeg.Encode(&jpg, scan, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	return buildPDF(append([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R " +
+			"/Resources << /XObject << /Im1 5 0 R >> /Font << /F1 6 0 R >> /ExtGState << " + extGState + " >> >> " +
+			page + " >>",
+		pdfStream("", []byte(content)),
+		pdfStream("/Type /XObject /Subtype /Image /Width 153 /Height 198 /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /DCTDecode", jpg.Bytes()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}, objects...)...)
+}
+
+// drawScan draws a scanPDF page's scan across the whole page.
+const drawScan = "612 0 0 792 0 0 cm /Im1 Do"
+
+// stampPDF builds a scanPDF page carrying a stamp annotation whose
+// appearance draws a red SECRET banner across the top of the page.
+func stampPDF(t *testing.T) []byte {
+	t.Helper()
+	return scanPDF(t, "q "+drawScan+" Q", "", "/Annots [7 0 R]",
+		"<< /Type /Annot /Subtype /Stamp /Rect [156 712 456 772] /F 4 /AP << /N 8 0 R >> >>",
+		pdfStream(
+			"/Type /XObject /Subtype /Form /BBox [0 0 300 60] /Resources << /Font << /F1 6 0 R >> >>",
+			[]byte("1 0 0 rg 0 0 300 60 re f BT 1 1 1 rg /F1 36 Tf 60 16 Td (SECRET) Tj ET"),
+		),
+	)
+}
+
+// pdfStream formats a stream object with the entries in dict and data.
+func pdfStream(dict string, data []byte) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+// buildPDF writes objects, numbered from 1, as a PDF whose catalog is
+// object 1.
+func buildPDF(objects ...string) []byte {
+	var b strings.Builder
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return []byte(b.String())
+}
+
+func writePNG(t *testing.T, path string, pixels []color.RGBA) {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, len(pixels), 1))
+	for x, p := range pixels {
+		img.SetRGBA(x, 0, p)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func decodePNG(t *testing.T, path string) image.Image {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("decode %s: %v", path, err)
+	}
+	return img
+}
+
+func gray(c color.Color) float64 {
+	return float64(color.GrayModel.Convert(c).(color.Gray).Y)
+}
+
+func meanGray(img image.Image) float64 {
+	b := img.Bounds()
+	var sum float64
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			sum += gray(img.At(x, y))
+		}
+	}
+	return sum / float64(b.Dx()*b.Dy())
+}
+
+// meanDiff averages the absolute gray difference of the overlapping pixels
+// of a and b.
+func meanDiff(a, b image.Image) float64 {
+	w := min(a.Bounds().Dx(), b.Bounds().Dx())
+	h := min(a.Bounds().Dy(), b.Bounds().Dy())
+	var sum float64
+	for y := range h {
+		for x := range w {
+			sum += math.Abs(gray(a.At(a.Bounds().Min.X+x, a.Bounds().Min.Y+y)) - gray(b.At(b.Bounds().Min.X+x, b.Bounds().Min.Y+y)))
+		}
+	}
+	return sum / float64(w*h)
+}
+
+func near(a, b color.RGBA, tolerance int) bool {
+	return abs(int(a.R)-int(b.R)) <= tolerance &&
+		abs(int(a.G)-int(b.G)) <= tolerance &&
+		abs(int(a.B)-int(b.B)) <= tolerance &&
+		a.A == b.A
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func intPtr(v int) *int { return &v }
@@ -0,0 +1,55 @@
+package format_test
+
+import (
+	"testing"
+
+	"github.com/JaimeStill/herald/internal/format"
+)
+
+func TestContentText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name: "lines and kerned arrays",
+			content: `BT /F1 12 Tf 72 720 Td (SECRET//NOFORN) Tj
+				0 -14 Td [(\(S) -300 (//NF\) Troop) 20 (s moved.)] TJ ET`,
+			want: "SECRET//NOFORN\n(S //NF) Troops moved.",
+		},
+		{
+			name:    "hex string",
+			content: `BT 72 720 Td <554E434C415353494649454420> Tj ET`,
+			want:    "UNCLASSIFIED",
+		},
+		{
+			name:    "two-byte glyph codes are skipped",
+			content: `BT 72 720 Td <00360045> Tj T* (TOP SECRET) Tj ET`,
+			want:    "TOP SECRET",
+		},
+		{
+			name:    "escapes and next-line operator",
+			content: `BT (CONFIDENTIAL) Tj (line\051 two\\) ' ET`,
+			want:    "CONFIDENTIAL\nline) two\\",
+		},
+		{
+			name:    "inline images are skipped",
+			content: "q BI /W 1 /H 1 ID \x00(Tj)\xff EI Q BT (SECRET) Tj ET",
+			want:    "SECRET",
+		},
+		{
+			name:    "no text layer",
+			content: `q 612 0 0 792 0 0 cm /Im0 Do Q`,
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := format.ContentText([]byte(tt.content)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
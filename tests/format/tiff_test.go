@@ -0,0 +1,203 @@
+package format_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/JaimeStill/herald/internal/format"
+	"github.com/JaimeStill/herald/internal/state"
+)
+
+func TestTIFFFrameCount(t *testing.T) {
+	n, err := format.TIFFFrameCount(grayTIFF(40, 50, 10, 120, 200))
+	if err != nil {
+		t.Fatalf("TIFFFrameCount: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("frames = %d, want 3", n)
+	}
+
+	looped := grayTIFF(1, 1, 0)
+	binary.LittleEndian.PutUint32(looped[len(looped)-4:], binary.LittleEndian.Uint32(looped[4:]))
+
+	for name, data := range map[string][]byte{
+		"not a tiff":   []byte("%PDF-1.4"),
+		"truncated":    grayTIFF(4, 4, 0)[:12],
+		"looped chain": looped,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := format.TIFFFrameCount(data); !errors.Is(err, format.ErrInvalidTIFF) {
+				t.Errorf("error = %v, want ErrInvalidTIFF", err)
+			}
+		})
+	}
+}
+
+func TestTIFFHandlerExtract(t *testing.T) {
+	dir := t.TempDir()
+	src := writeTIFF(t, dir, grayTIFF(40, 50, 10, 120, 200))
+
+	h := format.NewTIFFHandler(format.Options{Renderer: format.NativeRenderer()})
+	tempDir := t.TempDir()
+
+	pages, err := h.Extract(context.Background(), src, tempDir)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if len(pages) != 3 {
+		t.Fatalf("pages = %d, want 3", len(pages))
+	}
+
+	for i, want := range []uint8{10, 120, 200} {
+		page := pages[i]
+		if page.PageNumber != i+1 {
+			t.Errorf("page %d number = %d", i, page.PageNumber)
+		}
+		if page.BannerPath != "" {
+			t.Errorf("page %d banner path set outside crop mode", page.PageNumber)
+		}
+
+		img := decodePNG(t, page.ImagePath)
+		if b := img.Bounds(); b.Dx() != 40 || b.Dy() != 50 {
+			t.Errorf("page %d size = %v, want 40x50", page.PageNumber, b)
+		}
+		if got := gray(img.At(20, 25)); got != float64(want) {
+			t.Errorf("page %d gray = %v, want %d", page.PageNumber, got, want)
+		}
+	}
+}
+
+func TestTIFFHandlerEnhance(t *testing.T) {
+	dir := t.TempDir()
+	src := writeTIFF(t, dir, grayTIFF(40, 50, 10, 120, 200))
+
+	h := format.NewTIFFHandler(format.Options{Renderer: format.NativeRenderer()})
+	tempDir := t.TempDir()
+
+	pages, err := h.Extract(context.Background(), src, tempDir)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+
+	settings := &state.EnhanceSettings{Brightness: intPtr(20)}
+	path, err := h.Enhance(context.Background(), tempDir, &pages[1], settings)
+	if err != nil {
+		t.Fatalf("Enhance: %v", err)
+	}
+	if filepath.Base(path) != "page-2-enhanced.png" {
+		t.Errorf("path = %s, want page-2-enhanced.png", path)
+	}
+
+	// Frame 2 is gray 120; brightness 20 adds 51.
+	if got := gray(decodePNG(t, path).At(0, 0)); got != 171 {
+		t.Errorf("enhanced gray = %v, want 171", got)
+	}
+}
+
+func TestTIFFHandlerExtractCrop(t *testing.T) {
+	dir := t.TempDir()
+	src := writeTIFF(t, dir, grayTIFF(600, 800, 90, 160))
+
+	h := format.NewTIFFHandler(format.Options{
+		Crop:        true,
+		CropHeight:  0.1,
+		CropDensity: 300,
+		PageDensity: 150,
+		Renderer:    format.NativeRenderer(),
+	})
+
+	pages, err := h.Extract(context.Background(), src, t.TempDir())
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+
+	for _, page := range pages {
+		if b := decodePNG(t, page.ImagePath).Bounds(); b.Dx() != 300 || b.Dy() != 400 {
+			t.Errorf("page %d size = %v, want 300x400", page.PageNumber, b)
+		}
+		if b := decodePNG(t, page.BannerPath).Bounds(); b.Dx() != 600 || b.Dy() != 2*80+12 {
+			t.Errorf("page %d banners size = %v, want 600x172", page.PageNumber, b)
+		}
+	}
+}
+
+func TestTIFFHandlerRestore(t *testing.T) {
+	dir := t.TempDir()
+	src := writeTIFF(t, dir, grayTIFF(40, 50, 10, 120, 200))
+
+	h := format.NewTIFFHandler(format.Options{Renderer: format.NativeRenderer()})
+	tempDir := t.TempDir()
+
+	page := &state.ClassificationPage{PageNumber: 3}
+	if err := h.Restore(context.Background(), src, tempDir, []*state.ClassificationPage{page}); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	if got := gray(decodePNG(t, page.ImagePath).At(0, 0)); got != 200 {
+		t.Errorf("restored gray = %v, want 200", got)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "page-1.png")); !os.IsNotExist(err) {
+		t.Error("Restore rendered a page it was not given")
+	}
+}
+
+func TestTIFFHandlerMagick(t *testing.T) {
+	requireMagick(t)
+
+	dir := t.TempDir()
+	src := writeTIFF(t, dir, grayTIFF(40, 50, 10, 120))
+
+	pages, err := format.NewTIFFHandler(format.Options{}).Extract(context.Background(), src, t.TempDir())
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("pages = %d, want 2", len(pages))
+	}
+	if got := gray(decodePNG(t, pages[1].ImagePath).At(0, 0)); got != 120 {
+		t.Errorf("page 2 gray = %v, want 120", got)
+	}
+}
+
+func writeTIFF(t *testing.T, dir string, data []byte) *fixtureSource {
+	t.Helper()
+	path := filepath.Join(dir, "scan.tiff")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return &fixtureSource{path: path, contentType: "image/tiff"}
+}
+
+// grayTIFF builds an uncompressed little-endian TIFF with one w x h 8-bit
+// gray frame per level, each filled with that level.
+func grayTIFF(w, h int, levels ...uint8) []byte {
+	le := binary.LittleEndian
+	buf := []byte("II*\x00\x00\x00\x00\x00")
+	prev := 4
+	for _, level := range levels {
+		strip := len(buf)
+		for range w * h {
+			buf = append(buf, level)
+		}
+
+		le.PutUint32(buf[prev:], uint32(len(buf)))
+		entries := [][3]uint32{
+			{256, 3, uint32(w)}, {257, 3, uint32(h)}, {258, 3, 8}, {259, 3, 1}, {262, 3, 1},
+			{273, 4, uint32(strip)}, {277, 3, 1}, {278, 3, uint32(h)}, {279, 4, uint32(w * h)},
+		}
+		buf = le.AppendUint16(buf, uint16(len(entries)))
+		for _, e := range entries {
+			buf = le.AppendUint16(buf, uint16(e[0]))
+			buf = le.AppendUint16(buf, uint16(e[1]))
+			buf = le.AppendUint32(buf, 1)
+			buf = le.AppendUint32(buf, e[2])
+		}
+		prev = len(buf)
+		buf = le.AppendUint32(buf, 0)
+	}
+	return buf
+}
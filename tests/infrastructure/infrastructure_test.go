@@ -84,12 +84,35 @@ func TestNewAgentFactory(t *testing.T) {
 		t.Fatalf("NewAgent() error = %v", err)
 	}
 
-	if a.Model().Name != "llama3.1:8b" {
-		t.Errorf("model name = %q, want %q", a.Model().Name, "llama3.1:8b")
+	if a.Model() != "llama3.1:8b" {
+		t.Errorf("model name = %q, want %q", a.Model(), "llama3.1:8b")
 	}
 
-	if a.Provider().Name() != "ollama" {
-		t.Errorf("provider name = %q, want %q", a.Provider().Name(), "ollama")
+	if a.Provider() != "ollama" {
+		t.Errorf("provider name = %q, want %q", a.Provider(), "ollama")
+	}
+}
+
+func TestNewAgentFactoryReplay(t *testing.T) {
+	cfg := validConfig()
+	cfg.Agent.Provider.Name = "replay"
+	cfg.Replay.Fixtures = t.TempDir()
+
+	infra, err := infrastructure.New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	a, err := infra.NewAgent(t.Context())
+	if err != nil {
+		t.Fatalf("NewAgent() error = %v", err)
+	}
+
+	if a.Provider() != "replay" {
+		t.Errorf("provider name = %q, want replay", a.Provider())
+	}
+	if a.Model() != "llama3.1:8b" {
+		t.Errorf("model name = %q, want %q", a.Model(), "llama3.1:8b")
 	}
 }
 
@@ -115,3 +138,44 @@ func TestNewInvalidStorageConfig(t *testing.T) {
 		t.Fatal("expected error for invalid storage connection string")
 	}
 }
+
+func TestNewAgentRegistry(t *testing.T) {
+	cfg := validConfig()
+	cfg.Agents = map[string]tauconfig.AgentConfig{
+		"local": {
+			Name:   "local",
+			Format: "openai",
+			Provider: &tauconfig.ProviderConfig{
+				Name:    "ollama",
+				BaseURL: "http://localhost:11434",
+				Options: make(map[string]any),
+			},
+			Model: &tauconfig.ModelConfig{Name: "llama3.2:3b"},
+		},
+	}
+
+	infra, err := infrastructure.New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if got := infra.Agents.Default().Name; got != "test-agent" {
+		t.Errorf("default agent = %q, want test-agent", got)
+	}
+
+	e, err := infra.Agents.Lookup("local")
+	if err != nil {
+		t.Fatalf("Lookup(local) error = %v", err)
+	}
+	if e.Model != "llama3.2:3b" || e.Provider != "ollama" {
+		t.Errorf("local = %s/%s, want ollama/llama3.2:3b", e.Provider, e.Model)
+	}
+
+	a, err := e.New(t.Context())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if a.Model() != "llama3.2:3b" {
+		t.Errorf("agent model = %q, want llama3.2:3b", a.Model())
+	}
+}
@@ -91,3 +91,30 @@ func TestContextCancelledOnShutdown(t *testing.T) {
 		t.Error("context should be cancelled after shutdown")
 	}
 }
+
+func TestShutdownHooksWaitForDrain(t *testing.T) {
+	lc := lifecycle.New()
+
+	var drained atomic.Bool
+	lc.OnDrain(func() {
+		<-lc.Context().Done()
+		time.Sleep(50 * time.Millisecond)
+		drained.Store(true)
+	})
+
+	var closedAfterDrain atomic.Bool
+	lc.OnShutdown(func() {
+		<-lc.Drained()
+		closedAfterDrain.Store(drained.Load())
+	})
+
+	lc.WaitForStartup()
+
+	if err := lc.Shutdown(5 * time.Second); err != nil {
+		t.Fatalf("shutdown failed: %v", err)
+	}
+
+	if !closedAfterDrain.Load() {
+		t.Error("shutdown hook ran before the drain hook returned")
+	}
+}
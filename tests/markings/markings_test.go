@@ -0,0 +1,380 @@
+package markings_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/JaimeStill/herald/internal/markings"
+)
+
+func defaultParser() *markings.Parser {
+	return markings.NewParser(markings.DefaultTaxonomy())
+}
+
+func TestParseEquivalentSpellings(t *testing.T) {
+	p := defaultParser()
+
+	for _, raw := range []string{
+		"SECRET//NOFORN",
+		"Secret // NOFORN",
+		"S//NF",
+		"(S//NF)",
+		"  secret//noforn ",
+	} {
+		t.Run(raw, func(t *testing.T) {
+			m, err := p.Parse(raw)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", raw, err)
+			}
+			if m.Canonical != "SECRET//NOFORN" {
+				t.Errorf("Parse(%q).Canonical = %q, want SECRET//NOFORN", raw, m.Canonical)
+			}
+			if m.Level != "SECRET" {
+				t.Errorf("Parse(%q).Level = %q, want SECRET", raw, m.Level)
+			}
+			if !slices.Equal(m.Dissemination, []string{"NOFORN"}) {
+				t.Errorf("Parse(%q).Dissemination = %v, want [NOFORN]", raw, m.Dissemination)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       string
+		canonical string
+		check     func(t *testing.T, m markings.Marking)
+	}{
+		{
+			name:      "level only",
+			raw:       "UNCLASSIFIED",
+			canonical: "UNCLASSIFIED",
+		},
+		{
+			name:      "level alias",
+			raw:       "TS",
+			canonical: "TOP SECRET",
+		},
+		{
+			name:      "unclassified fouo",
+			raw:       "U//FOUO",
+			canonical: "UNCLASSIFIED//FOUO",
+		},
+		{
+			name:      "sci with compartment",
+			raw:       "TOP SECRET//SI-G ABCD/TK//NOFORN",
+			canonical: "TOP SECRET//SI-G ABCD/TK//NOFORN",
+			check: func(t *testing.T, m markings.Marking) {
+				if !slices.Equal(m.SCI, []string{"SI-G ABCD", "TK"}) {
+					t.Errorf("SCI = %v, want [SI-G ABCD TK]", m.SCI)
+				}
+			},
+		},
+		{
+			name:      "sci reordered",
+			raw:       "TS//TK/HCS//NF",
+			canonical: "TOP SECRET//HCS/TK//NOFORN",
+		},
+		{
+			name:      "dissemination reordered",
+			raw:       "SECRET//NOFORN/ORCON",
+			canonical: "SECRET//ORCON/NOFORN",
+		},
+		{
+			name:      "rel to ordering",
+			raw:       "SECRET//REL TO GBR, USA, AUS",
+			canonical: "SECRET//REL TO USA, AUS, GBR",
+			check: func(t *testing.T, m markings.Marking) {
+				if !slices.Equal(m.ReleasableTo, []string{"USA", "AUS", "GBR"}) {
+					t.Errorf("ReleasableTo = %v, want [USA AUS GBR]", m.ReleasableTo)
+				}
+				if !slices.Equal(m.Dissemination, []string{"REL TO USA, AUS, GBR"}) {
+					t.Errorf("Dissemination = %v", m.Dissemination)
+				}
+			},
+		},
+		{
+			name:      "rel shorthand",
+			raw:       "s//rel usa,can",
+			canonical: "SECRET//REL TO USA, CAN",
+		},
+		{
+			name:      "special access program",
+			raw:       "TOP SECRET//SPECIAL ACCESS REQUIRED-BUTTERED POPCORN//NOFORN",
+			canonical: "TOP SECRET//SAR-BUTTERED POPCORN//NOFORN",
+			check: func(t *testing.T, m markings.Marking) {
+				if !slices.Equal(m.SAP, []string{"SAR-BUTTERED POPCORN"}) {
+					t.Errorf("SAP = %v, want [SAR-BUTTERED POPCORN]", m.SAP)
+				}
+			},
+		},
+		{
+			name:      "declassification date",
+			raw:       "SECRET//NOFORN//20501231",
+			canonical: "SECRET//NOFORN//20501231",
+			check: func(t *testing.T, m markings.Marking) {
+				if m.Declassification != "20501231" {
+					t.Errorf("Declassification = %q, want 20501231", m.Declassification)
+				}
+			},
+		},
+		{
+			name:      "declassification exemption",
+			raw:       "TOP SECRET//NOFORN//25X1",
+			canonical: "TOP SECRET//NOFORN//25X1",
+		},
+	}
+
+	p := defaultParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := p.Parse(tt.raw)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.raw, err)
+			}
+			if m.Canonical != tt.canonical {
+				t.Errorf("Parse(%q).Canonical = %q, want %q", tt.raw, m.Canonical, tt.canonical)
+			}
+			if m.String() != m.Canonical {
+				t.Errorf("String() = %q, want %q", m.String(), m.Canonical)
+			}
+			if tt.check != nil {
+				tt.check(t, m)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	p := defaultParser()
+
+	for _, raw := range []string{
+		"",
+		"PROBABLY SECRET",
+		"SECRET//BOGUS",
+		"SECRET///NOFORN",
+		"UNCLASSIFIED//SI",
+		"SECRET//NOFORN/REL TO USA, GBR",
+		"SECRET//REL TO UNITED KINGDOM",
+		"SECRET//20501399",
+		"SECRET//SAR-",
+	} {
+		t.Run(raw, func(t *testing.T) {
+			_, err := p.Parse(raw)
+			if !errors.Is(err, markings.ErrInvalidMarking) {
+				t.Errorf("Parse(%q) error = %v, want ErrInvalidMarking", raw, err)
+			}
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	p := defaultParser()
+
+	tests := []struct {
+		level string
+		want  int
+	}{
+		{"UNCLASSIFIED", 0},
+		{"CONFIDENTIAL", 1},
+		{"SECRET", 2},
+		{"top secret", 3},
+		{"TS", 3},
+		{"COSMIC", -1},
+	}
+
+	for _, tt := range tests {
+		if got := p.Rank(tt.level); got != tt.want {
+			t.Errorf("Rank(%q) = %d, want %d", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestLevels(t *testing.T) {
+	p := defaultParser()
+
+	levels := p.Levels()
+	want := []string{"UNCLASSIFIED", "CONFIDENTIAL", "SECRET", "TOP SECRET"}
+	if !slices.Equal(levels, want) {
+		t.Fatalf("Levels() = %v, want %v", levels, want)
+	}
+
+	levels[0] = "MUTATED"
+	if p.Levels()[0] != "UNCLASSIFIED" {
+		t.Error("Levels() exposed the parser's internal slice")
+	}
+}
+
+func TestCustomTaxonomy(t *testing.T) {
+	tax := markings.Taxonomy{
+		Levels: []markings.Term{
+			{Name: "OFFICIAL"},
+			{Name: "OFFICIAL-SENSITIVE", Aliases: []string{"OS"}},
+		},
+		Dissemination: []markings.Term{
+			{Name: "UK EYES ONLY", Aliases: []string{"UKEO"}},
+		},
+	}
+	if err := tax.Finalize(); err != nil {
+		t.Fatalf("Finalize() error = %v", err)
+	}
+	if len(tax.SCI) == 0 {
+		t.Error("Finalize() left SCI empty, want defaults")
+	}
+
+	p := markings.NewParser(tax)
+
+	m, err := p.Parse("os//ukeo")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if m.Canonical != "OFFICIAL-SENSITIVE//UK EYES ONLY" {
+		t.Errorf("Canonical = %q, want OFFICIAL-SENSITIVE//UK EYES ONLY", m.Canonical)
+	}
+
+	if _, err := p.Parse("SECRET"); !errors.Is(err, markings.ErrInvalidMarking) {
+		t.Errorf("Parse(SECRET) error = %v, want ErrInvalidMarking", err)
+	}
+	if _, err := p.Parse("OFFICIAL//REL TO USA"); !errors.Is(err, markings.ErrInvalidMarking) {
+		t.Errorf("Parse(REL TO) without REL TO term error = %v, want ErrInvalidMarking", err)
+	}
+}
+
+func TestTaxonomyMerge(t *testing.T) {
+	tax := markings.DefaultTaxonomy()
+	tax.Merge(&markings.Taxonomy{
+		SCI: []markings.Term{{Name: "ZZ"}},
+	})
+
+	if len(tax.SCI) != 1 || tax.SCI[0].Name != "ZZ" {
+		t.Errorf("SCI = %v, want [ZZ]", tax.SCI)
+	}
+	if len(tax.Levels) != len(markings.DefaultTaxonomy().Levels) {
+		t.Error("Merge() replaced levels from an empty overlay")
+	}
+}
+
+func TestTaxonomyValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		tax  markings.Taxonomy
+	}{
+		{
+			name: "blank name",
+			tax:  markings.Taxonomy{Levels: []markings.Term{{Name: " "}}},
+		},
+		{
+			name: "alias maps to two terms",
+			tax: markings.Taxonomy{Dissemination: []markings.Term{
+				{Name: "NOFORN", Aliases: []string{"NF"}},
+				{Name: "NO FOREIGN", Aliases: []string{"nf"}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.tax.Finalize(); err == nil {
+				t.Error("Finalize() expected error")
+			}
+		})
+	}
+}
+
+func TestRollUp(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  []string
+		want string
+	}{
+		{
+			name: "highest level wins",
+			raw:  []string{"UNCLASSIFIED", "CONFIDENTIAL", "S", "(U)"},
+			want: "SECRET",
+		},
+		{
+			name: "controls are unioned",
+			raw:  []string{"SECRET//ORCON", "TOP SECRET//SI//NOFORN", "TS//TK"},
+			want: "TOP SECRET//SI/TK//ORCON/NOFORN",
+		},
+		{
+			name: "noforn overrides rel to",
+			raw:  []string{"SECRET//REL TO USA, GBR", "SECRET//NF"},
+			want: "SECRET//NOFORN",
+		},
+		{
+			name: "rel to keeps shared countries",
+			raw:  []string{"SECRET//REL TO USA, AUS, GBR", "S//REL TO USA, GBR, CAN"},
+			want: "SECRET//REL TO USA, GBR",
+		},
+		{
+			name: "rel to without shared partners becomes noforn",
+			raw:  []string{"SECRET//REL TO USA, AUS", "SECRET//REL TO USA, CAN"},
+			want: "SECRET//NOFORN",
+		},
+		{
+			name: "latest declassification date",
+			raw:  []string{"SECRET//20400101", "SECRET//20501231", "CONFIDENTIAL//20451231"},
+			want: "SECRET//20501231",
+		},
+		{
+			name: "exemption outranks date",
+			raw:  []string{"SECRET//20501231", "TOP SECRET//25X1"},
+			want: "TOP SECRET//25X1",
+		},
+		{
+			name: "unparseable markings are skipped",
+			raw:  []string{"CLASSIFIED BY: J. SMITH", "SECRET//NOFORN"},
+			want: "SECRET//NOFORN",
+		},
+	}
+
+	p := defaultParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, ok := p.RollUp(tt.raw)
+			if !ok {
+				t.Fatalf("RollUp(%v) reported no parseable markings", tt.raw)
+			}
+			if m.Canonical != tt.want {
+				t.Errorf("RollUp(%v) = %q, want %q", tt.raw, m.Canonical, tt.want)
+			}
+		})
+	}
+
+	t.Run("nothing parses", func(t *testing.T) {
+		if _, ok := p.RollUp([]string{"DRAFT", ""}); ok {
+			t.Error("RollUp() ok = true, want false")
+		}
+		if _, ok := p.RollUp(nil); ok {
+			t.Error("RollUp(nil) ok = true, want false")
+		}
+	})
+}
+
+func TestDifferences(t *testing.T) {
+	p := defaultParser()
+	parse := func(raw string) markings.Marking {
+		t.Helper()
+		m, err := p.Parse(raw)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", raw, err)
+		}
+		return m
+	}
+
+	if d := markings.Differences(parse("SECRET//NOFORN"), parse("S//NF")); len(d) != 0 {
+		t.Errorf("Differences() = %v, want none", d)
+	}
+	if d := markings.Differences(parse("SECRET//NOFORN//20501231"), parse("SECRET//NOFORN")); len(d) != 0 {
+		t.Errorf("Differences() with omitted declassification = %v, want none", d)
+	}
+
+	d := markings.Differences(parse("TOP SECRET//SI//NOFORN"), parse("SECRET//NOFORN"))
+	want := []string{"level: expected TOP SECRET, got SECRET", "sci: expected SI, got none"}
+	if !slices.Equal(d, want) {
+		t.Errorf("Differences() = %v, want %v", d, want)
+	}
+}
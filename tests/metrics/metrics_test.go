@@ -0,0 +1,200 @@
+package metrics_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/JaimeStill/herald/internal/agents"
+	"github.com/JaimeStill/herald/internal/metrics"
+	"github.com/JaimeStill/herald/pkg/storage"
+)
+
+// scrape returns the current exposition as a map from series to value.
+func scrape(t *testing.T) map[string]float64 {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("scrape status = %d, want 200", rec.Code)
+	}
+
+	series := make(map[string]float64)
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndex(line, " ")
+		v, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("parse %q: %v", line, err)
+		}
+		series[line[:i]] = v
+	}
+	return series
+}
+
+func TestHTTPRecordsRoutePattern(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /things/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	handler := metrics.HTTP("/api")(mux)
+
+	const matched = `herald_http_request_duration_seconds_count{method="GET",route="/api/things/{id}",status="201"}`
+	const unmatched = `herald_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"}`
+	before := scrape(t)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/things/1", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/things/2", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+
+	after := scrape(t)
+	if got := after[matched] - before[matched]; got != 2 {
+		t.Errorf("matched requests = %v, want 2", got)
+	}
+	if got := after[unmatched] - before[unmatched]; got != 1 {
+		t.Errorf("unmatched requests = %v, want 1", got)
+	}
+}
+
+func TestHTTPPreservesFlusher(t *testing.T) {
+	var flushable bool
+	handler := metrics.HTTP("/api")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, flushable = w.(http.Flusher)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if !flushable {
+		t.Error("wrapped ResponseWriter does not implement http.Flusher")
+	}
+}
+
+func TestObserveNode(t *testing.T) {
+	const count = `herald_workflow_node_duration_seconds_count{node="metrics-test"}`
+	const errs = `herald_workflow_node_errors_total{node="metrics-test"}`
+	before := scrape(t)
+
+	metrics.ObserveNode("metrics-test", time.Second, false)
+	metrics.ObserveNode("metrics-test", 2*time.Second, true)
+
+	after := scrape(t)
+	if got := after[count] - before[count]; got != 2 {
+		t.Errorf("node observations = %v, want 2", got)
+	}
+	if got := after[errs] - before[errs]; got != 1 {
+		t.Errorf("node errors = %v, want 1", got)
+	}
+}
+
+type stubAgent struct {
+	err error
+}
+
+func (s *stubAgent) Chat(context.Context, string) (agents.Response, error) {
+	return agents.Response{}, s.err
+}
+func (s *stubAgent) Vision(context.Context, string, []byte) (agents.Response, error) {
+	return agents.Response{Text: "ok"}, s.err
+}
+func (s *stubAgent) Model() string    { return "metrics-model" }
+func (s *stubAgent) Provider() string { return "stub" }
+
+func TestAgentsRecordVisionOutcome(t *testing.T) {
+	const success = `herald_agent_vision_call_duration_seconds_count{model="metrics-model",outcome="success"}`
+	const failure = `herald_agent_vision_call_duration_seconds_count{model="metrics-model",outcome="error"}`
+	before := scrape(t)
+
+	for _, err := range []error{nil, nil, errors.New("provider unavailable")} {
+		newAgent := metrics.Agents(func(context.Context) (agents.Agent, error) {
+			return &stubAgent{err: err}, nil
+		})
+		a, _ := newAgent(context.Background())
+		a.Vision(context.Background(), "prompt", nil)
+		a.Chat(context.Background(), "prompt")
+	}
+
+	after := scrape(t)
+	if got := after[success] - before[success]; got != 2 {
+		t.Errorf("successful vision calls = %v, want 2", got)
+	}
+	if got := after[failure] - before[failure]; got != 1 {
+		t.Errorf("failed vision calls = %v, want 1", got)
+	}
+}
+
+type memoryStorage struct {
+	storage.System
+	data []byte
+}
+
+func (s *memoryStorage) Upload(_ context.Context, _ string, r io.Reader, _ string) error {
+	data, err := io.ReadAll(r)
+	s.data = data
+	return err
+}
+
+func (s *memoryStorage) Download(context.Context, string) (*storage.BlobResult, error) {
+	return &storage.BlobResult{Body: io.NopCloser(bytes.NewReader(s.data))}, nil
+}
+
+func TestStorageCountsBytes(t *testing.T) {
+	const upload = `herald_storage_bytes_total{operation="upload"}`
+	const download = `herald_storage_bytes_total{operation="download"}`
+	before := scrape(t)
+
+	store := metrics.Storage(&memoryStorage{})
+	if err := store.Upload(context.Background(), "k", strings.NewReader("0123456789"), "text/plain"); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	result, err := store.Download(context.Background(), "k")
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	io.CopyN(io.Discard, result.Body, 4)
+	result.Body.Close()
+
+	after := scrape(t)
+	if got := after[upload] - before[upload]; got != 10 {
+		t.Errorf("uploaded bytes = %v, want 10", got)
+	}
+	if got := after[download] - before[download]; got != 4 {
+		t.Errorf("downloaded bytes = %v, want 4", got)
+	}
+}
+
+func TestRegisterDatabase(t *testing.T) {
+	for range 2 {
+		db, err := sql.Open("pgx", "postgres://herald@localhost:5432/herald")
+		if err != nil {
+			t.Fatalf("sql.Open() error = %v", err)
+		}
+		defer db.Close()
+		db.SetMaxOpenConns(7)
+
+		if err := metrics.RegisterDatabase(db); err != nil {
+			t.Fatalf("RegisterDatabase() error = %v", err)
+		}
+	}
+
+	series := scrape(t)
+	if got := series[`go_sql_max_open_connections{db_name="herald"}`]; got != 7 {
+		t.Errorf("max open connections = %v, want 7", got)
+	}
+}
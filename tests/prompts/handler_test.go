@@ -21,6 +21,7 @@ type mockSystem struct {
 	findFn         func(ctx context.Context, id uuid.UUID) (*prompts.Prompt, error)
 	instructionsFn func(ctx context.Context, stage prompts.Stage) (string, error)
 	specFn         func(ctx context.Context, stage prompts.Stage) (string, error)
+	versionsFn     func(ctx context.Context) ([]prompts.Version, error)
 	createFn       func(ctx context.Context, cmd prompts.CreateCommand) (*prompts.Prompt, error)
 	updateFn       func(ctx context.Context, id uuid.UUID, cmd prompts.UpdateCommand) (*prompts.Prompt, error)
 	deleteFn       func(ctx context.Context, id uuid.UUID) error
@@ -48,6 +49,10 @@ func (m *mockSystem) Spec(ctx context.Context, stage prompts.Stage) (string, err
 	return m.specFn(ctx, stage)
 }
 
+func (m *mockSystem) Versions(ctx context.Context) ([]prompts.Version, error) {
+	return m.versionsFn(ctx)
+}
+
 func (m *mockSystem) Create(ctx context.Context, cmd prompts.CreateCommand) (*prompts.Prompt, error) {
 	return m.createFn(ctx, cmd)
 }
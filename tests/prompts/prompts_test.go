@@ -229,6 +229,47 @@ func TestSpec(t *testing.T) {
 	})
 }
 
+func TestNewVersion(t *testing.T) {
+	t.Run("default instructions have no prompt reference", func(t *testing.T) {
+		v := prompts.NewVersion(prompts.StageClassify, nil, "instructions", "spec")
+
+		if v.Stage != prompts.StageClassify {
+			t.Errorf("Stage = %q, want classify", v.Stage)
+		}
+		if v.PromptID != nil || v.Name != nil {
+			t.Errorf("PromptID = %v, Name = %v, want nil", v.PromptID, v.Name)
+		}
+		if len(v.Digest) != 64 {
+			t.Errorf("Digest length = %d, want 64 hex characters", len(v.Digest))
+		}
+	})
+
+	t.Run("active override is referenced", func(t *testing.T) {
+		p := samplePrompt()
+		v := prompts.NewVersion(p.Stage, &p, p.Instructions, "spec")
+
+		if v.PromptID == nil || *v.PromptID != p.ID {
+			t.Errorf("PromptID = %v, want %v", v.PromptID, p.ID)
+		}
+		if v.Name == nil || *v.Name != p.Name {
+			t.Errorf("Name = %v, want %q", v.Name, p.Name)
+		}
+	})
+
+	t.Run("digest changes with instructions", func(t *testing.T) {
+		a := prompts.NewVersion(prompts.StageClassify, nil, "one", "spec")
+		b := prompts.NewVersion(prompts.StageClassify, nil, "two", "spec")
+		c := prompts.NewVersion(prompts.StageClassify, nil, "one", "spec")
+
+		if a.Digest == b.Digest {
+			t.Error("digests equal for different instructions")
+		}
+		if a.Digest != c.Digest {
+			t.Error("digests differ for identical instructions")
+		}
+	})
+}
+
 func TestFiltersFromQuery(t *testing.T) {
 	t.Run("all params present", func(t *testing.T) {
 		values := url.Values{
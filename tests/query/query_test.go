@@ -186,6 +186,21 @@ func TestBuilderBuildPage(t *testing.T) {
 	}
 }
 
+func TestBuilderBuildSelect(t *testing.T) {
+	p := testProjection()
+	b := query.NewBuilder(p, query.SortField{Field: "createdAt", Descending: true})
+	b.WhereEquals("filename", "test.pdf")
+	sql, args := b.BuildSelect("id")
+
+	wantSQL := "SELECT d.id FROM public.documents d WHERE d.filename = $1"
+	if sql != wantSQL {
+		t.Errorf("BuildSelect() sql = %q, want %q", sql, wantSQL)
+	}
+	if len(args) != 1 || args[0] != "test.pdf" {
+		t.Errorf("BuildSelect() args = %v, want [test.pdf]", args)
+	}
+}
+
 func TestBuilderBuildSingle(t *testing.T) {
 	p := testProjection()
 	b := query.NewBuilder(p)
@@ -282,6 +297,62 @@ func TestBuilderWhereContainsEmptySkipped(t *testing.T) {
 	}
 }
 
+func TestBuilderWhereJSONContains(t *testing.T) {
+	p := testProjection()
+	b := query.NewBuilder(p)
+	b.WhereJSONContains("filename", ptr("NOFORN"))
+	sql, args := b.Build()
+
+	wantSQL := "SELECT d.id, d.filename, d.created_at FROM public.documents d WHERE d.filename @> jsonb_build_array($1::text)"
+	if sql != wantSQL {
+		t.Errorf("sql = %q, want %q", sql, wantSQL)
+	}
+	if len(args) != 1 || args[0] != "NOFORN" {
+		t.Errorf("args = %v, want [NOFORN]", args)
+	}
+}
+
+func TestBuilderWhereJSONContainsEmptySkipped(t *testing.T) {
+	p := testProjection()
+	b := query.NewBuilder(p)
+	b.WhereJSONContains("filename", nil)
+	b.WhereJSONContains("filename", ptr(""))
+	_, args := b.Build()
+
+	if len(args) != 0 {
+		t.Errorf("args = %v, want empty", args)
+	}
+}
+
+func TestBuilderWherePresent(t *testing.T) {
+	present, absent := true, false
+
+	tests := []struct {
+		name    string
+		present *bool
+		wantSQL string
+	}{
+		{"nil skipped", nil, "SELECT d.id, d.filename, d.created_at FROM public.documents d"},
+		{"present", &present, "SELECT d.id, d.filename, d.created_at FROM public.documents d WHERE d.filename IS NOT NULL"},
+		{"absent", &absent, "SELECT d.id, d.filename, d.created_at FROM public.documents d WHERE d.filename IS NULL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := query.NewBuilder(testProjection())
+			b.WherePresent("filename", tt.present)
+			sql, args := b.Build()
+
+			if sql != tt.wantSQL {
+				t.Errorf("sql = %q, want %q", sql, tt.wantSQL)
+			}
+			if len(args) != 0 {
+				t.Errorf("args = %v, want empty", args)
+			}
+		})
+	}
+}
+
 func TestBuilderWhereIn(t *testing.T) {
 	p := testProjection()
 	b := query.NewBuilder(p)
@@ -589,6 +660,18 @@ func TestBuilderBuildSingleWithJoin(t *testing.T) {
 	}
 }
 
+func TestBuilderBuildSelectWithJoin(t *testing.T) {
+	p := testJoinedProjection()
+	b := query.NewBuilder(p)
+	b.WhereEquals("Classification", "SECRET")
+	sql, _ := b.BuildSelect("ID", "Filename")
+
+	wantSQL := "SELECT d.id, d.filename FROM public.documents d LEFT JOIN public.classifications c ON d.id = c.document_id WHERE c.classification = $1"
+	if sql != wantSQL {
+		t.Errorf("BuildSelect() sql = %q, want %q", sql, wantSQL)
+	}
+}
+
 func TestBuilderWhereOnJoinedColumn(t *testing.T) {
 	p := testJoinedProjection()
 	b := query.NewBuilder(p)
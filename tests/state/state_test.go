@@ -39,6 +39,27 @@ func TestEnhance(t *testing.T) {
 	}
 }
 
+func TestEnhanced(t *testing.T) {
+	pending := state.ClassificationPage{
+		PageNumber:   1,
+		Enhancements: &state.EnhanceSettings{Contrast: intPtr(20)},
+	}
+	if pending.Enhanced() {
+		t.Error("Enhanced() = true for page only flagged for enhancement, want false")
+	}
+
+	done := state.ClassificationPage{
+		PageNumber:   1,
+		EnhancedWith: &state.EnhanceSettings{Contrast: intPtr(20)},
+	}
+	if !done.Enhanced() {
+		t.Error("Enhanced() = false for page with EnhancedWith, want true")
+	}
+	if done.Enhance() {
+		t.Error("Enhance() = true after enhancement applied, want false")
+	}
+}
+
 func TestNeedsEnhance(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -210,6 +231,28 @@ func TestClassificationStateJSON(t *testing.T) {
 	}
 }
 
+func TestTotalUsage(t *testing.T) {
+	cs := state.ClassificationState{
+		Pages: []state.ClassificationPage{
+			{PageNumber: 1, Usage: state.Usage{VisionCalls: 1, PromptTokens: 1000, CompletionTokens: 50}},
+			{PageNumber: 2, Usage: state.Usage{VisionCalls: 2, PromptTokens: 2100, CompletionTokens: 90}},
+		},
+		Usage: state.Usage{ChatCalls: 1, PromptTokens: 800, CompletionTokens: 40},
+	}
+
+	want := state.Usage{VisionCalls: 3, ChatCalls: 1, PromptTokens: 3900, CompletionTokens: 180}
+	got := cs.TotalUsage()
+	if got != want {
+		t.Errorf("TotalUsage() = %+v, want %+v", got, want)
+	}
+	if got.TotalTokens() != 4080 {
+		t.Errorf("TotalTokens() = %d, want 4080", got.TotalTokens())
+	}
+	if cs.Usage.ChatCalls != 1 || cs.Usage.PromptTokens != 800 {
+		t.Errorf("TotalUsage() modified document usage: %+v", cs.Usage)
+	}
+}
+
 func TestEnhanceSettingsJSON(t *testing.T) {
 	t.Run("null round-trips as nil", func(t *testing.T) {
 		input := `{"page_number":1,"image_path":"/tmp/p.png","markings_found":null,"rationale":""}`
@@ -265,3 +308,26 @@ func TestConfidenceConstants(t *testing.T) {
 		})
 	}
 }
+
+func TestFailed(t *testing.T) {
+	cs := &state.ClassificationState{
+		Pages: []state.ClassificationPage{
+			{PageNumber: 1},
+			{PageNumber: 2, Error: "classify: vision call: timeout"},
+			{PageNumber: 3},
+			{PageNumber: 4, Error: "enhance: render failed"},
+		},
+	}
+
+	got := cs.Failed()
+	if len(got) != 2 || got[0] != 2 || got[1] != 4 {
+		t.Errorf("Failed() = %v, want [2 4]", got)
+	}
+	if !cs.Pages[1].Failed() || cs.Pages[0].Failed() {
+		t.Error("page Failed() does not reflect Error")
+	}
+
+	if got := (&state.ClassificationState{Pages: []state.ClassificationPage{{PageNumber: 1}}}).Failed(); got != nil {
+		t.Errorf("Failed() = %v, want nil", got)
+	}
+}
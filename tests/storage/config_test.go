@@ -185,3 +185,93 @@ func TestMergeZeroMaxListSizePreservesBase(t *testing.T) {
 		t.Errorf("max_list_size: got %d, want 50 (preserved)", base.MaxListSize)
 	}
 }
+
+func TestFinalizeBackend(t *testing.T) {
+	t.Run("defaults to azure", func(t *testing.T) {
+		cfg := storage.Config{}
+		if err := cfg.Finalize(nil); err != nil {
+			t.Fatalf("finalize failed: %v", err)
+		}
+		if cfg.Backend != storage.BackendAzure {
+			t.Errorf("backend: got %s, want %s", cfg.Backend, storage.BackendAzure)
+		}
+		if cfg.Root != ".storage" {
+			t.Errorf("root: got %s, want .storage", cfg.Root)
+		}
+		if cfg.Region != "us-east-1" {
+			t.Errorf("region: got %s, want us-east-1", cfg.Region)
+		}
+	})
+
+	t.Run("env override", func(t *testing.T) {
+		t.Setenv("TEST_BACKEND", "filesystem")
+		t.Setenv("TEST_ROOT", "/var/lib/herald")
+
+		cfg := storage.Config{Backend: storage.BackendAzure}
+		if err := cfg.Finalize(&storage.Env{Backend: "TEST_BACKEND", Root: "TEST_ROOT"}); err != nil {
+			t.Fatalf("finalize failed: %v", err)
+		}
+		if cfg.Backend != storage.BackendFilesystem {
+			t.Errorf("backend: got %s, want %s", cfg.Backend, storage.BackendFilesystem)
+		}
+		if cfg.Root != "/var/lib/herald" {
+			t.Errorf("root: got %s, want /var/lib/herald", cfg.Root)
+		}
+	})
+
+	t.Run("s3 env overrides", func(t *testing.T) {
+		t.Setenv("TEST_ENDPOINT", "http://localhost:9000")
+		t.Setenv("TEST_REGION", "eu-west-1")
+		t.Setenv("TEST_ACCESS_KEY", "access")
+		t.Setenv("TEST_SECRET_KEY", "secret")
+
+		env := &storage.Env{
+			Endpoint:        "TEST_ENDPOINT",
+			Region:          "TEST_REGION",
+			AccessKeyID:     "TEST_ACCESS_KEY",
+			SecretAccessKey: "TEST_SECRET_KEY",
+		}
+
+		cfg := storage.Config{Backend: storage.BackendS3}
+		if err := cfg.Finalize(env); err != nil {
+			t.Fatalf("finalize failed: %v", err)
+		}
+		if cfg.Endpoint != "http://localhost:9000" || cfg.Region != "eu-west-1" {
+			t.Errorf("endpoint, region: got %s, %s", cfg.Endpoint, cfg.Region)
+		}
+		if cfg.AccessKeyID != "access" || cfg.SecretAccessKey != "secret" {
+			t.Errorf("credentials: got %s, %s", cfg.AccessKeyID, cfg.SecretAccessKey)
+		}
+	})
+
+	t.Run("unknown backend is invalid", func(t *testing.T) {
+		cfg := storage.Config{Backend: "ftp"}
+		if err := cfg.Finalize(nil); err == nil {
+			t.Fatal("expected error for unknown backend")
+		}
+	})
+}
+
+func TestMergeBackend(t *testing.T) {
+	base := storage.Config{
+		Backend:       storage.BackendAzure,
+		ContainerName: "documents",
+		Region:        "us-east-1",
+	}
+
+	overlay := storage.Config{
+		Backend:  storage.BackendS3,
+		Endpoint: "http://localhost:9000",
+	}
+	base.Merge(&overlay)
+
+	if base.Backend != storage.BackendS3 {
+		t.Errorf("backend: got %s, want %s", base.Backend, storage.BackendS3)
+	}
+	if base.Endpoint != "http://localhost:9000" {
+		t.Errorf("endpoint: got %s, want http://localhost:9000", base.Endpoint)
+	}
+	if base.Region != "us-east-1" {
+		t.Errorf("region should remain us-east-1, got %s", base.Region)
+	}
+}
@@ -0,0 +1,342 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/JaimeStill/herald/pkg/lifecycle"
+	"github.com/JaimeStill/herald/pkg/storage"
+)
+
+// runConformance exercises the storage.System contract against a backend.
+// newSystem must return a started, empty store for each subtest.
+func runConformance(t *testing.T, newSystem func(t *testing.T) storage.System) {
+	ctx := context.Background()
+
+	t.Run("upload find download", func(t *testing.T) {
+		sys := newSystem(t)
+		content := []byte("%PDF-1.7 conformance")
+		key := "documents/annual report.pdf"
+
+		upload(t, sys, key, content, "application/pdf")
+
+		meta, err := sys.Find(ctx, key)
+		if err != nil {
+			t.Fatalf("Find() error = %v", err)
+		}
+		if meta.Name != key {
+			t.Errorf("Find().Name = %q, want %q", meta.Name, key)
+		}
+		if meta.ContentType != "application/pdf" {
+			t.Errorf("Find().ContentType = %q, want application/pdf", meta.ContentType)
+		}
+		if meta.ContentLength != int64(len(content)) {
+			t.Errorf("Find().ContentLength = %d, want %d", meta.ContentLength, len(content))
+		}
+		if meta.LastModified.IsZero() {
+			t.Error("Find().LastModified is zero")
+		}
+
+		got, result := download(t, sys, key)
+		if !bytes.Equal(got, content) {
+			t.Errorf("Download() body = %q, want %q", got, content)
+		}
+		if result.ContentType != "application/pdf" {
+			t.Errorf("Download().ContentType = %q, want application/pdf", result.ContentType)
+		}
+		if result.ContentLength != int64(len(content)) {
+			t.Errorf("Download().ContentLength = %d, want %d", result.ContentLength, len(content))
+		}
+	})
+
+	t.Run("upload overwrites", func(t *testing.T) {
+		sys := newSystem(t)
+		key := "documents/overwrite.txt"
+
+		upload(t, sys, key, []byte("first version"), "text/plain")
+		upload(t, sys, key, []byte("second"), "text/plain")
+
+		got, _ := download(t, sys, key)
+		if string(got) != "second" {
+			t.Errorf("Download() body = %q, want second", got)
+		}
+
+		meta, err := sys.Find(ctx, key)
+		if err != nil {
+			t.Fatalf("Find() error = %v", err)
+		}
+		if meta.ContentLength != int64(len("second")) {
+			t.Errorf("Find().ContentLength = %d, want %d", meta.ContentLength, len("second"))
+		}
+	})
+
+	t.Run("exists", func(t *testing.T) {
+		sys := newSystem(t)
+		key := "documents/exists.txt"
+
+		if ok, err := sys.Exists(ctx, key); err != nil || ok {
+			t.Fatalf("Exists() before upload = %v, %v, want false, nil", ok, err)
+		}
+
+		upload(t, sys, key, []byte("here"), "text/plain")
+
+		if ok, err := sys.Exists(ctx, key); err != nil || !ok {
+			t.Errorf("Exists() after upload = %v, %v, want true, nil", ok, err)
+		}
+	})
+
+	t.Run("missing blob", func(t *testing.T) {
+		sys := newSystem(t)
+		key := "documents/missing.pdf"
+
+		if _, err := sys.Find(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("Find() error = %v, want ErrNotFound", err)
+		}
+		if _, err := sys.Download(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("Download() error = %v, want ErrNotFound", err)
+		}
+		if err := sys.Delete(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("Delete() error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		sys := newSystem(t)
+		key := "documents/nested/delete.txt"
+
+		upload(t, sys, key, []byte("gone soon"), "text/plain")
+
+		if err := sys.Delete(ctx, key); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if ok, err := sys.Exists(ctx, key); err != nil || ok {
+			t.Errorf("Exists() after delete = %v, %v, want false, nil", ok, err)
+		}
+		if _, err := sys.Find(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("Find() after delete error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("list by prefix", func(t *testing.T) {
+		sys := newSystem(t)
+		for _, key := range []string{"a/2.txt", "a/1.txt", "ab/1.txt", "b/1.txt"} {
+			upload(t, sys, key, []byte(key), "text/plain")
+		}
+
+		list, err := sys.List(ctx, "a/", "", 10)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+
+		want := []string{"a/1.txt", "a/2.txt"}
+		if got := names(list.Blobs); !slices.Equal(got, want) {
+			t.Errorf("List() names = %v, want %v", got, want)
+		}
+		if list.NextMarker != "" {
+			t.Errorf("List().NextMarker = %q, want empty", list.NextMarker)
+		}
+		for _, b := range list.Blobs {
+			if b.ContentLength != int64(len(b.Name)) {
+				t.Errorf("List() %s ContentLength = %d, want %d", b.Name, b.ContentLength, len(b.Name))
+			}
+		}
+	})
+
+	t.Run("list pagination", func(t *testing.T) {
+		sys := newSystem(t)
+		want := []string{"page/1", "page/2", "page/3", "page/4", "page/5"}
+		for _, key := range want {
+			upload(t, sys, key, []byte(key), "text/plain")
+		}
+
+		var got []string
+		marker := ""
+		for range len(want) + 1 {
+			list, err := sys.List(ctx, "page/", marker, 2)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(list.Blobs) > 2 {
+				t.Fatalf("List() returned %d blobs, want at most 2", len(list.Blobs))
+			}
+			got = append(got, names(list.Blobs)...)
+
+			marker = list.NextMarker
+			if marker == "" {
+				break
+			}
+		}
+
+		if !slices.Equal(got, want) {
+			t.Errorf("paged names = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("list pagination in key order", func(t *testing.T) {
+		sys := newSystem(t)
+		want := []string{"mixed/a-b", "mixed/a.txt", "mixed/a/x", "mixed/a/y", "mixed/b"}
+		for _, key := range []string{"mixed/b", "mixed/a/y", "mixed/a.txt", "mixed/a/x", "mixed/a-b"} {
+			upload(t, sys, key, []byte(key), "text/plain")
+		}
+
+		var got []string
+		marker := ""
+		for range len(want) + 1 {
+			list, err := sys.List(ctx, "mixed/", marker, 1)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			got = append(got, names(list.Blobs)...)
+
+			marker = list.NextMarker
+			if marker == "" {
+				break
+			}
+		}
+
+		if !slices.Equal(got, want) {
+			t.Errorf("paged names = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("list empty", func(t *testing.T) {
+		sys := newSystem(t)
+
+		list, err := sys.List(ctx, "nothing/", "", 10)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(list.Blobs) != 0 || list.NextMarker != "" {
+			t.Errorf("List() = %+v, want no blobs and no marker", list)
+		}
+	})
+
+	t.Run("key validation", func(t *testing.T) {
+		sys := newSystem(t)
+
+		for _, tt := range []struct {
+			key  string
+			want error
+		}{
+			{"", storage.ErrEmptyKey},
+			{"documents/../secrets", storage.ErrInvalidKey},
+		} {
+			if err := sys.Upload(ctx, tt.key, bytes.NewReader(nil), "text/plain"); !errors.Is(err, tt.want) {
+				t.Errorf("Upload(%q) error = %v, want %v", tt.key, err, tt.want)
+			}
+			if _, err := sys.Find(ctx, tt.key); !errors.Is(err, tt.want) {
+				t.Errorf("Find(%q) error = %v, want %v", tt.key, err, tt.want)
+			}
+			if _, err := sys.Download(ctx, tt.key); !errors.Is(err, tt.want) {
+				t.Errorf("Download(%q) error = %v, want %v", tt.key, err, tt.want)
+			}
+			if err := sys.Delete(ctx, tt.key); !errors.Is(err, tt.want) {
+				t.Errorf("Delete(%q) error = %v, want %v", tt.key, err, tt.want)
+			}
+			if _, err := sys.Exists(ctx, tt.key); !errors.Is(err, tt.want) {
+				t.Errorf("Exists(%q) error = %v, want %v", tt.key, err, tt.want)
+			}
+		}
+	})
+}
+
+func TestFilesystemConformance(t *testing.T) {
+	runConformance(t, func(t *testing.T) storage.System {
+		return start(t, &storage.Config{
+			Backend:       storage.BackendFilesystem,
+			ContainerName: "documents",
+			Root:          t.TempDir(),
+		})
+	})
+}
+
+func TestS3Conformance(t *testing.T) {
+	runConformance(t, func(t *testing.T) storage.System {
+		server := newFakeS3(t)
+		return start(t, &storage.Config{
+			Backend:         storage.BackendS3,
+			ContainerName:   "documents",
+			Endpoint:        server.URL,
+			Region:          "us-east-1",
+			AccessKeyID:     fakeS3AccessKey,
+			SecretAccessKey: "secret",
+		})
+	})
+}
+
+// TestAzureConformance runs the suite against Azurite when
+// HERALD_TEST_AZURE_CONNECTION_STRING is set, for example to azuriteConnString
+// with the compose stack running.
+func TestAzureConformance(t *testing.T) {
+	conn := os.Getenv("HERALD_TEST_AZURE_CONNECTION_STRING")
+	if conn == "" {
+		t.Skip("HERALD_TEST_AZURE_CONNECTION_STRING not set")
+	}
+
+	runConformance(t, func(t *testing.T) storage.System {
+		suffix := make([]byte, 6)
+		rand.Read(suffix)
+
+		return start(t, &storage.Config{
+			Backend:          storage.BackendAzure,
+			ContainerName:    "conformance-" + hex.EncodeToString(suffix),
+			ConnectionString: conn,
+		})
+	})
+}
+
+func start(t *testing.T, cfg *storage.Config) storage.System {
+	t.Helper()
+
+	sys, err := storage.New(cfg, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	lc := lifecycle.New()
+	if err := sys.Start(lc); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	lc.WaitForStartup()
+
+	return sys
+}
+
+func upload(t *testing.T, sys storage.System, key string, content []byte, contentType string) {
+	t.Helper()
+	if err := sys.Upload(context.Background(), key, bytes.NewReader(content), contentType); err != nil {
+		t.Fatalf("Upload(%q) error = %v", key, err)
+	}
+}
+
+func download(t *testing.T, sys storage.System, key string) ([]byte, *storage.BlobResult) {
+	t.Helper()
+
+	result, err := sys.Download(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Download(%q) error = %v", key, err)
+	}
+	defer result.Body.Close()
+
+	body, err := io.ReadAll(result.Body)
+	if err != nil {
+		t.Fatalf("read %q: %v", key, err)
+	}
+	return body, result
+}
+
+func names(blobs []storage.BlobMeta) []string {
+	out := make([]string, len(blobs))
+	for i, b := range blobs {
+		out[i] = b.Name
+	}
+	return out
+}
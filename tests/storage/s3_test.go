@@ -0,0 +1,267 @@
+package storage_test
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JaimeStill/herald/pkg/storage"
+)
+
+const fakeS3AccessKey = "herald-test"
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+	modified    time.Time
+}
+
+// fakeS3 is an in-memory, path-style S3 endpoint covering the operations the
+// storage backend uses. It rejects unsigned requests and payloads whose body
+// does not match the signed content hash.
+type fakeS3 struct {
+	mu      sync.Mutex
+	buckets map[string]map[string]fakeObject
+}
+
+func newFakeS3(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	f := &fakeS3{buckets: make(map[string]map[string]fakeObject)}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential="+fakeS3AccessKey+"/") {
+		f.fail(w, http.StatusForbidden, "AccessDenied")
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if key == "" {
+		f.serveBucket(w, r, bucket)
+		return
+	}
+
+	objects, ok := f.buckets[bucket]
+	if !ok {
+		f.fail(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil || r.ContentLength != int64(len(body)) {
+			f.fail(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		sum := sha256.Sum256(body)
+		if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+			f.fail(w, http.StatusBadRequest, "XAmzContentSHA256Mismatch")
+			return
+		}
+		objects[key] = fakeObject{
+			data:        body,
+			contentType: r.Header.Get("Content-Type"),
+			modified:    time.Now().UTC(),
+		}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodHead, http.MethodGet:
+		obj, ok := objects[key]
+		if !ok {
+			f.fail(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(obj.data)))
+		w.Header().Set("ETag", etag(obj.data))
+		w.Header().Set("Last-Modified", obj.modified.Format(http.TimeFormat))
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			w.Write(obj.data)
+		}
+	case http.MethodDelete:
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		f.fail(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+func (f *fakeS3) serveBucket(w http.ResponseWriter, r *http.Request, bucket string) {
+	switch r.Method {
+	case http.MethodPut:
+		if _, ok := f.buckets[bucket]; ok {
+			f.fail(w, http.StatusConflict, "BucketAlreadyOwnedByYou")
+			return
+		}
+		f.buckets[bucket] = make(map[string]fakeObject)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		objects, ok := f.buckets[bucket]
+		if !ok {
+			f.fail(w, http.StatusNotFound, "NoSuchBucket")
+			return
+		}
+		f.list(w, r, objects)
+	default:
+		f.fail(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+type fakeListEntry struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int    `xml:"Size"`
+}
+
+type fakeListResult struct {
+	XMLName               xml.Name        `xml:"ListBucketResult"`
+	Contents              []fakeListEntry `xml:"Contents"`
+	IsTruncated           bool            `xml:"IsTruncated"`
+	NextContinuationToken string          `xml:"NextContinuationToken,omitempty"`
+}
+
+// list implements ListObjectsV2, using the last returned key as the
+// continuation token.
+func (f *fakeS3) list(w http.ResponseWriter, r *http.Request, objects map[string]fakeObject) {
+	q := r.URL.Query()
+	prefix := q.Get("prefix")
+	after := q.Get("continuation-token")
+	maxKeys, err := strconv.Atoi(q.Get("max-keys"))
+	if err != nil || maxKeys < 1 {
+		maxKeys = 1000
+	}
+
+	var keys []string
+	for k := range objects {
+		if strings.HasPrefix(k, prefix) && k > after {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	var out fakeListResult
+	for _, k := range keys {
+		if len(out.Contents) == maxKeys {
+			out.IsTruncated = true
+			out.NextContinuationToken = out.Contents[len(out.Contents)-1].Key
+			break
+		}
+		obj := objects[k]
+		out.Contents = append(out.Contents, fakeListEntry{
+			Key:          k,
+			LastModified: obj.modified.Format(time.RFC3339),
+			ETag:         etag(obj.data),
+			Size:         len(obj.data),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(out)
+}
+
+func (f *fakeS3) fail(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code></Error>", code)
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func TestNewS3Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     storage.Config
+		wantErr bool
+	}{
+		{
+			name: "aws endpoint from region",
+			cfg:  storage.Config{ContainerName: "documents", Region: "us-east-1"},
+		},
+		{
+			name: "custom endpoint",
+			cfg: storage.Config{
+				ContainerName:   "documents",
+				Endpoint:        "http://localhost:9000",
+				Region:          "us-east-1",
+				AccessKeyID:     "access",
+				SecretAccessKey: "secret",
+			},
+		},
+		{
+			name:    "missing region",
+			cfg:     storage.Config{ContainerName: "documents"},
+			wantErr: true,
+		},
+		{
+			name: "access key without secret",
+			cfg: storage.Config{
+				ContainerName: "documents",
+				Region:        "us-east-1",
+				AccessKeyID:   "access",
+			},
+			wantErr: true,
+		},
+		{
+			name: "endpoint without scheme",
+			cfg: storage.Config{
+				ContainerName: "documents",
+				Endpoint:      "localhost:9000",
+				Region:        "us-east-1",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := storage.NewS3(&tt.cfg, slog.Default())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewS3() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewSelectsBackend(t *testing.T) {
+	t.Run("unknown backend", func(t *testing.T) {
+		_, err := storage.New(&storage.Config{Backend: "ftp"}, slog.Default())
+		if err == nil {
+			t.Fatal("expected error for unknown backend")
+		}
+	})
+
+	t.Run("filesystem requires root", func(t *testing.T) {
+		_, err := storage.New(&storage.Config{
+			Backend:       storage.BackendFilesystem,
+			ContainerName: "documents",
+		}, slog.Default())
+		if err == nil {
+			t.Fatal("expected error for missing root")
+		}
+	})
+}
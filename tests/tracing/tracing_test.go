@@ -0,0 +1,165 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/tailored-agentic-units/orchestrate/observability"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/JaimeStill/herald/internal/config"
+	"github.com/JaimeStill/herald/internal/tracing"
+	"github.com/JaimeStill/herald/internal/workflow"
+	"github.com/JaimeStill/herald/pkg/storage"
+
+	taustate "github.com/tailored-agentic-units/orchestrate/state"
+)
+
+// recorder captures every span ended in this test binary. The global
+// provider is installed once because package-level tracers bind to the
+// first provider set; tracing.New with tracing disabled installs only the
+// propagator.
+var recorder = tracetest.NewSpanRecorder()
+
+func TestMain(m *testing.M) {
+	cfg := &config.TracingConfig{Exporter: config.TracingNone}
+	if _, err := tracing.New(cfg, "test", slog.Default()); err != nil {
+		panic(err)
+	}
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	os.Exit(m.Run())
+}
+
+// ended returns the spans ended since the last call.
+func ended() []sdktrace.ReadOnlySpan {
+	spans := recorder.Ended()
+	recorder.Reset()
+	return spans
+}
+
+func TestHTTPNamesSpanByRoute(t *testing.T) {
+	ended()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /things/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	handler := tracing.HTTP("/api")(mux)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/things/1", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/missing", nil))
+
+	spans := ended()
+	if len(spans) != 2 {
+		t.Fatalf("spans = %d, want 2", len(spans))
+	}
+	if got := spans[0].Name(); got != "GET /api/things/{id}" {
+		t.Errorf("matched span name = %q, want %q", got, "GET /api/things/{id}")
+	}
+	if got := spans[1].Name(); got != "POST" {
+		t.Errorf("unmatched span name = %q, want %q", got, "POST")
+	}
+}
+
+func TestHTTPContinuesPropagatedTrace(t *testing.T) {
+	ended()
+
+	handler := tracing.HTTP("/api")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := ended()
+	if len(spans) != 1 {
+		t.Fatalf("spans = %d, want 1", len(spans))
+	}
+	if got := spans[0].SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace id = %s, want the propagated trace", got)
+	}
+}
+
+type failingStorage struct {
+	storage.System
+}
+
+func (failingStorage) Exists(context.Context, string) (bool, error) {
+	return false, nil
+}
+
+func (failingStorage) Delete(context.Context, string) error {
+	return errors.New("blob locked")
+}
+
+func TestStorageSpans(t *testing.T) {
+	ended()
+
+	store := tracing.Storage(failingStorage{})
+	store.Exists(context.Background(), "documents/a.pdf")
+	store.Delete(context.Background(), "documents/a.pdf")
+
+	spans := ended()
+	if len(spans) != 2 {
+		t.Fatalf("spans = %d, want 2", len(spans))
+	}
+	if got := spans[0].Name(); got != "storage.Exists" {
+		t.Errorf("span name = %q, want storage.Exists", got)
+	}
+	if got := spans[0].Status().Code; got != codes.Unset {
+		t.Errorf("Exists status = %v, want unset", got)
+	}
+	if got := spans[1].Status().Code; got != codes.Error {
+		t.Errorf("Delete status = %v, want error", got)
+	}
+}
+
+func TestTracingObserverNodeSpans(t *testing.T) {
+	ended()
+
+	obs := workflow.NewTracingObserver()
+	start := time.Now()
+
+	for _, node := range []string{"classify", "enhance"} {
+		obs.OnEvent(context.Background(), observability.Event{
+			Type:      taustate.EventNodeStart,
+			Timestamp: start,
+			Data:      map[string]any{"node": node, "iteration": float64(1)},
+		})
+	}
+	obs.OnEvent(context.Background(), observability.Event{
+		Type:      taustate.EventNodeComplete,
+		Timestamp: start.Add(time.Second),
+		Data:      map[string]any{"node": "classify", "iteration": float64(1)},
+	})
+	obs.OnEvent(context.Background(), observability.Event{
+		Type:      taustate.EventNodeComplete,
+		Timestamp: start.Add(time.Second),
+		Data: map[string]any{
+			"node":          "enhance",
+			"iteration":     float64(1),
+			"error":         true,
+			"error_message": "render failed",
+		},
+	})
+
+	spans := ended()
+	if len(spans) != 2 {
+		t.Fatalf("spans = %d, want 2", len(spans))
+	}
+	if got := spans[0].Name(); got != "workflow.node classify" {
+		t.Errorf("span name = %q, want %q", got, "workflow.node classify")
+	}
+	if !spans[0].StartTime().Equal(start) {
+		t.Errorf("span start = %v, want event timestamp", spans[0].StartTime())
+	}
+	if got := spans[1].Status(); got.Code != codes.Error || got.Description != "render failed" {
+		t.Errorf("failed node status = %+v, want error with node message", got)
+	}
+}
@@ -0,0 +1,138 @@
+package workflow_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/JaimeStill/herald/internal/agents"
+	"github.com/JaimeStill/herald/internal/format"
+	"github.com/JaimeStill/herald/internal/state"
+	"github.com/JaimeStill/herald/internal/workflow"
+)
+
+// croppingHandler stands in for an image handler in banner crop mode by
+// copying the page image as its banner crop, so the banners strategy can be
+// exercised without ImageMagick.
+type croppingHandler struct {
+	format.Handler
+}
+
+func (h *croppingHandler) Extract(ctx context.Context, src format.SourceReader, tempDir string) ([]state.ClassificationPage, error) {
+	pages, err := h.Handler.Extract(ctx, src, tempDir)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pages {
+		data, err := os.ReadFile(pages[i].ImagePath)
+		if err != nil {
+			return nil, err
+		}
+		pages[i].BannerPath = filepath.Join(tempDir, "page-1-banners.png")
+		if err := os.WriteFile(pages[i].BannerPath, data, 0600); err != nil {
+			return nil, err
+		}
+	}
+	return pages, nil
+}
+
+// bannersAgent answers banner crop calls with a fixed response and records
+// the stage of every vision call.
+type bannersAgent struct {
+	agents.Agent
+	banners string
+
+	mu     sync.Mutex
+	stages []string
+}
+
+func (a *bannersAgent) Vision(ctx context.Context, prompt string, image []byte) (agents.Response, error) {
+	call, _ := agents.CallFrom(ctx)
+	a.mu.Lock()
+	a.stages = append(a.stages, call.Stage)
+	a.mu.Unlock()
+
+	if call.Stage == "banners" {
+		return agents.Response{Text: a.banners}, nil
+	}
+	return a.Agent.Vision(ctx, prompt, image)
+}
+
+func TestExecuteBannersStrategy(t *testing.T) {
+	fixtures := projectPath(t, "_project/marked-documents/replay")
+	path := projectPath(t, "_project/marked-documents/images/marked-document.3.png")
+
+	tests := []struct {
+		name        string
+		strategy    workflow.Strategy
+		banners     string
+		wantStages  []string
+		bannersOnly bool
+	}{
+		{
+			name:        "legible crop skips the full page",
+			strategy:    workflow.StrategyBanners,
+			banners:     `{"markings_found": ["SECRET//NOFORN"], "rationale": "banners", "enhancements": null}`,
+			wantStages:  []string{"banners"},
+			bannersOnly: true,
+		},
+		{
+			name:       "empty crop falls back to the full page",
+			strategy:   workflow.StrategyBanners,
+			banners:    `{"markings_found": [], "rationale": "nothing legible", "enhancements": null}`,
+			wantStages: []string{"banners", "classify"},
+		},
+		{
+			name:       "enhancement request falls back to the full page",
+			strategy:   workflow.StrategyBanners,
+			banners:    `{"markings_found": ["SECRET"], "rationale": "faded", "enhancements": {"contrast": 20}}`,
+			wantStages: []string{"banners", "classify"},
+		},
+		{
+			name:       "malformed response falls back to the full page",
+			strategy:   workflow.StrategyBanners,
+			banners:    `The header reads SECRET.`,
+			wantStages: []string{"banners", "classify"},
+		},
+		{
+			name:       "page strategy ignores the crop",
+			strategy:   workflow.StrategyPage,
+			wantStages: []string{"classify"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt, docs := replayRuntime(t, fixtures)
+			rt.Formats = format.NewRegistry(&croppingHandler{Handler: format.NewImageHandler(format.Options{})})
+			rt.Strategy = tt.strategy
+
+			agent := &bannersAgent{Agent: agents.NewReplay(fixtures, "replay-model", false), banners: tt.banners}
+			rt.NewAgent = func(context.Context) (agents.Agent, error) { return agent, nil }
+
+			id := upload(t, rt, docs, path, "image/png")
+			result, err := execute(t, rt, id)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if !slices.Equal(agent.stages, tt.wantStages) {
+				t.Errorf("vision stages = %v, want %v", agent.stages, tt.wantStages)
+			}
+
+			page := result.State.Pages[0]
+			if page.BannersOnly != tt.bannersOnly {
+				t.Errorf("BannersOnly = %v, want %v", page.BannersOnly, tt.bannersOnly)
+			}
+			if got := page.Usage.VisionCalls; got != len(tt.wantStages) {
+				t.Errorf("page VisionCalls = %d, want %d", got, len(tt.wantStages))
+			}
+			if tt.bannersOnly && page.Rationale != "banners" {
+				t.Errorf("Rationale = %q, want the banner crop's", page.Rationale)
+			}
+		})
+	}
+}
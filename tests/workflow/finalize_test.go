@@ -0,0 +1,123 @@
+package workflow_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/JaimeStill/herald/internal/markings"
+	"github.com/JaimeStill/herald/internal/state"
+	"github.com/JaimeStill/herald/internal/workflow"
+)
+
+func reconcileState(classification string, pageMarkings ...[]string) *state.ClassificationState {
+	cs := &state.ClassificationState{
+		Classification: classification,
+		Confidence:     state.ConfidenceHigh,
+		Rationale:      "synthesized",
+	}
+	for i, found := range pageMarkings {
+		cs.Pages = append(cs.Pages, state.ClassificationPage{
+			PageNumber:    i + 1,
+			MarkingsFound: found,
+		})
+	}
+	return cs
+}
+
+func TestReconcile(t *testing.T) {
+	p := markings.NewParser(markings.DefaultTaxonomy())
+
+	tests := []struct {
+		name           string
+		cs             *state.ClassificationState
+		wantRollUp     string
+		wantConfidence state.Confidence
+		wantDiscrepant bool
+		wantContains   string
+	}{
+		{
+			name: "agreement keeps confidence",
+			cs: reconcileState("Secret // NOFORN",
+				[]string{"SECRET//NOFORN"},
+				[]string{"UNCLASSIFIED", "(S//NF)"},
+			),
+			wantRollUp:     "SECRET//NOFORN",
+			wantConfidence: state.ConfidenceHigh,
+		},
+		{
+			name: "lower level downgrades confidence",
+			cs: reconcileState("SECRET",
+				[]string{"SECRET"},
+				[]string{"TOP SECRET"},
+			),
+			wantRollUp:     "TOP SECRET",
+			wantConfidence: state.ConfidenceLow,
+			wantDiscrepant: true,
+			wantContains:   "level: expected TOP SECRET, got SECRET",
+		},
+		{
+			name: "missing caveat downgrades confidence",
+			cs: reconcileState("SECRET",
+				[]string{"SECRET//NOFORN"},
+			),
+			wantRollUp:     "SECRET//NOFORN",
+			wantConfidence: state.ConfidenceLow,
+			wantDiscrepant: true,
+			wantContains:   "dissemination: expected NOFORN, got none",
+		},
+		{
+			name: "unparseable answer downgrades confidence",
+			cs: reconcileState("PROBABLY SECRET",
+				[]string{"SECRET"},
+			),
+			wantRollUp:     "SECRET",
+			wantConfidence: state.ConfidenceLow,
+			wantDiscrepant: true,
+			wantContains:   "unknown classification level",
+		},
+		{
+			name: "no parseable page markings skips check",
+			cs: reconcileState("SECRET",
+				[]string{"DRAFT"},
+				nil,
+			),
+			wantConfidence: state.ConfidenceHigh,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow.Reconcile(p, tt.cs)
+
+			if tt.cs.RollUp != tt.wantRollUp {
+				t.Errorf("RollUp = %q, want %q", tt.cs.RollUp, tt.wantRollUp)
+			}
+			if tt.cs.Confidence != tt.wantConfidence {
+				t.Errorf("Confidence = %s, want %s", tt.cs.Confidence, tt.wantConfidence)
+			}
+			if (tt.cs.Discrepancy != "") != tt.wantDiscrepant {
+				t.Errorf("Discrepancy = %q, want discrepant %v", tt.cs.Discrepancy, tt.wantDiscrepant)
+			}
+			if !strings.Contains(tt.cs.Discrepancy, tt.wantContains) {
+				t.Errorf("Discrepancy = %q, want it to contain %q", tt.cs.Discrepancy, tt.wantContains)
+			}
+		})
+	}
+}
+
+func TestReconcileClearsPreviousResult(t *testing.T) {
+	p := markings.NewParser(markings.DefaultTaxonomy())
+
+	cs := reconcileState("SECRET", []string{"SECRET"})
+	cs.RollUp = "TOP SECRET"
+	cs.Discrepancy = "stale"
+
+	workflow.Reconcile(p, cs)
+
+	if cs.Discrepancy != "" {
+		t.Errorf("Discrepancy = %q, want empty", cs.Discrepancy)
+	}
+	if cs.RollUp != "SECRET" {
+		t.Errorf("RollUp = %q, want SECRET", cs.RollUp)
+	}
+}
@@ -0,0 +1,230 @@
+package workflow_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/JaimeStill/herald/internal/workflow"
+)
+
+func nodeEvent(eventType workflow.ExecutionEventType, node string) workflow.ExecutionEvent {
+	return workflow.ExecutionEvent{
+		Type:      eventType,
+		Timestamp: time.Now(),
+		Data:      map[string]any{"node": node},
+	}
+}
+
+func receive(t *testing.T, sub *workflow.Subscription) workflow.ExecutionEvent {
+	t.Helper()
+	select {
+	case e, ok := <-sub.Events():
+		if !ok {
+			t.Fatal("subscription closed, want event")
+		}
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	return workflow.ExecutionEvent{}
+}
+
+func TestHubPublishAssignsIncreasingIDs(t *testing.T) {
+	hub := workflow.NewHub(8, time.Minute)
+	doc := uuid.New()
+
+	hub.Begin(doc)
+	hub.Publish(doc, nodeEvent(workflow.NodeStart, "init"))
+	hub.Publish(doc, nodeEvent(workflow.NodeComplete, "init"))
+
+	replay, sub := hub.Subscribe(doc, 0)
+	defer sub.Cancel()
+
+	if len(replay) != 2 {
+		t.Fatalf("replay length = %d, want 2", len(replay))
+	}
+	if replay[0].ID == 0 || replay[1].ID <= replay[0].ID {
+		t.Errorf("ids = %d, %d, want increasing non-zero", replay[0].ID, replay[1].ID)
+	}
+}
+
+func TestHubSubscribeReplaysAfterLastEventID(t *testing.T) {
+	hub := workflow.NewHub(8, time.Minute)
+	doc := uuid.New()
+
+	hub.Begin(doc)
+	hub.Publish(doc, nodeEvent(workflow.NodeStart, "init"))
+	hub.Publish(doc, nodeEvent(workflow.NodeComplete, "init"))
+	hub.Publish(doc, nodeEvent(workflow.NodeStart, "classify"))
+
+	all, first := hub.Subscribe(doc, 0)
+	first.Cancel()
+
+	replay, sub := hub.Subscribe(doc, all[0].ID)
+	defer sub.Cancel()
+
+	if len(replay) != 2 {
+		t.Fatalf("replay length = %d, want 2", len(replay))
+	}
+	if replay[0].Type != workflow.NodeComplete {
+		t.Errorf("replay[0].Type = %q, want node.complete", replay[0].Type)
+	}
+}
+
+func TestHubMultipleSubscribersReceiveLiveEvents(t *testing.T) {
+	hub := workflow.NewHub(8, time.Minute)
+	doc := uuid.New()
+
+	hub.Begin(doc)
+	_, a := hub.Subscribe(doc, 0)
+	defer a.Cancel()
+	_, b := hub.Subscribe(doc, 0)
+	defer b.Cancel()
+
+	hub.Publish(doc, nodeEvent(workflow.NodeStart, "init"))
+
+	for name, sub := range map[string]*workflow.Subscription{"a": a, "b": b} {
+		e := receive(t, sub)
+		if e.Type != workflow.NodeStart {
+			t.Errorf("subscriber %s: type = %q, want node.start", name, e.Type)
+		}
+	}
+}
+
+func TestHubFinishClosesSubscribers(t *testing.T) {
+	hub := workflow.NewHub(8, time.Minute)
+	doc := uuid.New()
+
+	hub.Begin(doc)
+	_, sub := hub.Subscribe(doc, 0)
+	defer sub.Cancel()
+
+	hub.Publish(doc, nodeEvent(workflow.Complete, ""))
+	hub.Finish(doc)
+
+	if e := receive(t, sub); e.Type != workflow.Complete {
+		t.Errorf("type = %q, want complete", e.Type)
+	}
+	if _, ok := <-sub.Events(); ok {
+		t.Error("subscription open after Finish, want closed")
+	}
+}
+
+func TestHubSubscribeAfterFinishReplaysAndCloses(t *testing.T) {
+	hub := workflow.NewHub(8, time.Minute)
+	doc := uuid.New()
+
+	hub.Begin(doc)
+	hub.Publish(doc, nodeEvent(workflow.NodeStart, "init"))
+	hub.Publish(doc, nodeEvent(workflow.Complete, ""))
+	hub.Finish(doc)
+
+	replay, sub := hub.Subscribe(doc, 0)
+	defer sub.Cancel()
+
+	if len(replay) != 2 {
+		t.Fatalf("replay length = %d, want 2", len(replay))
+	}
+	if _, ok := <-sub.Events(); ok {
+		t.Error("subscription open for finished run, want closed")
+	}
+}
+
+func TestHubBeginResetsBufferAndKeepsSubscribers(t *testing.T) {
+	hub := workflow.NewHub(8, time.Minute)
+	doc := uuid.New()
+
+	hub.Begin(doc)
+	hub.Publish(doc, nodeEvent(workflow.NodeStart, "init"))
+
+	_, sub := hub.Subscribe(doc, 0)
+	defer sub.Cancel()
+
+	hub.Begin(doc)
+	hub.Publish(doc, nodeEvent(workflow.NodeStart, "classify"))
+
+	if e := receive(t, sub); e.Data["node"] != "classify" {
+		t.Errorf("node = %v, want classify", e.Data["node"])
+	}
+
+	replay, other := hub.Subscribe(doc, 0)
+	defer other.Cancel()
+
+	if len(replay) != 1 {
+		t.Errorf("replay length = %d, want 1 after Begin", len(replay))
+	}
+}
+
+func TestHubBufferKeepsMostRecent(t *testing.T) {
+	hub := workflow.NewHub(2, time.Minute)
+	doc := uuid.New()
+
+	hub.Begin(doc)
+	hub.Publish(doc, nodeEvent(workflow.NodeStart, "init"))
+	hub.Publish(doc, nodeEvent(workflow.NodeStart, "classify"))
+	hub.Publish(doc, nodeEvent(workflow.NodeStart, "finalize"))
+
+	replay, sub := hub.Subscribe(doc, 0)
+	defer sub.Cancel()
+
+	if len(replay) != 2 {
+		t.Fatalf("replay length = %d, want 2", len(replay))
+	}
+	if replay[0].Data["node"] != "classify" {
+		t.Errorf("oldest buffered node = %v, want classify", replay[0].Data["node"])
+	}
+}
+
+func TestHubSlowSubscriberIsClosed(t *testing.T) {
+	hub := workflow.NewHub(1, time.Minute)
+	doc := uuid.New()
+
+	hub.Begin(doc)
+	_, sub := hub.Subscribe(doc, 0)
+	defer sub.Cancel()
+
+	hub.Publish(doc, nodeEvent(workflow.NodeStart, "init"))
+	hub.Publish(doc, nodeEvent(workflow.NodeStart, "classify"))
+
+	receive(t, sub)
+	if _, ok := <-sub.Events(); ok {
+		t.Error("slow subscription open, want closed")
+	}
+}
+
+func TestHubRetentionEvictsFinishedRun(t *testing.T) {
+	hub := workflow.NewHub(8, 10*time.Millisecond)
+	doc := uuid.New()
+
+	hub.Begin(doc)
+	hub.Publish(doc, nodeEvent(workflow.Complete, ""))
+	hub.Finish(doc)
+
+	if !hub.Active(doc) {
+		t.Fatal("Active = false immediately after Finish, want true")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for hub.Active(doc) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if hub.Active(doc) {
+		t.Error("Active = true after retention, want false")
+	}
+}
+
+func TestHubCancelIsIdempotent(t *testing.T) {
+	hub := workflow.NewHub(8, time.Minute)
+	doc := uuid.New()
+
+	_, sub := hub.Subscribe(doc, 0)
+	sub.Cancel()
+	sub.Cancel()
+
+	if hub.Active(doc) {
+		t.Error("Active = true after last waiting subscriber cancelled, want false")
+	}
+}
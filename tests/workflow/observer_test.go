@@ -4,12 +4,15 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/tailored-agentic-units/orchestrate/observability"
 	"github.com/tailored-agentic-units/orchestrate/state"
 
+	"github.com/JaimeStill/herald/internal/metrics"
 	"github.com/JaimeStill/herald/internal/workflow"
 )
 
@@ -266,3 +269,41 @@ func TestNonBlockingSendOnFullBuffer(t *testing.T) {
 		t.Error("expected first event in buffer")
 	}
 }
+
+func TestOnEventRecordsNodeMetrics(t *testing.T) {
+	obs := workflow.NewStreamingObserver(8, testLogger)
+	defer obs.Close()
+
+	scrape := func() string {
+		rec := httptest.NewRecorder()
+		metrics.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		return rec.Body.String()
+	}
+
+	start := time.Now()
+	obs.OnEvent(context.Background(), observability.Event{
+		Type:      state.EventNodeStart,
+		Timestamp: start,
+		Data:      map[string]any{"node": "observer-metrics", "iteration": float64(1)},
+	})
+	obs.OnEvent(context.Background(), observability.Event{
+		Type:      state.EventNodeComplete,
+		Timestamp: start.Add(3 * time.Second),
+		Data: map[string]any{
+			"node":          "observer-metrics",
+			"iteration":     float64(1),
+			"error":         true,
+			"error_message": "render failed",
+		},
+	})
+
+	body := scrape()
+	for _, want := range []string{
+		`herald_workflow_node_duration_seconds_sum{node="observer-metrics"} 3`,
+		`herald_workflow_node_errors_total{node="observer-metrics"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+}
@@ -51,6 +51,8 @@ func (m *mockPrompts) Spec(_ context.Context, stage prompts.Stage) (string, erro
 	return text, nil
 }
 
+func (m *mockPrompts) Versions(context.Context) ([]prompts.Version, error) { return nil, nil }
+
 func newMockPrompts() *mockPrompts {
 	return &mockPrompts{
 		instructions: map[prompts.Stage]string{
@@ -120,6 +122,23 @@ func TestComposePrompt(t *testing.T) {
 		}
 	})
 
+	t.Run("serialized state omits page text and margins", func(t *testing.T) {
+		state := &state.ClassificationState{
+			Pages: []state.ClassificationPage{
+				{PageNumber: 1, Text: "layer text", Margins: "Header: margin text"},
+			},
+		}
+
+		got, err := workflow.ComposePrompt(ctx, mock, prompts.StageFinalize, state)
+		if err != nil {
+			t.Fatalf("ComposePrompt error: %v", err)
+		}
+
+		if strings.Contains(got, "layer text") || strings.Contains(got, "margin text") {
+			t.Error("serialized state should not carry page text or margins")
+		}
+	})
+
 	t.Run("enhance stage uses enhance instructions and spec", func(t *testing.T) {
 		got, err := workflow.ComposePrompt(ctx, mock, prompts.StageEnhance, nil)
 		if err != nil {
@@ -0,0 +1,69 @@
+package workflow_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/JaimeStill/herald/internal/markings"
+	"github.com/JaimeStill/herald/internal/workflow"
+)
+
+func TestTextMarkings(t *testing.T) {
+	p := markings.NewParser(markings.DefaultTaxonomy())
+
+	tests := []struct {
+		name   string
+		text   string
+		want   []string
+		wantOK bool
+	}{
+		{
+			name:   "banner matches portions",
+			text:   "SECRET//NOFORN\n(U) Routine summary.\n(S//NF) Troop movements.\n(U) Closing.\nSECRET//NOFORN",
+			want:   []string{"SECRET//NOFORN", "(U)", "(S//NF)"},
+			wantOK: true,
+		},
+		{
+			name:   "lower-case asides are not portions",
+			text:   "CONFIDENTIAL\n(C) See item (a) and document(s).\nCONFIDENTIAL",
+			want:   []string{"CONFIDENTIAL", "(C)"},
+			wantOK: true,
+		},
+		{
+			name: "banners differ",
+			text: "SECRET\n(S) Body.\nCONFIDENTIAL",
+		},
+		{
+			name: "portion above banner",
+			text: "CONFIDENTIAL\n(S) Body.\nCONFIDENTIAL",
+		},
+		{
+			name: "portions below banner",
+			text: "SECRET//NOFORN\n(U) Body.\nSECRET//NOFORN",
+		},
+		{
+			name: "malformed portion",
+			text: "SECRET\n(S//BOGUS) Body.\nSECRET",
+		},
+		{
+			name: "no portions",
+			text: "SECRET\nBody text.\nSECRET",
+		},
+		{
+			name: "no banner",
+			text: "(S) Body.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := workflow.TextMarkings(p, tt.text)
+			if ok != tt.wantOK {
+				t.Fatalf("ok: got %v, want %v", ok, tt.wantOK)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("markings: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,644 @@
+package workflow_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/JaimeStill/herald/internal/agents"
+	"github.com/JaimeStill/herald/internal/documents"
+	"github.com/JaimeStill/herald/internal/format"
+	"github.com/JaimeStill/herald/internal/markings"
+	"github.com/JaimeStill/herald/internal/state"
+	"github.com/JaimeStill/herald/internal/workflow"
+	"github.com/JaimeStill/herald/pkg/lifecycle"
+	"github.com/JaimeStill/herald/pkg/pagination"
+	"github.com/JaimeStill/herald/pkg/storage"
+)
+
+// mockDocuments serves documents registered by upload from memory.
+type mockDocuments struct {
+	docs map[uuid.UUID]*documents.Document
+}
+
+func (m *mockDocuments) Handler(int64) *documents.Handler { return nil }
+func (m *mockDocuments) List(context.Context, pagination.PageRequest, documents.Filters) (*pagination.PageResult[documents.Document], error) {
+	return nil, nil
+}
+func (m *mockDocuments) Find(_ context.Context, id uuid.UUID) (*documents.Document, error) {
+	doc, ok := m.docs[id]
+	if !ok {
+		return nil, documents.ErrNotFound
+	}
+	return doc, nil
+}
+func (m *mockDocuments) Create(context.Context, documents.CreateCommand) (*documents.Document, error) {
+	return nil, nil
+}
+func (m *mockDocuments) Delete(context.Context, uuid.UUID) error { return nil }
+func (m *mockDocuments) UpdatePageCount(_ context.Context, id uuid.UUID, count int) error {
+	doc, ok := m.docs[id]
+	if !ok {
+		return documents.ErrNotFound
+	}
+	doc.PageCount = &count
+	return nil
+}
+
+// projectPath resolves a path under _project/ relative to the repository root.
+func projectPath(t *testing.T, relative string) string {
+	t.Helper()
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	for {
+		candidate := filepath.Join(dir, relative)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			t.Fatalf("could not resolve %s", relative)
+		}
+		dir = parent
+	}
+}
+
+// replayRuntime builds a workflow runtime backed by filesystem storage and a
+// replay agent serving fixtures from dir without the default fallback.
+func replayRuntime(t *testing.T, dir string) (*workflow.Runtime, *mockDocuments) {
+	t.Helper()
+
+	logger := slog.New(slog.DiscardHandler)
+
+	store, err := storage.New(&storage.Config{
+		Backend:       storage.BackendFilesystem,
+		Root:          t.TempDir(),
+		ContainerName: "documents",
+	}, logger)
+	if err != nil {
+		t.Fatalf("storage.New() error = %v", err)
+	}
+	lc := lifecycle.New()
+	if err := store.Start(lc); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	lc.WaitForStartup()
+
+	replay := agents.NewReplay(dir, "replay-model", false)
+	docs := &mockDocuments{docs: make(map[uuid.UUID]*documents.Document)}
+
+	return &workflow.Runtime{
+		NewAgent: func(context.Context) (agents.Agent, error) {
+			return replay, nil
+		},
+		Model:     replay.Model(),
+		Provider:  replay.Provider(),
+		Storage:   store,
+		Documents: docs,
+		Prompts:   newMockPrompts(),
+		Formats:   format.NewRegistry(format.NewPDFHandler(format.Options{}), format.NewImageHandler(format.Options{})),
+		Markings:  markings.NewParser(markings.DefaultTaxonomy()),
+		Logger:    logger,
+	}, docs
+}
+
+// upload stores the file at path and registers it as a document.
+func upload(t *testing.T, rt *workflow.Runtime, docs *mockDocuments, path, contentType string) uuid.UUID {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer f.Close()
+
+	id := uuid.New()
+	key := "documents/" + id.String() + "/" + filepath.Base(path)
+	if err := rt.Storage.Upload(context.Background(), key, f, contentType); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	docs.docs[id] = &documents.Document{
+		ID:          id,
+		Filename:    filepath.Base(path),
+		ContentType: contentType,
+		StorageKey:  key,
+	}
+	return id
+}
+
+func execute(t *testing.T, rt *workflow.Runtime, id uuid.UUID) (*workflow.WorkflowResult, error) {
+	t.Helper()
+	return executeRun(t, rt, uuid.New(), id)
+}
+
+func executeRun(t *testing.T, rt *workflow.Runtime, runID, id uuid.UUID) (*workflow.WorkflowResult, error) {
+	t.Helper()
+
+	observer := workflow.NewStreamingObserver(64, rt.Logger)
+	defer observer.Close()
+
+	return workflow.Execute(context.Background(), rt, runID, id, observer)
+}
+
+func TestExecuteReplay(t *testing.T) {
+	fixtures := projectPath(t, "_project/marked-documents/replay")
+	corpus := projectPath(t, "_project/marked-documents")
+
+	tests := []struct {
+		name           string
+		file           string
+		contentType    string
+		magick         bool
+		fallback       bool
+		classification string
+		confidence     state.Confidence
+		rollUp         string
+		enhanced       []int
+	}{
+		{
+			name:           "marked image",
+			file:           "images/marked-document.3.png",
+			contentType:    "image/png",
+			classification: "SECRET//NOFORN//20280901",
+			confidence:     state.ConfidenceHigh,
+			rollUp:         "SECRET//NOFORN//20280901",
+		},
+		{
+			name:           "unrecorded image uses default fixture when enabled",
+			file:           "images/marked-document.2.png",
+			contentType:    "image/png",
+			fallback:       true,
+			classification: "UNCLASSIFIED",
+			confidence:     state.ConfidenceMedium,
+		},
+		{
+			name:           "escalation with enhancement",
+			file:           "escalation-unclass-to-secret.pdf",
+			contentType:    "application/pdf",
+			magick:         true,
+			classification: "SECRET",
+			confidence:     state.ConfidenceHigh,
+			rollUp:         "SECRET",
+			enhanced:       []int{3},
+		},
+		{
+			name:           "legacy declassification marking",
+			file:           "single-secret-noforn-x1.pdf",
+			contentType:    "application/pdf",
+			magick:         true,
+			classification: "SECRET//NOFORN",
+			confidence:     state.ConfidenceHigh,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.magick {
+				if _, err := exec.LookPath("magick"); err != nil {
+					t.Skip("skipping: magick not on PATH")
+				}
+			}
+
+			rt, docs := replayRuntime(t, fixtures)
+			if tt.fallback {
+				replay := agents.NewReplay(fixtures, "replay-model", true)
+				rt.NewAgent = func(context.Context) (agents.Agent, error) { return replay, nil }
+			}
+			id := upload(t, rt, docs, filepath.Join(corpus, tt.file), tt.contentType)
+
+			result, err := execute(t, rt, id)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			cs := result.State
+			if cs.Classification != tt.classification {
+				t.Errorf("Classification = %q, want %q", cs.Classification, tt.classification)
+			}
+			if cs.Confidence != tt.confidence {
+				t.Errorf("Confidence = %q, want %q", cs.Confidence, tt.confidence)
+			}
+			if cs.RollUp != tt.rollUp {
+				t.Errorf("RollUp = %q, want %q", cs.RollUp, tt.rollUp)
+			}
+			if cs.Discrepancy != "" {
+				t.Errorf("Discrepancy = %q, want none", cs.Discrepancy)
+			}
+			if result.Filename != filepath.Base(tt.file) {
+				t.Errorf("Filename = %q, want %q", result.Filename, filepath.Base(tt.file))
+			}
+
+			var enhanced []int
+			for _, page := range cs.Pages {
+				if page.EnhancedWith != nil {
+					enhanced = append(enhanced, page.PageNumber)
+				}
+			}
+			if len(enhanced) != len(tt.enhanced) {
+				t.Errorf("enhanced pages = %v, want %v", enhanced, tt.enhanced)
+			}
+		})
+	}
+}
+
+func TestExecuteUpdatesPageCount(t *testing.T) {
+	path := projectPath(t, "_project/marked-documents/images/marked-document.3.png")
+
+	tests := []struct {
+		name   string
+		stored *int
+	}{
+		{"missing count", nil},
+		{"stale count", new(int)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt, docs := replayRuntime(t, projectPath(t, "_project/marked-documents/replay"))
+			id := upload(t, rt, docs, path, "image/png")
+			docs.docs[id].PageCount = tt.stored
+
+			if _, err := execute(t, rt, id); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if got := docs.docs[id].PageCount; got == nil || *got != 1 {
+				t.Errorf("PageCount = %v, want 1", got)
+			}
+		})
+	}
+}
+
+func TestExecuteReplayUsage(t *testing.T) {
+	rt, docs := replayRuntime(t, projectPath(t, "_project/marked-documents/replay"))
+	path := projectPath(t, "_project/marked-documents/images/marked-document.3.png")
+	id := upload(t, rt, docs, path, "image/png")
+
+	result, err := execute(t, rt, id)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	cs := result.State
+	page := state.Usage{VisionCalls: 1, PromptTokens: 1105, CompletionTokens: 62}
+	if cs.Pages[0].Usage != page {
+		t.Errorf("page usage = %+v, want %+v", cs.Pages[0].Usage, page)
+	}
+
+	finalize := state.Usage{ChatCalls: 1, PromptTokens: 848, CompletionTokens: 41}
+	if cs.Usage != finalize {
+		t.Errorf("document usage = %+v, want %+v", cs.Usage, finalize)
+	}
+
+	total := state.Usage{VisionCalls: 1, ChatCalls: 1, PromptTokens: 1953, CompletionTokens: 103}
+	if got := cs.TotalUsage(); got != total {
+		t.Errorf("TotalUsage() = %+v, want %+v", got, total)
+	}
+}
+
+func TestExecuteReplayMissingFixture(t *testing.T) {
+	rt, docs := replayRuntime(t, projectPath(t, "_project/marked-documents/replay"))
+	path := projectPath(t, "_project/marked-documents/images/marked-document.2.png")
+	id := upload(t, rt, docs, path, "image/png")
+
+	_, err := execute(t, rt, id)
+	if !errors.Is(err, agents.ErrFixtureNotFound) {
+		t.Fatalf("Execute() error = %v, want ErrFixtureNotFound", err)
+	}
+	if !strings.Contains(err.Error(), "marked-document.2.png/classify/1") {
+		t.Errorf("error %q does not identify the call", err)
+	}
+}
+
+// failingAgent fails the vision call for one page and defers every other
+// call to the wrapped agent.
+type failingAgent struct {
+	agents.Agent
+	page int
+}
+
+func (f *failingAgent) Vision(ctx context.Context, prompt string, image []byte) (agents.Response, error) {
+	if call, ok := agents.CallFrom(ctx); ok && call.Page == f.page {
+		return agents.Response{}, errors.New("status 400: invalid image")
+	}
+	return f.Agent.Vision(ctx, prompt, image)
+}
+
+func TestExecutePartialPages(t *testing.T) {
+	if _, err := exec.LookPath("magick"); err != nil {
+		t.Skip("skipping: magick not on PATH")
+	}
+
+	corpus := projectPath(t, "_project/marked-documents")
+	path := filepath.Join(corpus, "escalation-unclass-to-secret.pdf")
+
+	partial := func(t *testing.T, enabled bool) (*workflow.WorkflowResult, error) {
+		rt, docs := replayRuntime(t, filepath.Join(corpus, "replay"))
+		replay, _ := rt.NewAgent(context.Background())
+		rt.NewAgent = func(context.Context) (agents.Agent, error) {
+			return &failingAgent{Agent: replay, page: 3}, nil
+		}
+		rt.PartialPages = enabled
+
+		id := upload(t, rt, docs, path, "application/pdf")
+		return execute(t, rt, id)
+	}
+
+	t.Run("disabled fails the run", func(t *testing.T) {
+		_, err := partial(t, false)
+		if !errors.Is(err, workflow.ErrClassifyFailed) {
+			t.Fatalf("Execute() error = %v, want ErrClassifyFailed", err)
+		}
+	})
+
+	t.Run("enabled records the failed page", func(t *testing.T) {
+		result, err := partial(t, true)
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+
+		cs := result.State
+		if len(cs.FailedPages) != 1 || cs.FailedPages[0] != 3 {
+			t.Errorf("FailedPages = %v, want [3]", cs.FailedPages)
+		}
+		if cs.Confidence != state.ConfidenceLow {
+			t.Errorf("Confidence = %q, want %q", cs.Confidence, state.ConfidenceLow)
+		}
+		if cs.Classification != "SECRET" {
+			t.Errorf("Classification = %q, want SECRET", cs.Classification)
+		}
+
+		page := cs.Pages[2]
+		if !strings.Contains(page.Error, "invalid image") {
+			t.Errorf("page 3 error = %q, want the vision failure", page.Error)
+		}
+		if page.EnhancedWith != nil {
+			t.Errorf("page 3 EnhancedWith = %+v, want nil", page.EnhancedWith)
+		}
+		if len(cs.Pages[3].MarkingsFound) == 0 {
+			t.Error("page 4 was not classified")
+		}
+	})
+}
+
+func TestExecutePartialPagesAllFailed(t *testing.T) {
+	rt, docs := replayRuntime(t, projectPath(t, "_project/marked-documents/replay"))
+	replay, _ := rt.NewAgent(context.Background())
+	rt.NewAgent = func(context.Context) (agents.Agent, error) {
+		return &failingAgent{Agent: replay, page: 1}, nil
+	}
+	rt.PartialPages = true
+
+	path := projectPath(t, "_project/marked-documents/images/marked-document.3.png")
+	id := upload(t, rt, docs, path, "image/png")
+
+	_, err := execute(t, rt, id)
+	if !errors.Is(err, workflow.ErrClassifyFailed) {
+		t.Fatalf("Execute() error = %v, want ErrClassifyFailed", err)
+	}
+	if !strings.Contains(err.Error(), "all 1 pages failed") {
+		t.Errorf("error %q does not report that every page failed", err)
+	}
+}
+
+// memoryCheckpoints keeps every checkpoint saved, encoded as JSON the way the
+// database stores them, and loads the latest for a run.
+type memoryCheckpoints struct {
+	mu    sync.Mutex
+	saved map[uuid.UUID][][]byte
+}
+
+func newMemoryCheckpoints() *memoryCheckpoints {
+	return &memoryCheckpoints{saved: make(map[uuid.UUID][][]byte)}
+}
+
+func (m *memoryCheckpoints) Load(_ context.Context, runID uuid.UUID) (*workflow.Checkpoint, error) {
+	history := m.history(runID)
+	if len(history) == 0 {
+		return nil, workflow.ErrNoCheckpoint
+	}
+	cp := history[len(history)-1]
+	return &cp, nil
+}
+
+func (m *memoryCheckpoints) Save(_ context.Context, cp *workflow.Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.saved[cp.RunID] = append(m.saved[cp.RunID], data)
+	return nil
+}
+
+func (m *memoryCheckpoints) history(runID uuid.UUID) []workflow.Checkpoint {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var history []workflow.Checkpoint
+	for _, data := range m.saved[runID] {
+		var cp workflow.Checkpoint
+		if err := json.Unmarshal(data, &cp); err != nil {
+			panic(err)
+		}
+		history = append(history, cp)
+	}
+	return history
+}
+
+// countingAgent counts the calls made through it.
+type countingAgent struct {
+	agents.Agent
+	vision atomic.Int32
+	chat   atomic.Int32
+}
+
+func (c *countingAgent) Vision(ctx context.Context, prompt string, image []byte) (agents.Response, error) {
+	c.vision.Add(1)
+	return c.Agent.Vision(ctx, prompt, image)
+}
+
+func (c *countingAgent) Chat(ctx context.Context, prompt string) (agents.Response, error) {
+	c.chat.Add(1)
+	return c.Agent.Chat(ctx, prompt)
+}
+
+func TestExecuteCheckpointResume(t *testing.T) {
+	fixtures := projectPath(t, "_project/marked-documents/replay")
+	path := projectPath(t, "_project/marked-documents/images/marked-document.3.png")
+
+	rt, docs := replayRuntime(t, fixtures)
+	store := newMemoryCheckpoints()
+	rt.Checkpoints = store
+	id := upload(t, rt, docs, path, "image/png")
+
+	runID := uuid.New()
+	first, err := executeRun(t, rt, runID, id)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	history := store.history(runID)
+	var nodes []string
+	for _, cp := range history {
+		nodes = append(nodes, cp.Node)
+	}
+	if want := []string{"init", "init", "classify", "finalize"}; !slices.Equal(nodes, want) {
+		t.Fatalf("checkpoint nodes = %v, want %v", nodes, want)
+	}
+	if got := history[1].ClassifiedPages; !slices.Equal(got, []int{1}) {
+		t.Errorf("page checkpoint ClassifiedPages = %v, want [1]", got)
+	}
+
+	tests := []struct {
+		name       string
+		checkpoint workflow.Checkpoint
+		vision     int32
+		chat       int32
+	}{
+		{"after init", history[0], 1, 1},
+		{"mid classify", history[1], 0, 1},
+		{"after classify", history[2], 0, 1},
+		{"after finalize", history[3], 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resumeStore := newMemoryCheckpoints()
+			resumeStore.Save(context.Background(), &tt.checkpoint)
+
+			replay, _ := replayRuntime(t, fixtures)
+			counter := &countingAgent{Agent: agents.NewReplay(fixtures, "replay-model", false)}
+			replay.NewAgent = func(context.Context) (agents.Agent, error) { return counter, nil }
+			replay.Storage = rt.Storage
+			replay.Documents = docs
+			replay.Checkpoints = resumeStore
+
+			result, err := executeRun(t, replay, runID, id)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if got := counter.vision.Load(); got != tt.vision {
+				t.Errorf("vision calls = %d, want %d", got, tt.vision)
+			}
+			if got := counter.chat.Load(); got != tt.chat {
+				t.Errorf("chat calls = %d, want %d", got, tt.chat)
+			}
+			if result.State.Classification != first.State.Classification {
+				t.Errorf("Classification = %q, want %q", result.State.Classification, first.State.Classification)
+			}
+			if got, want := result.State.TotalUsage(), first.State.TotalUsage(); got != want {
+				t.Errorf("TotalUsage() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestExecuteCheckpointWrongDocument(t *testing.T) {
+	rt, docs := replayRuntime(t, projectPath(t, "_project/marked-documents/replay"))
+	store := newMemoryCheckpoints()
+	rt.Checkpoints = store
+
+	path := projectPath(t, "_project/marked-documents/images/marked-document.3.png")
+	id := upload(t, rt, docs, path, "image/png")
+
+	runID := uuid.New()
+	store.Save(context.Background(), &workflow.Checkpoint{RunID: runID, DocumentID: uuid.New(), Node: "init"})
+
+	if _, err := executeRun(t, rt, runID, id); err == nil {
+		t.Fatal("Execute() succeeded, want error for a checkpoint of another document")
+	}
+}
+
+func TestExecuteEscalation(t *testing.T) {
+	fixtures := projectPath(t, "_project/marked-documents/replay")
+	path := projectPath(t, "_project/marked-documents/images/marked-document.3.png")
+
+	run := func(t *testing.T, threshold state.Confidence) (*workflow.WorkflowResult, *countingAgent, []string) {
+		rt, docs := replayRuntime(t, fixtures)
+		counter := &countingAgent{Agent: agents.NewReplay(fixtures, "stronger-model", false)}
+		rt.Escalation = &workflow.Escalation{
+			Agent: agents.Entry{
+				Name:     "stronger",
+				Model:    "stronger-model",
+				Provider: "replay",
+				New:      func(context.Context) (agents.Agent, error) { return counter, nil },
+			},
+			Threshold: threshold,
+		}
+		store := newMemoryCheckpoints()
+		rt.Checkpoints = store
+		id := upload(t, rt, docs, path, "image/png")
+
+		runID := uuid.New()
+		result, err := executeRun(t, rt, runID, id)
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+
+		var nodes []string
+		for _, cp := range store.history(runID) {
+			nodes = append(nodes, cp.Node)
+		}
+		return result, counter, nodes
+	}
+
+	t.Run("result above threshold is kept", func(t *testing.T) {
+		result, counter, nodes := run(t, state.ConfidenceMedium)
+
+		if result.State.Escalation != nil {
+			t.Errorf("Escalation = %+v, want nil", result.State.Escalation)
+		}
+		if slices.Contains(nodes, "escalate") {
+			t.Errorf("nodes = %v, want no escalate", nodes)
+		}
+		if got := counter.vision.Load() + counter.chat.Load(); got != 0 {
+			t.Errorf("escalation agent calls = %d, want 0", got)
+		}
+	})
+
+	t.Run("result at threshold escalates", func(t *testing.T) {
+		result, counter, nodes := run(t, state.ConfidenceHigh)
+
+		if !slices.Contains(nodes, "escalate") {
+			t.Errorf("nodes = %v, want escalate", nodes)
+		}
+		if counter.vision.Load() != 1 || counter.chat.Load() != 1 {
+			t.Errorf("escalation agent calls = %d vision, %d chat, want 1 each",
+				counter.vision.Load(), counter.chat.Load())
+		}
+
+		esc := result.State.Escalation
+		if esc == nil {
+			t.Fatal("Escalation = nil")
+		}
+		if esc.Agent != "stronger" || esc.Model != "stronger-model" {
+			t.Errorf("Escalation agent = %s (%s), want stronger (stronger-model)", esc.Agent, esc.Model)
+		}
+		if esc.Classification == "" || esc.Usage.VisionCalls != 1 || esc.Usage.ChatCalls != 1 {
+			t.Errorf("Escalation = %+v, want the first answer and its usage", esc)
+		}
+		if got := result.State.TotalUsage(); got.VisionCalls != 1 || got.ChatCalls != 1 {
+			t.Errorf("TotalUsage() = %+v, want only the escalated attempt", got)
+		}
+		if len(result.State.Pages[0].MarkingsFound) == 0 {
+			t.Error("escalated attempt did not classify the page")
+		}
+	})
+}